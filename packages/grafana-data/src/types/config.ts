@@ -39,6 +39,7 @@ export interface FeatureToggles {
    */
   meta: boolean;
   newVariables: boolean;
+  live: boolean;
 }
 
 /**
@@ -85,6 +86,7 @@ export interface GrafanaConfig {
   verifyEmailEnabled: boolean;
   oauth: any;
   disableUserSignUp: boolean;
+  twoFactorEnabled: boolean;
   loginHint: any;
   passwordHint: any;
   loginError: any;
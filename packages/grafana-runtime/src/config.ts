@@ -38,6 +38,7 @@ export class GrafanaBootConfig implements GrafanaConfig {
   verifyEmailEnabled = false;
   oauth: any;
   disableUserSignUp = false;
+  twoFactorEnabled = false;
   loginHint: any;
   passwordHint: any;
   loginError: any;
@@ -53,6 +54,7 @@ export class GrafanaBootConfig implements GrafanaConfig {
     newEdit: false,
     meta: false,
     newVariables: true,
+    live: false,
   };
   licenseInfo: LicenseInfo = {} as LicenseInfo;
   rendererAvailable = false;
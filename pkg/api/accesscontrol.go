@@ -0,0 +1,204 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+// GET /api/access-control/permissions
+func GetAccessControlActions(c *models.ReqContext) Response {
+	return JSON(200, accesscontrol.Actions())
+}
+
+// GET /api/access-control/user/permissions
+func GetSignedInUserPermissions(c *models.ReqContext) Response {
+	permissions, err := accesscontrol.ContextGetPermissions(c)
+	if err != nil {
+		return Error(500, "Failed to get permissions", err)
+	}
+
+	return JSON(200, permissions)
+}
+
+// GET /api/access-control/roles
+func GetRoles(c *models.ReqContext) Response {
+	query := models.GetRolesQuery{OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return Error(500, "Failed to get roles", err)
+	}
+
+	return JSON(200, query.Result)
+}
+
+// GET /api/access-control/roles/:roleId
+func GetRoleByID(c *models.ReqContext) Response {
+	query := models.GetRoleByIdQuery{Id: c.ParamsInt64(":roleId"), OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		if err == models.ErrRoleNotFound {
+			return Error(404, "Role not found", err)
+		}
+		return Error(500, "Failed to get role", err)
+	}
+
+	return JSON(200, query.Result)
+}
+
+// POST /api/access-control/roles
+func CreateRole(c *models.ReqContext, cmd models.CreateRoleCommand) Response {
+	cmd.OrgId = c.OrgId
+
+	if resp := validateRolePermissions(c, cmd.Permissions); resp != nil {
+		return resp
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == models.ErrRoleWithSameNameExists {
+			return Error(409, "A role with the same name already exists", err)
+		}
+		return Error(500, "Failed to create role", err)
+	}
+
+	return JSON(200, &util.DynMap{
+		"id":      cmd.Result.Id,
+		"message": "Role created",
+		"role":    cmd.Result,
+	})
+}
+
+// PUT /api/access-control/roles/:roleId
+func UpdateRole(c *models.ReqContext, cmd models.UpdateRoleCommand) Response {
+	cmd.OrgId = c.OrgId
+	cmd.Id = c.ParamsInt64(":roleId")
+
+	if resp := validateRolePermissions(c, cmd.Permissions); resp != nil {
+		return resp
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == models.ErrRoleNotFound {
+			return Error(404, "Role not found", err)
+		}
+		if err == models.ErrRoleWithSameNameExists {
+			return Error(409, "A role with the same name already exists", err)
+		}
+		return Error(500, "Failed to update role", err)
+	}
+
+	return Success("Role updated")
+}
+
+// DELETE /api/access-control/roles/:roleId
+func DeleteRole(c *models.ReqContext) Response {
+	cmd := models.DeleteRoleCommand{Id: c.ParamsInt64(":roleId"), OrgId: c.OrgId}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == models.ErrRoleNotFound {
+			return Error(404, "Role not found", err)
+		}
+		return Error(500, "Failed to delete role", err)
+	}
+
+	return Success("Role deleted")
+}
+
+// GET /api/access-control/roles/:roleId/assignments
+func GetRoleAssignments(c *models.ReqContext) Response {
+	roleQuery := models.GetRoleByIdQuery{Id: c.ParamsInt64(":roleId"), OrgId: c.OrgId}
+	if err := bus.Dispatch(&roleQuery); err != nil {
+		if err == models.ErrRoleNotFound {
+			return Error(404, "Role not found", err)
+		}
+		return Error(500, "Failed to get role", err)
+	}
+
+	query := models.GetRoleAssignmentsQuery{RoleId: roleQuery.Result.Id, OrgId: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return Error(500, "Failed to get role assignments", err)
+	}
+
+	return JSON(200, query.Result)
+}
+
+// POST /api/access-control/roles/:roleId/assignments
+func AddRoleAssignment(c *models.ReqContext, cmd models.AddRoleAssignmentCommand) Response {
+	cmd.OrgId = c.OrgId
+	cmd.RoleId = c.ParamsInt64(":roleId")
+
+	if !cmd.SubjectType.IsValid() {
+		return Error(400, "Invalid subject type, expected one of user, team or api_key", nil)
+	}
+
+	roleQuery := models.GetRoleByIdQuery{Id: cmd.RoleId, OrgId: c.OrgId}
+	if err := bus.Dispatch(&roleQuery); err != nil {
+		if err == models.ErrRoleNotFound {
+			return Error(404, "Role not found", err)
+		}
+		return Error(500, "Failed to get role", err)
+	}
+
+	if resp := validateRolePermissions(c, roleQuery.Result.Permissions); resp != nil {
+		return resp
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		switch err {
+		case models.ErrRoleNotFound:
+			return Error(404, "Role not found", err)
+		case models.ErrOrgUserNotFound, models.ErrTeamNotFound, models.ErrInvalidApiKey:
+			return Error(404, "Subject not found", err)
+		case models.ErrRoleAssignmentExists:
+			return Error(409, "Role is already assigned", err)
+		}
+		return Error(500, "Failed to assign role", err)
+	}
+
+	return JSON(200, &util.DynMap{
+		"id":      cmd.Result.Id,
+		"message": "Role assigned",
+	})
+}
+
+// DELETE /api/access-control/roles/:roleId/assignments/:assignmentId
+func RemoveRoleAssignment(c *models.ReqContext) Response {
+	cmd := models.RemoveRoleAssignmentCommand{
+		Id:     c.ParamsInt64(":assignmentId"),
+		RoleId: c.ParamsInt64(":roleId"),
+		OrgId:  c.OrgId,
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == models.ErrRoleAssignmentNotFound {
+			return Error(404, "Role assignment not found", err)
+		}
+		return Error(500, "Failed to remove role assignment", err)
+	}
+
+	return Success("Role assignment removed")
+}
+
+// validateRolePermissions makes sure roles only contain known permissions
+// and that users can't grant permissions they don't have themselves.
+func validateRolePermissions(c *models.ReqContext, permissions []string) Response {
+	granted, err := accesscontrol.ContextGetPermissions(c)
+	if err != nil {
+		return Error(500, "Failed to get permissions", err)
+	}
+
+	held := make(map[string]bool, len(granted))
+	for _, permission := range granted {
+		held[permission] = true
+	}
+
+	for _, permission := range permissions {
+		if !accesscontrol.IsValidAction(permission) {
+			return Error(400, "Invalid permission "+permission, models.ErrRoleInvalidPermission)
+		}
+
+		if !held[permission] {
+			return Error(403, "Not allowed to grant permission "+permission, nil)
+		}
+	}
+
+	return nil
+}
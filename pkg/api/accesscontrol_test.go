@@ -0,0 +1,62 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddRoleAssignment(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+
+	roles := map[int64][]string{
+		1: {accesscontrol.ActionDatasourcesRead},
+		2: {accesscontrol.ActionRolesWrite, accesscontrol.ActionDatasourcesWrite},
+	}
+	bus.AddHandler("test", func(query *models.GetRoleByIdQuery) error {
+		permissions, ok := roles[query.Id]
+		if !ok {
+			return models.ErrRoleNotFound
+		}
+		query.Result = &models.RoleDTO{Id: query.Id, OrgId: query.OrgId, Permissions: permissions}
+		return nil
+	})
+	bus.AddHandler("test", func(query *models.GetCustomPermissionsQuery) error {
+		// the assigner may manage roles, but doesn't hold datasources:write
+		query.Result = []string{accesscontrol.ActionRolesWrite, accesscontrol.ActionDatasourcesRead}
+		return nil
+	})
+	assigned := 0
+	bus.AddHandler("test", func(cmd *models.AddRoleAssignmentCommand) error {
+		assigned++
+		cmd.Result = &models.RoleAssignment{Id: 1}
+		return nil
+	})
+
+	assign := func(roleID string) int {
+		sc := setupScenarioContext("/api/access-control/roles/" + roleID + "/assignments")
+		sc.m.Post("/api/access-control/roles/:roleId/assignments", Wrap(func(c *models.ReqContext) Response {
+			c.SignedInUser = &models.SignedInUser{UserId: TestUserID, OrgId: TestOrgID, OrgRole: models.ROLE_VIEWER}
+			return AddRoleAssignment(c, models.AddRoleAssignmentCommand{SubjectType: models.RoleSubjectUser, SubjectId: 2})
+		}))
+		sc.fakeReqNoAssertions("POST", sc.url).exec()
+		return sc.resp.Code
+	}
+
+	t.Run("Should assign role with permissions the assigner holds", func(t *testing.T) {
+		assert.Equal(t, 200, assign("1"))
+		assert.Equal(t, 1, assigned)
+	})
+
+	t.Run("Should not assign role with permissions the assigner doesn't hold", func(t *testing.T) {
+		assert.Equal(t, 403, assign("2"))
+		assert.Equal(t, 1, assigned)
+	})
+
+	t.Run("Should return 404 for unknown role", func(t *testing.T) {
+		assert.Equal(t, 404, assign("3"))
+	})
+}
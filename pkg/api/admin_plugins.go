@@ -0,0 +1,115 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/util"
+	"golang.org/x/xerrors"
+)
+
+// AdminInstallPlugin installs a plugin from the plugin repository.
+func (hs *HTTPServer) AdminInstallPlugin(c *models.ReqContext, cmd dtos.InstallPluginCommand) Response {
+	plugin, err := hs.PluginManager.InstallPlugin(c.Params(":pluginId"), cmd.Version)
+	if err != nil {
+		return pluginAdminError("Failed to install plugin", err)
+	}
+	return pluginAdminSuccess("Plugin installed", plugin)
+}
+
+// AdminUpgradePlugin replaces an installed plugin with a version from the plugin repository.
+func (hs *HTTPServer) AdminUpgradePlugin(c *models.ReqContext, cmd dtos.InstallPluginCommand) Response {
+	plugin, err := hs.PluginManager.UpgradePlugin(c.Params(":pluginId"), cmd.Version)
+	if err != nil {
+		return pluginAdminError("Failed to upgrade plugin", err)
+	}
+	return pluginAdminSuccess("Plugin upgraded", plugin)
+}
+
+// AdminUploadPlugin installs the plugin of the zip archive in the request body.
+func (hs *HTTPServer) AdminUploadPlugin(c *models.ReqContext) Response {
+	plugin, err := hs.PluginManager.InstallPluginArchive(c.Req.Request.Body)
+	if err != nil {
+		return pluginAdminError("Failed to install plugin", err)
+	}
+	return pluginAdminSuccess("Plugin installed", plugin)
+}
+
+// AdminReloadPlugin loads a plugin again from the plugins directory.
+func (hs *HTTPServer) AdminReloadPlugin(c *models.ReqContext) Response {
+	plugin, err := hs.PluginManager.ReloadPlugin(c.Params(":pluginId"))
+	if err != nil {
+		return pluginAdminError("Failed to reload plugin", err)
+	}
+	return pluginAdminSuccess("Plugin reloaded", plugin)
+}
+
+// AdminUninstallPlugin unloads a plugin and removes it from the plugins directory.
+func (hs *HTTPServer) AdminUninstallPlugin(c *models.ReqContext) Response {
+	if err := hs.PluginManager.UninstallPlugin(c.Params(":pluginId")); err != nil {
+		return pluginAdminError("Failed to uninstall plugin", err)
+	}
+	return Success("Plugin uninstalled")
+}
+
+// AdminGetBackendPlugins returns the process status of all backend plugins.
+func (hs *HTTPServer) AdminGetBackendPlugins(c *models.ReqContext) Response {
+	return JSON(200, hs.BackendPluginManager.PluginStatuses())
+}
+
+// AdminGetBackendPlugin returns the process status of a backend plugin.
+func (hs *HTTPServer) AdminGetBackendPlugin(c *models.ReqContext) Response {
+	status, err := hs.BackendPluginManager.PluginStatus(c.Params(":pluginId"))
+	if err != nil {
+		return backendPluginError("Failed to get backend plugin status", err)
+	}
+	return JSON(200, status)
+}
+
+// AdminRestartBackendPlugin restarts the process of a backend plugin, also when it is in a crash loop.
+func (hs *HTTPServer) AdminRestartBackendPlugin(c *models.ReqContext) Response {
+	if err := hs.BackendPluginManager.RestartPlugin(c.Params(":pluginId")); err != nil {
+		return backendPluginError("Failed to restart backend plugin", err)
+	}
+	return Success("Backend plugin restarting")
+}
+
+func backendPluginError(message string, err error) Response {
+	switch {
+	case xerrors.Is(err, backendplugin.ErrPluginNotRegistered):
+		return Error(404, "Backend plugin not found", err)
+	case xerrors.Is(err, backendplugin.ErrPluginNotRunning):
+		return Error(400, "Backend plugin is not running", err)
+	}
+	return Error(500, message, err)
+}
+
+func pluginAdminSuccess(message string, plugin *plugins.PluginBase) Response {
+	return JSON(200, util.DynMap{
+		"message": message,
+		"id":      plugin.Id,
+		"type":    plugin.Type,
+		"version": plugin.Info.Version,
+	})
+}
+
+func pluginAdminError(message string, err error) Response {
+	var notFound plugins.PluginNotFoundError
+	var invalid plugins.InvalidPluginError
+	switch {
+	case xerrors.Is(err, plugins.ErrPluginAdminDisabled):
+		return Error(403, "Plugin admin is disabled", err)
+	case xerrors.As(err, &notFound):
+		return Error(404, notFound.Error(), err)
+	case xerrors.Is(err, plugins.ErrPluginVersionNotFound):
+		return Error(404, "Plugin version not found", err)
+	case xerrors.Is(err, plugins.ErrPluginAlreadyInstalled):
+		return Error(409, "Plugin is already installed", err)
+	case xerrors.Is(err, plugins.ErrPluginNotInPluginsDir), xerrors.Is(err, plugins.ErrPluginRequiresRestart):
+		return Error(400, err.Error(), err)
+	case xerrors.As(err, &invalid):
+		return Error(400, invalid.Error(), err)
+	}
+	return Error(500, message, err)
+}
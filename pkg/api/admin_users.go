@@ -194,3 +194,35 @@ func (server *HTTPServer) AdminRevokeUserAuthToken(c *models.ReqContext, cmd mod
 	userID := c.ParamsInt64(":id")
 	return server.revokeUserAuthTokenInternal(c, userID, cmd)
 }
+
+// POST /api/admin/auth-tokens/revoke-all
+func (server *HTTPServer) AdminRevokeAllAuthTokens(c *models.ReqContext) Response {
+	if err := server.AuthTokenService.RevokeAllTokens(c.Req.Context()); err != nil {
+		return Error(500, "Failed to revoke auth tokens", err)
+	}
+
+	return JSON(200, util.DynMap{
+		"message": "All users logged out",
+	})
+}
+
+// POST /api/admin/orgs/:orgId/auth-tokens/revoke-all
+func (server *HTTPServer) AdminRevokeAllOrgAuthTokens(c *models.ReqContext) Response {
+	orgID := c.ParamsInt64(":orgId")
+
+	orgQuery := models.GetOrgByIdQuery{Id: orgID}
+	if err := bus.Dispatch(&orgQuery); err != nil {
+		if err == models.ErrOrgNotFound {
+			return Error(404, "Organization not found", err)
+		}
+		return Error(500, "Failed to get organization", err)
+	}
+
+	if err := server.AuthTokenService.RevokeAllOrgUserTokens(c.Req.Context(), orgID); err != nil {
+		return Error(500, "Failed to revoke auth tokens", err)
+	}
+
+	return JSON(200, util.DynMap{
+		"message": "All organization users logged out",
+	})
+}
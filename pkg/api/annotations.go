@@ -5,6 +5,7 @@ import (
 
 	"github.com/grafana/grafana/pkg/api/dtos"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/services/annotations"
 	"github.com/grafana/grafana/pkg/services/guardian"
 	"github.com/grafana/grafana/pkg/util"
@@ -51,7 +52,7 @@ func (e *CreateAnnotationError) Error() string {
 }
 
 func PostAnnotation(c *models.ReqContext, cmd dtos.PostAnnotationsCmd) Response {
-	if canSave, err := canSaveByDashboardID(c, cmd.DashboardId); err != nil || !canSave {
+	if canSave, err := canSaveByDashboardID(c, cmd.DashboardId, accesscontrol.ActionAnnotationsCreate); err != nil || !canSave {
 		return dashboardGuardianResponse(err)
 	}
 
@@ -256,16 +257,14 @@ func DeleteAnnotationByID(c *models.ReqContext) Response {
 	return Success("Annotation deleted")
 }
 
-func canSaveByDashboardID(c *models.ReqContext, dashboardID int64) (bool, error) {
-	if dashboardID == 0 && !c.SignedInUser.HasRole(models.ROLE_EDITOR) {
-		return false, nil
+func canSaveByDashboardID(c *models.ReqContext, dashboardID int64, action string) (bool, error) {
+	if dashboardID == 0 {
+		return accesscontrol.ContextHasPermission(c, action)
 	}
 
-	if dashboardID != 0 {
-		guard := guardian.New(dashboardID, c.OrgId, c.SignedInUser)
-		if canEdit, err := guard.CanEdit(); err != nil || !canEdit {
-			return false, err
-		}
+	guard := guardian.New(dashboardID, c.OrgId, c.SignedInUser)
+	if canEdit, err := guard.CanEdit(); err != nil || !canEdit {
+		return false, err
 	}
 
 	return true, nil
@@ -280,7 +279,7 @@ func canSave(c *models.ReqContext, repo annotations.Repository, annotationID int
 
 	dashboardID := items[0].DashboardId
 
-	if canSave, err := canSaveByDashboardID(c, dashboardID); err != nil || !canSave {
+	if canSave, err := canSaveByDashboardID(c, dashboardID, accesscontrol.ActionAnnotationsWrite); err != nil || !canSave {
 		return dashboardGuardianResponse(err)
 	}
 
@@ -31,6 +31,11 @@ func TestAnnotationsApiEndpoint(t *testing.T) {
 			Tags: []string{"tag1", "tag2"},
 		}
 
+		bus.AddHandler("test", func(query *models.GetCustomPermissionsQuery) error {
+			query.Result = []string{}
+			return nil
+		})
+
 		Convey("When user is an Org Viewer", func() {
 			role := models.ROLE_VIEWER
 			Convey("Should not be allowed to save an annotation", func() {
@@ -57,6 +62,27 @@ func TestAnnotationsApiEndpoint(t *testing.T) {
 			})
 		})
 
+		Convey("When user is an Org Viewer with a custom role", func() {
+			role := models.ROLE_VIEWER
+
+			bus.AddHandler("test", func(query *models.GetCustomPermissionsQuery) error {
+				query.Result = []string{"annotations:create", "annotations:write"}
+				return nil
+			})
+
+			Convey("Should be able to save an annotation", func() {
+				postAnnotationScenario("When calling POST on", "/api/annotations", "/api/annotations", role, cmd, func(sc *scenarioContext) {
+					sc.fakeReqWithParams("POST", sc.url, map[string]string{}).exec()
+					So(sc.resp.Code, ShouldEqual, 200)
+				})
+
+				putAnnotationScenario("When calling PUT on", "/api/annotations/1", "/api/annotations/:annotationId", role, updateCmd, func(sc *scenarioContext) {
+					sc.fakeReqWithParams("PUT", sc.url, map[string]string{}).exec()
+					So(sc.resp.Code, ShouldEqual, 200)
+				})
+			})
+		})
+
 		Convey("When user is an Org Editor", func() {
 			role := models.ROLE_EDITOR
 			Convey("Should be able to save an annotation", func() {
@@ -130,6 +156,11 @@ func TestAnnotationsApiEndpoint(t *testing.T) {
 			return nil
 		})
 
+		bus.AddHandler("test", func(query *models.GetCustomPermissionsQuery) error {
+			query.Result = []string{}
+			return nil
+		})
+
 		Convey("When user is an Org Viewer", func() {
 			role := models.ROLE_VIEWER
 			Convey("Should not be allowed to save an annotation", func() {
@@ -7,13 +7,14 @@ import (
 	"github.com/grafana/grafana/pkg/api/routing"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 )
 
 func (hs *HTTPServer) registerRoutes() {
 	reqSignedIn := middleware.ReqSignedIn
 	reqGrafanaAdmin := middleware.ReqGrafanaAdmin
 	reqEditorRole := middleware.ReqEditorRole
-	reqOrgAdmin := middleware.ReqOrgAdmin
+	reqPermission := middleware.ReqPermission
 	reqCanAccessTeams := middleware.AdminOrFeatureEnabled(hs.Cfg.EditorsCanAdmin)
 	reqSnapshotPublicModeOrSignedIn := middleware.SnapshotPublicModeOrSignedIn()
 	redirectFromLegacyDashboardURL := middleware.RedirectFromLegacyDashboardURL()
@@ -26,6 +27,7 @@ func (hs *HTTPServer) registerRoutes() {
 	// not logged in views
 	r.Get("/logout", hs.Logout)
 	r.Post("/login", quota("session"), bind(dtos.LoginCommand{}), Wrap(hs.LoginPost))
+	r.Post("/login/two-factor", quota("session"), bind(dtos.LoginTwoFactorCommand{}), Wrap(hs.LoginTwoFactorPost))
 	r.Get("/login/:name", quota("session"), hs.OAuthLogin)
 	r.Get("/login", hs.LoginView)
 	r.Get("/invite/:code", hs.Index)
@@ -34,17 +36,17 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/profile/", reqSignedIn, hs.Index)
 	r.Get("/profile/password", reqSignedIn, hs.Index)
 	r.Get("/profile/switch-org/:id", reqSignedIn, hs.ChangeActiveOrgAndRedirectToHome)
-	r.Get("/org/", reqOrgAdmin, hs.Index)
+	r.Get("/org/", reqSignedIn, reqPermission(accesscontrol.ActionOrgWrite), hs.Index)
 	r.Get("/org/new", reqGrafanaAdmin, hs.Index)
-	r.Get("/datasources/", reqOrgAdmin, hs.Index)
-	r.Get("/datasources/new", reqOrgAdmin, hs.Index)
-	r.Get("/datasources/edit/*", reqOrgAdmin, hs.Index)
-	r.Get("/org/users", reqOrgAdmin, hs.Index)
-	r.Get("/org/users/new", reqOrgAdmin, hs.Index)
-	r.Get("/org/users/invite", reqOrgAdmin, hs.Index)
+	r.Get("/datasources/", reqSignedIn, reqPermission(accesscontrol.ActionDatasourcesRead), hs.Index)
+	r.Get("/datasources/new", reqSignedIn, reqPermission(accesscontrol.ActionDatasourcesWrite), hs.Index)
+	r.Get("/datasources/edit/*", reqSignedIn, reqPermission(accesscontrol.ActionDatasourcesRead), hs.Index)
+	r.Get("/org/users", reqSignedIn, reqPermission(accesscontrol.ActionOrgUsersRead), hs.Index)
+	r.Get("/org/users/new", reqSignedIn, reqPermission(accesscontrol.ActionOrgUsersWrite), hs.Index)
+	r.Get("/org/users/invite", reqSignedIn, reqPermission(accesscontrol.ActionOrgUsersWrite), hs.Index)
 	r.Get("/org/teams", reqCanAccessTeams, hs.Index)
 	r.Get("/org/teams/*", reqCanAccessTeams, hs.Index)
-	r.Get("/org/apikeys/", reqOrgAdmin, hs.Index)
+	r.Get("/org/apikeys/", reqSignedIn, reqPermission(accesscontrol.ActionApiKeysRead), hs.Index)
 	r.Get("/dashboard/import/", reqSignedIn, hs.Index)
 	r.Get("/configuration", reqGrafanaAdmin, hs.Index)
 	r.Get("/admin", reqGrafanaAdmin, hs.Index)
@@ -134,19 +136,24 @@ func (hs *HTTPServer) registerRoutes() {
 
 			userRoute.Get("/auth-tokens", Wrap(hs.GetUserAuthTokens))
 			userRoute.Post("/revoke-auth-token", bind(models.RevokeAuthTokenCmd{}), Wrap(hs.RevokeUserAuthToken))
+
+			userRoute.Get("/two-factor", Wrap(hs.GetUserTwoFactor))
+			userRoute.Post("/two-factor/enroll", Wrap(hs.EnrollUserTwoFactor))
+			userRoute.Post("/two-factor/activate", bind(dtos.TwoFactorCodeCommand{}), Wrap(hs.ActivateUserTwoFactor))
+			userRoute.Post("/two-factor/disable", bind(dtos.TwoFactorCodeCommand{}), Wrap(hs.DisableUserTwoFactor))
 		})
 
 		// users (admin permission required)
 		apiRoute.Group("/users", func(usersRoute routing.RouteRegister) {
-			usersRoute.Get("/", Wrap(SearchUsers))
-			usersRoute.Get("/search", Wrap(SearchUsersWithPaging))
-			usersRoute.Get("/:id", Wrap(GetUserByID))
-			usersRoute.Get("/:id/teams", Wrap(GetUserTeams))
-			usersRoute.Get("/:id/orgs", Wrap(GetUserOrgList))
+			usersRoute.Get("/", reqPermission(accesscontrol.ActionUsersRead), Wrap(SearchUsers))
+			usersRoute.Get("/search", reqPermission(accesscontrol.ActionUsersRead), Wrap(SearchUsersWithPaging))
+			usersRoute.Get("/:id", reqPermission(accesscontrol.ActionUsersRead), Wrap(GetUserByID))
+			usersRoute.Get("/:id/teams", reqPermission(accesscontrol.ActionUsersRead), Wrap(GetUserTeams))
+			usersRoute.Get("/:id/orgs", reqPermission(accesscontrol.ActionUsersRead), Wrap(GetUserOrgList))
 			// query parameters /users/lookup?loginOrEmail=admin@example.com
-			usersRoute.Get("/lookup", Wrap(GetUserByLoginOrEmail))
-			usersRoute.Put("/:id", bind(models.UpdateUserCommand{}), Wrap(UpdateUser))
-			usersRoute.Post("/:id/using/:orgId", Wrap(UpdateUserActiveOrg))
+			usersRoute.Get("/lookup", reqPermission(accesscontrol.ActionUsersRead), Wrap(GetUserByLoginOrEmail))
+			usersRoute.Put("/:id", reqPermission(accesscontrol.ActionUsersWrite), bind(models.UpdateUserCommand{}), Wrap(UpdateUser))
+			usersRoute.Post("/:id/using/:orgId", reqPermission(accesscontrol.ActionUsersWrite), Wrap(UpdateUserActiveOrg))
 		}, reqGrafanaAdmin)
 
 		// team (admin permission required)
@@ -176,22 +183,26 @@ func (hs *HTTPServer) registerRoutes() {
 
 		// current org
 		apiRoute.Group("/org", func(orgRoute routing.RouteRegister) {
-			orgRoute.Put("/", bind(dtos.UpdateOrgForm{}), Wrap(UpdateOrgCurrent))
-			orgRoute.Put("/address", bind(dtos.UpdateOrgAddressForm{}), Wrap(UpdateOrgAddressCurrent))
-			orgRoute.Get("/users", Wrap(GetOrgUsersForCurrentOrg))
-			orgRoute.Post("/users", quota("user"), bind(models.AddOrgUserCommand{}), Wrap(AddOrgUserToCurrentOrg))
-			orgRoute.Patch("/users/:userId", bind(models.UpdateOrgUserCommand{}), Wrap(UpdateOrgUserForCurrentOrg))
-			orgRoute.Delete("/users/:userId", Wrap(RemoveOrgUserForCurrentOrg))
+			orgRoute.Put("/", reqPermission(accesscontrol.ActionOrgWrite), bind(dtos.UpdateOrgForm{}), Wrap(UpdateOrgCurrent))
+			orgRoute.Put("/address", reqPermission(accesscontrol.ActionOrgWrite), bind(dtos.UpdateOrgAddressForm{}), Wrap(UpdateOrgAddressCurrent))
+			orgRoute.Get("/users", reqPermission(accesscontrol.ActionOrgUsersRead), Wrap(GetOrgUsersForCurrentOrg))
+			orgRoute.Post("/users", reqPermission(accesscontrol.ActionOrgUsersWrite), quota("user"), bind(models.AddOrgUserCommand{}), Wrap(AddOrgUserToCurrentOrg))
+			orgRoute.Patch("/users/:userId", reqPermission(accesscontrol.ActionOrgUsersWrite), bind(models.UpdateOrgUserCommand{}), Wrap(UpdateOrgUserForCurrentOrg))
+			orgRoute.Delete("/users/:userId", reqPermission(accesscontrol.ActionOrgUsersWrite), Wrap(RemoveOrgUserForCurrentOrg))
 
 			// invites
-			orgRoute.Get("/invites", Wrap(GetPendingOrgInvites))
-			orgRoute.Post("/invites", quota("user"), bind(dtos.AddInviteForm{}), Wrap(AddOrgInvite))
-			orgRoute.Patch("/invites/:code/revoke", Wrap(RevokeInvite))
+			orgRoute.Get("/invites", reqPermission(accesscontrol.ActionOrgUsersRead), Wrap(GetPendingOrgInvites))
+			orgRoute.Post("/invites", reqPermission(accesscontrol.ActionOrgUsersWrite), quota("user"), bind(dtos.AddInviteForm{}), Wrap(AddOrgInvite))
+			orgRoute.Patch("/invites/:code/revoke", reqPermission(accesscontrol.ActionOrgUsersWrite), Wrap(RevokeInvite))
 
 			// prefs
-			orgRoute.Get("/preferences", Wrap(GetOrgPreferences))
-			orgRoute.Put("/preferences", bind(dtos.UpdatePrefsCmd{}), Wrap(UpdateOrgPreferences))
-		}, reqOrgAdmin)
+			orgRoute.Get("/preferences", reqPermission(accesscontrol.ActionOrgPreferencesRead), Wrap(GetOrgPreferences))
+			orgRoute.Put("/preferences", reqPermission(accesscontrol.ActionOrgPreferencesWrite), bind(dtos.UpdatePrefsCmd{}), Wrap(UpdateOrgPreferences))
+
+			// two-factor policy
+			orgRoute.Get("/two-factor-policy", reqPermission(accesscontrol.ActionOrgPreferencesRead), Wrap(GetOrgTwoFactorPolicy))
+			orgRoute.Put("/two-factor-policy", reqPermission(accesscontrol.ActionOrgWrite), bind(dtos.OrgTwoFactorPolicy{}), Wrap(hs.UpdateOrgTwoFactorPolicy))
+		})
 
 		// current org without requirement of user to be org admin
 		apiRoute.Group("/org", func(orgRoute routing.RouteRegister) {
@@ -202,33 +213,33 @@ func (hs *HTTPServer) registerRoutes() {
 		apiRoute.Post("/orgs", quota("org"), bind(models.CreateOrgCommand{}), Wrap(CreateOrg))
 
 		// search all orgs
-		apiRoute.Get("/orgs", reqGrafanaAdmin, Wrap(SearchOrgs))
+		apiRoute.Get("/orgs", reqGrafanaAdmin, reqPermission(accesscontrol.ActionOrgsRead), Wrap(SearchOrgs))
 
 		// orgs (admin routes)
 		apiRoute.Group("/orgs/:orgId", func(orgsRoute routing.RouteRegister) {
-			orgsRoute.Get("/", Wrap(GetOrgByID))
-			orgsRoute.Put("/", bind(dtos.UpdateOrgForm{}), Wrap(UpdateOrg))
-			orgsRoute.Put("/address", bind(dtos.UpdateOrgAddressForm{}), Wrap(UpdateOrgAddress))
-			orgsRoute.Delete("/", Wrap(DeleteOrgByID))
-			orgsRoute.Get("/users", Wrap(GetOrgUsers))
-			orgsRoute.Post("/users", bind(models.AddOrgUserCommand{}), Wrap(AddOrgUser))
-			orgsRoute.Patch("/users/:userId", bind(models.UpdateOrgUserCommand{}), Wrap(UpdateOrgUser))
-			orgsRoute.Delete("/users/:userId", Wrap(RemoveOrgUser))
-			orgsRoute.Get("/quotas", Wrap(GetOrgQuotas))
-			orgsRoute.Put("/quotas/:target", bind(models.UpdateOrgQuotaCmd{}), Wrap(UpdateOrgQuota))
+			orgsRoute.Get("/", reqPermission(accesscontrol.ActionOrgsRead), Wrap(GetOrgByID))
+			orgsRoute.Put("/", reqPermission(accesscontrol.ActionOrgsWrite), bind(dtos.UpdateOrgForm{}), Wrap(UpdateOrg))
+			orgsRoute.Put("/address", reqPermission(accesscontrol.ActionOrgsWrite), bind(dtos.UpdateOrgAddressForm{}), Wrap(UpdateOrgAddress))
+			orgsRoute.Delete("/", reqPermission(accesscontrol.ActionOrgsWrite), Wrap(DeleteOrgByID))
+			orgsRoute.Get("/users", reqPermission(accesscontrol.ActionOrgsRead), Wrap(GetOrgUsers))
+			orgsRoute.Post("/users", reqPermission(accesscontrol.ActionOrgsWrite), bind(models.AddOrgUserCommand{}), Wrap(AddOrgUser))
+			orgsRoute.Patch("/users/:userId", reqPermission(accesscontrol.ActionOrgsWrite), bind(models.UpdateOrgUserCommand{}), Wrap(UpdateOrgUser))
+			orgsRoute.Delete("/users/:userId", reqPermission(accesscontrol.ActionOrgsWrite), Wrap(RemoveOrgUser))
+			orgsRoute.Get("/quotas", reqPermission(accesscontrol.ActionOrgsRead), Wrap(GetOrgQuotas))
+			orgsRoute.Put("/quotas/:target", reqPermission(accesscontrol.ActionOrgsWrite), bind(models.UpdateOrgQuotaCmd{}), Wrap(UpdateOrgQuota))
 		}, reqGrafanaAdmin)
 
 		// orgs (admin routes)
 		apiRoute.Group("/orgs/name/:name", func(orgsRoute routing.RouteRegister) {
 			orgsRoute.Get("/", Wrap(GetOrgByName))
-		}, reqGrafanaAdmin)
+		}, reqGrafanaAdmin, reqPermission(accesscontrol.ActionOrgsRead))
 
 		// auth api keys
 		apiRoute.Group("/auth/keys", func(keysRoute routing.RouteRegister) {
-			keysRoute.Get("/", Wrap(GetAPIKeys))
-			keysRoute.Post("/", quota("api_key"), bind(models.AddApiKeyCommand{}), Wrap(hs.AddAPIKey))
-			keysRoute.Delete("/:id", Wrap(DeleteAPIKey))
-		}, reqOrgAdmin)
+			keysRoute.Get("/", reqPermission(accesscontrol.ActionApiKeysRead), Wrap(GetAPIKeys))
+			keysRoute.Post("/", reqPermission(accesscontrol.ActionApiKeysWrite), quota("api_key"), bind(models.AddApiKeyCommand{}), Wrap(hs.AddAPIKey))
+			keysRoute.Delete("/:id", reqPermission(accesscontrol.ActionApiKeysWrite), Wrap(DeleteAPIKey))
+		})
 
 		// Preferences
 		apiRoute.Group("/preferences", func(prefRoute routing.RouteRegister) {
@@ -237,14 +248,21 @@ func (hs *HTTPServer) registerRoutes() {
 
 		// Data sources
 		apiRoute.Group("/datasources", func(datasourceRoute routing.RouteRegister) {
-			datasourceRoute.Get("/", Wrap(GetDataSources))
-			datasourceRoute.Post("/", quota("data_source"), bind(models.AddDataSourceCommand{}), Wrap(AddDataSource))
-			datasourceRoute.Put("/:id", bind(models.UpdateDataSourceCommand{}), Wrap(UpdateDataSource))
-			datasourceRoute.Delete("/:id", Wrap(DeleteDataSourceById))
-			datasourceRoute.Delete("/name/:name", Wrap(DeleteDataSourceByName))
-			datasourceRoute.Get("/:id", Wrap(GetDataSourceById))
-			datasourceRoute.Get("/name/:name", Wrap(GetDataSourceByName))
-		}, reqOrgAdmin)
+			datasourceRoute.Get("/", reqPermission(accesscontrol.ActionDatasourcesRead), Wrap(GetDataSources))
+			datasourceRoute.Post("/", reqPermission(accesscontrol.ActionDatasourcesWrite), quota("data_source"), bind(models.AddDataSourceCommand{}), Wrap(AddDataSource))
+			datasourceRoute.Put("/:id", ReqDataSourcePermission(accesscontrol.ActionDatasourcesWrite), bind(models.UpdateDataSourceCommand{}), Wrap(UpdateDataSource))
+			datasourceRoute.Delete("/:id", ReqDataSourcePermission(accesscontrol.ActionDatasourcesWrite), Wrap(DeleteDataSourceById))
+			datasourceRoute.Delete("/name/:name", reqPermission(accesscontrol.ActionDatasourcesWrite), Wrap(DeleteDataSourceByName))
+			datasourceRoute.Get("/:id", ReqDataSourcePermission(accesscontrol.ActionDatasourcesRead), Wrap(GetDataSourceById))
+			datasourceRoute.Get("/name/:name", reqPermission(accesscontrol.ActionDatasourcesRead), Wrap(GetDataSourceByName))
+
+			// permissions
+			datasourceRoute.Get("/:id/permissions", reqPermission(accesscontrol.ActionDatasourcesRead), Wrap(GetDataSourcePermissions))
+			datasourceRoute.Post("/:id/permissions", reqPermission(accesscontrol.ActionDatasourcesWrite), bind(models.AddDataSourcePermissionCommand{}), Wrap(AddDataSourcePermission))
+			datasourceRoute.Delete("/:id/permissions/:permissionId", reqPermission(accesscontrol.ActionDatasourcesWrite), Wrap(RemoveDataSourcePermission))
+			datasourceRoute.Post("/:id/enable-permissions", reqPermission(accesscontrol.ActionDatasourcesWrite), Wrap(EnableDataSourcePermissions))
+			datasourceRoute.Post("/:id/disable-permissions", reqPermission(accesscontrol.ActionDatasourcesWrite), Wrap(DisableDataSourcePermissions))
+		})
 
 		apiRoute.Get("/datasources/id/:name", Wrap(GetDataSourceIdByName), reqSignedIn)
 
@@ -259,13 +277,13 @@ func (hs *HTTPServer) registerRoutes() {
 			pluginRoute.Get("/:pluginId/dashboards/", Wrap(GetPluginDashboards))
 			pluginRoute.Post("/:pluginId/settings", bind(models.UpdatePluginSettingCmd{}), Wrap(UpdatePluginSetting))
 			pluginRoute.Get("/:pluginId/metrics", Wrap(hs.CollectPluginMetrics))
-		}, reqOrgAdmin)
+		}, reqPermission(accesscontrol.ActionPluginsWrite))
 
 		apiRoute.Get("/frontend/settings/", hs.GetFrontendSettings)
-		apiRoute.Any("/datasources/proxy/:id/*", reqSignedIn, hs.ProxyDataSourceRequest)
-		apiRoute.Any("/datasources/proxy/:id", reqSignedIn, hs.ProxyDataSourceRequest)
-		apiRoute.Any("/datasources/:id/resources", hs.CallDatasourceResource)
-		apiRoute.Any("/datasources/:id/resources/*", hs.CallDatasourceResource)
+		apiRoute.Any("/datasources/proxy/:id/*", reqSignedIn, reqPermission(accesscontrol.ActionDatasourcesQuery), hs.ProxyDataSourceRequest)
+		apiRoute.Any("/datasources/proxy/:id", reqSignedIn, reqPermission(accesscontrol.ActionDatasourcesQuery), hs.ProxyDataSourceRequest)
+		apiRoute.Any("/datasources/:id/resources", reqPermission(accesscontrol.ActionDatasourcesQuery), hs.CallDatasourceResource)
+		apiRoute.Any("/datasources/:id/resources/*", reqPermission(accesscontrol.ActionDatasourcesQuery), hs.CallDatasourceResource)
 		apiRoute.Any("/datasources/:id/health", hs.CheckDatasourceHealth)
 
 		// Folders
@@ -324,9 +342,9 @@ func (hs *HTTPServer) registerRoutes() {
 			playlistRoute.Get("/:id", ValidateOrgPlaylist, Wrap(GetPlaylist))
 			playlistRoute.Get("/:id/items", ValidateOrgPlaylist, Wrap(GetPlaylistItems))
 			playlistRoute.Get("/:id/dashboards", ValidateOrgPlaylist, Wrap(GetPlaylistDashboards))
-			playlistRoute.Delete("/:id", reqEditorRole, ValidateOrgPlaylist, Wrap(DeletePlaylist))
-			playlistRoute.Put("/:id", reqEditorRole, bind(models.UpdatePlaylistCommand{}), ValidateOrgPlaylist, Wrap(UpdatePlaylist))
-			playlistRoute.Post("/", reqEditorRole, bind(models.CreatePlaylistCommand{}), Wrap(CreatePlaylist))
+			playlistRoute.Delete("/:id", reqPermission(accesscontrol.ActionPlaylistsWrite), ValidateOrgPlaylist, Wrap(DeletePlaylist))
+			playlistRoute.Put("/:id", reqPermission(accesscontrol.ActionPlaylistsWrite), bind(models.UpdatePlaylistCommand{}), ValidateOrgPlaylist, Wrap(UpdatePlaylist))
+			playlistRoute.Post("/", reqPermission(accesscontrol.ActionPlaylistsWrite), bind(models.CreatePlaylistCommand{}), Wrap(CreatePlaylist))
 		})
 
 		// Search
@@ -334,35 +352,38 @@ func (hs *HTTPServer) registerRoutes() {
 		apiRoute.Get("/search/", Wrap(Search))
 
 		// metrics
-		apiRoute.Post("/tsdb/query", bind(dtos.MetricRequest{}), Wrap(hs.QueryMetrics))
+		apiRoute.Post("/tsdb/query", reqPermission(accesscontrol.ActionDatasourcesQuery), bind(dtos.MetricRequest{}), Wrap(hs.QueryMetrics))
 		apiRoute.Get("/tsdb/testdata/scenarios", Wrap(GetTestDataScenarios))
 		apiRoute.Get("/tsdb/testdata/gensql", reqGrafanaAdmin, Wrap(GenerateSQLTestData))
 		apiRoute.Get("/tsdb/testdata/random-walk", Wrap(GetTestDataRandomWalk))
 
 		// DataSource w/ expressions
-		apiRoute.Post("/ds/query", bind(dtos.MetricRequest{}), Wrap(hs.QueryMetricsV2))
+		apiRoute.Post("/ds/query", reqPermission(accesscontrol.ActionDatasourcesQuery), bind(dtos.MetricRequest{}), Wrap(hs.QueryMetricsV2))
+
+		// live
+		apiRoute.Post("/live/push/*", Wrap(hs.PushLiveMeasurements))
 
 		apiRoute.Group("/alerts", func(alertsRoute routing.RouteRegister) {
 			alertsRoute.Post("/test", bind(dtos.AlertTestCommand{}), Wrap(AlertTest))
-			alertsRoute.Post("/:alertId/pause", reqEditorRole, bind(dtos.PauseAlertCommand{}), Wrap(PauseAlert))
+			alertsRoute.Post("/:alertId/pause", reqPermission(accesscontrol.ActionAlertsPause), bind(dtos.PauseAlertCommand{}), Wrap(PauseAlert))
 			alertsRoute.Get("/:alertId", ValidateOrgAlert, Wrap(GetAlert))
 			alertsRoute.Get("/", Wrap(GetAlerts))
 			alertsRoute.Get("/states-for-dashboard", Wrap(GetAlertStatesForDashboard))
 		})
 
-		apiRoute.Get("/alert-notifiers", reqEditorRole, Wrap(GetAlertNotifiers))
+		apiRoute.Get("/alert-notifiers", reqPermission(accesscontrol.ActionAlertNotificationsRead), Wrap(GetAlertNotifiers))
 
 		apiRoute.Group("/alert-notifications", func(alertNotifications routing.RouteRegister) {
-			alertNotifications.Get("/", Wrap(GetAlertNotifications))
-			alertNotifications.Post("/test", bind(dtos.NotificationTestCommand{}), Wrap(NotificationTest))
-			alertNotifications.Post("/", bind(models.CreateAlertNotificationCommand{}), Wrap(CreateAlertNotification))
-			alertNotifications.Put("/:notificationId", bind(models.UpdateAlertNotificationCommand{}), Wrap(UpdateAlertNotification))
-			alertNotifications.Get("/:notificationId", Wrap(GetAlertNotificationByID))
-			alertNotifications.Delete("/:notificationId", Wrap(DeleteAlertNotification))
-			alertNotifications.Get("/uid/:uid", Wrap(GetAlertNotificationByUID))
-			alertNotifications.Put("/uid/:uid", bind(models.UpdateAlertNotificationWithUidCommand{}), Wrap(UpdateAlertNotificationByUID))
-			alertNotifications.Delete("/uid/:uid", Wrap(DeleteAlertNotificationByUID))
-		}, reqEditorRole)
+			alertNotifications.Get("/", reqPermission(accesscontrol.ActionAlertNotificationsRead), Wrap(GetAlertNotifications))
+			alertNotifications.Post("/test", reqPermission(accesscontrol.ActionAlertNotificationsWrite), bind(dtos.NotificationTestCommand{}), Wrap(NotificationTest))
+			alertNotifications.Post("/", reqPermission(accesscontrol.ActionAlertNotificationsWrite), bind(models.CreateAlertNotificationCommand{}), Wrap(CreateAlertNotification))
+			alertNotifications.Put("/:notificationId", reqPermission(accesscontrol.ActionAlertNotificationsWrite), bind(models.UpdateAlertNotificationCommand{}), Wrap(UpdateAlertNotification))
+			alertNotifications.Get("/:notificationId", reqPermission(accesscontrol.ActionAlertNotificationsRead), Wrap(GetAlertNotificationByID))
+			alertNotifications.Delete("/:notificationId", reqPermission(accesscontrol.ActionAlertNotificationsWrite), Wrap(DeleteAlertNotification))
+			alertNotifications.Get("/uid/:uid", reqPermission(accesscontrol.ActionAlertNotificationsRead), Wrap(GetAlertNotificationByUID))
+			alertNotifications.Put("/uid/:uid", reqPermission(accesscontrol.ActionAlertNotificationsWrite), bind(models.UpdateAlertNotificationWithUidCommand{}), Wrap(UpdateAlertNotificationByUID))
+			alertNotifications.Delete("/uid/:uid", reqPermission(accesscontrol.ActionAlertNotificationsWrite), Wrap(DeleteAlertNotificationByUID))
+		})
 
 		// alert notifications without requirement of user to be org editor
 		apiRoute.Group("/alert-notifications", func(orgRoute routing.RouteRegister) {
@@ -370,14 +391,28 @@ func (hs *HTTPServer) registerRoutes() {
 		})
 
 		apiRoute.Get("/annotations", Wrap(GetAnnotations))
-		apiRoute.Post("/annotations/mass-delete", reqOrgAdmin, bind(dtos.DeleteAnnotationsCmd{}), Wrap(DeleteAnnotations))
+		apiRoute.Post("/annotations/mass-delete", reqPermission(accesscontrol.ActionAnnotationsDelete), bind(dtos.DeleteAnnotationsCmd{}), Wrap(DeleteAnnotations))
 
 		apiRoute.Group("/annotations", func(annotationsRoute routing.RouteRegister) {
 			annotationsRoute.Post("/", bind(dtos.PostAnnotationsCmd{}), Wrap(PostAnnotation))
 			annotationsRoute.Delete("/:annotationId", Wrap(DeleteAnnotationByID))
 			annotationsRoute.Put("/:annotationId", bind(dtos.UpdateAnnotationsCmd{}), Wrap(UpdateAnnotation))
 			annotationsRoute.Patch("/:annotationId", bind(dtos.PatchAnnotationsCmd{}), Wrap(PatchAnnotation))
-			annotationsRoute.Post("/graphite", reqEditorRole, bind(dtos.PostGraphiteAnnotationsCmd{}), Wrap(PostGraphiteAnnotation))
+			annotationsRoute.Post("/graphite", reqPermission(accesscontrol.ActionAnnotationsCreate), bind(dtos.PostGraphiteAnnotationsCmd{}), Wrap(PostGraphiteAnnotation))
+		})
+
+		// access control
+		apiRoute.Group("/access-control", func(acRoute routing.RouteRegister) {
+			acRoute.Get("/permissions", reqPermission(accesscontrol.ActionRolesRead), Wrap(GetAccessControlActions))
+			acRoute.Get("/user/permissions", Wrap(GetSignedInUserPermissions))
+			acRoute.Get("/roles", reqPermission(accesscontrol.ActionRolesRead), Wrap(GetRoles))
+			acRoute.Post("/roles", reqPermission(accesscontrol.ActionRolesWrite), bind(models.CreateRoleCommand{}), Wrap(CreateRole))
+			acRoute.Get("/roles/:roleId", reqPermission(accesscontrol.ActionRolesRead), Wrap(GetRoleByID))
+			acRoute.Put("/roles/:roleId", reqPermission(accesscontrol.ActionRolesWrite), bind(models.UpdateRoleCommand{}), Wrap(UpdateRole))
+			acRoute.Delete("/roles/:roleId", reqPermission(accesscontrol.ActionRolesWrite), Wrap(DeleteRole))
+			acRoute.Get("/roles/:roleId/assignments", reqPermission(accesscontrol.ActionRolesRead), Wrap(GetRoleAssignments))
+			acRoute.Post("/roles/:roleId/assignments", reqPermission(accesscontrol.ActionRolesWrite), bind(models.AddRoleAssignmentCommand{}), Wrap(AddRoleAssignment))
+			acRoute.Delete("/roles/:roleId/assignments/:assignmentId", reqPermission(accesscontrol.ActionRolesWrite), Wrap(RemoveRoleAssignment))
 		})
 
 		// error test
@@ -397,15 +432,28 @@ func (hs *HTTPServer) registerRoutes() {
 		adminRoute.Get("/users/:id/quotas", Wrap(GetUserQuotas))
 		adminRoute.Put("/users/:id/quotas/:target", bind(models.UpdateUserQuotaCmd{}), Wrap(UpdateUserQuota))
 		adminRoute.Get("/stats", AdminGetStats)
+		adminRoute.Get("/audit", Wrap(SearchAuditEntries))
+		adminRoute.Get("/audit/export", Wrap(ExportAuditEntries))
 		adminRoute.Post("/pause-all-alerts", bind(dtos.PauseAllAlertsCommand{}), Wrap(PauseAllAlerts))
 
 		adminRoute.Post("/users/:id/logout", Wrap(hs.AdminLogoutUser))
 		adminRoute.Get("/users/:id/auth-tokens", Wrap(hs.AdminGetUserAuthTokens))
 		adminRoute.Post("/users/:id/revoke-auth-token", bind(models.RevokeAuthTokenCmd{}), Wrap(hs.AdminRevokeUserAuthToken))
+		adminRoute.Post("/auth-tokens/revoke-all", Wrap(hs.AdminRevokeAllAuthTokens))
+		adminRoute.Post("/orgs/:orgId/auth-tokens/revoke-all", Wrap(hs.AdminRevokeAllOrgAuthTokens))
+		adminRoute.Delete("/users/:id/two-factor", Wrap(hs.AdminResetUserTwoFactor))
 
 		adminRoute.Post("/provisioning/dashboards/reload", Wrap(hs.AdminProvisioningReloadDasboards))
 		adminRoute.Post("/provisioning/datasources/reload", Wrap(hs.AdminProvisioningReloadDatasources))
 		adminRoute.Post("/provisioning/notifications/reload", Wrap(hs.AdminProvisioningReloadNotifications))
+		adminRoute.Post("/plugins/upload", Wrap(hs.AdminUploadPlugin))
+		adminRoute.Post("/plugins/:pluginId/install", bind(dtos.InstallPluginCommand{}), Wrap(hs.AdminInstallPlugin))
+		adminRoute.Post("/plugins/:pluginId/upgrade", bind(dtos.InstallPluginCommand{}), Wrap(hs.AdminUpgradePlugin))
+		adminRoute.Post("/plugins/:pluginId/reload", Wrap(hs.AdminReloadPlugin))
+		adminRoute.Delete("/plugins/:pluginId", Wrap(hs.AdminUninstallPlugin))
+		adminRoute.Get("/backend-plugins", Wrap(hs.AdminGetBackendPlugins))
+		adminRoute.Get("/backend-plugins/:pluginId", Wrap(hs.AdminGetBackendPlugin))
+		adminRoute.Post("/backend-plugins/:pluginId/restart", Wrap(hs.AdminRestartBackendPlugin))
 		adminRoute.Post("/ldap/reload", Wrap(hs.ReloadLDAPCfg))
 		adminRoute.Post("/ldap/sync/:id", Wrap(hs.PostSyncUserWithLDAP))
 		adminRoute.Get("/ldap/:username", Wrap(hs.GetUserFromLDAP))
@@ -423,7 +471,7 @@ func (hs *HTTPServer) registerRoutes() {
 	r.Get("/avatar/:hash", avatarCacheServer.Handler)
 
 	// Websocket
-	r.Any("/ws", hs.streamManager.Serve)
+	r.Any("/ws", reqSignedIn, hs.streamManager.Serve)
 
 	// streams
 	//r.Post("/api/streams/push", reqSignedIn, bind(dtos.StreamMessage{}), liveConn.PushToStream)
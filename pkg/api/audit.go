@@ -0,0 +1,87 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// GET /api/admin/audit
+func SearchAuditEntries(c *models.ReqContext) Response {
+	query := auditQueryFromContext(c)
+
+	query.Limit = c.QueryInt("perpage")
+	if query.Limit <= 0 {
+		query.Limit = 100
+	}
+	query.Page = c.QueryInt("page")
+	if query.Page < 1 {
+		query.Page = 1
+	}
+
+	if err := bus.Dispatch(query); err != nil {
+		return Error(500, "Failed to search audit entries", err)
+	}
+
+	query.Result.Page = query.Page
+	query.Result.PerPage = query.Limit
+
+	return JSON(200, query.Result)
+}
+
+// maxAuditExportEntries is the maximum number of audit entries in one export.
+const maxAuditExportEntries = 10000
+
+// GET /api/admin/audit/export
+func ExportAuditEntries(c *models.ReqContext) Response {
+	query := auditQueryFromContext(c)
+
+	query.Limit = c.QueryInt("perpage")
+	if query.Limit <= 0 || query.Limit > maxAuditExportEntries {
+		query.Limit = maxAuditExportEntries
+	}
+	query.Page = c.QueryInt("page")
+	if query.Page < 1 {
+		query.Page = 1
+	}
+
+	if err := bus.Dispatch(query); err != nil {
+		return Error(500, "Failed to export audit entries", err)
+	}
+
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, entry := range query.Result.Entries {
+		if err := encoder.Encode(entry); err != nil {
+			return Error(500, "Failed to encode audit entry", err)
+		}
+	}
+
+	return Respond(200, buf.Bytes()).
+		Header("Content-Type", "application/x-ndjson").
+		Header("X-Total-Count", strconv.FormatInt(query.Result.TotalCount, 10)).
+		Header("Content-Disposition", `attachment; filename="grafana-audit.ndjson"`)
+}
+
+func auditQueryFromContext(c *models.ReqContext) *models.SearchAuditEntriesQuery {
+	query := &models.SearchAuditEntriesQuery{
+		OrgId:        c.QueryInt64("orgId"),
+		ActorId:      c.QueryInt64("actorId"),
+		ActorType:    models.AuditActorType(c.Query("actorType")),
+		ResourceType: c.Query("resourceType"),
+		ResourceId:   c.Query("resourceId"),
+	}
+
+	if from := c.QueryInt64("from"); from > 0 {
+		query.From = time.Unix(0, from*int64(time.Millisecond))
+	}
+	if to := c.QueryInt64("to"); to > 0 {
+		query.To = time.Unix(0, to*int64(time.Millisecond))
+	}
+
+	return query
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/setting"
 	"gopkg.in/macaron.v1"
 )
@@ -33,6 +34,9 @@ type NormalResponse struct {
 func Wrap(action interface{}) macaron.Handler {
 
 	return func(c *models.ReqContext) {
+		// the route's authorization middlewares have run at this point
+		audit.CaptureBeforeState(c)
+
 		var res Response
 		val, err := c.Invoke(action)
 		if err == nil && val != nil && len(val) > 0 {
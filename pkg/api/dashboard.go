@@ -302,7 +302,7 @@ func dashboardSaveErrorToApiResponse(err error) Response {
 	if pluginErr, ok := err.(models.UpdatePluginDashboardError); ok {
 		message := "The dashboard belongs to plugin " + pluginErr.PluginId + "."
 		// look up plugin name
-		if pluginDef, exist := plugins.Plugins[pluginErr.PluginId]; exist {
+		if pluginDef, exist := plugins.Plugins()[pluginErr.PluginId]; exist {
 			message = "The dashboard belongs to plugin " + pluginDef.Name + "."
 		}
 		return JSON(412, util.DynMap{"status": "plugin-dashboard", "message": message})
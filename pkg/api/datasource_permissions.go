@@ -0,0 +1,130 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
+)
+
+// ReqDataSourcePermission lets the request through if the user has been granted the action
+// or the edit permission on the data source with the id in the route.
+func ReqDataSourcePermission(action string) func(c *models.ReqContext) {
+	return func(c *models.ReqContext) {
+		ok, err := accesscontrol.ContextHasPermission(c, action)
+		if err != nil {
+			c.JsonApiErr(500, "Failed to evaluate permissions", err)
+			return
+		}
+		if ok {
+			return
+		}
+
+		if c.SignedInUser.UserId != 0 {
+			query := models.GetDataSourceUserPermissionQuery{
+				OrgId:        c.OrgId,
+				DataSourceId: c.ParamsInt64(":id"),
+				UserId:       c.SignedInUser.UserId,
+			}
+			if err := bus.Dispatch(&query); err != nil {
+				c.JsonApiErr(500, "Failed to get data source permissions", err)
+				return
+			}
+			if query.Result == models.DsPermissionEdit {
+				return
+			}
+		}
+
+		c.JsonApiErr(403, "Permission denied", nil)
+	}
+}
+
+// GET /api/datasources/:id/permissions
+func GetDataSourcePermissions(c *models.ReqContext) Response {
+	query := models.GetDataSourcePermissionsQuery{OrgId: c.OrgId, DataSourceId: c.ParamsInt64(":id")}
+	if err := bus.Dispatch(&query); err != nil {
+		if err == models.ErrDataSourceNotFound {
+			return Error(404, "Data source not found", err)
+		}
+		return Error(500, "Failed to get data source permissions", err)
+	}
+
+	for _, perm := range query.Result.Permissions {
+		if perm.UserId > 0 {
+			perm.UserAvatarUrl = dtos.GetGravatarUrl(perm.UserEmail)
+		}
+		if perm.TeamId > 0 {
+			perm.TeamAvatarUrl = dtos.GetGravatarUrlWithDefault(perm.TeamEmail, perm.Team)
+		}
+	}
+
+	return JSON(200, query.Result)
+}
+
+// POST /api/datasources/:id/enable-permissions
+func EnableDataSourcePermissions(c *models.ReqContext) Response {
+	cmd := models.EnableDataSourcePermissionsCommand{OrgId: c.OrgId, DataSourceId: c.ParamsInt64(":id")}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == models.ErrDataSourceNotFound {
+			return Error(404, "Data source not found", err)
+		}
+		return Error(500, "Failed to enable data source permissions", err)
+	}
+
+	return Success("Datasource permissions enabled")
+}
+
+// POST /api/datasources/:id/disable-permissions
+func DisableDataSourcePermissions(c *models.ReqContext) Response {
+	cmd := models.DisableDataSourcePermissionsCommand{OrgId: c.OrgId, DataSourceId: c.ParamsInt64(":id")}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == models.ErrDataSourceNotFound {
+			return Error(404, "Data source not found", err)
+		}
+		return Error(500, "Failed to disable data source permissions", err)
+	}
+
+	return Success("Datasource permissions disabled")
+}
+
+// POST /api/datasources/:id/permissions
+func AddDataSourcePermission(c *models.ReqContext, cmd models.AddDataSourcePermissionCommand) Response {
+	cmd.OrgId = c.OrgId
+	cmd.DataSourceId = c.ParamsInt64(":id")
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		switch err {
+		case models.ErrDataSourceNotFound:
+			return Error(404, "Data source not found", err)
+		case models.ErrDataSourcePermissionsNotEnabled,
+			models.ErrDataSourceAclInfoMissing,
+			models.ErrDataSourceAclInfoAmbiguous,
+			models.ErrDataSourcePermissionInvalid,
+			models.ErrDataSourcePermissionExists,
+			models.ErrUserNotFound,
+			models.ErrTeamNotFound:
+			return Error(400, err.Error(), err)
+		}
+		return Error(500, "Failed to add data source permission", err)
+	}
+
+	return Success("Datasource permission added")
+}
+
+// DELETE /api/datasources/:id/permissions/:permissionId
+func RemoveDataSourcePermission(c *models.ReqContext) Response {
+	cmd := models.RemoveDataSourcePermissionCommand{
+		Id:           c.ParamsInt64(":permissionId"),
+		OrgId:        c.OrgId,
+		DataSourceId: c.ParamsInt64(":id"),
+	}
+
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == models.ErrDataSourcePermissionNotFound {
+			return Error(404, "Data source permission not found", err)
+		}
+		return Error(500, "Failed to remove data source permission", err)
+	}
+
+	return Success("Datasource permission removed")
+}
@@ -41,7 +41,7 @@ func GetDataSources(c *models.ReqContext) Response {
 			ReadOnly:  ds.ReadOnly,
 		}
 
-		if plugin, exists := plugins.DataSources[ds.Type]; exists {
+		if plugin, exists := plugins.DataSources()[ds.Type]; exists {
 			dsItem.TypeLogoUrl = plugin.Info.Logos.Small
 		} else {
 			dsItem.TypeLogoUrl = "public/img/icn-datasource.svg"
@@ -289,7 +289,7 @@ func (hs *HTTPServer) CallDatasourceResource(c *models.ReqContext) {
 	}
 
 	// find plugin
-	plugin, ok := plugins.DataSources[ds.Type]
+	plugin, ok := plugins.DataSources()[ds.Type]
 	if !ok {
 		c.JsonApiErr(500, "Unable to find datasource plugin", err)
 		return
@@ -19,25 +19,29 @@ type PluginSetting struct {
 	JsonData      map[string]interface{}      `json:"jsonData"`
 	DefaultNavUrl string                      `json:"defaultNavUrl"`
 
-	LatestVersion string                  `json:"latestVersion"`
-	HasUpdate     bool                    `json:"hasUpdate"`
-	State         plugins.PluginState     `json:"state"`
-	Signature     plugins.PluginSignature `json:"signature"`
+	LatestVersion string                      `json:"latestVersion"`
+	HasUpdate     bool                        `json:"hasUpdate"`
+	State         plugins.PluginState         `json:"state"`
+	Signature     plugins.PluginSignature     `json:"signature"`
+	SignatureType plugins.PluginSignatureType `json:"signatureType,omitempty"`
+	SignatureOrg  string                      `json:"signatureOrg,omitempty"`
 }
 
 type PluginListItem struct {
-	Name          string                  `json:"name"`
-	Type          string                  `json:"type"`
-	Id            string                  `json:"id"`
-	Enabled       bool                    `json:"enabled"`
-	Pinned        bool                    `json:"pinned"`
-	Info          *plugins.PluginInfo     `json:"info"`
-	LatestVersion string                  `json:"latestVersion"`
-	HasUpdate     bool                    `json:"hasUpdate"`
-	DefaultNavUrl string                  `json:"defaultNavUrl"`
-	Category      string                  `json:"category"`
-	State         plugins.PluginState     `json:"state"`
-	Signature     plugins.PluginSignature `json:"signature"`
+	Name          string                      `json:"name"`
+	Type          string                      `json:"type"`
+	Id            string                      `json:"id"`
+	Enabled       bool                        `json:"enabled"`
+	Pinned        bool                        `json:"pinned"`
+	Info          *plugins.PluginInfo         `json:"info"`
+	LatestVersion string                      `json:"latestVersion"`
+	HasUpdate     bool                        `json:"hasUpdate"`
+	DefaultNavUrl string                      `json:"defaultNavUrl"`
+	Category      string                      `json:"category"`
+	State         plugins.PluginState         `json:"state"`
+	Signature     plugins.PluginSignature     `json:"signature"`
+	SignatureType plugins.PluginSignatureType `json:"signatureType,omitempty"`
+	SignatureOrg  string                      `json:"signatureOrg,omitempty"`
 }
 
 type PluginList []PluginListItem
@@ -62,3 +66,7 @@ type ImportDashboardCommand struct {
 	Inputs    []plugins.ImportDashboardInput `json:"inputs"`
 	FolderId  int64                          `json:"folderId"`
 }
+
+type InstallPluginCommand struct {
+	Version string `json:"version"`
+}
@@ -0,0 +1,14 @@
+package dtos
+
+type TwoFactorCodeCommand struct {
+	Code string `json:"code" binding:"Required"`
+}
+
+type UserTwoFactorStatus struct {
+	Enabled  bool `json:"enabled"`
+	Required bool `json:"required"`
+}
+
+type OrgTwoFactorPolicy struct {
+	RequireTwoFactor bool `json:"requireTwoFactor"`
+}
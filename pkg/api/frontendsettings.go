@@ -130,12 +130,12 @@ func (hs *HTTPServer) getFrontendSettingsMap(c *models.ReqContext) (map[string]i
 	}
 
 	// add datasources that are built in (meaning they are not added via data sources page, nor have any entry in datasource table)
-	for _, ds := range plugins.DataSources {
+	for _, ds := range plugins.DataSources() {
 		if ds.BuiltIn {
 			datasources[ds.Name] = map[string]interface{}{
 				"type": ds.Type,
 				"name": ds.Name,
-				"meta": plugins.DataSources[ds.Id],
+				"meta": plugins.DataSources()[ds.Id],
 			}
 		}
 	}
@@ -186,6 +186,7 @@ func (hs *HTTPServer) getFrontendSettingsMap(c *models.ReqContext) (map[string]i
 		"googleAnalyticsId":          setting.GoogleAnalyticsId,
 		"disableLoginForm":           setting.DisableLoginForm,
 		"disableUserSignUp":          !setting.AllowUserSignUp,
+		"twoFactorEnabled":           hs.Cfg.TwoFactorEnabled,
 		"loginHint":                  setting.LoginHint,
 		"passwordHint":               setting.PasswordHint,
 		"externalUserMngInfo":        setting.ExternalUserMngInfo,
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strings"
 	"sync"
 
 	"github.com/grafana/grafana/pkg/plugins/backendplugin"
@@ -25,12 +26,15 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/services/audit"
 	"github.com/grafana/grafana/pkg/services/datasources"
 	"github.com/grafana/grafana/pkg/services/hooks"
 	"github.com/grafana/grafana/pkg/services/login"
 	"github.com/grafana/grafana/pkg/services/provisioning"
+	"github.com/grafana/grafana/pkg/services/querycache"
 	"github.com/grafana/grafana/pkg/services/quota"
 	"github.com/grafana/grafana/pkg/services/rendering"
+	"github.com/grafana/grafana/pkg/services/twofactor"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util/errutil"
 	"github.com/prometheus/client_golang/prometheus"
@@ -69,12 +73,22 @@ type HTTPServer struct {
 	BackendPluginManager backendplugin.Manager            `inject:""`
 	PluginManager        *plugins.PluginManager           `inject:""`
 	SearchService        *search.SearchService            `inject:""`
+	AuditService         *audit.AuditService              `inject:""`
+	TwoFactorService     *twofactor.TwoFactorService      `inject:""`
+	QueryCacheService    *querycache.QueryCacheService    `inject:""`
 }
 
 func (hs *HTTPServer) Init() error {
 	hs.log = log.New("http.server")
 
-	hs.streamManager = live.NewStreamManager()
+	broker, err := live.NewBroker(hs.Cfg)
+	if err != nil {
+		return err
+	}
+
+	hs.streamManager = live.NewStreamManager(hs.Cfg, broker)
+	hs.streamManager.RegisterEventListeners(hs.Bus)
+	hs.streamManager.RegisterChannelHandler("plugin", live.NewPluginChannelHandler(hs.BackendPluginManager, hs.getStreamPluginContext))
 	hs.macaron = hs.newMacaron()
 	hs.registerRoutes()
 
@@ -281,11 +295,12 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 
 	m.Use(middleware.Recovery())
 
-	for _, route := range plugins.StaticRoutes {
+	for _, route := range plugins.StaticRoutes() {
 		pluginRoute := path.Join("/public/plugins/", route.PluginId)
 		hs.log.Debug("Plugins: Adding route", "route", pluginRoute, "dir", route.Directory)
 		hs.mapStatic(m, route.Directory, "", pluginRoute)
 	}
+	m.Use(hs.installedPluginStaticRoutes())
 
 	hs.mapStatic(m, setting.StaticRootPath, "build", "public/build")
 	hs.mapStatic(m, setting.StaticRootPath, "", "public")
@@ -313,6 +328,7 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 		hs.AuthTokenService,
 		hs.RemoteCacheService,
 		hs.RenderService,
+		hs.Cfg,
 	))
 	m.Use(middleware.OrgRedirect())
 
@@ -322,6 +338,8 @@ func (hs *HTTPServer) addMiddlewaresAndStaticRoutes() {
 	}
 
 	m.Use(middleware.HandleNoCacheHeader())
+	m.Use(hs.AuditService.Middleware())
+	m.Use(hs.TwoFactorService.Middleware())
 }
 
 func (hs *HTTPServer) metricsEndpoint(ctx *macaron.Context) {
@@ -369,7 +387,55 @@ func (hs *HTTPServer) healthHandler(ctx *macaron.Context) {
 	}
 }
 
+// installedPluginStaticRoutes serves the files of plugins that were installed or moved while
+// Grafana is running, the routes of the plugins found at startup are mapped when the server starts.
+func (hs *HTTPServer) installedPluginStaticRoutes() macaron.Handler {
+	var mu sync.Mutex
+	handlers := make(map[string]macaron.Handler)
+
+	return func(c *macaron.Context) {
+		if !strings.HasPrefix(c.Req.URL.Path, "/public/plugins/") {
+			return
+		}
+		pluginID := strings.SplitN(strings.TrimPrefix(c.Req.URL.Path, "/public/plugins/"), "/", 2)[0]
+
+		for _, route := range plugins.StaticRoutes() {
+			if route.PluginId != pluginID {
+				continue
+			}
+
+			mu.Lock()
+			handler, exists := handlers[route.Directory]
+			if !exists {
+				handler = httpstatic.Static(route.Directory, httpstatic.StaticOptions{
+					SkipLogging: true,
+					Prefix:      path.Join("/public/plugins/", route.PluginId),
+					AddHeaders:  staticHeaders(""),
+				})
+				handlers[route.Directory] = handler
+			}
+			mu.Unlock()
+
+			if _, err := c.Invoke(handler); err != nil {
+				hs.log.Error("Failed to serve plugin file", "pluginId", pluginID, "error", err)
+			}
+			return
+		}
+	}
+}
+
 func (hs *HTTPServer) mapStatic(m *macaron.Macaron, rootDir string, dir string, prefix string) {
+	m.Use(httpstatic.Static(
+		path.Join(rootDir, dir),
+		httpstatic.StaticOptions{
+			SkipLogging: true,
+			Prefix:      prefix,
+			AddHeaders:  staticHeaders(prefix),
+		},
+	))
+}
+
+func staticHeaders(prefix string) func(c *macaron.Context) {
 	headers := func(c *macaron.Context) {
 		c.Resp.Header().Set("Cache-Control", "public, max-age=3600")
 	}
@@ -386,14 +452,7 @@ func (hs *HTTPServer) mapStatic(m *macaron.Macaron, rootDir string, dir string,
 		}
 	}
 
-	m.Use(httpstatic.Static(
-		path.Join(rootDir, dir),
-		httpstatic.StaticOptions{
-			SkipLogging: true,
-			Prefix:      prefix,
-			AddHeaders:  headers,
-		},
-	))
+	return headers
 }
 
 func (hs *HTTPServer) metricsEndpointBasicAuthEnabled() bool {
@@ -1,10 +1,22 @@
 package api
 
 import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins"
 	"github.com/grafana/grafana/pkg/setting"
 	. "github.com/smartystreets/goconvey/convey"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
 )
 
 func TestHTTPServer(t *testing.T) {
@@ -28,3 +40,62 @@ func TestHTTPServer(t *testing.T) {
 		})
 	})
 }
+
+func TestInstalledPluginStaticRoutes(t *testing.T) {
+	origPluginsPath := setting.PluginsPath
+	origRootPath := setting.StaticRootPath
+	origEnv := setting.Env
+	t.Cleanup(func() {
+		setting.PluginsPath = origPluginsPath
+		setting.StaticRootPath = origRootPath
+		setting.Env = origEnv
+	})
+
+	dir, err := ioutil.TempDir("", "plugins")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := os.RemoveAll(dir)
+		assert.NoError(t, err)
+	})
+	setting.PluginsPath = dir
+	setting.StaticRootPath, err = filepath.Abs("../../public/")
+	require.NoError(t, err)
+	setting.Env = setting.PROD
+
+	pm := &plugins.PluginManager{Cfg: &setting.Cfg{PluginAdminEnabled: true}}
+	require.NoError(t, pm.Init())
+
+	hs := &HTTPServer{log: log.New("test")}
+	m := macaron.New()
+	m.Use(hs.installedPluginStaticRoutes())
+
+	get := func(url string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		m.ServeHTTP(rec, httptest.NewRequest("GET", url, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusNotFound, get("/public/plugins/test-panel/module.js").Code)
+
+	var archive bytes.Buffer
+	zw := zip.NewWriter(&archive)
+	for name, content := range map[string]string{
+		"plugin.json": `{"id": "test-panel", "type": "panel", "info": {"version": "1.0.0"}}`,
+		"module.js":   "define([], function() {});",
+	} {
+		w, err := zw.Create("test-panel/dist/" + name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+
+	_, err = pm.InstallPluginArchive(bytes.NewReader(archive.Bytes()))
+	require.NoError(t, err)
+
+	rec := get("/public/plugins/test-panel/module.js")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "define([], function() {});", rec.Body.String())
+
+	assert.Equal(t, http.StatusNotFound, get("/public/plugins/test-panel/missing.js").Code)
+}
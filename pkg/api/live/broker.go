@@ -0,0 +1,74 @@
+package live
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/setting"
+)
+
+// Broker delivers the messages published on any Grafana instance to the hubs of all
+// instances, so that clients receive them regardless of the instance they are connected to.
+type Broker interface {
+	// Run delivers the messages published by all instances to deliver until the context is done.
+	Run(ctx context.Context, deliver func(*ChannelMessage)) error
+	// Publish sends the message to the hubs of all instances.
+	Publish(msg *ChannelMessage) error
+}
+
+// NewBroker creates the broker of the ha_engine of the live settings.
+func NewBroker(cfg *setting.Cfg) (Broker, error) {
+	switch cfg.LiveHAEngine {
+	case "", "memory":
+		return NewMemoryBroker(), nil
+	case "redis":
+		connStr := cfg.LiveHAEngineConnStr
+		if connStr == "" && cfg.RemoteCacheOptions != nil && cfg.RemoteCacheOptions.Name == "redis" {
+			connStr = cfg.RemoteCacheOptions.ConnStr
+		}
+		if connStr == "" {
+			return nil, fmt.Errorf("live ha_engine redis requires ha_engine_connstr or a redis remote cache")
+		}
+
+		client, err := remotecache.NewRedisClient(connStr)
+		if err != nil {
+			return nil, err
+		}
+		return newRedisBroker(&redisClientAdapter{client: client}, cfg.LiveHAEngineChannel), nil
+	default:
+		return nil, fmt.Errorf("unsupported live ha_engine %q", cfg.LiveHAEngine)
+	}
+}
+
+// memoryBroker delivers messages to the hub of this instance only.
+type memoryBroker struct {
+	mutex   sync.RWMutex
+	deliver func(*ChannelMessage)
+}
+
+// NewMemoryBroker creates a broker for a single Grafana instance.
+func NewMemoryBroker() Broker {
+	return &memoryBroker{}
+}
+
+func (b *memoryBroker) Run(ctx context.Context, deliver func(*ChannelMessage)) error {
+	b.mutex.Lock()
+	b.deliver = deliver
+	b.mutex.Unlock()
+
+	<-ctx.Done()
+	return nil
+}
+
+func (b *memoryBroker) Publish(msg *ChannelMessage) error {
+	b.mutex.RLock()
+	deliver := b.deliver
+	b.mutex.RUnlock()
+
+	if deliver != nil {
+		deliver(msg)
+	}
+	return nil
+}
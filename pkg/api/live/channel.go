@@ -0,0 +1,81 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+var (
+	ErrInvalidChannel   = errors.New("invalid channel name")
+	ErrUnknownChannel   = errors.New("unknown channel")
+	ErrPermissionDenied = errors.New("permission denied")
+
+	channelSegmentPattern = regexp.MustCompile(`^[A-Za-z0-9_\-=.]+$`)
+)
+
+// Channel is the name of a live channel in the form scope/namespace/path, e.g.
+// grafana/dashboard/<uid> or plugin/<id>/<path>. Channels are scoped by the
+// organization of the user, so the same name refers to a different channel in
+// every organization.
+type Channel struct {
+	Scope     string
+	Namespace string
+	Path      string
+}
+
+// ParseChannel parses and validates a channel name.
+func ParseChannel(name string) (Channel, error) {
+	parts := strings.SplitN(name, "/", 3)
+	if len(parts) < 2 {
+		return Channel{}, ErrInvalidChannel
+	}
+
+	for _, segment := range strings.Split(name, "/") {
+		if !channelSegmentPattern.MatchString(segment) {
+			return Channel{}, ErrInvalidChannel
+		}
+	}
+
+	channel := Channel{Scope: parts[0], Namespace: parts[1]}
+	if len(parts) == 3 {
+		channel.Path = parts[2]
+	}
+	return channel, nil
+}
+
+func (c Channel) String() string {
+	if c.Path == "" {
+		return c.Scope + "/" + c.Namespace
+	}
+	return c.Scope + "/" + c.Namespace + "/" + c.Path
+}
+
+// orgChannelKey returns the key of the channel in the organization.
+func orgChannelKey(orgID int64, channel string) string {
+	return fmt.Sprintf("%d/%s", orgID, channel)
+}
+
+// ChannelHandler authorizes subscriptions and messages of the channels it is
+// registered for.
+type ChannelHandler interface {
+	// OnSubscribe returns an error if the user is not allowed to subscribe to the channel.
+	OnSubscribe(user *models.SignedInUser, channel Channel) error
+	// OnPublish returns an error if the user is not allowed to publish to the channel,
+	// otherwise the data that is sent to the subscribers of the channel.
+	OnPublish(user *models.SignedInUser, channel Channel, data json.RawMessage) (json.RawMessage, error)
+}
+
+// ChannelRunner is implemented by channel handlers that produce the messages of their
+// channels. A channel runs once per organization and instance while it has subscribers
+// on the instance, and its messages are only delivered to the subscribers on the instance.
+// The run is shared by all subscribers, which are authorized by OnSubscribe.
+type ChannelRunner interface {
+	// RunChannel publishes the messages of the channel of the organization until the context is done.
+	RunChannel(ctx context.Context, orgID int64, channel Channel, publish func(data json.RawMessage))
+}
@@ -1,12 +1,16 @@
 package live
 
 import (
+	"encoding/json"
 	"net/http"
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/gorilla/websocket"
-	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 const (
@@ -20,30 +24,66 @@ const (
 	pingPeriod = (pongWait * 9) / 10
 
 	// Maximum message size allowed from peer.
-	maxMessageSize = 512
+	maxMessageSize = 64 * 1024
 )
 
 var upgrader = websocket.Upgrader{
 	ReadBufferSize:  1024,
 	WriteBufferSize: 1024,
-	CheckOrigin: func(r *http.Request) bool {
+	CheckOrigin:     checkOrigin,
+}
+
+// checkOrigin only allows connections from the Grafana UI, since connections are
+// authenticated by the session cookie of the user.
+func checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+
+	originURL, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(originURL.Host, r.Host) {
 		return true
-	},
+	}
+
+	appURL, err := url.Parse(setting.AppUrl)
+	return err == nil && strings.EqualFold(originURL.Host, appURL.Host)
+}
+
+// clientMessage is a message of a client to subscribe, unsubscribe or publish to a channel.
+type clientMessage struct {
+	Action  string          `json:"action"`
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// errorMessage is sent to clients if their message is rejected.
+type errorMessage struct {
+	Action  string `json:"action,omitempty"`
+	Channel string `json:"channel,omitempty"`
+	Error   string `json:"error"`
 }
 
 type connection struct {
-	hub  *hub
-	ws   *websocket.Conn
-	send chan []byte
-	log  log.Logger
+	hub      *hub
+	handlers *channelHandlers
+	user     *models.SignedInUser
+	ws       *websocket.Conn
+	send     chan []byte
+	log      log.Logger
 }
 
-func newConnection(ws *websocket.Conn, hub *hub, logger log.Logger) *connection {
+func newConnection(ws *websocket.Conn, hub *hub, handlers *channelHandlers, user *models.SignedInUser, logger log.Logger) *connection {
 	return &connection{
-		hub:  hub,
-		send: make(chan []byte, 256),
-		ws:   ws,
-		log:  logger,
+		hub:      hub,
+		handlers: handlers,
+		user:     user,
+		send:     make(chan []byte, 256),
+		ws:       ws,
+		log:      logger,
 	}
 }
 
@@ -74,26 +114,67 @@ func (c *connection) readPump() {
 }
 
 func (c *connection) handleMessage(message []byte) {
-	json, err := simplejson.NewJson(message)
-	if err != nil {
-		log.Error(3, "Unreadable message on websocket channel. error: %v", err)
+	var msg clientMessage
+	if err := json.Unmarshal(message, &msg); err != nil {
+		c.log.Debug("Unreadable message on websocket channel", "error", err)
+		c.sendError(&msg, "invalid message")
+		return
 	}
 
-	msgType := json.Get("action").MustString()
-	streamName := json.Get("stream").MustString()
+	channel, err := ParseChannel(msg.Channel)
+	if err != nil {
+		c.sendError(&msg, err.Error())
+		return
+	}
 
-	if len(streamName) == 0 {
-		log.Error(3, "Not allowed to subscribe to empty stream name")
+	handler, err := c.handlers.get(channel)
+	if err != nil {
+		c.sendError(&msg, err.Error())
 		return
 	}
 
-	switch msgType {
+	key := orgChannelKey(c.user.OrgId, channel.String())
+
+	switch msg.Action {
 	case "subscribe":
-		c.hub.subChannel <- &streamSubscription{name: streamName, conn: c}
+		if err := handler.OnSubscribe(c.user, channel); err != nil {
+			c.rejected(&msg, err)
+			return
+		}
+		c.hub.subChannel <- &channelSubscription{key: key, conn: c, channel: channel, handler: handler}
 	case "unsubscribe":
-		c.hub.subChannel <- &streamSubscription{name: streamName, conn: c, remove: true}
+		c.hub.subChannel <- &channelSubscription{key: key, conn: c, remove: true}
+	case "publish":
+		data, err := handler.OnPublish(c.user, channel, msg.Data)
+		if err != nil {
+			c.rejected(&msg, err)
+			return
+		}
+		if err := c.hub.broadcast(&ChannelMessage{OrgID: c.user.OrgId, Channel: channel.String(), Data: data}); err != nil {
+			c.rejected(&msg, err)
+		}
+	default:
+		c.sendError(&msg, "unknown action")
 	}
+}
+
+func (c *connection) rejected(msg *clientMessage, err error) {
+	switch err {
+	case ErrPermissionDenied, ErrUnknownChannel, ErrInvalidChannel:
+		c.log.Debug("Rejected message", "action", msg.Action, "channel", msg.Channel, "user", c.user.Login, "error", err)
+		c.sendError(msg, err.Error())
+	default:
+		c.log.Error("Failed to handle message", "action", msg.Action, "channel", msg.Channel, "error", err)
+		c.sendError(msg, "internal error")
+	}
+}
 
+func (c *connection) sendError(msg *clientMessage, text string) {
+	data, err := json.Marshal(&errorMessage{Action: msg.Action, Channel: msg.Channel, Error: text})
+	if err != nil {
+		return
+	}
+	c.hub.reply <- &connectionMessage{conn: c, data: data}
 }
 
 func (c *connection) write(mt int, payload []byte) error {
@@ -0,0 +1,108 @@
+package live
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/guardian"
+)
+
+// dashboardHandler handles the grafana/dashboard/<uid> channels. Users can subscribe
+// to the channels of dashboards they can view, only Grafana publishes to them.
+type dashboardHandler struct{}
+
+func (h *dashboardHandler) OnSubscribe(user *models.SignedInUser, channel Channel) error {
+	if channel.Path == "" {
+		return ErrInvalidChannel
+	}
+
+	query := models.GetDashboardQuery{Uid: channel.Path, OrgId: user.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		if err == models.ErrDashboardNotFound {
+			return ErrUnknownChannel
+		}
+		return err
+	}
+
+	canView, err := guardian.New(query.Result.Id, user.OrgId, user).CanView()
+	if err != nil {
+		return err
+	}
+	if !canView {
+		return ErrPermissionDenied
+	}
+	return nil
+}
+
+func (h *dashboardHandler) OnPublish(user *models.SignedInUser, channel Channel, data json.RawMessage) (json.RawMessage, error) {
+	return nil, ErrPermissionDenied
+}
+
+// dashboardEvent is sent to the grafana/dashboard/<uid> channel of a dashboard when
+// it is saved, deleted or reloaded by provisioning.
+type dashboardEvent struct {
+	Action    string    `json:"action"`
+	UID       string    `json:"uid"`
+	Title     string    `json:"title,omitempty"`
+	Version   int       `json:"version,omitempty"`
+	UserID    int64     `json:"userId,omitempty"`
+	UserLogin string    `json:"userLogin,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+const (
+	dashboardSavedAction       = "saved"
+	dashboardDeletedAction     = "deleted"
+	dashboardProvisionedAction = "provisioned"
+)
+
+// RegisterEventListeners publishes the changes of dashboards to their channels.
+func (sm *StreamManager) RegisterEventListeners(b bus.Bus) {
+	b.AddEventListener(sm.onDashboardSaved)
+	b.AddEventListener(sm.onDashboardDeleted)
+}
+
+func (sm *StreamManager) onDashboardSaved(event *events.DashboardSaved) error {
+	msg := &dashboardEvent{
+		Action:    dashboardSavedAction,
+		UID:       event.Uid,
+		Title:     event.Title,
+		Version:   event.Version,
+		Message:   event.Message,
+		Timestamp: event.Timestamp,
+	}
+
+	if event.Provisioned {
+		msg.Action = dashboardProvisionedAction
+	} else if event.UserId > 0 {
+		query := models.GetUserByIdQuery{Id: event.UserId}
+		if err := bus.Dispatch(&query); err == nil {
+			msg.UserID = query.Result.Id
+			msg.UserLogin = query.Result.Login
+		}
+	}
+
+	sm.publishDashboardEvent(event.OrgId, msg)
+	return nil
+}
+
+func (sm *StreamManager) onDashboardDeleted(event *events.DashboardDeleted) error {
+	sm.publishDashboardEvent(event.OrgId, &dashboardEvent{
+		Action:    dashboardDeletedAction,
+		UID:       event.Uid,
+		Timestamp: event.Timestamp,
+	})
+	return nil
+}
+
+// publishDashboardEvent only logs failures, since returning errors from event
+// listeners would stop other listeners of the event.
+func (sm *StreamManager) publishDashboardEvent(orgID int64, msg *dashboardEvent) {
+	if err := sm.Publish(orgID, "grafana/dashboard/"+msg.UID, msg); err != nil {
+		sm.log.Warn("Failed to publish dashboard event", "uid", msg.UID, "action", msg.Action, "error", err)
+	}
+}
@@ -2,98 +2,278 @@ package live
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
-	"github.com/grafana/grafana/pkg/api/dtos"
-	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 )
 
 type hub struct {
 	log         log.Logger
+	broker      Broker
 	connections map[*connection]bool
-	streams     map[string]map[*connection]bool
+	channels    map[string]map[*connection]bool
+	runs        map[string]*channelRun
+	buffers     map[string]*messageBuffer
+	bufferSize  int
 
-	register      chan *connection
-	unregister    chan *connection
-	streamChannel chan *dtos.StreamMessage
-	subChannel    chan *streamSubscription
+	register   chan *connection
+	unregister chan *connection
+	publish    chan *ChannelMessage
+	reply      chan *connectionMessage
+	subChannel chan *channelSubscription
+	runDone    chan *channelRun
+	started    chan struct{}
+	done       chan struct{}
 }
 
-type streamSubscription struct {
-	conn   *connection
-	name   string
-	remove bool
+// connectionMessage is a message to a single connection, e.g. an error frame.
+type connectionMessage struct {
+	conn *connection
+	data []byte
 }
 
-func newHub() *hub {
+type channelSubscription struct {
+	conn    *connection
+	key     string
+	channel Channel
+	handler ChannelHandler
+	remove  bool
+}
+
+// channelRun is a running ChannelRunner of a channel with subscribers on this instance.
+type channelRun struct {
+	key    string
+	cancel context.CancelFunc
+}
+
+// ChannelMessage is a message to the subscribers of a channel of an organization.
+type ChannelMessage struct {
+	OrgID   int64           `json:"-"`
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+	// Points is the number of pushed points of the message, messages with points are
+	// buffered for new subscribers.
+	Points int `json:"-"`
+}
+
+func newHub(broker Broker, bufferSize int) *hub {
 	return &hub{
-		connections:   make(map[*connection]bool),
-		streams:       make(map[string]map[*connection]bool),
-		register:      make(chan *connection),
-		unregister:    make(chan *connection),
-		streamChannel: make(chan *dtos.StreamMessage),
-		subChannel:    make(chan *streamSubscription),
-		log:           log.New("stream.hub"),
+		broker:      broker,
+		bufferSize:  bufferSize,
+		connections: make(map[*connection]bool),
+		channels:    make(map[string]map[*connection]bool),
+		runs:        make(map[string]*channelRun),
+		buffers:     make(map[string]*messageBuffer),
+		register:    make(chan *connection),
+		unregister:  make(chan *connection),
+		publish:     make(chan *ChannelMessage),
+		reply:       make(chan *connectionMessage),
+		subChannel:  make(chan *channelSubscription),
+		runDone:     make(chan *channelRun),
+		started:     make(chan struct{}),
+		done:        make(chan struct{}),
+		log:         log.New("stream.hub"),
 	}
 }
 
 func (h *hub) run(ctx context.Context) {
+	close(h.started)
+	defer close(h.done)
+
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case now := <-ticker.C:
+			h.removeExpiredBuffers(now)
 		case c := <-h.register:
 			h.connections[c] = true
-			h.log.Info("New connection", "total", len(h.connections))
+			h.log.Info("New connection", "total", len(h.connections), "user", c.user.Login, "orgId", c.user.OrgId)
 
 		case c := <-h.unregister:
 			if _, ok := h.connections[c]; ok {
 				h.log.Info("Closing connection", "total", len(h.connections))
-				delete(h.connections, c)
-				close(c.send)
+				h.removeConnection(c)
 			}
-			// hand stream subscriptions
+			// handle channel subscriptions
 		case sub := <-h.subChannel:
-			h.log.Info("Subscribing", "channel", sub.name, "remove", sub.remove)
-			subscribers, exists := h.streams[sub.name]
+			h.log.Debug("Subscribing", "channel", sub.key, "remove", sub.remove)
+			subscribers, exists := h.channels[sub.key]
 
 			// handle unsubscribe
-			if exists && sub.remove {
-				delete(subscribers, sub.conn)
+			if sub.remove {
+				if exists {
+					delete(subscribers, sub.conn)
+					if len(subscribers) == 0 {
+						h.removeChannel(sub.key)
+					}
+				}
+				continue
+			}
+
+			// the connection may have been closed while the subscription was queued
+			if !h.connections[sub.conn] {
 				continue
 			}
 
 			if !exists {
 				subscribers = make(map[*connection]bool)
-				h.streams[sub.name] = subscribers
+				h.channels[sub.key] = subscribers
 			}
 
+			if subscribers[sub.conn] {
+				continue
+			}
 			subscribers[sub.conn] = true
 
-			// handle stream messages
-		case message := <-h.streamChannel:
-			subscribers, exists := h.streams[message.Stream]
-			if !exists || len(subscribers) == 0 {
-				h.log.Info("Message to stream without subscribers", "stream", message.Stream)
+			if !h.sendBuffered(sub.conn, sub.key) {
 				continue
 			}
 
-			messageBytes, _ := simplejson.NewFromAny(message).Encode()
-			for sub := range subscribers {
-				// check if channel is open
-				if _, ok := h.connections[sub]; !ok {
-					delete(subscribers, sub)
-					continue
+			if runner, ok := sub.handler.(ChannelRunner); ok {
+				h.startRun(ctx, runner, sub)
+			}
+
+		case run := <-h.runDone:
+			if h.runs[run.key] == run {
+				delete(h.runs, run.key)
+			}
+
+		case message := <-h.reply:
+			if _, ok := h.connections[message.conn]; !ok {
+				continue
+			}
+			select {
+			case message.conn.send <- message.data:
+			default:
+				h.removeConnection(message.conn)
+			}
+
+			// handle channel messages
+		case message := <-h.publish:
+			key := orgChannelKey(message.OrgID, message.Channel)
+			subscribers := h.channels[key]
+			buffered := message.Points > 0 && h.bufferSize > 0
+			if len(subscribers) == 0 && !buffered {
+				h.log.Debug("Message to channel without subscribers", "channel", key)
+				continue
+			}
+
+			messageBytes, err := json.Marshal(message)
+			if err != nil {
+				h.log.Error("Failed to encode channel message", "channel", key, "error", err)
+				continue
+			}
+
+			if buffered {
+				buffer, exists := h.buffers[key]
+				if !exists {
+					buffer = &messageBuffer{}
+					h.buffers[key] = buffer
 				}
+				buffer.add(message.Points, messageBytes, h.bufferSize, time.Now())
+			}
 
+			for sub := range subscribers {
 				select {
 				case sub.send <- messageBytes:
 				default:
-					close(sub.send)
-					delete(h.connections, sub)
-					delete(subscribers, sub)
+					h.removeConnection(sub)
 				}
 			}
 		}
 	}
 }
+
+// broadcast publishes a message to the subscribers of the channel on all instances.
+func (h *hub) broadcast(msg *ChannelMessage) error {
+	return h.broker.Publish(msg)
+}
+
+// deliver sends a message of the broker to the subscribers of the channel on this instance.
+func (h *hub) deliver(msg *ChannelMessage) {
+	select {
+	case h.publish <- msg:
+	case <-h.done:
+	}
+}
+
+// startRun runs the runner of the channel for all its subscribers, unless it is already
+// running. Subscribers are authorized by the handler of the channel when they subscribe.
+func (h *hub) startRun(ctx context.Context, runner ChannelRunner, sub *channelSubscription) {
+	if _, running := h.runs[sub.key]; running {
+		return
+	}
+
+	runCtx, cancel := context.WithCancel(ctx)
+	run := &channelRun{key: sub.key, cancel: cancel}
+	h.runs[sub.key] = run
+
+	orgID := sub.conn.user.OrgId
+	channel := sub.channel.String()
+	go func() {
+		runner.RunChannel(runCtx, orgID, sub.channel, func(data json.RawMessage) {
+			h.deliver(&ChannelMessage{OrgID: orgID, Channel: channel, Data: data})
+		})
+		cancel()
+
+		select {
+		case h.runDone <- run:
+		case <-h.done:
+		}
+	}()
+}
+
+// sendBuffered sends the buffered messages of the channel to a new subscriber, and returns
+// false if the connection was closed because its send buffer is full.
+func (h *hub) sendBuffered(c *connection, key string) bool {
+	buffer, exists := h.buffers[key]
+	if !exists {
+		return true
+	}
+
+	for _, message := range buffer.messages {
+		select {
+		case c.send <- message.data:
+		default:
+			h.removeConnection(c)
+			return false
+		}
+	}
+	return true
+}
+
+// removeExpiredBuffers removes the buffers of channels without pushes for pushBufferTTL.
+func (h *hub) removeExpiredBuffers(now time.Time) {
+	for key, buffer := range h.buffers {
+		if now.Sub(buffer.updated) > pushBufferTTL {
+			delete(h.buffers, key)
+		}
+	}
+}
+
+// removeChannel removes a channel without subscribers and stops its runner.
+func (h *hub) removeChannel(key string) {
+	delete(h.channels, key)
+
+	if run, running := h.runs[key]; running {
+		run.cancel()
+		delete(h.runs, key)
+	}
+}
+
+// removeConnection closes the connection and removes its subscriptions.
+func (h *hub) removeConnection(c *connection) {
+	for key, subscribers := range h.channels {
+		delete(subscribers, c)
+		if len(subscribers) == 0 {
+			h.removeChannel(key)
+		}
+	}
+	delete(h.connections, c)
+	close(c.send)
+}
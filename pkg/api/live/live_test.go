@@ -0,0 +1,164 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/events"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseChannel(t *testing.T) {
+	channel, err := ParseChannel("plugin/testdata/random-walk/1")
+	require.NoError(t, err)
+	assert.Equal(t, Channel{Scope: "plugin", Namespace: "testdata", Path: "random-walk/1"}, channel)
+	assert.Equal(t, "plugin/testdata/random-walk/1", channel.String())
+
+	channel, err = ParseChannel("grafana/dashboard")
+	require.NoError(t, err)
+	assert.Equal(t, Channel{Scope: "grafana", Namespace: "dashboard"}, channel)
+
+	for _, name := range []string{"", "grafana", "grafana/", "/dashboard", "grafana//abc", "grafana/dashboard/a b", "grafana/dashboard/abc/"} {
+		_, err := ParseChannel(name)
+		assert.Equal(t, ErrInvalidChannel, err, name)
+	}
+}
+
+type fakeChannelHandler struct {
+	subscribeErr error
+}
+
+func (h *fakeChannelHandler) OnSubscribe(user *models.SignedInUser, channel Channel) error {
+	return h.subscribeErr
+}
+
+func (h *fakeChannelHandler) OnPublish(user *models.SignedInUser, channel Channel, data json.RawMessage) (json.RawMessage, error) {
+	if user.OrgRole != models.ROLE_ADMIN {
+		return nil, ErrPermissionDenied
+	}
+	return data, nil
+}
+
+func TestStreamManager(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sm := NewStreamManager(&setting.Cfg{}, NewMemoryBroker())
+	sm.RegisterChannelHandler("test", &fakeChannelHandler{})
+	sm.RegisterChannelHandler("test/private", &fakeChannelHandler{subscribeErr: ErrPermissionDenied})
+	sm.Run(ctx)
+	waitForBroker(t, sm.hub.broker.(*memoryBroker))
+
+	connect := func(orgID int64, role models.RoleType) *connection {
+		user := &models.SignedInUser{UserId: 1, OrgId: orgID, OrgRole: role}
+		c := newConnection(nil, sm.hub, sm.handlers, user, log.New("test"))
+		sm.hub.register <- c
+		return c
+	}
+
+	receive := func(c *connection) string {
+		select {
+		case msg := <-c.send:
+			return string(msg)
+		case <-time.After(time.Second):
+			return ""
+		}
+	}
+
+	viewer := connect(1, models.ROLE_VIEWER)
+	admin := connect(1, models.ROLE_ADMIN)
+	otherOrg := connect(2, models.ROLE_ADMIN)
+
+	viewer.handleMessage([]byte(`{"action": "subscribe", "channel": "test/public/a"}`))
+	otherOrg.handleMessage([]byte(`{"action": "subscribe", "channel": "test/public/a"}`))
+
+	t.Run("should send messages to subscribers of the channel in the org", func(t *testing.T) {
+		admin.handleMessage([]byte(`{"action": "publish", "channel": "test/public/a", "data": {"value": 1}}`))
+		assert.JSONEq(t, `{"channel": "test/public/a", "data": {"value": 1}}`, receive(viewer))
+
+		require.NoError(t, sm.Publish(2, "test/public/a", map[string]int{"value": 2}))
+		assert.JSONEq(t, `{"channel": "test/public/a", "data": {"value": 2}}`, receive(otherOrg))
+		assert.Empty(t, viewer.send)
+	})
+
+	t.Run("should reject unauthorized subscriptions and messages with error frames", func(t *testing.T) {
+		viewer.handleMessage([]byte(`{"action": "subscribe", "channel": "test/private/a"}`))
+		assert.JSONEq(t, `{"action": "subscribe", "channel": "test/private/a", "error": "permission denied"}`, receive(viewer))
+
+		viewer.handleMessage([]byte(`{"action": "publish", "channel": "test/public/a", "data": {}}`))
+		assert.JSONEq(t, `{"action": "publish", "channel": "test/public/a", "error": "permission denied"}`, receive(viewer))
+
+		viewer.handleMessage([]byte(`{"action": "subscribe", "channel": "unknown/channel"}`))
+		assert.JSONEq(t, `{"action": "subscribe", "channel": "unknown/channel", "error": "unknown channel"}`, receive(viewer))
+
+		viewer.handleMessage([]byte(`{"action": "subscribe", "channel": "test"}`))
+		assert.JSONEq(t, `{"action": "subscribe", "channel": "test", "error": "invalid channel name"}`, receive(viewer))
+	})
+
+	t.Run("should not send messages after unsubscribing", func(t *testing.T) {
+		viewer.handleMessage([]byte(`{"action": "unsubscribe", "channel": "test/public/a"}`))
+		require.NoError(t, sm.Publish(1, "test/public/a", 3))
+		assert.Equal(t, "", receive(viewer))
+	})
+
+	t.Run("should publish dashboard events to the channel of the dashboard", func(t *testing.T) {
+		sm.RegisterChannelHandler("grafana/dashboard", &fakeChannelHandler{})
+		viewer.handleMessage([]byte(`{"action": "subscribe", "channel": "grafana/dashboard/abc"}`))
+
+		timestamp := time.Date(2020, 4, 1, 10, 0, 0, 0, time.UTC)
+		require.NoError(t, sm.onDashboardSaved(&events.DashboardSaved{Timestamp: timestamp, OrgId: 1, Uid: "abc", Title: "Test", Version: 3, Provisioned: true}))
+		assert.JSONEq(t, `{"channel": "grafana/dashboard/abc", "data": {
+			"action": "provisioned", "uid": "abc", "title": "Test", "version": 3, "timestamp": "2020-04-01T10:00:00Z"
+		}}`, receive(viewer))
+
+		require.NoError(t, sm.onDashboardDeleted(&events.DashboardDeleted{Timestamp: timestamp, OrgId: 1, Uid: "abc"}))
+		assert.JSONEq(t, `{"channel": "grafana/dashboard/abc", "data": {
+			"action": "deleted", "uid": "abc", "timestamp": "2020-04-01T10:00:00Z"
+		}}`, receive(viewer))
+	})
+}
+
+func TestHubIgnoresSubscriptionsOfClosedConnections(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	h := newHub(NewMemoryBroker(), 10)
+	go h.run(ctx)
+	<-h.started
+
+	// buffered messages are replayed to new subscribers
+	h.publish <- &ChannelMessage{OrgID: 1, Channel: "test/a", Data: json.RawMessage(`{}`), Points: 1}
+
+	user := &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_VIEWER}
+	c := newConnection(nil, h, nil, user, log.New("test"))
+	h.register <- c
+	h.unregister <- c
+
+	h.subChannel <- &channelSubscription{conn: c, key: orgChannelKey(1, "test/a"), handler: &fakeChannelHandler{}}
+	// the hub handles the subscription before the next message
+	h.register <- newConnection(nil, h, nil, user, log.New("test"))
+
+	assert.Empty(t, h.channels[orgChannelKey(1, "test/a")])
+}
+
+// waitForBroker waits until the broker runs. It polls instead of using require.Eventually,
+// which can send on a closed channel when the condition is slow under the race detector.
+func waitForBroker(t *testing.T, b *memoryBroker) {
+	running := func() bool {
+		b.mutex.RLock()
+		defer b.mutex.RUnlock()
+		return b.deliver != nil
+	}
+
+	for deadline := time.Now().Add(time.Second); !running(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("broker is not running")
+		}
+	}
+}
@@ -0,0 +1,369 @@
+package live
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Measurement is a point of a series pushed to a live channel. Field values are
+// float64, string or bool.
+type Measurement struct {
+	Name   string
+	Tags   map[string]string
+	Fields map[string]interface{}
+	Time   time.Time
+}
+
+// MeasurementsError is returned for measurements that can not be parsed or converted
+// to data frames.
+type MeasurementsError struct {
+	msg string
+}
+
+func (e *MeasurementsError) Error() string {
+	return e.msg
+}
+
+func measurementsErrorf(format string, args ...interface{}) error {
+	return &MeasurementsError{msg: fmt.Sprintf(format, args...)}
+}
+
+var linePrecisions = map[string]time.Duration{
+	"":   time.Nanosecond,
+	"ns": time.Nanosecond,
+	"u":  time.Microsecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+}
+
+// ParseLineProtocol parses measurements in the Influx line protocol. Timestamps have
+// the precision ns, us, ms or s, lines without a timestamp get the time now.
+func ParseLineProtocol(body []byte, precision string, now time.Time) ([]Measurement, error) {
+	unit, ok := linePrecisions[precision]
+	if !ok {
+		return nil, measurementsErrorf("invalid precision %q", precision)
+	}
+
+	var measurements []Measurement
+	for i, line := range strings.Split(string(body), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		m, err := parseLine(line, unit, now)
+		if err != nil {
+			return nil, measurementsErrorf("line %d: %s", i+1, err)
+		}
+		measurements = append(measurements, m)
+	}
+	return measurements, nil
+}
+
+func parseLine(line string, unit time.Duration, now time.Time) (Measurement, error) {
+	sections := splitUnescaped(line, ' ')
+	if len(sections) < 2 || len(sections) > 3 {
+		return Measurement{}, fmt.Errorf("expected measurement, fields and optional timestamp")
+	}
+
+	key := splitUnescaped(sections[0], ',')
+	m := Measurement{
+		Name:   unescape(key[0]),
+		Tags:   make(map[string]string),
+		Fields: make(map[string]interface{}),
+		Time:   now,
+	}
+	if m.Name == "" {
+		return m, fmt.Errorf("missing measurement name")
+	}
+
+	for _, tag := range key[1:] {
+		name, value, err := splitKeyValue(tag)
+		if err != nil {
+			return m, err
+		}
+		m.Tags[unescape(name)] = unescape(value)
+	}
+
+	for _, field := range splitUnescaped(sections[1], ',') {
+		name, value, err := splitKeyValue(field)
+		if err != nil {
+			return m, err
+		}
+		parsed, err := parseFieldValue(value)
+		if err != nil {
+			return m, fmt.Errorf("field %s: %s", name, err)
+		}
+		m.Fields[unescape(name)] = parsed
+	}
+
+	if len(sections) == 3 {
+		timestamp, err := strconv.ParseInt(sections[2], 10, 64)
+		if err != nil {
+			return m, fmt.Errorf("invalid timestamp %q", sections[2])
+		}
+		m.Time = time.Unix(0, timestamp*int64(unit))
+	}
+	return m, nil
+}
+
+func parseFieldValue(value string) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(value, `"`):
+		if len(value) < 2 || !strings.HasSuffix(value, `"`) {
+			return nil, fmt.Errorf("unterminated string")
+		}
+		return unescape(value[1 : len(value)-1]), nil
+	case value == "t" || value == "T" || strings.EqualFold(value, "true"):
+		return true, nil
+	case value == "f" || value == "F" || strings.EqualFold(value, "false"):
+		return false, nil
+	case strings.HasSuffix(value, "i"):
+		i, err := strconv.ParseInt(strings.TrimSuffix(value, "i"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", value)
+		}
+		return float64(i), nil
+	case strings.HasSuffix(value, "u"):
+		u, err := strconv.ParseUint(strings.TrimSuffix(value, "u"), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid unsigned integer %q", value)
+		}
+		return float64(u), nil
+	}
+
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value %q", value)
+	}
+	return f, nil
+}
+
+// splitUnescaped splits s at the separators that are neither escaped with a backslash
+// nor inside a double quoted string.
+func splitUnescaped(s string, sep byte) []string {
+	var parts []string
+	escaped, quoted, start := false, false, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case escaped:
+			escaped = false
+		case s[i] == '\\':
+			escaped = true
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+func splitKeyValue(s string) (string, string, error) {
+	parts := splitUnescaped(s, '=')
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid key value pair %q", s)
+	}
+	return parts[0], parts[1], nil
+}
+
+func unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			i++
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+type jsonMeasurement struct {
+	Name   string                 `json:"name"`
+	Tags   map[string]string      `json:"tags"`
+	Fields map[string]interface{} `json:"fields"`
+	// Time is the time of the measurement in milliseconds since the epoch.
+	Time *int64 `json:"time"`
+}
+
+// ParseJSONMeasurements parses a measurement or an array of measurements like
+// {"name": "cpu", "tags": {"host": "a"}, "fields": {"value": 1}, "time": 1585735200000}.
+// Measurements without a time get the time now.
+func ParseJSONMeasurements(body []byte, now time.Time) ([]Measurement, error) {
+	var items []jsonMeasurement
+	trimmed := bytes.TrimSpace(body)
+	if bytes.HasPrefix(trimmed, []byte("[")) {
+		if err := json.Unmarshal(trimmed, &items); err != nil {
+			return nil, measurementsErrorf("invalid JSON: %s", err)
+		}
+	} else {
+		var item jsonMeasurement
+		if err := json.Unmarshal(trimmed, &item); err != nil {
+			return nil, measurementsErrorf("invalid JSON: %s", err)
+		}
+		items = append(items, item)
+	}
+
+	measurements := make([]Measurement, 0, len(items))
+	for i, item := range items {
+		if item.Name == "" {
+			return nil, measurementsErrorf("measurement %d: missing name", i)
+		}
+		if len(item.Fields) == 0 {
+			return nil, measurementsErrorf("measurement %d: missing fields", i)
+		}
+		for name, value := range item.Fields {
+			switch value.(type) {
+			case float64, string, bool:
+			default:
+				return nil, measurementsErrorf("measurement %d: field %s must be a number, string or boolean", i, name)
+			}
+		}
+
+		m := Measurement{Name: item.Name, Tags: item.Tags, Fields: item.Fields, Time: now}
+		if item.Time != nil {
+			m.Time = time.Unix(0, *item.Time*int64(time.Millisecond))
+		}
+		measurements = append(measurements, m)
+	}
+	return measurements, nil
+}
+
+// measurementSeries collects the points of the measurements with the same name and tags.
+type measurementSeries struct {
+	name   string
+	tags   map[string]string
+	times  []time.Time
+	fields map[string][]interface{}
+}
+
+// measurementFrames converts measurements to a data frame per series, with a time field
+// and a field per measurement field that has the tags as labels.
+func measurementFrames(measurements []Measurement) (data.Frames, error) {
+	var series []*measurementSeries
+	seriesByKey := make(map[string]*measurementSeries)
+
+	for _, m := range measurements {
+		key := seriesKey(m)
+		s, exists := seriesByKey[key]
+		if !exists {
+			s = &measurementSeries{name: m.Name, tags: m.Tags, fields: make(map[string][]interface{})}
+			seriesByKey[key] = s
+			series = append(series, s)
+		}
+
+		for name := range m.Fields {
+			if _, exists := s.fields[name]; !exists {
+				s.fields[name] = make([]interface{}, len(s.times))
+			}
+		}
+		for name, values := range s.fields {
+			s.fields[name] = append(values, m.Fields[name])
+		}
+		s.times = append(s.times, m.Time)
+	}
+
+	frames := make(data.Frames, 0, len(series))
+	for _, s := range series {
+		frame, err := s.frame()
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+	return frames, nil
+}
+
+func (s *measurementSeries) frame() (*data.Frame, error) {
+	names := make([]string, 0, len(s.fields))
+	for name := range s.fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fields := []*data.Field{data.NewField("time", nil, s.times)}
+	for _, name := range names {
+		field, err := newMeasurementField(name, data.Labels(s.tags), s.fields[name])
+		if err != nil {
+			return nil, measurementsErrorf("measurement %s: %s", s.name, err)
+		}
+		fields = append(fields, field)
+	}
+	return data.NewFrame(s.name, fields...), nil
+}
+
+// newMeasurementField creates a nullable field of the type of the first value.
+func newMeasurementField(name string, labels data.Labels, values []interface{}) (*data.Field, error) {
+	var first interface{}
+	for _, value := range values {
+		if value != nil {
+			first = value
+			break
+		}
+	}
+
+	var field *data.Field
+	switch first.(type) {
+	case float64:
+		field = data.NewField(name, labels, make([]*float64, len(values)))
+	case string:
+		field = data.NewField(name, labels, make([]*string, len(values)))
+	case bool:
+		field = data.NewField(name, labels, make([]*bool, len(values)))
+	default:
+		return nil, fmt.Errorf("field %s has no values", name)
+	}
+
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+
+		switch v := value.(type) {
+		case float64:
+			if field.Type() != data.FieldTypeNullableFloat64 {
+				return nil, fmt.Errorf("field %s has values of different types", name)
+			}
+			field.Set(i, &v)
+		case string:
+			if field.Type() != data.FieldTypeNullableString {
+				return nil, fmt.Errorf("field %s has values of different types", name)
+			}
+			field.Set(i, &v)
+		case bool:
+			if field.Type() != data.FieldTypeNullableBool {
+				return nil, fmt.Errorf("field %s has values of different types", name)
+			}
+			field.Set(i, &v)
+		}
+	}
+	return field, nil
+}
+
+func seriesKey(m Measurement) string {
+	names := make([]string, 0, len(m.Tags))
+	for name := range m.Tags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString(m.Name)
+	for _, name := range names {
+		b.WriteString("\x00" + name + "=" + m.Tags[name])
+	}
+	return b.String()
+}
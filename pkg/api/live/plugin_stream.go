@@ -0,0 +1,149 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+)
+
+// pluginStreamRetryInterval is the time to wait before a failed plugin stream is restarted.
+var pluginStreamRetryInterval = 5 * time.Second
+
+// PluginContextGetter returns the plugin context of the stream of a plugin channel of an
+// organization, and the path of the stream. With a user, it returns an error if the user
+// is not allowed to use the stream. Without a user, it returns the context of the stream
+// shared by all subscribers, which has no user.
+type PluginContextGetter func(orgID int64, user *models.SignedInUser, channel Channel) (backend.PluginContext, string, error)
+
+// pluginHandler handles the plugin/<id>/<path> channels of installed plugins. Backend
+// data source plugins with streaming enabled stream the messages of their channels.
+type pluginHandler struct {
+	manager          backendplugin.Manager
+	getPluginContext PluginContextGetter
+	log              log.Logger
+}
+
+// NewPluginChannelHandler creates the handler of plugin channels, which runs the streams
+// of plugins with the backend plugin manager.
+func NewPluginChannelHandler(manager backendplugin.Manager, getPluginContext PluginContextGetter) ChannelHandler {
+	return &pluginHandler{
+		manager:          manager,
+		getPluginContext: getPluginContext,
+		log:              log.New("live.plugin"),
+	}
+}
+
+// loadedPlugins and loadedDataSources return the loaded plugins, tests replace them.
+var (
+	loadedPlugins     = plugins.Plugins
+	loadedDataSources = plugins.DataSources
+)
+
+func (h *pluginHandler) isStreaming(pluginID string) bool {
+	if h.manager == nil {
+		return false
+	}
+
+	ds, exists := loadedDataSources()[pluginID]
+	return exists && ds.Backend && ds.Streaming
+}
+
+func (h *pluginHandler) OnSubscribe(user *models.SignedInUser, channel Channel) error {
+	if _, exists := loadedPlugins()[channel.Namespace]; !exists {
+		return ErrUnknownChannel
+	}
+
+	if h.isStreaming(channel.Namespace) {
+		_, _, err := h.getPluginContext(user.OrgId, user, channel)
+		return err
+	}
+	return nil
+}
+
+func (h *pluginHandler) OnPublish(user *models.SignedInUser, channel Channel, data json.RawMessage) (json.RawMessage, error) {
+	if !h.isStreaming(channel.Namespace) {
+		return nil, ErrPermissionDenied
+	}
+
+	pCtx, path, err := h.getPluginContext(user.OrgId, user, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.manager.PublishStream(context.Background(), pCtx, path, data)
+	if err != nil {
+		if err == backendplugin.ErrStreamPublishDenied {
+			return nil, ErrPermissionDenied
+		}
+		return nil, err
+	}
+
+	if len(result) == 0 {
+		return data, nil
+	}
+	if !json.Valid(result) {
+		return nil, errors.New("plugin returned an invalid message")
+	}
+	return result, nil
+}
+
+// RunChannel runs the stream of the plugin for all subscribers of the channel, and
+// restarts it if it fails while the channel has subscribers.
+func (h *pluginHandler) RunChannel(ctx context.Context, orgID int64, channel Channel, publish func(data json.RawMessage)) {
+	if !h.isStreaming(channel.Namespace) {
+		return
+	}
+
+	pCtx, path, err := h.getPluginContext(orgID, nil, channel)
+	if err != nil {
+		h.log.Warn("Failed to get plugin context of stream", "channel", channel.String(), "error", err)
+		return
+	}
+
+	for {
+		h.log.Debug("Running plugin stream", "channel", channel.String(), "orgId", orgID)
+		err := h.manager.RunStream(ctx, pCtx, path, func(msg *backendplugin.StreamMessage) error {
+			data, err := streamMessageData(msg)
+			if err != nil {
+				h.log.Warn("Invalid plugin stream message", "channel", channel.String(), "error", err)
+				return nil
+			}
+			publish(data)
+			return nil
+		})
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			h.log.Error("Plugin stream failed", "channel", channel.String(), "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(pluginStreamRetryInterval):
+		}
+	}
+}
+
+// streamMessageData returns the JSON data of a stream message. Arrow data frames are sent
+// as base64 encoded frames, like the data frames of query results.
+func streamMessageData(msg *backendplugin.StreamMessage) (json.RawMessage, error) {
+	if strings.Contains(msg.ContentType, "arrow") {
+		return json.Marshal(map[string][][]byte{"frames": {msg.Data}})
+	}
+
+	if !json.Valid(msg.Data) {
+		return nil, errors.New("stream message is not valid JSON")
+	}
+	return msg.Data, nil
+}
@@ -0,0 +1,194 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStreamManager struct {
+	backendplugin.Manager
+	runs    int32
+	stopped chan struct{}
+	publish func(path string, data []byte) ([]byte, error)
+}
+
+func (m *fakeStreamManager) RunStream(ctx context.Context, pCtx backend.PluginContext, path string, send func(*backendplugin.StreamMessage) error) error {
+	atomic.AddInt32(&m.runs, 1)
+	defer func() { m.stopped <- struct{}{} }()
+
+	data := []byte(`{"path": "` + path + `", "orgId": ` + strconv.FormatInt(pCtx.OrgID, 10) + `}`)
+	if err := send(&backendplugin.StreamMessage{ContentType: "application/json", Data: data}); err != nil {
+		return err
+	}
+	if err := send(&backendplugin.StreamMessage{ContentType: "application/json", Data: []byte(`invalid`)}); err != nil {
+		return err
+	}
+	if err := send(&backendplugin.StreamMessage{ContentType: "application/vnd.apache.arrow.file", Data: []byte{1, 2, 3}}); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return nil
+}
+
+func (m *fakeStreamManager) PublishStream(ctx context.Context, pCtx backend.PluginContext, path string, data []byte) ([]byte, error) {
+	return m.publish(path, data)
+}
+
+func TestPluginChannelHandler(t *testing.T) {
+	loadedPlugins = func() map[string]*plugins.PluginBase {
+		return map[string]*plugins.PluginBase{
+			"test-stream": {Id: "test-stream"},
+			"test-panel":  {Id: "test-panel"},
+		}
+	}
+	loadedDataSources = func() map[string]*plugins.DataSourcePlugin {
+		return map[string]*plugins.DataSourcePlugin{
+			"test-stream": {FrontendPluginBase: plugins.FrontendPluginBase{PluginBase: plugins.PluginBase{Id: "test-stream"}}, Backend: true, Streaming: true},
+		}
+	}
+	defer func() {
+		loadedPlugins = plugins.Plugins
+		loadedDataSources = plugins.DataSources
+	}()
+
+	manager := &fakeStreamManager{stopped: make(chan struct{}, 2)}
+	getPluginContext := func(orgID int64, user *models.SignedInUser, channel Channel) (backend.PluginContext, string, error) {
+		if user != nil && user.OrgRole != models.ROLE_ADMIN {
+			return backend.PluginContext{}, "", ErrPermissionDenied
+		}
+		return backend.PluginContext{OrgID: orgID, PluginID: channel.Namespace}, channel.Path, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sm := NewStreamManager(&setting.Cfg{}, NewMemoryBroker())
+	sm.RegisterChannelHandler("plugin", NewPluginChannelHandler(manager, getPluginContext))
+	sm.Run(ctx)
+	waitForBroker(t, sm.hub.broker.(*memoryBroker))
+
+	connect := func(userID int64, login string, role models.RoleType) *connection {
+		user := &models.SignedInUser{UserId: userID, Login: login, OrgId: 1, OrgRole: role}
+		c := newConnection(nil, sm.hub, sm.handlers, user, log.New("test"))
+		sm.hub.register <- c
+		return c
+	}
+
+	receive := func(c *connection) string {
+		select {
+		case msg := <-c.send:
+			return string(msg)
+		case <-time.After(time.Second):
+			return ""
+		}
+	}
+
+	first := connect(1, "admin", models.ROLE_ADMIN)
+	second := connect(1, "admin", models.ROLE_ADMIN)
+	viewer := connect(2, "viewer", models.ROLE_VIEWER)
+
+	t.Run("should run the stream of the channel once for all subscribers", func(t *testing.T) {
+		first.handleMessage([]byte(`{"action": "subscribe", "channel": "plugin/test-stream/1/random"}`))
+		assert.JSONEq(t, `{"channel": "plugin/test-stream/1/random", "data": {"path": "1/random", "orgId": 1}}`, receive(first))
+		assert.JSONEq(t, `{"channel": "plugin/test-stream/1/random", "data": {"frames": ["AQID"]}}`, receive(first))
+
+		second.handleMessage([]byte(`{"action": "subscribe", "channel": "plugin/test-stream/1/random"}`))
+		require.NoError(t, sm.Publish(1, "plugin/test-stream/1/random", map[string]int{"value": 1}))
+		assert.JSONEq(t, `{"channel": "plugin/test-stream/1/random", "data": {"value": 1}}`, receive(first))
+		assert.JSONEq(t, `{"channel": "plugin/test-stream/1/random", "data": {"value": 1}}`, receive(second))
+		assert.Equal(t, int32(1), atomic.LoadInt32(&manager.runs))
+	})
+
+	t.Run("should stop the stream when the channel has no subscribers", func(t *testing.T) {
+		first.handleMessage([]byte(`{"action": "unsubscribe", "channel": "plugin/test-stream/1/random"}`))
+		select {
+		case <-manager.stopped:
+			t.Fatal("stream stopped while the channel has subscribers")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		second.handleMessage([]byte(`{"action": "unsubscribe", "channel": "plugin/test-stream/1/random"}`))
+		select {
+		case <-manager.stopped:
+		case <-time.After(time.Second):
+			t.Fatal("stream not stopped")
+		}
+	})
+
+	t.Run("should share the stream of the channel between users", func(t *testing.T) {
+		alice := connect(3, "alice", models.ROLE_ADMIN)
+		bob := connect(4, "bob", models.ROLE_ADMIN)
+		runs := atomic.LoadInt32(&manager.runs)
+
+		alice.handleMessage([]byte(`{"action": "subscribe", "channel": "plugin/test-stream/1/random"}`))
+		assert.JSONEq(t, `{"channel": "plugin/test-stream/1/random", "data": {"path": "1/random", "orgId": 1}}`, receive(alice))
+		assert.JSONEq(t, `{"channel": "plugin/test-stream/1/random", "data": {"frames": ["AQID"]}}`, receive(alice))
+
+		bob.handleMessage([]byte(`{"action": "subscribe", "channel": "plugin/test-stream/1/random"}`))
+		require.NoError(t, sm.Publish(1, "plugin/test-stream/1/random", map[string]int{"value": 1}))
+		assert.JSONEq(t, `{"channel": "plugin/test-stream/1/random", "data": {"value": 1}}`, receive(alice))
+		assert.JSONEq(t, `{"channel": "plugin/test-stream/1/random", "data": {"value": 1}}`, receive(bob))
+		assert.Equal(t, runs+1, atomic.LoadInt32(&manager.runs))
+
+		alice.handleMessage([]byte(`{"action": "unsubscribe", "channel": "plugin/test-stream/1/random"}`))
+		select {
+		case <-manager.stopped:
+			t.Fatal("stream stopped while bob is subscribed")
+		case <-time.After(50 * time.Millisecond):
+		}
+
+		bob.handleMessage([]byte(`{"action": "unsubscribe", "channel": "plugin/test-stream/1/random"}`))
+		select {
+		case <-manager.stopped:
+		case <-time.After(time.Second):
+			t.Fatal("stream not stopped")
+		}
+	})
+
+	t.Run("should check access to the stream of the channel", func(t *testing.T) {
+		viewer.handleMessage([]byte(`{"action": "subscribe", "channel": "plugin/test-stream/1/random"}`))
+		assert.JSONEq(t, `{"action": "subscribe", "channel": "plugin/test-stream/1/random", "error": "permission denied"}`, receive(viewer))
+
+		viewer.handleMessage([]byte(`{"action": "subscribe", "channel": "plugin/unknown/1/random"}`))
+		assert.JSONEq(t, `{"action": "subscribe", "channel": "plugin/unknown/1/random", "error": "unknown channel"}`, receive(viewer))
+	})
+
+	t.Run("should send published messages to the plugin", func(t *testing.T) {
+		handler := NewPluginChannelHandler(manager, getPluginContext)
+		admin := &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_ADMIN}
+		channel := Channel{Scope: "plugin", Namespace: "test-stream", Path: "1/random"}
+
+		manager.publish = func(path string, data []byte) ([]byte, error) {
+			assert.Equal(t, "1/random", path)
+			return []byte(`{"value": 2}`), nil
+		}
+		data, err := handler.OnPublish(admin, channel, json.RawMessage(`{"value": 1}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"value": 2}`, string(data))
+
+		manager.publish = func(path string, data []byte) ([]byte, error) { return nil, nil }
+		data, err = handler.OnPublish(admin, channel, json.RawMessage(`{"value": 1}`))
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"value": 1}`, string(data))
+
+		manager.publish = func(path string, data []byte) ([]byte, error) { return nil, backendplugin.ErrStreamPublishDenied }
+		_, err = handler.OnPublish(admin, channel, json.RawMessage(`{}`))
+		assert.Equal(t, ErrPermissionDenied, err)
+
+		_, err = handler.OnPublish(admin, Channel{Scope: "plugin", Namespace: "test-panel", Path: "a"}, json.RawMessage(`{}`))
+		assert.Equal(t, ErrPermissionDenied, err)
+	})
+}
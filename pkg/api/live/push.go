@@ -0,0 +1,159 @@
+package live
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// ErrPushRateLimited is returned when an organization pushes measurements faster than the
+// push rate limit.
+var ErrPushRateLimited = errors.New("too many live push requests")
+
+// pushBufferTTL is the time the buffered measurements of a channel are kept after the
+// last push to the channel.
+const pushBufferTTL = time.Hour
+
+// PublishMeasurements publishes measurements to a channel of the organization of the user
+// as data frames. The handler of the channel authorizes the message like messages published
+// by clients.
+func (sm *StreamManager) PublishMeasurements(user *models.SignedInUser, name string, measurements []Measurement) error {
+	channel, err := ParseChannel(name)
+	if err != nil {
+		return err
+	}
+
+	handler, err := sm.handlers.get(channel)
+	if err != nil {
+		return err
+	}
+
+	if !sm.pushLimiter.allow(user.OrgId) {
+		return ErrPushRateLimited
+	}
+
+	frames, err := measurementFrames(measurements)
+	if err != nil {
+		return err
+	}
+
+	encoded := make([][]byte, 0, len(frames))
+	for _, frame := range frames {
+		bytes, err := frame.MarshalArrow()
+		if err != nil {
+			return err
+		}
+		encoded = append(encoded, bytes)
+	}
+
+	bytes, err := json.Marshal(map[string][][]byte{"frames": encoded})
+	if err != nil {
+		return err
+	}
+
+	bytes, err = handler.OnPublish(user, channel, bytes)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-sm.hub.started:
+	default:
+		return nil
+	}
+
+	return sm.hub.broadcast(&ChannelMessage{OrgID: user.OrgId, Channel: channel.String(), Data: bytes, Points: len(measurements)})
+}
+
+// streamHandler handles the stream/<namespace>/<path> channels that measurements are pushed to.
+// All users of the organization can subscribe, editors can publish.
+type streamHandler struct{}
+
+func (h *streamHandler) OnSubscribe(user *models.SignedInUser, channel Channel) error {
+	return nil
+}
+
+func (h *streamHandler) OnPublish(user *models.SignedInUser, channel Channel, data json.RawMessage) (json.RawMessage, error) {
+	if !user.OrgRole.Includes(models.ROLE_EDITOR) {
+		return nil, ErrPermissionDenied
+	}
+	return data, nil
+}
+
+// orgRateLimiter limits the rate of requests of every organization with a token bucket
+// that holds up to a second of requests.
+type orgRateLimiter struct {
+	mutex   sync.Mutex
+	rate    float64
+	buckets map[int64]*tokenBucket
+	now     func() time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func newOrgRateLimiter(rate int) *orgRateLimiter {
+	return &orgRateLimiter{
+		rate:    float64(rate),
+		buckets: make(map[int64]*tokenBucket),
+		now:     time.Now,
+	}
+}
+
+// allow takes a token from the bucket of the organization, unless it is empty.
+func (l *orgRateLimiter) allow(orgID int64) bool {
+	if l.rate <= 0 {
+		return true
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	bucket, exists := l.buckets[orgID]
+	if !exists {
+		bucket = &tokenBucket{tokens: l.rate, updated: now}
+		l.buckets[orgID] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.updated).Seconds() * l.rate
+	if bucket.tokens > l.rate {
+		bucket.tokens = l.rate
+	}
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// messageBuffer holds the last messages of a channel that contain at least the last
+// size points, so that new subscribers receive them.
+type messageBuffer struct {
+	messages []bufferedMessage
+	points   int
+	updated  time.Time
+}
+
+type bufferedMessage struct {
+	points int
+	data   []byte
+}
+
+func (b *messageBuffer) add(points int, data []byte, size int, now time.Time) {
+	b.messages = append(b.messages, bufferedMessage{points: points, data: data})
+	b.points += points
+	b.updated = now
+
+	for len(b.messages) > 1 && b.points-b.messages[0].points >= size {
+		b.points -= b.messages[0].points
+		b.messages = b.messages[1:]
+	}
+}
@@ -0,0 +1,194 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLineProtocol(t *testing.T) {
+	now := time.Date(2020, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	measurements, err := ParseLineProtocol([]byte(`
+# comment
+cpu,host=a,region=us\ west value=0.5,count=3i,ok=t,msg="a \"b\", c" 1585735200000
+mem free=10u
+`), "ms", now)
+	require.NoError(t, err)
+	require.Len(t, measurements, 2)
+
+	assert.Equal(t, Measurement{
+		Name:   "cpu",
+		Tags:   map[string]string{"host": "a", "region": "us west"},
+		Fields: map[string]interface{}{"value": 0.5, "count": float64(3), "ok": true, "msg": `a "b", c`},
+		Time:   time.Unix(1585735200, 0),
+	}, measurements[0])
+	assert.Equal(t, Measurement{
+		Name:   "mem",
+		Tags:   map[string]string{},
+		Fields: map[string]interface{}{"free": float64(10)},
+		Time:   now,
+	}, measurements[1])
+
+	for _, body := range []string{"cpu", "cpu value", "cpu value=abc", `cpu value="a`, "cpu value=1 abc", "cpu,host value=1"} {
+		_, err := ParseLineProtocol([]byte(body), "", now)
+		assert.IsType(t, &MeasurementsError{}, err, body)
+	}
+
+	_, err = ParseLineProtocol([]byte("cpu value=1"), "h", now)
+	assert.IsType(t, &MeasurementsError{}, err)
+}
+
+func TestParseJSONMeasurements(t *testing.T) {
+	now := time.Date(2020, 4, 1, 10, 0, 0, 0, time.UTC)
+
+	measurements, err := ParseJSONMeasurements([]byte(`[
+		{"name": "cpu", "tags": {"host": "a"}, "fields": {"value": 0.5}, "time": 1585735200000},
+		{"name": "cpu", "fields": {"value": 1}}
+	]`), now)
+	require.NoError(t, err)
+	require.Len(t, measurements, 2)
+	assert.Equal(t, time.Unix(1585735200, 0), measurements[0].Time)
+	assert.Equal(t, map[string]string{"host": "a"}, measurements[0].Tags)
+	assert.Equal(t, now, measurements[1].Time)
+
+	measurements, err = ParseJSONMeasurements([]byte(`{"name": "cpu", "fields": {"up": true}}`), now)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"up": true}, measurements[0].Fields)
+
+	for _, body := range []string{`{`, `{"fields": {"value": 1}}`, `{"name": "cpu"}`, `{"name": "cpu", "fields": {"value": [1]}}`} {
+		_, err := ParseJSONMeasurements([]byte(body), now)
+		assert.IsType(t, &MeasurementsError{}, err, body)
+	}
+}
+
+func TestMeasurementFrames(t *testing.T) {
+	t1 := time.Unix(1585735200, 0)
+	t2 := t1.Add(time.Second)
+
+	frames, err := measurementFrames([]Measurement{
+		{Name: "cpu", Tags: map[string]string{"host": "a"}, Fields: map[string]interface{}{"value": 0.5}, Time: t1},
+		{Name: "cpu", Tags: map[string]string{"host": "b"}, Fields: map[string]interface{}{"value": 0.7}, Time: t1},
+		{Name: "cpu", Tags: map[string]string{"host": "a"}, Fields: map[string]interface{}{"value": 0.6, "state": "ok"}, Time: t2},
+	})
+	require.NoError(t, err)
+	require.Len(t, frames, 2)
+
+	frame := frames[0]
+	assert.Equal(t, "cpu", frame.Name)
+	require.Len(t, frame.Fields, 3)
+	assert.Equal(t, "time", frame.Fields[0].Name)
+	assert.Equal(t, t2, frame.Fields[0].At(1))
+	assert.Equal(t, "state", frame.Fields[1].Name)
+	assert.Equal(t, data.FieldTypeNullableString, frame.Fields[1].Type())
+	assert.Nil(t, frame.Fields[1].At(0))
+	assert.Equal(t, data.Labels{"host": "a"}, frame.Fields[2].Labels)
+	value, ok := frame.Fields[2].ConcreteAt(1)
+	require.True(t, ok)
+	assert.Equal(t, 0.6, value)
+	assert.Equal(t, data.Labels{"host": "b"}, frames[1].Fields[1].Labels)
+
+	_, err = measurementFrames([]Measurement{
+		{Name: "cpu", Fields: map[string]interface{}{"value": 0.5}, Time: t1},
+		{Name: "cpu", Fields: map[string]interface{}{"value": "high"}, Time: t2},
+	})
+	assert.IsType(t, &MeasurementsError{}, err)
+}
+
+func TestOrgRateLimiter(t *testing.T) {
+	now := time.Date(2020, 4, 1, 10, 0, 0, 0, time.UTC)
+	limiter := newOrgRateLimiter(2)
+	limiter.now = func() time.Time { return now }
+
+	assert.True(t, limiter.allow(1))
+	assert.True(t, limiter.allow(1))
+	assert.False(t, limiter.allow(1))
+	assert.True(t, limiter.allow(2))
+
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, limiter.allow(1))
+	assert.False(t, limiter.allow(1))
+
+	assert.True(t, newOrgRateLimiter(0).allow(1))
+}
+
+func TestPublishMeasurements(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sm := NewStreamManager(&setting.Cfg{LivePushRateLimit: 100, LivePushBufferSize: 2}, NewMemoryBroker())
+	sm.Run(ctx)
+	waitForBroker(t, sm.hub.broker.(*memoryBroker))
+
+	editor := &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_EDITOR}
+	push := func(values ...float64) {
+		measurements := make([]Measurement, 0, len(values))
+		for _, value := range values {
+			measurements = append(measurements, Measurement{Name: "build", Fields: map[string]interface{}{"value": value}, Time: time.Now()})
+		}
+		require.NoError(t, sm.PublishMeasurements(editor, "stream/ci/builds", measurements))
+	}
+
+	receive := func(c *connection) []float64 {
+		select {
+		case msg := <-c.send:
+			var message struct {
+				Data struct {
+					Frames [][]byte `json:"frames"`
+				} `json:"data"`
+			}
+			require.NoError(t, json.Unmarshal(msg, &message))
+			require.Len(t, message.Data.Frames, 1)
+			frame, err := data.UnmarshalArrowFrame(message.Data.Frames[0])
+			require.NoError(t, err)
+
+			var values []float64
+			for i := 0; i < frame.Fields[1].Len(); i++ {
+				value, _ := frame.Fields[1].ConcreteAt(i)
+				values = append(values, value.(float64))
+			}
+			return values
+		case <-time.After(time.Second):
+			return nil
+		}
+	}
+
+	t.Run("should send the buffered points to new subscribers", func(t *testing.T) {
+		push(1)
+		push(2, 3)
+		push(4)
+
+		viewer := newConnection(nil, sm.hub, sm.handlers, &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_VIEWER}, log.New("test"))
+		sm.hub.register <- viewer
+		viewer.handleMessage([]byte(`{"action": "subscribe", "channel": "stream/ci/builds"}`))
+		assert.Equal(t, []float64{2, 3}, receive(viewer))
+		assert.Equal(t, []float64{4}, receive(viewer))
+
+		push(5)
+		assert.Equal(t, []float64{5}, receive(viewer))
+	})
+
+	t.Run("should only allow editors to push", func(t *testing.T) {
+		viewer := &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_VIEWER}
+		err := sm.PublishMeasurements(viewer, "stream/ci/builds", []Measurement{{Name: "build", Fields: map[string]interface{}{"value": 1.0}}})
+		assert.Equal(t, ErrPermissionDenied, err)
+
+		err = sm.PublishMeasurements(editor, "unknown/ci", []Measurement{{Name: "build", Fields: map[string]interface{}{"value": 1.0}}})
+		assert.Equal(t, ErrUnknownChannel, err)
+	})
+
+	t.Run("should limit the rate of pushes of an organization", func(t *testing.T) {
+		sm.pushLimiter = newOrgRateLimiter(1)
+		measurements := []Measurement{{Name: "build", Fields: map[string]interface{}{"value": 1.0}}}
+		require.NoError(t, sm.PublishMeasurements(editor, "stream/ci/builds", measurements))
+		assert.Equal(t, ErrPushRateLimited, sm.PublishMeasurements(editor, "stream/ci/builds", measurements))
+	})
+}
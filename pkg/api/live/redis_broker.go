@@ -0,0 +1,156 @@
+package live
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	redis "gopkg.in/redis.v5"
+)
+
+var (
+	// subscribeRetryBackoff is the delay before subscribing again after the first failure,
+	// it doubles with every failure up to maxSubscribeRetryBackoff.
+	subscribeRetryBackoff    = time.Second
+	maxSubscribeRetryBackoff = 30 * time.Second
+)
+
+// redisClient is the part of the redis client the redis broker uses.
+type redisClient interface {
+	Publish(channel string, message string) error
+	Subscribe(channel string) (redisSubscription, error)
+}
+
+type redisSubscription interface {
+	// ReceiveMessage blocks until the next message of the channel.
+	ReceiveMessage() (string, error)
+	Close() error
+}
+
+// redisMessage is the encoding of messages in the redis channel.
+type redisMessage struct {
+	OrgID   int64           `json:"orgId"`
+	Channel string          `json:"channel"`
+	Data    json.RawMessage `json:"data"`
+	Points  int             `json:"points,omitempty"`
+}
+
+// redisBroker delivers messages to the hubs of all instances through a redis pub/sub channel.
+type redisBroker struct {
+	client  redisClient
+	channel string
+	log     log.Logger
+}
+
+func newRedisBroker(client redisClient, channel string) *redisBroker {
+	return &redisBroker{
+		client:  client,
+		channel: channel,
+		log:     log.New("live.broker.redis"),
+	}
+}
+
+func (b *redisBroker) Publish(msg *ChannelMessage) error {
+	data, err := json.Marshal(&redisMessage{OrgID: msg.OrgID, Channel: msg.Channel, Data: msg.Data, Points: msg.Points})
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(b.channel, string(data))
+}
+
+func (b *redisBroker) Run(ctx context.Context, deliver func(*ChannelMessage)) error {
+	sub, err := b.subscribe(ctx)
+	if err != nil {
+		// the context is done
+		return nil
+	}
+
+	go func() {
+		<-ctx.Done()
+		if err := sub.Close(); err != nil {
+			b.log.Warn("Failed to close redis subscription", "error", err)
+		}
+	}()
+
+	for {
+		payload, err := sub.ReceiveMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			b.log.Error("Failed to receive live message from redis", "error", err)
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+
+		var msg redisMessage
+		if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+			b.log.Warn("Invalid live message in redis channel", "error", err)
+			continue
+		}
+
+		deliver(&ChannelMessage{OrgID: msg.OrgID, Channel: msg.Channel, Data: msg.Data, Points: msg.Points})
+	}
+}
+
+// subscribe subscribes to the redis channel, retrying with backoff until it
+// succeeds or the context is done.
+func (b *redisBroker) subscribe(ctx context.Context) (redisSubscription, error) {
+	backoff := subscribeRetryBackoff
+	for {
+		sub, err := b.client.Subscribe(b.channel)
+		if err == nil {
+			return sub, nil
+		}
+
+		b.log.Error("Failed to subscribe to redis channel", "channel", b.channel, "error", err, "retryIn", backoff)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxSubscribeRetryBackoff {
+			backoff = maxSubscribeRetryBackoff
+		}
+	}
+}
+
+type redisClientAdapter struct {
+	client *redis.Client
+}
+
+func (a *redisClientAdapter) Publish(channel string, message string) error {
+	return a.client.Publish(channel, message).Err()
+}
+
+func (a *redisClientAdapter) Subscribe(channel string) (redisSubscription, error) {
+	pubsub, err := a.client.Subscribe(channel)
+	if err != nil {
+		return nil, err
+	}
+	return &redisSubscriptionAdapter{pubsub: pubsub}, nil
+}
+
+type redisSubscriptionAdapter struct {
+	pubsub *redis.PubSub
+}
+
+func (a *redisSubscriptionAdapter) ReceiveMessage() (string, error) {
+	msg, err := a.pubsub.ReceiveMessage()
+	if err != nil {
+		return "", err
+	}
+	return msg.Payload, nil
+}
+
+func (a *redisSubscriptionAdapter) Close() error {
+	return a.pubsub.Close()
+}
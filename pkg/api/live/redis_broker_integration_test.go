@@ -0,0 +1,30 @@
+// +build redis
+
+package live
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedisBrokerIntegration(t *testing.T) {
+	const channel = "grafana.live.test"
+
+	client, err := remotecache.NewRedisClient("addr=localhost:6379")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = client.Close()
+	})
+
+	testHABrokers(t, func() Broker {
+		client, err := remotecache.NewRedisClient("addr=localhost:6379")
+		require.NoError(t, err)
+		return newRedisBroker(&redisClientAdapter{client: client}, channel)
+	}, func() bool {
+		// the brokers subscribe in the background when the stream managers run
+		subscribers, err := client.PubSubNumSub(channel).Result()
+		return err == nil && subscribers[channel] == 2
+	})
+}
@@ -0,0 +1,140 @@
+package live
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRedis is an in-process redis pub/sub server.
+type fakeRedis struct {
+	mutex         sync.Mutex
+	subscriptions map[string][]*fakeRedisSubscription
+	// subscribeFailures is the number of Subscribe calls that fail before subscribing succeeds
+	subscribeFailures int
+}
+
+type fakeRedisSubscription struct {
+	messages chan string
+	closed   chan struct{}
+}
+
+func (r *fakeRedis) Publish(channel string, message string) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for _, sub := range r.subscriptions[channel] {
+		select {
+		case sub.messages <- message:
+		case <-sub.closed:
+		}
+	}
+	return nil
+}
+
+func (r *fakeRedis) Subscribe(channel string) (redisSubscription, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.subscribeFailures > 0 {
+		r.subscribeFailures--
+		return nil, errors.New("connection refused")
+	}
+
+	sub := &fakeRedisSubscription{messages: make(chan string, 10), closed: make(chan struct{})}
+	r.subscriptions[channel] = append(r.subscriptions[channel], sub)
+	return sub, nil
+}
+
+func (r *fakeRedis) subscribers(channel string) int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return len(r.subscriptions[channel])
+}
+
+func (s *fakeRedisSubscription) ReceiveMessage() (string, error) {
+	select {
+	case msg := <-s.messages:
+		return msg, nil
+	case <-s.closed:
+		return "", errors.New("subscription closed")
+	}
+}
+
+func (s *fakeRedisSubscription) Close() error {
+	close(s.closed)
+	return nil
+}
+
+func testHABrokers(t *testing.T, newBroker func() Broker, ready func() bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	managers := []*StreamManager{NewStreamManager(&setting.Cfg{}, newBroker()), NewStreamManager(&setting.Cfg{}, newBroker())}
+	connections := []*connection{}
+	for _, sm := range managers {
+		sm.RegisterChannelHandler("test", &fakeChannelHandler{})
+		sm.Run(ctx)
+
+		c := newConnection(nil, sm.hub, sm.handlers, &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_ADMIN}, log.New("test"))
+		sm.hub.register <- c
+		c.handleMessage([]byte(`{"action": "subscribe", "channel": "test/ha/a"}`))
+		connections = append(connections, c)
+	}
+	require.Eventually(t, ready, time.Second, time.Millisecond)
+
+	// a message published to the first instance is delivered to the clients of both instances
+	connections[0].handleMessage([]byte(`{"action": "publish", "channel": "test/ha/a", "data": {"value": 1}}`))
+	for _, c := range connections {
+		select {
+		case msg := <-c.send:
+			assert.JSONEq(t, `{"channel": "test/ha/a", "data": {"value": 1}}`, string(msg))
+		case <-time.After(time.Second):
+			t.Fatal("message was not delivered")
+		}
+	}
+
+	// messages of other organizations are not delivered
+	require.NoError(t, managers[1].Publish(2, "test/ha/a", 2))
+	for _, c := range connections {
+		select {
+		case msg := <-c.send:
+			t.Fatalf("unexpected message %s", msg)
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+}
+
+func TestRedisBroker(t *testing.T) {
+	redis := &fakeRedis{subscriptions: map[string][]*fakeRedisSubscription{}}
+
+	testHABrokers(t, func() Broker {
+		return newRedisBroker(redis, "grafana.live")
+	}, func() bool {
+		return redis.subscribers("grafana.live") == 2
+	})
+}
+
+func TestRedisBrokerRetriesSubscribe(t *testing.T) {
+	origBackoff, origMaxBackoff := subscribeRetryBackoff, maxSubscribeRetryBackoff
+	subscribeRetryBackoff, maxSubscribeRetryBackoff = time.Millisecond, 2*time.Millisecond
+	t.Cleanup(func() {
+		subscribeRetryBackoff, maxSubscribeRetryBackoff = origBackoff, origMaxBackoff
+	})
+
+	redis := &fakeRedis{subscriptions: map[string][]*fakeRedisSubscription{}, subscribeFailures: 3}
+
+	testHABrokers(t, func() Broker {
+		return newRedisBroker(redis, "grafana.live")
+	}, func() bool {
+		return redis.subscribers("grafana.live") == 2
+	})
+}
@@ -2,12 +2,13 @@ package live
 
 import (
 	"context"
-	"net/http"
+	"encoding/json"
 	"sync"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
 )
 
 type StreamManager struct {
@@ -15,15 +16,53 @@ type StreamManager struct {
 	streams       map[string]*Stream
 	streamRWMutex *sync.RWMutex
 	hub           *hub
+	handlers      *channelHandlers
+	pushLimiter   *orgRateLimiter
 }
 
-func NewStreamManager() *StreamManager {
-	return &StreamManager{
-		hub:           newHub(),
+// NewStreamManager creates the stream manager with the push settings of the live configuration.
+func NewStreamManager(cfg *setting.Cfg, broker Broker) *StreamManager {
+	sm := &StreamManager{
+		hub:           newHub(broker, cfg.LivePushBufferSize),
+		pushLimiter:   newOrgRateLimiter(cfg.LivePushRateLimit),
 		log:           log.New("stream.manager"),
 		streams:       make(map[string]*Stream),
 		streamRWMutex: &sync.RWMutex{},
+		handlers:      &channelHandlers{handlers: make(map[string]ChannelHandler)},
 	}
+
+	sm.RegisterChannelHandler("grafana/dashboard", &dashboardHandler{})
+	sm.RegisterChannelHandler("plugin", NewPluginChannelHandler(nil, nil))
+	sm.RegisterChannelHandler("stream", &streamHandler{})
+
+	return sm
+}
+
+// RegisterChannelHandler registers the handler of the channels of a scope, e.g. plugin,
+// or of a namespace of a scope, e.g. grafana/dashboard.
+func (sm *StreamManager) RegisterChannelHandler(prefix string, handler ChannelHandler) {
+	sm.handlers.register(prefix, handler)
+}
+
+// Publish sends data to the subscribers of a channel of an organization.
+func (sm *StreamManager) Publish(orgID int64, channel string, data interface{}) error {
+	if _, err := ParseChannel(channel); err != nil {
+		return err
+	}
+
+	// there are no subscribers before the hub runs, e.g. while dashboards are provisioned on startup
+	select {
+	case <-sm.hub.started:
+	default:
+		return nil
+	}
+
+	bytes, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return sm.hub.broadcast(&ChannelMessage{OrgID: orgID, Channel: channel, Data: bytes})
 }
 
 func (sm *StreamManager) Run(context context.Context) {
@@ -33,24 +72,56 @@ func (sm *StreamManager) Run(context context.Context) {
 		sm.hub.run(context)
 		log.Info("Stopped Stream Manager")
 	}()
+
+	go func() {
+		if err := sm.hub.broker.Run(context, sm.hub.deliver); err != nil {
+			sm.log.Error("Live message broker stopped", "error", err)
+		}
+	}()
 }
 
-func (sm *StreamManager) Serve(w http.ResponseWriter, r *http.Request) {
-	sm.log.Info("Upgrading to WebSocket")
+// Serve upgrades the request of a signed in user to a WebSocket connection.
+func (sm *StreamManager) Serve(ctx *models.ReqContext) {
+	sm.log.Info("Upgrading to WebSocket", "user", ctx.Login, "orgId", ctx.OrgId)
 
-	ws, err := upgrader.Upgrade(w, r, nil)
+	ws, err := upgrader.Upgrade(ctx.Resp, ctx.Req.Request, nil)
 	if err != nil {
 		sm.log.Error("Failed to upgrade connection to WebSocket", "error", err)
 		return
 	}
 
-	c := newConnection(ws, sm.hub, sm.log)
+	c := newConnection(ws, sm.hub, sm.handlers, ctx.SignedInUser, sm.log)
 	sm.hub.register <- c
 
 	go c.writePump()
 	c.readPump()
 }
 
+type channelHandlers struct {
+	mutex    sync.RWMutex
+	handlers map[string]ChannelHandler
+}
+
+func (h *channelHandlers) register(prefix string, handler ChannelHandler) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.handlers[prefix] = handler
+}
+
+// get returns the handler of the namespace of the channel, or else of its scope.
+func (h *channelHandlers) get(channel Channel) (ChannelHandler, error) {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	if handler, exists := h.handlers[channel.Scope+"/"+channel.Namespace]; exists {
+		return handler, nil
+	}
+	if handler, exists := h.handlers[channel.Scope]; exists {
+		return handler, nil
+	}
+	return nil, ErrUnknownChannel
+}
+
 func (s *StreamManager) GetStreamList() models.StreamList {
 	list := make(models.StreamList, 0)
 
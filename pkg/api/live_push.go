@@ -0,0 +1,66 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/api/live"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// maxLivePushBodySize is the maximum size of the body of a live push request.
+const maxLivePushBodySize = 1 << 20
+
+// POST /api/live/push/*
+func (hs *HTTPServer) PushLiveMeasurements(c *models.ReqContext) Response {
+	channel := c.Params("*")
+
+	body, err := ioutil.ReadAll(io.LimitReader(c.Req.Request.Body, maxLivePushBodySize+1))
+	if err != nil {
+		return Error(500, "Failed to read request body", err)
+	}
+	if len(body) > maxLivePushBodySize {
+		return Error(413, fmt.Sprintf("Request body is larger than %d bytes", maxLivePushBodySize), nil)
+	}
+
+	var measurements []live.Measurement
+	now := time.Now()
+	if strings.HasPrefix(c.Req.Header.Get("Content-Type"), "application/json") {
+		measurements, err = live.ParseJSONMeasurements(body, now)
+	} else {
+		measurements, err = live.ParseLineProtocol(body, c.Query("precision"), now)
+	}
+	if err != nil {
+		return Error(400, "Invalid measurements: "+err.Error(), nil)
+	}
+	if len(measurements) == 0 {
+		return Error(400, "No measurements in request", nil)
+	}
+
+	err = hs.streamManager.PublishMeasurements(c.SignedInUser, channel, measurements)
+	if err != nil {
+		var measurementsErr *live.MeasurementsError
+		switch {
+		case errors.As(err, &measurementsErr):
+			return Error(400, "Invalid measurements: "+err.Error(), nil)
+		case err == live.ErrInvalidChannel:
+			return Error(400, "Invalid channel name", nil)
+		case err == live.ErrUnknownChannel:
+			return Error(404, "Unknown channel", nil)
+		case err == live.ErrPermissionDenied:
+			return Error(403, "Not allowed to push to the channel", nil)
+		case err == live.ErrPushRateLimited:
+			return Error(429, "Too many live push requests", nil)
+		}
+		return Error(500, "Failed to publish measurements", err)
+	}
+
+	return JSON(200, map[string]interface{}{
+		"message": "Measurements published",
+		"points":  len(measurements),
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/grafana/grafana/pkg/login"
 	"github.com/grafana/grafana/pkg/middleware"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/twofactor"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 	"github.com/grafana/grafana/pkg/util/errutil"
@@ -174,6 +175,58 @@ func (hs *HTTPServer) LoginPost(c *models.ReqContext, cmd dtos.LoginCommand) Res
 
 	user := authQuery.User
 
+	if hs.Cfg.TwoFactorEnabled {
+		enabled, err := hs.TwoFactorService.IsEnabled(user.Id)
+		if err != nil {
+			return Error(500, "Error while trying to authenticate user", err)
+		}
+
+		// the session is created once the code has been verified in LoginTwoFactorPost
+		if enabled {
+			token, err := hs.TwoFactorService.StartLogin(user.Id)
+			if err != nil {
+				return Error(500, "Error while trying to authenticate user", err)
+			}
+
+			return JSON(200, map[string]interface{}{
+				"message":           "Two-factor authentication required",
+				"twoFactorRequired": true,
+				"twoFactorToken":    token,
+			})
+		}
+	}
+
+	return hs.loginSucceeded(c, user)
+}
+
+// POST /login/two-factor
+func (hs *HTTPServer) LoginTwoFactorPost(c *models.ReqContext, cmd dtos.LoginTwoFactorCommand) Response {
+	if setting.DisableLoginForm || !hs.Cfg.TwoFactorEnabled {
+		return Error(401, "Login is disabled", nil)
+	}
+
+	userID, err := hs.TwoFactorService.CompleteLogin(cmd.Token, cmd.Code)
+	if err != nil {
+		switch err {
+		case twofactor.ErrInvalidCode, twofactor.ErrLoginExpired, twofactor.ErrTooManyCodeAttempts, twofactor.ErrLockedOut:
+			return Error(401, err.Error(), err)
+		}
+		return Error(500, "Error while trying to authenticate user", err)
+	}
+
+	query := models.GetUserByIdQuery{Id: userID}
+	if err := bus.Dispatch(&query); err != nil {
+		return Error(500, "Error while trying to authenticate user", err)
+	}
+
+	if query.Result.IsDisabled {
+		return Error(401, "Invalid username or password", nil)
+	}
+
+	return hs.loginSucceeded(c, query.Result)
+}
+
+func (hs *HTTPServer) loginSucceeded(c *models.ReqContext, user *models.User) Response {
 	err := hs.loginUserWithUser(user, c)
 	if err != nil {
 		return Error(500, "Error while signing in user", err)
@@ -2,10 +2,14 @@ package api
 
 import (
 	"context"
+	"fmt"
 	"sort"
+	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/services/querycache"
 	"github.com/grafana/grafana/pkg/setting"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
@@ -27,11 +31,12 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDto dtos.MetricReq
 		TimeRange: tsdb.NewTimeRange(reqDto.From, reqDto.To),
 		Debug:     reqDto.Debug,
 		User:      c.SignedInUser,
+		NoCache:   skipQueryCache(c),
 	}
 
 	expr := false
-	var ds *models.DataSource
-	for i, query := range reqDto.Queries {
+	dataSources := map[int64]*models.DataSource{}
+	for _, query := range reqDto.Queries {
 		name := query.Get("datasource").MustString("")
 		if name == "__expr__" {
 			expr = true
@@ -42,13 +47,20 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDto dtos.MetricReq
 			return Error(500, "datasource missing ID", nil)
 		}
 
-		if i == 0 && !expr {
-			ds, err = hs.DatasourceCache.GetDatasource(datasourceID, c.SignedInUser, c.SkipCache)
-			if err != nil {
-				if err == models.ErrDataSourceAccessDenied {
-					return Error(403, "Access denied to datasource", err)
+		// every data source is loaded to verify that the user is allowed to query it,
+		// including the ones only referenced by expressions
+		var ds *models.DataSource
+		if name != "__expr__" {
+			var exists bool
+			if ds, exists = dataSources[datasourceID]; !exists {
+				ds, err = hs.DatasourceCache.GetDatasource(datasourceID, c.SignedInUser, c.SkipCache)
+				if err != nil {
+					if err == models.ErrDataSourceAccessDenied {
+						return Error(403, "Access denied to datasource", err)
+					}
+					return Error(500, "Unable to load datasource meta data", err)
 				}
-				return Error(500, "Unable to load datasource meta data", err)
+				dataSources[datasourceID] = ds
 			}
 		}
 
@@ -64,11 +76,25 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDto dtos.MetricReq
 
 	var resp *tsdb.Response
 	var err error
+	cacheEnabled := false
+	// the browser can only cache the response if every data source is cached, for the shortest TTL
+	var cacheTTL time.Duration
 	if !expr {
-		resp, err = tsdb.HandleRequest(c.Req.Context(), ds, request)
+		resp, err = tsdb.HandleMixedRequest(c.Req.Context(), request, hs.QueryCacheService.HandleRequest)
 		if err != nil {
 			return Error(500, "Metric request error", err)
 		}
+
+		for _, ds := range dataSources {
+			if !hs.QueryCacheService.IsEnabled(ds) {
+				cacheTTL = -1
+				continue
+			}
+			cacheEnabled = true
+			if ttl := hs.QueryCacheService.TTL(ds, request.TimeRange); cacheTTL == 0 || (cacheTTL > 0 && ttl < cacheTTL) {
+				cacheTTL = ttl
+			}
+		}
 	} else {
 		if !setting.IsExpressionsEnabled() {
 			return Error(404, "Expressions feature toggle is not enabled", nil)
@@ -89,6 +115,10 @@ func (hs *HTTPServer) QueryMetricsV2(c *models.ReqContext, reqDto dtos.MetricReq
 		}
 	}
 
+	if cacheEnabled {
+		setQueryCacheHeaders(c, resp, statusCode == 200, cacheTTL)
+	}
+
 	return JSON(statusCode, &resp)
 }
 
@@ -118,6 +148,7 @@ func (hs *HTTPServer) QueryMetrics(c *models.ReqContext, reqDto dtos.MetricReque
 		TimeRange: timeRange,
 		Debug:     reqDto.Debug,
 		User:      c.SignedInUser,
+		NoCache:   skipQueryCache(c),
 	}
 
 	for _, query := range reqDto.Queries {
@@ -130,7 +161,7 @@ func (hs *HTTPServer) QueryMetrics(c *models.ReqContext, reqDto dtos.MetricReque
 		})
 	}
 
-	resp, err := tsdb.HandleRequest(c.Req.Context(), ds, request)
+	resp, err := hs.QueryCacheService.HandleRequest(c.Req.Context(), ds, request)
 	if err != nil {
 		return Error(500, "Metric request error", err)
 	}
@@ -144,9 +175,32 @@ func (hs *HTTPServer) QueryMetrics(c *models.ReqContext, reqDto dtos.MetricReque
 		}
 	}
 
+	if hs.QueryCacheService.IsEnabled(ds) {
+		setQueryCacheHeaders(c, resp, statusCode == 200, hs.QueryCacheService.TTL(ds, timeRange))
+	}
+
 	return JSON(statusCode, &resp)
 }
 
+// skipQueryCache returns true if the client asked for results that are not read from the query cache
+func skipQueryCache(c *models.ReqContext) bool {
+	return c.SkipCache || strings.Contains(c.Req.Header.Get("Cache-Control"), "no-cache")
+}
+
+// setQueryCacheHeaders sets the X-Cache header of a response of a data source with query caching
+// and lets the browser of the user cache successful responses as long as the query cache does.
+func setQueryCacheHeaders(c *models.ReqContext, resp *tsdb.Response, success bool, ttl time.Duration) {
+	status := querycache.Status(resp, true)
+	if status == "" {
+		return
+	}
+
+	c.Resp.Header().Set("X-Cache", status)
+	if success && ttl >= time.Second {
+		c.Resp.Header().Set("Cache-Control", fmt.Sprintf("private, max-age=%d", int64(ttl/time.Second)))
+	}
+}
+
 // GET /api/tsdb/testdata/scenarios
 func GetTestDataScenarios(c *models.ReqContext) Response {
 	result := make([]interface{}, 0)
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/macaron.v1"
+)
+
+func TestSetQueryCacheHeaders(t *testing.T) {
+	newContext := func() *models.ReqContext {
+		return &models.ReqContext{Context: &macaron.Context{Resp: macaron.NewResponseWriter("POST", httptest.NewRecorder())}}
+	}
+	resp := &tsdb.Response{Results: map[string]*tsdb.QueryResult{"A": {Cached: true}}}
+
+	t.Run("should let the browser cache successful responses", func(t *testing.T) {
+		c := newContext()
+		setQueryCacheHeaders(c, resp, true, time.Minute)
+		assert.Equal(t, "HIT", c.Resp.Header().Get("X-Cache"))
+		assert.Equal(t, "private, max-age=60", c.Resp.Header().Get("Cache-Control"))
+	})
+
+	t.Run("should not let the browser cache failed responses", func(t *testing.T) {
+		c := newContext()
+		setQueryCacheHeaders(c, resp, false, time.Minute)
+		assert.Equal(t, "HIT", c.Resp.Header().Get("X-Cache"))
+		assert.Empty(t, c.Resp.Header().Get("Cache-Control"))
+	})
+
+	t.Run("should not let the browser cache responses of data sources without caching", func(t *testing.T) {
+		c := newContext()
+		setQueryCacheHeaders(c, resp, true, -1)
+		assert.Empty(t, c.Resp.Header().Get("Cache-Control"))
+	})
+}
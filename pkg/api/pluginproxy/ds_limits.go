@@ -0,0 +1,223 @@
+package pluginproxy
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"golang.org/x/xerrors"
+)
+
+// defaultLimiter counts the requests of all data source proxies.
+var defaultLimiter = newDataSourceLimiter()
+
+// dataSourceLimits are the limits of the requests the data source proxy forwards to a data source.
+// Zero means unlimited.
+type dataSourceLimits struct {
+	maxConcurrentRequests int
+	userRequestsPerSecond float64
+	maxResponseSize       int64
+	requestTimeout        time.Duration
+}
+
+// getDataSourceLimits returns the limits of the data source. The dataproxy section of the configuration
+// sets the highest limits, the proxy settings in jsonData of the data source can only lower them.
+func getDataSourceLimits(ds *models.DataSource, cfg *setting.Cfg) dataSourceLimits {
+	limits := dataSourceLimits{
+		maxConcurrentRequests: cfg.DataProxyMaxConcurrentRequests,
+		userRequestsPerSecond: cfg.DataProxyUserRequestsPerSecond,
+		maxResponseSize:       cfg.DataProxyMaxResponseSizeMB * 1024 * 1024,
+		requestTimeout:        time.Duration(cfg.DataProxyRequestTimeout) * time.Second,
+	}
+	if ds.JsonData == nil {
+		return limits
+	}
+
+	if v, ok := jsonDataFloat(ds, "proxyMaxConcurrentRequests"); ok && lowers(v, float64(limits.maxConcurrentRequests)) {
+		limits.maxConcurrentRequests = int(v)
+	}
+	if v, ok := jsonDataFloat(ds, "proxyUserRequestsPerSecond"); ok && lowers(v, limits.userRequestsPerSecond) {
+		limits.userRequestsPerSecond = v
+	}
+	if v, ok := jsonDataFloat(ds, "proxyMaxResponseSizeMB"); ok && lowers(v*1024*1024, float64(limits.maxResponseSize)) {
+		limits.maxResponseSize = int64(v * 1024 * 1024)
+	}
+	if v, ok := jsonDataFloat(ds, "proxyRequestTimeout"); ok && lowers(v*float64(time.Second), float64(limits.requestTimeout)) {
+		limits.requestTimeout = time.Duration(v * float64(time.Second))
+	}
+	return limits
+}
+
+// lowers returns true if the limit of a data source is lower than the limit of the configuration.
+// Zero is unlimited, so a data source can't lift a limit by setting it to zero.
+func lowers(value float64, limit float64) bool {
+	if value <= 0 {
+		return false
+	}
+	return limit <= 0 || value < limit
+}
+
+// jsonDataFloat returns a number of the jsonData of the data source, which may be saved as a string.
+func jsonDataFloat(ds *models.DataSource, key string) (float64, bool) {
+	value, exists := ds.JsonData.CheckGet(key)
+	if !exists {
+		return 0, false
+	}
+	if v, err := value.Float64(); err == nil {
+		return v, v >= 0
+	}
+	if v, err := strconv.ParseFloat(value.MustString(), 64); err == nil {
+		return v, v >= 0
+	}
+	return 0, false
+}
+
+// limitError is returned when a request exceeds a limit of a data source.
+type limitError struct {
+	// reason is the label of the rejected requests metric.
+	reason  string
+	message string
+}
+
+func (e limitError) Error() string {
+	return e.message
+}
+
+// userKey identifies the user, or API key, that requests a data source.
+type userKey struct {
+	dataSourceID int64
+	userID       int64
+	apiKeyID     int64
+}
+
+// dataSourceLimiter counts the requests in flight to every data source, and limits the rate of the
+// requests of every user to a data source with a token bucket that holds up to a second of requests.
+type dataSourceLimiter struct {
+	mutex       sync.Mutex
+	inFlight    map[int64]int
+	buckets     map[userKey]*tokenBucket
+	lastCleanup time.Time
+	now         func() time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func newDataSourceLimiter() *dataSourceLimiter {
+	return &dataSourceLimiter{
+		inFlight: make(map[int64]int),
+		buckets:  make(map[userKey]*tokenBucket),
+		now:      time.Now,
+	}
+}
+
+// acquire admits a request of the user to the data source, unless it exceeds the limits. The
+// returned function must be called once the request is done.
+func (l *dataSourceLimiter) acquire(ds *models.DataSource, user *models.SignedInUser, limits dataSourceLimits) (func(), error) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	if limits.userRequestsPerSecond > 0 {
+		key := userKey{dataSourceID: ds.Id, userID: user.UserId, apiKeyID: user.ApiKeyId}
+		if !l.allow(key, limits.userRequestsPerSecond) {
+			return nil, limitError{
+				reason:  "rate_limit",
+				message: fmt.Sprintf("Too many requests to data source %s, the limit is %g requests per second per user", ds.Name, limits.userRequestsPerSecond),
+			}
+		}
+	}
+
+	if limits.maxConcurrentRequests > 0 && l.inFlight[ds.Id] >= limits.maxConcurrentRequests {
+		return nil, limitError{
+			reason:  "concurrency",
+			message: fmt.Sprintf("Too many concurrent requests to data source %s, the limit is %d", ds.Name, limits.maxConcurrentRequests),
+		}
+	}
+
+	l.inFlight[ds.Id]++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mutex.Lock()
+			defer l.mutex.Unlock()
+			l.inFlight[ds.Id]--
+			if l.inFlight[ds.Id] <= 0 {
+				delete(l.inFlight, ds.Id)
+			}
+		})
+	}, nil
+}
+
+// allow takes a token from the bucket of the user, unless it is empty. Buckets of users that haven't
+// made requests for a while are full and are removed.
+func (l *dataSourceLimiter) allow(key userKey, rate float64) bool {
+	now := l.now()
+	if now.Sub(l.lastCleanup) > time.Minute {
+		for k, bucket := range l.buckets {
+			if now.Sub(bucket.updated) > time.Minute {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastCleanup = now
+	}
+
+	capacity := rate
+	if capacity < 1 {
+		capacity = 1
+	}
+
+	bucket, exists := l.buckets[key]
+	if !exists {
+		bucket = &tokenBucket{tokens: capacity, updated: now}
+		l.buckets[key] = bucket
+	}
+
+	bucket.tokens += now.Sub(bucket.updated).Seconds() * rate
+	if bucket.tokens > capacity {
+		bucket.tokens = capacity
+	}
+	bucket.updated = now
+
+	if bucket.tokens < 1 {
+		return false
+	}
+	bucket.tokens--
+	return true
+}
+
+// errResponseTooLarge is returned when the response of a data source exceeds the response size limit.
+var errResponseTooLarge = xerrors.New("data source response too large")
+
+// limitResponseSize rejects responses larger than the limit. Responses without a content length are
+// buffered up to the limit, so that they can still be rejected before they are sent to the client.
+func limitResponseSize(maxSize int64) func(*http.Response) error {
+	return func(res *http.Response) error {
+		if res.ContentLength > maxSize {
+			res.Body.Close()
+			return errResponseTooLarge
+		}
+		if res.ContentLength >= 0 {
+			return nil
+		}
+
+		body, err := ioutil.ReadAll(io.LimitReader(res.Body, maxSize+1))
+		res.Body.Close()
+		if err != nil {
+			return err
+		}
+		if int64(len(body)) > maxSize {
+			return errResponseTooLarge
+		}
+		res.Body = ioutil.NopCloser(bytes.NewReader(body))
+		return nil
+	}
+}
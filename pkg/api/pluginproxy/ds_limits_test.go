@@ -0,0 +1,231 @@
+package pluginproxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/plugins"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	macaron "gopkg.in/macaron.v1"
+)
+
+func TestDataSourceLimits(t *testing.T) {
+	cfg := &setting.Cfg{
+		DataProxyMaxConcurrentRequests: 10,
+		DataProxyUserRequestsPerSecond: 5,
+		DataProxyMaxResponseSizeMB:     2,
+		DataProxyRequestTimeout:        60,
+	}
+
+	t.Run("Should use limits of dataproxy section by default", func(t *testing.T) {
+		limits := getDataSourceLimits(&models.DataSource{JsonData: simplejson.New()}, cfg)
+		assert.Equal(t, dataSourceLimits{
+			maxConcurrentRequests: 10,
+			userRequestsPerSecond: 5,
+			maxResponseSize:       2 * 1024 * 1024,
+			requestTimeout:        time.Minute,
+		}, limits)
+	})
+
+	t.Run("Should use lower limits of data source", func(t *testing.T) {
+		limits := getDataSourceLimits(&models.DataSource{JsonData: simplejson.NewFromAny(map[string]interface{}{
+			"proxyMaxConcurrentRequests": 2,
+			"proxyUserRequestsPerSecond": "0.5",
+			"proxyMaxResponseSizeMB":     1,
+			"proxyRequestTimeout":        "invalid",
+		})}, cfg)
+		assert.Equal(t, dataSourceLimits{
+			maxConcurrentRequests: 2,
+			userRequestsPerSecond: 0.5,
+			maxResponseSize:       1024 * 1024,
+			requestTimeout:        time.Minute,
+		}, limits)
+	})
+
+	t.Run("Should not let data sources lift limits", func(t *testing.T) {
+		limits := getDataSourceLimits(&models.DataSource{JsonData: simplejson.NewFromAny(map[string]interface{}{
+			"proxyMaxConcurrentRequests": 20,
+			"proxyUserRequestsPerSecond": 0,
+			"proxyMaxResponseSizeMB":     0,
+			"proxyRequestTimeout":        120,
+		})}, cfg)
+		assert.Equal(t, dataSourceLimits{
+			maxConcurrentRequests: 10,
+			userRequestsPerSecond: 5,
+			maxResponseSize:       2 * 1024 * 1024,
+			requestTimeout:        time.Minute,
+		}, limits)
+	})
+
+	t.Run("Should let data sources set limits the configuration doesn't set", func(t *testing.T) {
+		limits := getDataSourceLimits(&models.DataSource{JsonData: simplejson.NewFromAny(map[string]interface{}{
+			"proxyMaxConcurrentRequests": 20,
+		})}, &setting.Cfg{})
+		assert.Equal(t, 20, limits.maxConcurrentRequests)
+	})
+}
+
+func TestDataSourceLimiter(t *testing.T) {
+	ds := &models.DataSource{Id: 1, Name: "Elasticsearch"}
+	user := &models.SignedInUser{UserId: 1}
+	otherUser := &models.SignedInUser{UserId: 2}
+
+	t.Run("Should limit concurrent requests", func(t *testing.T) {
+		l := newDataSourceLimiter()
+		limits := dataSourceLimits{maxConcurrentRequests: 2}
+
+		release, err := l.acquire(ds, user, limits)
+		require.NoError(t, err)
+		_, err = l.acquire(ds, otherUser, limits)
+		require.NoError(t, err)
+
+		_, err = l.acquire(ds, user, limits)
+		require.Error(t, err)
+		assert.Equal(t, "concurrency", err.(limitError).reason)
+
+		_, err = l.acquire(&models.DataSource{Id: 2}, user, limits)
+		require.NoError(t, err)
+
+		release()
+		release()
+		_, err = l.acquire(ds, user, limits)
+		require.NoError(t, err)
+		_, err = l.acquire(ds, user, limits)
+		require.Error(t, err)
+	})
+
+	t.Run("Should limit requests per second of every user", func(t *testing.T) {
+		l := newDataSourceLimiter()
+		now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+		l.now = func() time.Time { return now }
+		limits := dataSourceLimits{userRequestsPerSecond: 2}
+
+		for i := 0; i < 2; i++ {
+			release, err := l.acquire(ds, user, limits)
+			require.NoError(t, err)
+			release()
+		}
+		_, err := l.acquire(ds, user, limits)
+		require.Error(t, err)
+		assert.Equal(t, "rate_limit", err.(limitError).reason)
+
+		_, err = l.acquire(ds, otherUser, limits)
+		require.NoError(t, err)
+
+		now = now.Add(500 * time.Millisecond)
+		_, err = l.acquire(ds, user, limits)
+		require.NoError(t, err)
+		_, err = l.acquire(ds, user, limits)
+		require.Error(t, err)
+	})
+
+	t.Run("Should allow a request at rates below one per second", func(t *testing.T) {
+		l := newDataSourceLimiter()
+		now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+		l.now = func() time.Time { return now }
+		limits := dataSourceLimits{userRequestsPerSecond: 0.5}
+
+		_, err := l.acquire(ds, user, limits)
+		require.NoError(t, err)
+		now = now.Add(time.Second)
+		_, err = l.acquire(ds, user, limits)
+		require.Error(t, err)
+		now = now.Add(time.Second)
+		_, err = l.acquire(ds, user, limits)
+		require.NoError(t, err)
+	})
+}
+
+func TestDataSourceProxyLimits(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/large":
+			w.Header().Set("Content-Length", "2048")
+			_, _ = w.Write(make([]byte, 2048))
+		case "/chunked":
+			for i := 0; i < 4; i++ {
+				_, _ = w.Write(make([]byte, 512))
+				w.(http.Flusher).Flush()
+			}
+		case "/slow":
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		default:
+			_, _ = w.Write([]byte("ok"))
+		}
+	}))
+	defer backend.Close()
+
+	// the rate limit doesn't depend on the duration of the requests
+	limiter := newDataSourceLimiter()
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter.now = func() time.Time { return now }
+
+	handle := func(t *testing.T, ds *models.DataSource, path string) *httptest.ResponseRecorder {
+		recorder := &CloseNotifierResponseRecorder{ResponseRecorder: httptest.NewRecorder()}
+		resp := macaron.NewResponseWriter("GET", recorder)
+		ctx := &models.ReqContext{
+			SignedInUser: &models.SignedInUser{UserId: 1},
+			Context: &macaron.Context{
+				Req:    macaron.Request{Request: httptest.NewRequest("GET", path, nil)},
+				Resp:   resp,
+				Render: &macaron.TplRender{ResponseWriter: resp, Opt: &macaron.RenderOptions{}},
+			},
+		}
+		proxy, err := NewDataSourceProxy(ds, &plugins.DataSourcePlugin{}, ctx, path, &setting.Cfg{})
+		require.NoError(t, err)
+		proxy.limiter = limiter
+		proxy.HandleRequest()
+		return recorder.ResponseRecorder
+	}
+
+	ds := &models.DataSource{
+		Id:   100,
+		Name: "Graphite",
+		Url:  backend.URL,
+		Type: models.DS_GRAPHITE,
+		JsonData: simplejson.NewFromAny(map[string]interface{}{
+			"proxyMaxResponseSizeMB":     0.001,
+			"proxyRequestTimeout":        0.1,
+			"proxyUserRequestsPerSecond": 4,
+		}),
+	}
+
+	t.Run("Should proxy responses within the limits", func(t *testing.T) {
+		res := handle(t, ds, "/ok")
+		assert.Equal(t, 200, res.Code)
+		assert.Equal(t, "ok", res.Body.String())
+	})
+
+	t.Run("Should reject large responses", func(t *testing.T) {
+		res := handle(t, ds, "/large")
+		assert.Equal(t, 413, res.Code)
+		assert.Contains(t, res.Body.String(), "larger than the limit of 1048 bytes")
+	})
+
+	t.Run("Should reject large responses without content length", func(t *testing.T) {
+		res := handle(t, ds, "/chunked")
+		assert.Equal(t, 413, res.Code)
+	})
+
+	t.Run("Should time out slow requests", func(t *testing.T) {
+		res := handle(t, ds, "/slow")
+		assert.Equal(t, 504, res.Code)
+		assert.Contains(t, res.Body.String(), "timed out")
+	})
+
+	t.Run("Should reject requests over the rate limit", func(t *testing.T) {
+		res := handle(t, ds, "/ok")
+		assert.Equal(t, 429, res.Code)
+		assert.Equal(t, "1", res.Header().Get("Retry-After"))
+		assert.Contains(t, res.Body.String(), "Too many requests")
+	})
+}
@@ -2,6 +2,7 @@ package pluginproxy
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -15,10 +16,12 @@ import (
 
 	"github.com/opentracing/opentracing-go"
 	"golang.org/x/oauth2"
+	"golang.org/x/xerrors"
 
 	"github.com/grafana/grafana/pkg/api/datasource"
 	"github.com/grafana/grafana/pkg/bus"
 	glog "github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/login/social"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
@@ -40,6 +43,7 @@ type DataSourceProxy struct {
 	route     *plugins.AppPluginRoute
 	plugin    *plugins.DataSourcePlugin
 	cfg       *setting.Cfg
+	limiter   *dataSourceLimiter
 }
 
 type handleResponseTransport struct {
@@ -85,6 +89,7 @@ func NewDataSourceProxy(ds *models.DataSource, plugin *plugins.DataSourcePlugin,
 		proxyPath: proxyPath,
 		targetUrl: targetURL,
 		cfg:       cfg,
+		limiter:   defaultLimiter,
 	}, nil
 }
 
@@ -101,12 +106,29 @@ func (proxy *DataSourceProxy) HandleRequest() {
 		return
 	}
 
+	limits := getDataSourceLimits(proxy.ds, proxy.cfg)
+	release, err := proxy.limiter.acquire(proxy.ds, proxy.ctx.SignedInUser, limits)
+	if err != nil {
+		proxy.rejectRequest(err)
+		return
+	}
+	defer release()
+
+	inFlight := metrics.MDataSourceProxyInFlightRequests.WithLabelValues(proxy.ds.Name, proxy.ds.Type)
+	inFlight.Inc()
+	defer inFlight.Dec()
+
 	proxyErrorLogger := logger.New("userId", proxy.ctx.UserId, "orgId", proxy.ctx.OrgId, "uname", proxy.ctx.Login, "path", proxy.ctx.Req.URL.Path, "remote_addr", proxy.ctx.RemoteAddr(), "referer", proxy.ctx.Req.Referer())
 
 	reverseProxy := &httputil.ReverseProxy{
 		Director:      proxy.getDirector(),
 		FlushInterval: time.Millisecond * 200,
 		ErrorLog:      log.New(&logWrapper{logger: proxyErrorLogger}, "", 0),
+		ErrorHandler:  proxy.handleProxyError(proxyErrorLogger, limits),
+	}
+
+	if limits.maxResponseSize > 0 {
+		reverseProxy.ModifyResponse = limitResponseSize(limits.maxResponseSize)
 	}
 
 	transport, err := proxy.ds.GetHttpTransport()
@@ -122,6 +144,11 @@ func (proxy *DataSourceProxy) HandleRequest() {
 	proxy.logRequest()
 
 	span, ctx := opentracing.StartSpanFromContext(proxy.ctx.Req.Context(), "datasource reverse proxy")
+	if limits.requestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limits.requestTimeout)
+		defer cancel()
+	}
 	proxy.ctx.Req.Request = proxy.ctx.Req.WithContext(ctx)
 
 	defer span.Finish()
@@ -143,6 +170,37 @@ func (proxy *DataSourceProxy) HandleRequest() {
 	reverseProxy.ServeHTTP(proxy.ctx.Resp, proxy.ctx.Req.Request)
 }
 
+// rejectRequest responds to a request that exceeds a limit of the data source.
+func (proxy *DataSourceProxy) rejectRequest(err error) {
+	var limitErr limitError
+	if !xerrors.As(err, &limitErr) {
+		proxy.ctx.JsonApiErr(500, "Failed to check data source limits", err)
+		return
+	}
+
+	metrics.MDataSourceProxyRejectedRequests.WithLabelValues(proxy.ds.Name, proxy.ds.Type, limitErr.reason).Inc()
+	proxy.ctx.Resp.Header().Set("Retry-After", "1")
+	proxy.ctx.JsonApiErr(429, limitErr.Error(), nil)
+}
+
+// handleProxyError responds to requests that failed, with 413 to responses larger than the
+// response size limit and 504 to requests that exceeded the request timeout.
+func (proxy *DataSourceProxy) handleProxyError(proxyErrorLogger glog.Logger, limits dataSourceLimits) func(http.ResponseWriter, *http.Request, error) {
+	return func(rw http.ResponseWriter, req *http.Request, err error) {
+		switch {
+		case xerrors.Is(err, errResponseTooLarge):
+			metrics.MDataSourceProxyRejectedRequests.WithLabelValues(proxy.ds.Name, proxy.ds.Type, "response_size").Inc()
+			proxy.ctx.JsonApiErr(413, fmt.Sprintf("Data source response is larger than the limit of %d bytes", limits.maxResponseSize), nil)
+		case limits.requestTimeout > 0 && xerrors.Is(req.Context().Err(), context.DeadlineExceeded):
+			metrics.MDataSourceProxyRejectedRequests.WithLabelValues(proxy.ds.Name, proxy.ds.Type, "timeout").Inc()
+			proxy.ctx.JsonApiErr(504, fmt.Sprintf("Data source request timed out after %s", limits.requestTimeout), nil)
+		default:
+			proxyErrorLogger.Error("Data proxy error", "error", err)
+			rw.WriteHeader(http.StatusBadGateway)
+		}
+	}
+}
+
 func (proxy *DataSourceProxy) addTraceFromHeaderValue(span opentracing.Span, headerName string, tagName string) {
 	panelId := proxy.ctx.Req.Header.Get(headerName)
 	dashId, err := strconv.Atoi(panelId)
@@ -5,11 +5,14 @@ import (
 	"errors"
 	"net/http"
 	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 
 	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/api/live"
 	"github.com/grafana/grafana/pkg/bus"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/plugins"
@@ -24,7 +27,7 @@ var ErrPluginNotFound error = errors.New("plugin not found, no installed plugin
 
 func (hs *HTTPServer) getPluginContext(pluginID string, user *models.SignedInUser) (backend.PluginContext, error) {
 	pc := backend.PluginContext{}
-	plugin, exists := plugins.Plugins[pluginID]
+	plugin, exists := plugins.Plugins()[pluginID]
 	if !exists {
 		return pc, ErrPluginNotFound
 	}
@@ -61,6 +64,59 @@ func (hs *HTTPServer) getPluginContext(pluginID string, user *models.SignedInUse
 	}, nil
 }
 
+// getStreamPluginContext returns the plugin context of the stream of a data source plugin channel,
+// plugin/<pluginId>/<datasourceId>/<path>, and the path of the stream. Without a user, the data
+// source is loaded without checking permissions for the stream shared by the subscribers of the channel.
+func (hs *HTTPServer) getStreamPluginContext(orgID int64, user *models.SignedInUser, channel live.Channel) (backend.PluginContext, string, error) {
+	pc := backend.PluginContext{}
+	parts := strings.SplitN(channel.Path, "/", 2)
+	if len(parts) != 2 {
+		return pc, "", live.ErrInvalidChannel
+	}
+
+	datasourceID, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return pc, "", live.ErrInvalidChannel
+	}
+
+	var ds *models.DataSource
+	if user != nil {
+		ds, err = hs.DatasourceCache.GetDatasource(datasourceID, user, false)
+	} else {
+		query := models.GetDataSourceByIdQuery{Id: datasourceID, OrgId: orgID}
+		err = bus.Dispatch(&query)
+		ds = query.Result
+	}
+	if err != nil {
+		switch err {
+		case models.ErrDataSourceAccessDenied:
+			return pc, "", live.ErrPermissionDenied
+		case models.ErrDataSourceNotFound:
+			return pc, "", live.ErrUnknownChannel
+		}
+		return pc, "", err
+	}
+
+	if ds.Type != channel.Namespace {
+		return pc, "", live.ErrUnknownChannel
+	}
+
+	settings, err := wrapper.ModelToInstanceSettings(ds)
+	if err != nil {
+		return pc, "", err
+	}
+
+	pc = backend.PluginContext{
+		OrgID:                      orgID,
+		PluginID:                   ds.Type,
+		DataSourceInstanceSettings: settings,
+	}
+	if user != nil {
+		pc.User = wrapper.BackendUserFromSignedInUser(user)
+	}
+	return pc, parts[1], nil
+}
+
 func (hs *HTTPServer) GetPluginList(c *models.ReqContext) Response {
 	typeFilter := c.Query("type")
 	enabledFilter := c.Query("enabled")
@@ -79,7 +135,7 @@ func (hs *HTTPServer) GetPluginList(c *models.ReqContext) Response {
 	}
 
 	result := make(dtos.PluginList, 0)
-	for _, pluginDef := range plugins.Plugins {
+	for _, pluginDef := range plugins.Plugins() {
 		// filter out app sub plugins
 		if embeddedFilter == "0" && pluginDef.IncludedInAppId != "" {
 			continue
@@ -110,6 +166,8 @@ func (hs *HTTPServer) GetPluginList(c *models.ReqContext) Response {
 			DefaultNavUrl: pluginDef.DefaultNavUrl,
 			State:         pluginDef.State,
 			Signature:     pluginDef.Signature,
+			SignatureType: pluginDef.SignatureType,
+			SignatureOrg:  pluginDef.SignatureOrg,
 		}
 
 		if pluginSetting, exists := pluginSettingsMap[pluginDef.Id]; exists {
@@ -127,7 +185,7 @@ func (hs *HTTPServer) GetPluginList(c *models.ReqContext) Response {
 		}
 
 		// filter out built in data sources
-		if ds, exists := plugins.DataSources[pluginDef.Id]; exists {
+		if ds, exists := plugins.DataSources()[pluginDef.Id]; exists {
 			if ds.BuiltIn {
 				continue
 			}
@@ -143,7 +201,7 @@ func (hs *HTTPServer) GetPluginList(c *models.ReqContext) Response {
 func GetPluginSettingByID(c *models.ReqContext) Response {
 	pluginID := c.Params(":pluginId")
 
-	def, exists := plugins.Plugins[pluginID]
+	def, exists := plugins.Plugins()[pluginID]
 	if !exists {
 		return Error(404, "Plugin not found, no installed plugin with that id", nil)
 	}
@@ -162,6 +220,8 @@ func GetPluginSettingByID(c *models.ReqContext) Response {
 		HasUpdate:     def.GrafanaNetHasUpdate,
 		State:         def.State,
 		Signature:     def.Signature,
+		SignatureType: def.SignatureType,
+		SignatureOrg:  def.SignatureOrg,
 	}
 
 	query := models.GetPluginSettingByIdQuery{PluginId: pluginID, OrgId: c.OrgId}
@@ -184,7 +244,7 @@ func UpdatePluginSetting(c *models.ReqContext, cmd models.UpdatePluginSettingCmd
 	cmd.OrgId = c.OrgId
 	cmd.PluginId = pluginID
 
-	if _, ok := plugins.Apps[cmd.PluginId]; !ok {
+	if _, ok := plugins.Apps()[cmd.PluginId]; !ok {
 		return Error(404, "Plugin not installed.", nil)
 	}
 
@@ -264,7 +324,7 @@ func ImportDashboard(c *models.ReqContext, apiCmd dtos.ImportDashboardCommand) R
 // /api/plugins/:pluginId/metrics
 func (hs *HTTPServer) CollectPluginMetrics(c *models.ReqContext) Response {
 	pluginID := c.Params("pluginId")
-	plugin, exists := plugins.Plugins[pluginID]
+	plugin, exists := plugins.Plugins()[pluginID]
 	if !exists {
 		return Error(404, "Plugin not found, no installed plugin with that id", nil)
 	}
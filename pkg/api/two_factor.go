@@ -0,0 +1,122 @@
+package api
+
+import (
+	"github.com/grafana/grafana/pkg/api/dtos"
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/twofactor"
+)
+
+// GET /api/user/two-factor
+func (hs *HTTPServer) GetUserTwoFactor(c *models.ReqContext) Response {
+	enabled, err := hs.TwoFactorService.IsEnabled(c.UserId)
+	if err != nil {
+		return Error(500, "Failed to get two-factor authentication status", err)
+	}
+
+	required, err := hs.TwoFactorService.IsRequired(c.SignedInUser)
+	if err != nil {
+		return Error(500, "Failed to get two-factor authentication status", err)
+	}
+
+	return JSON(200, dtos.UserTwoFactorStatus{Enabled: enabled, Required: required})
+}
+
+// POST /api/user/two-factor/enroll
+func (hs *HTTPServer) EnrollUserTwoFactor(c *models.ReqContext) Response {
+	enrollment, err := hs.TwoFactorService.Enroll(c.SignedInUser)
+	if err != nil {
+		switch err {
+		case twofactor.ErrDisabled, twofactor.ErrExternalUser, models.ErrUserTotpAlreadyEnabled:
+			return Error(400, err.Error(), err)
+		}
+		return Error(500, "Failed to set up two-factor authentication", err)
+	}
+
+	return JSON(200, enrollment)
+}
+
+// POST /api/user/two-factor/activate
+func (hs *HTTPServer) ActivateUserTwoFactor(c *models.ReqContext, cmd dtos.TwoFactorCodeCommand) Response {
+	recoveryCodes, err := hs.TwoFactorService.Activate(c.UserId, cmd.Code)
+	if err != nil {
+		switch err {
+		case twofactor.ErrInvalidCode, models.ErrUserTotpAlreadyEnabled:
+			return Error(400, err.Error(), err)
+		case models.ErrUserTotpNotFound:
+			return Error(400, "Two-factor authentication has not been set up", err)
+		}
+		return Error(500, "Failed to enable two-factor authentication", err)
+	}
+
+	return JSON(200, map[string]interface{}{
+		"message":       "Two-factor authentication enabled",
+		"recoveryCodes": recoveryCodes,
+	})
+}
+
+// POST /api/user/two-factor/disable
+func (hs *HTTPServer) DisableUserTwoFactor(c *models.ReqContext, cmd dtos.TwoFactorCodeCommand) Response {
+	if err := hs.TwoFactorService.Verify(c.UserId, cmd.Code); err != nil {
+		switch err {
+		case twofactor.ErrInvalidCode:
+			return Error(400, err.Error(), err)
+		case models.ErrUserTotpNotFound:
+			return Error(400, "Two-factor authentication is not enabled", err)
+		}
+		return Error(500, "Failed to disable two-factor authentication", err)
+	}
+
+	if err := hs.TwoFactorService.Disable(c.UserId); err != nil {
+		return Error(500, "Failed to disable two-factor authentication", err)
+	}
+
+	return Success("Two-factor authentication disabled")
+}
+
+// DELETE /api/admin/users/:id/two-factor
+func (hs *HTTPServer) AdminResetUserTwoFactor(c *models.ReqContext) Response {
+	userID := c.ParamsInt64(":id")
+
+	query := models.GetUserByIdQuery{Id: userID}
+	if err := bus.Dispatch(&query); err != nil {
+		if err == models.ErrUserNotFound {
+			return Error(404, "User not found", err)
+		}
+		return Error(500, "Failed to get user", err)
+	}
+
+	if err := hs.TwoFactorService.Disable(userID); err != nil {
+		return Error(500, "Failed to reset two-factor authentication", err)
+	}
+
+	return Success("Two-factor authentication reset")
+}
+
+// GET /api/org/two-factor-policy
+func GetOrgTwoFactorPolicy(c *models.ReqContext) Response {
+	query := models.GetOrgByIdQuery{Id: c.OrgId}
+	if err := bus.Dispatch(&query); err != nil {
+		return Error(500, "Failed to get organization", err)
+	}
+
+	return JSON(200, dtos.OrgTwoFactorPolicy{RequireTwoFactor: query.Result.RequireTwoFactor})
+}
+
+// PUT /api/org/two-factor-policy
+func (hs *HTTPServer) UpdateOrgTwoFactorPolicy(c *models.ReqContext, form dtos.OrgTwoFactorPolicy) Response {
+	if form.RequireTwoFactor && !hs.Cfg.TwoFactorEnabled {
+		return Error(400, twofactor.ErrDisabled.Error(), nil)
+	}
+
+	cmd := models.SetOrgRequireTwoFactorCommand{OrgId: c.OrgId, RequireTwoFactor: form.RequireTwoFactor}
+	if err := bus.Dispatch(&cmd); err != nil {
+		if err == models.ErrOrgNotFound {
+			return Error(404, "Organization not found", err)
+		}
+		return Error(500, "Failed to update two-factor policy", err)
+	}
+
+	hs.TwoFactorService.InvalidateOrgPolicy()
+	return Success("Two-factor policy updated")
+}
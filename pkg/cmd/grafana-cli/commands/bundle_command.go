@@ -0,0 +1,215 @@
+package commands
+
+import (
+	"archive/zip"
+	"crypto/md5"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/models"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/services"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"golang.org/x/xerrors"
+)
+
+// bundleCommand downloads plugins and the plugins they depend on into a bundle, from which
+// hosts without access to the plugin repository install them with the bundle flag.
+func (cmd Command) bundleCommand(c utils.CommandLine) error {
+	args := c.Args().Slice()
+	if len(args) < 2 {
+		return errors.New("please specify the bundle file and the plugins to bundle")
+	}
+
+	tmpDir, err := ioutil.TempDir("", "grafana-plugin-bundle")
+	if err != nil {
+		return errutil.Wrap("failed to create temporary directory", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	b := &pluginBundle{
+		dir:      tmpDir,
+		client:   cmd.Client,
+		c:        c,
+		versions: make(map[string]*models.Version),
+		archives: make(map[string]string),
+	}
+	for _, arg := range args[1:] {
+		pluginName, version := arg, ""
+		if i := strings.Index(arg, "@"); i >= 0 {
+			pluginName, version = arg[:i], arg[i+1:]
+		}
+		if err := b.add(pluginName, version, nil); err != nil {
+			return errutil.Wrapf(err, "failed to bundle plugin '%s'", pluginName)
+		}
+	}
+
+	if err := b.write(args[0]); err != nil {
+		return errutil.Wrap("failed to write plugin bundle", err)
+	}
+
+	logger.Infof("%s Bundled %d plugins into %s\n", color.GreenString("✔"), len(b.order), args[0])
+	return nil
+}
+
+// pluginBundle collects the archives of the plugins of a bundle.
+type pluginBundle struct {
+	dir      string
+	client   utils.ApiClient
+	c        utils.CommandLine
+	order    []string
+	versions map[string]*models.Version
+	archives map[string]string
+}
+
+// add downloads a version of a plugin, or the latest version that matches the constraint, and the
+// plugins it depends on.
+func (b *pluginBundle) add(pluginName, version string, constraint *versionConstraint) error {
+	if existing, exists := b.versions[pluginName]; exists {
+		if (version == "" || version == existing.Version) && constraint.check(existing.Version) {
+			return nil
+		}
+		return xerrors.Errorf("the bundle requires several versions of '%s'", pluginName)
+	}
+
+	plugin, err := b.client.GetPlugin(pluginName, b.c.RepoDirectory())
+	if err != nil {
+		return err
+	}
+
+	var v *models.Version
+	if constraint != nil {
+		v, err = selectVersionMatching(&plugin, constraint)
+	} else {
+		v, err = SelectVersion(&plugin, version)
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("downloading %v @ %v\n", pluginName, v.Version)
+
+	archive := filepath.Join(b.dir, fmt.Sprintf("%s-%s.zip", pluginName, v.Version))
+	f, err := os.Create(archive)
+	if err != nil {
+		return errutil.Wrap("failed to create plugin archive", err)
+	}
+
+	var checksum string
+	if v.Arch != nil {
+		checksum = v.Arch[osAndArchString()].Md5
+	}
+	err = b.client.DownloadFile(pluginName, f, pluginDownloadURL(b.c, pluginName, v.Version), checksum)
+	if err != nil {
+		f.Close()
+		return errutil.Wrap("failed to download plugin archive", err)
+	}
+	if err := f.Close(); err != nil {
+		return errutil.Wrap("failed to close plugin archive", err)
+	}
+
+	bundled := &models.Version{
+		Version: v.Version,
+		Url:     "plugins/" + filepath.Base(archive),
+	}
+	// the archive of a plugin with binaries is the archive for the os and platform of this host
+	if v.Arch != nil {
+		sum, err := md5File(archive)
+		if err != nil {
+			return err
+		}
+		bundled.Arch = map[string]models.ArchMeta{osAndArchString(): {Md5: sum}}
+	}
+
+	b.order = append(b.order, pluginName)
+	b.versions[pluginName] = bundled
+	b.archives[pluginName] = archive
+
+	pluginJSON, found, err := readPluginArchive(archive)
+	if err != nil {
+		return errutil.Wrap("failed to read plugin archive", err)
+	}
+	if !found {
+		return nil
+	}
+
+	for _, dep := range pluginJSON.Dependencies.Plugins {
+		depConstraint, err := parseVersionConstraint(dep.Version)
+		if err != nil {
+			return errutil.Wrapf(err, "invalid dependency '%s'", dep.Id)
+		}
+		if err := b.add(dep.Id, "", depConstraint); err != nil {
+			return errutil.Wrapf(err, "failed to bundle dependency '%s'", dep.Id)
+		}
+	}
+	return nil
+}
+
+// write writes the bundle with the plugin archives and the manifest of the bundled plugins.
+func (b *pluginBundle) write(bundleFile string) (err error) {
+	f, err := os.Create(bundleFile)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}()
+
+	w := zip.NewWriter(f)
+	manifest := models.PluginRepo{Plugins: make([]models.Plugin, 0, len(b.order))}
+	for _, pluginName := range b.order {
+		v := b.versions[pluginName]
+		if err := addZipFile(w, v.Url, b.archives[pluginName]); err != nil {
+			return err
+		}
+		manifest.Plugins = append(manifest.Plugins, models.Plugin{Id: pluginName, Versions: []models.Version{*v}})
+	}
+
+	manifestWriter, err := w.Create(services.BundleManifestFile)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(manifestWriter).Encode(manifest); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+func addZipFile(w *zip.Writer, name string, file string) error {
+	src, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := w.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(dst, src)
+	return err
+}
+
+func md5File(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/commandstest"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/models"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPluginBundle(t *testing.T) {
+	pluginsDir, cleanUp := setupFakePluginsDir(t)
+	defer cleanUp()
+	tmpDir, err := ioutil.TempDir("", "plugin-bundle")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	services.IoHelper = services.IoUtilImp{}
+
+	archives := map[string]string{
+		"test-app": writePluginArchive(t, tmpDir, "test-app", `{
+			"id": "test-app", "type": "app", "info": {"version": "1.0.0"},
+			"dependencies": {"plugins": [{"id": "test-datasource", "type": "datasource", "version": "2.0.0"}]}
+		}`),
+		"test-datasource": writePluginArchive(t, tmpDir, "test-datasource", `{
+			"id": "test-datasource", "type": "datasource", "info": {"version": "2.1.0"}
+		}`),
+	}
+
+	client := &commandstest.FakeGrafanaComClient{
+		GetPluginFunc: func(pluginId, repoUrl string) (models.Plugin, error) {
+			switch pluginId {
+			case "test-app":
+				return *makePluginWithVersions(versionArg{Version: "1.0.0"}), nil
+			case "test-datasource":
+				return *makePluginWithVersions(versionArg{Version: "2.1.0", Arch: []string{osAndArchString()}}), nil
+			}
+			return models.Plugin{}, services.ErrNotFoundError
+		},
+		DownloadFileFunc: func(pluginName string, tmpFile *os.File, url string, checksum string) error {
+			f, err := os.Open(archives[pluginName])
+			require.NoError(t, err)
+			defer f.Close()
+			_, err = io.Copy(tmpFile, f)
+			return err
+		},
+	}
+
+	c, err := commandstest.NewCliContext(map[string]string{"pluginsDir": pluginsDir})
+	require.NoError(t, err)
+
+	bundleFile := filepath.Join(tmpDir, "bundle.zip")
+	b := &pluginBundle{
+		dir:      tmpDir,
+		client:   client,
+		c:        c,
+		versions: make(map[string]*models.Version),
+		archives: make(map[string]string),
+	}
+	require.NoError(t, b.add("test-app", "", nil))
+	require.NoError(t, b.write(bundleFile))
+	assert.Equal(t, []string{"test-app", "test-datasource"}, b.order)
+
+	t.Run("Should list the plugins of the bundle", func(t *testing.T) {
+		repo, err := services.NewBundleClient(bundleFile).ListAllPlugins("")
+		require.NoError(t, err)
+		require.Len(t, repo.Plugins, 2)
+		assert.Equal(t, "test-datasource", repo.Plugins[1].Id)
+		assert.Equal(t, "2.1.0", repo.Plugins[1].Versions[0].Version)
+		assert.NotEmpty(t, repo.Plugins[1].Versions[0].Arch[osAndArchString()].Md5)
+	})
+
+	t.Run("Should install the plugins and their dependencies from the bundle", func(t *testing.T) {
+		err := InstallPlugin("test-app", "", c, services.NewBundleClient(bundleFile))
+		require.NoError(t, err)
+
+		datasource, err := services.ReadPlugin(pluginsDir, "test-datasource")
+		require.NoError(t, err)
+		assert.Equal(t, "2.1.0", datasource.Info.Version)
+	})
+
+	t.Run("Should fail for plugins that are not in the bundle", func(t *testing.T) {
+		err := InstallPlugin("other-app", "", c, services.NewBundleClient(bundleFile))
+		require.Error(t, err)
+	})
+}
@@ -50,6 +50,13 @@ func runDbCommand(command func(commandLine utils.CommandLine, sqlStore *sqlstore
 	}
 }
 
+func runCommand(command func(commandLine utils.CommandLine) error) func(context *cli.Context) error {
+	return func(context *cli.Context) error {
+		cmd := &utils.ContextCommandLine{Context: context}
+		return command(cmd)
+	}
+}
+
 func runPluginCommand(command func(commandLine utils.CommandLine) error) func(context *cli.Context) error {
 	return func(context *cli.Context) error {
 		cmd := &utils.ContextCommandLine{Context: context}
@@ -67,6 +74,15 @@ type Command struct {
 	Client utils.ApiClient
 }
 
+// apiClient returns the client of the plugin bundle of the bundle flag, or else the client of
+// the plugin repository.
+func (cmd Command) apiClient(c utils.CommandLine) utils.ApiClient {
+	if bundle := c.String("bundle"); bundle != "" {
+		return services.NewBundleClient(bundle)
+	}
+	return cmd.Client
+}
+
 var cmd Command = Command{
 	Client: &services.GrafanaComClient{},
 }
@@ -76,6 +92,26 @@ var pluginCommands = []*cli.Command{
 		Name:   "install",
 		Usage:  "install <plugin id> <plugin version (optional)>",
 		Action: runPluginCommand(cmd.installCommand),
+	}, {
+		Name:   "bundle",
+		Usage:  "bundle <bundle file> <plugin id>[@<version>]... downloads plugins and their dependencies into a bundle to install with --bundle",
+		Action: runCommand(cmd.bundleCommand),
+	}, {
+		Name:   "sign",
+		Usage:  "sign <plugin directory> --signingKey <private key file> signs a plugin with a private key",
+		Action: runCommand(cmd.signCommand),
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "signingKey",
+				Usage:   "Path to the armored private key to sign the plugin with",
+				EnvVars: []string{"GF_PLUGIN_SIGNING_KEY"},
+			},
+			&cli.StringFlag{
+				Name:    "signingKeyPassphrase",
+				Usage:   "Passphrase of the private key",
+				EnvVars: []string{"GF_PLUGIN_SIGNING_KEY_PASSPHRASE"},
+			},
+		},
 	}, {
 		Name:   "list-remote",
 		Usage:  "list remote available plugins",
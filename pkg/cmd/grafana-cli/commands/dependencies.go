@@ -0,0 +1,203 @@
+package commands
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/models"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/services"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"github.com/hashicorp/go-version"
+	"golang.org/x/xerrors"
+)
+
+// versionConstraint is a version constraint of plugin.json. A nil constraint matches all versions.
+type versionConstraint struct {
+	raw         string
+	constraints version.Constraints
+}
+
+// parseVersionConstraint parses the version of a dependency in plugin.json. A version without an
+// operator is the minimum version, e.g. 1.2.0, unless it has wildcards, e.g. 7.x.x matches 7.0.0 up
+// to but not including 8.0.0. Versions with operators are constraints, e.g. >=1.2.0, <2.0.0.
+func parseVersionConstraint(raw string) (*versionConstraint, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || raw == "*" {
+		return nil, nil
+	}
+
+	constraint := raw
+	if !strings.ContainsAny(raw, "<>=~!") {
+		parts := strings.Split(raw, ".")
+		fixed := make([]string, 0, len(parts))
+		for _, part := range parts {
+			if part == "x" || part == "X" || part == "*" {
+				break
+			}
+			fixed = append(fixed, part)
+		}
+
+		switch {
+		case len(fixed) == 0:
+			return nil, nil
+		case len(fixed) == len(parts):
+			constraint = ">= " + raw
+		default:
+			last, err := strconv.Atoi(fixed[len(fixed)-1])
+			if err != nil {
+				return nil, xerrors.Errorf("invalid version %q", raw)
+			}
+			upper := append(append([]string{}, fixed[:len(fixed)-1]...), strconv.Itoa(last+1))
+			constraint = fmt.Sprintf(">= %s, < %s", strings.Join(fixed, "."), strings.Join(upper, "."))
+		}
+	}
+
+	constraints, err := version.NewConstraint(constraint)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid version constraint %q", raw)
+	}
+	return &versionConstraint{raw: raw, constraints: constraints}, nil
+}
+
+// check returns true if the release of the version, without pre-release and metadata, matches
+// the constraint.
+func (vc *versionConstraint) check(v string) bool {
+	if vc == nil {
+		return true
+	}
+
+	parsed, err := version.NewVersion(v)
+	if err != nil {
+		return false
+	}
+	segments := parsed.Segments()
+	release, err := version.NewVersion(fmt.Sprintf("%d.%d.%d", segments[0], segments[1], segments[2]))
+	if err != nil {
+		return false
+	}
+	return vc.constraints.Check(release)
+}
+
+func (vc *versionConstraint) String() string {
+	if vc == nil {
+		return "*"
+	}
+	return vc.raw
+}
+
+// selectVersionMatching returns the latest version of the plugin that matches the constraint and
+// supports the current os and platform. It expects plugin.Versions to be sorted so the newest
+// version is first.
+func selectVersionMatching(plugin *models.Plugin, constraint *versionConstraint) (*models.Version, error) {
+	for i := range plugin.Versions {
+		v := &plugin.Versions[i]
+		if constraint.check(v.Version) && supportsCurrentArch(v) {
+			return v, nil
+		}
+	}
+	return nil, xerrors.Errorf("could not find a version of %s matching %s that is supported on your architecture and OS", plugin.Id, constraint)
+}
+
+// checkGrafanaVersion returns an error if the plugin requires another version of Grafana. The
+// check is skipped for builds without a release version.
+func checkGrafanaVersion(plugin models.InstalledPlugin) error {
+	constraint, err := parseVersionConstraint(plugin.Dependencies.GrafanaVersion)
+	if err != nil {
+		return errutil.Wrapf(err, "invalid grafanaVersion dependency of plugin '%s'", plugin.Id)
+	}
+	if constraint == nil {
+		return nil
+	}
+
+	grafanaVersion := services.GrafanaVersion()
+	if _, err := version.NewVersion(grafanaVersion); err != nil {
+		logger.Debugf("Skipping Grafana version check of %s, %q is not a release version\n", plugin.Id, grafanaVersion)
+		return nil
+	}
+
+	if !constraint.check(grafanaVersion) {
+		return xerrors.Errorf("plugin '%s' requires Grafana %s, but this is Grafana %s", plugin.Id, constraint, grafanaVersion)
+	}
+	return nil
+}
+
+// installDependencies installs the plugins the plugin depends on, unless a matching version is
+// already installed. installing holds the plugins being installed, to stop at dependency cycles.
+func installDependencies(plugin models.InstalledPlugin, c utils.CommandLine, client utils.ApiClient, installing map[string]bool) error {
+	for _, dep := range plugin.Dependencies.Plugins {
+		constraint, err := parseVersionConstraint(dep.Version)
+		if err != nil {
+			return errutil.Wrapf(err, "invalid dependency '%s' of plugin '%s'", dep.Id, plugin.Id)
+		}
+
+		if installing[dep.Id] {
+			continue
+		}
+
+		if installed, err := services.ReadPlugin(c.PluginDirectory(), dep.Id); err == nil {
+			if !constraint.check(installed.Info.Version) {
+				return xerrors.Errorf("plugin '%s' requires %s %s, but version %s is installed. Update it with grafana-cli plugins update %s",
+					plugin.Id, dep.Id, constraint, installed.Info.Version, dep.Id)
+			}
+			logger.Infof("Dependency %v @ %v is already installed\n", dep.Id, installed.Info.Version)
+			continue
+		}
+
+		if err := installPlugin(dep.Id, "", "", constraint, c, client, installing); err != nil {
+			return errutil.Wrapf(err, "failed to install plugin '%s'", dep.Id)
+		}
+
+		logger.Infof("Installed dependency: %v ✔\n", dep.Id)
+	}
+	return nil
+}
+
+// readPluginArchive reads the plugin.json of a plugin archive. It returns false if the archive
+// has no plugin.json.
+func readPluginArchive(archiveFile string) (models.InstalledPlugin, bool, error) {
+	var plugin models.InstalledPlugin
+
+	r, err := zip.OpenReader(archiveFile)
+	if err != nil {
+		return plugin, false, err
+	}
+	defer r.Close()
+
+	var pluginJSON *zip.File
+	for _, zf := range r.File {
+		// like RemoveGitBuildFromName, the first directory of the archive is the plugin directory
+		name := RemoveGitBuildFromName("", zf.Name)
+		if name == "/dist/plugin.json" {
+			pluginJSON = zf
+			break
+		}
+		if name == "/plugin.json" {
+			pluginJSON = zf
+		}
+	}
+	if pluginJSON == nil {
+		return plugin, false, nil
+	}
+
+	src, err := pluginJSON.Open()
+	if err != nil {
+		return plugin, false, err
+	}
+	defer src.Close()
+
+	if err := json.NewDecoder(src).Decode(&plugin); err != nil {
+		return plugin, false, errutil.Wrap("failed to read plugin.json", err)
+	}
+	if plugin.Id == "" {
+		return plugin, false, xerrors.New("plugin.json has no plugin id")
+	}
+	if plugin.Info.Version == "" {
+		plugin.Info.Version = "0.0.0"
+	}
+	return plugin, true, nil
+}
@@ -21,7 +21,6 @@ import (
 
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/models"
-	"github.com/grafana/grafana/pkg/cmd/grafana-cli/services"
 )
 
 func validateInput(c utils.CommandLine, pluginFolder string) error {
@@ -59,15 +58,56 @@ func (cmd Command) installCommand(c utils.CommandLine) error {
 	pluginToInstall := c.Args().First()
 	version := c.Args().Get(1)
 
-	return InstallPlugin(pluginToInstall, version, c, cmd.Client)
+	if isPluginArchive(pluginToInstall) {
+		return InstallPluginArchive(pluginToInstall, c, cmd.apiClient(c))
+	}
+
+	return InstallPlugin(pluginToInstall, version, c, cmd.apiClient(c))
+}
+
+// isPluginArchive returns true if the argument of the install command is a local plugin archive.
+func isPluginArchive(arg string) bool {
+	if !strings.HasSuffix(strings.ToLower(arg), ".zip") {
+		return false
+	}
+	fileInfo, err := os.Stat(arg)
+	return err == nil && !fileInfo.IsDir()
 }
 
 // InstallPlugin downloads the plugin code as a zip file from the Grafana.com API
 // and then extracts the zip into the plugins directory.
 func InstallPlugin(pluginName, version string, c utils.CommandLine, client utils.ApiClient) error {
+	return installPlugin(pluginName, version, c.PluginURL(), nil, c, client, make(map[string]bool))
+}
+
+// InstallPluginArchive installs the plugin of a local zip file into the plugins directory. The
+// plugins it depends on are installed from the plugin repository.
+func InstallPluginArchive(archiveFile string, c utils.CommandLine, client utils.ApiClient) error {
+	plugin, found, err := readPluginArchive(archiveFile)
+	if err != nil {
+		return errutil.Wrap("failed to read plugin archive", err)
+	}
+	if !found {
+		return fmt.Errorf("%s is not a plugin archive, it has no plugin.json", archiveFile)
+	}
+	if strings.ContainsAny(plugin.Id, `/\`) {
+		return fmt.Errorf("invalid plugin id %q", plugin.Id)
+	}
+
+	logger.Infof("installing %v @ %v\n", plugin.Id, plugin.Info.Version)
+	logger.Infof("from: %v\n", archiveFile)
+	logger.Infof("into: %v\n", c.PluginDirectory())
+	logger.Info("\n")
+
+	return installArchive(archiveFile, plugin.Id, false, c, client, map[string]bool{plugin.Id: true})
+}
+
+// installPlugin installs a version of a plugin, or the latest version that matches the constraint,
+// and the plugins it depends on.
+func installPlugin(pluginName, version, downloadURL string, constraint *versionConstraint, c utils.CommandLine, client utils.ApiClient, installing map[string]bool) error {
 	pluginFolder := c.PluginDirectory()
-	downloadURL := c.PluginURL()
 	isInternal := false
+	installing[pluginName] = true
 
 	var checksum string
 	if downloadURL == "" {
@@ -83,19 +123,18 @@ func InstallPlugin(pluginName, version string, c utils.CommandLine, client utils
 			return err
 		}
 
-		v, err := SelectVersion(&plugin, version)
+		var v *models.Version
+		if constraint != nil {
+			v, err = selectVersionMatching(&plugin, constraint)
+		} else {
+			v, err = SelectVersion(&plugin, version)
+		}
 		if err != nil {
 			return err
 		}
 
-		if version == "" {
-			version = v.Version
-		}
-		downloadURL = fmt.Sprintf("%s/%s/versions/%s/download",
-			c.String("repo"),
-			pluginName,
-			version,
-		)
+		version = v.Version
+		downloadURL = pluginDownloadURL(c, pluginName, version)
 
 		// Plugins which are downloaded just as sourcecode zipball from github do not have checksum
 		if v.Arch != nil {
@@ -125,23 +164,42 @@ func InstallPlugin(pluginName, version string, c utils.CommandLine, client utils
 		return errutil.Wrap("failed to close tmp file", err)
 	}
 
-	err = extractFiles(tmpFile.Name(), pluginName, pluginFolder, isInternal)
+	return installArchive(tmpFile.Name(), pluginName, isInternal, c, client, installing)
+}
+
+// installArchive checks the Grafana version the plugin of the archive requires, extracts the
+// archive into the plugins directory and installs the plugins it depends on.
+func installArchive(archiveFile, pluginName string, isInternal bool, c utils.CommandLine, client utils.ApiClient, installing map[string]bool) error {
+	plugin, found, err := readPluginArchive(archiveFile)
+	if err != nil {
+		return errutil.Wrap("failed to read plugin archive", err)
+	}
+	if found {
+		if err := checkGrafanaVersion(plugin); err != nil {
+			return err
+		}
+	}
+
+	err = extractFiles(archiveFile, pluginName, c.PluginDirectory(), isInternal)
 	if err != nil {
 		return errutil.Wrap("failed to extract plugin archive", err)
 	}
 
 	logger.Infof("%s Installed %s successfully \n", color.GreenString("✔"), pluginName)
 
-	res, _ := services.ReadPlugin(pluginFolder, pluginName)
-	for _, v := range res.Dependencies.Plugins {
-		if err := InstallPlugin(v.Id, "", c, client); err != nil {
-			return errutil.Wrapf(err, "failed to install plugin '%s'", v.Id)
-		}
-
-		logger.Infof("Installed dependency: %v ✔\n", v.Id)
+	if !found {
+		return nil
 	}
+	return installDependencies(plugin, c, client, installing)
+}
 
-	return err
+// pluginDownloadURL returns the url of the archive of a plugin version in the plugin repository.
+func pluginDownloadURL(c utils.CommandLine, pluginName, version string) string {
+	return fmt.Sprintf("%s/%s/versions/%s/download",
+		c.String("repo"),
+		pluginName,
+		version,
+	)
 }
 
 func osAndArchString() string {
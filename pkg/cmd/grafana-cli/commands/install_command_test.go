@@ -1,14 +1,18 @@
 package commands
 
 import (
+	"archive/zip"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"runtime"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/commands/commandstest"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/models"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/services"
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -276,3 +280,140 @@ func makePluginWithVersions(versions ...versionArg) *models.Plugin {
 
 	return plugin
 }
+
+func TestParseVersionConstraint(t *testing.T) {
+	tcs := []struct {
+		constraint string
+		matching   []string
+		other      []string
+	}{
+		{constraint: "1.2.0", matching: []string{"1.2.0", "1.3.0", "2.0.0"}, other: []string{"1.1.9"}},
+		{constraint: "7.x.x", matching: []string{"7.0.0", "7.3.1", "7.4.0-beta1"}, other: []string{"6.7.3", "8.0.0"}},
+		{constraint: "7.3.x", matching: []string{"7.3.0", "7.3.9"}, other: []string{"7.2.0", "7.4.0"}},
+		{constraint: ">=1.0.0, <2.0.0", matching: []string{"1.0.0", "1.9.9"}, other: []string{"0.9.0", "2.0.0"}},
+		{constraint: "~> 1.2", matching: []string{"1.2.0", "1.9.0"}, other: []string{"2.0.0"}},
+	}
+
+	for _, tc := range tcs {
+		constraint, err := parseVersionConstraint(tc.constraint)
+		require.NoError(t, err)
+		for _, v := range tc.matching {
+			assert.True(t, constraint.check(v), "%s should match %s", v, tc.constraint)
+		}
+		for _, v := range tc.other {
+			assert.False(t, constraint.check(v), "%s should not match %s", v, tc.constraint)
+		}
+	}
+
+	for _, raw := range []string{"", "*", "x.x.x"} {
+		constraint, err := parseVersionConstraint(raw)
+		require.NoError(t, err)
+		assert.Nil(t, constraint)
+		assert.True(t, constraint.check("1.0.0"))
+	}
+
+	_, err := parseVersionConstraint(">= abc")
+	assert.Error(t, err)
+}
+
+func TestInstallPluginArchive(t *testing.T) {
+	pluginsDir, cleanUp := setupFakePluginsDir(t)
+	defer cleanUp()
+	archiveDir, err := ioutil.TempDir("", "plugin-archives")
+	require.NoError(t, err)
+	defer os.RemoveAll(archiveDir)
+
+	services.IoHelper = services.IoUtilImp{}
+	services.Init("7.3.0", false)
+	defer services.Init("master", false)
+
+	c, err := commandstest.NewCliContext(map[string]string{"pluginsDir": pluginsDir})
+	require.NoError(t, err)
+
+	appArchive := writePluginArchive(t, archiveDir, "test-app", `{
+		"id": "test-app", "type": "app", "info": {"version": "1.0.0"},
+		"dependencies": {"grafanaVersion": "7.x.x", "plugins": [{"id": "test-datasource", "type": "datasource", "version": "2.x.x"}]}
+	}`)
+	datasourceArchives := map[string]string{
+		"2.1.0": writePluginArchive(t, archiveDir, "test-datasource", `{
+			"id": "test-datasource", "type": "datasource", "info": {"version": "2.1.0"},
+			"dependencies": {"plugins": [{"id": "test-app", "type": "app", "version": "1.0.0"}]}
+		}`),
+	}
+
+	var downloads []string
+	client := &commandstest.FakeGrafanaComClient{
+		GetPluginFunc: func(pluginId, repoUrl string) (models.Plugin, error) {
+			require.Equal(t, "test-datasource", pluginId)
+			return *makePluginWithVersions(versionArg{Version: "3.0.0"}, versionArg{Version: "2.1.0"}, versionArg{Version: "2.0.0"}), nil
+		},
+		DownloadFileFunc: func(pluginName string, tmpFile *os.File, url string, checksum string) error {
+			downloads = append(downloads, url)
+			f, err := os.Open(datasourceArchives["2.1.0"])
+			require.NoError(t, err)
+			defer f.Close()
+			_, err = io.Copy(tmpFile, f)
+			return err
+		},
+	}
+
+	t.Run("Should install the plugin and the latest version of its dependencies matching their constraints", func(t *testing.T) {
+		err := InstallPluginArchive(appArchive, c, client)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"/test-datasource/versions/2.1.0/download"}, downloads)
+
+		app, err := services.ReadPlugin(pluginsDir, "test-app")
+		require.NoError(t, err)
+		assert.Equal(t, "1.0.0", app.Info.Version)
+		datasource, err := services.ReadPlugin(pluginsDir, "test-datasource")
+		require.NoError(t, err)
+		assert.Equal(t, "2.1.0", datasource.Info.Version)
+	})
+
+	t.Run("Should not install dependencies that are already installed", func(t *testing.T) {
+		downloads = nil
+		err := InstallPluginArchive(appArchive, c, client)
+		require.NoError(t, err)
+		assert.Empty(t, downloads)
+	})
+
+	t.Run("Should fail if an installed dependency does not match the constraint", func(t *testing.T) {
+		otherApp := writePluginArchive(t, archiveDir, "other-app", `{
+			"id": "other-app", "type": "app", "info": {"version": "1.0.0"},
+			"dependencies": {"plugins": [{"id": "test-datasource", "type": "datasource", "version": "3.0.0"}]}
+		}`)
+		err := InstallPluginArchive(otherApp, c, client)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requires test-datasource 3.0.0, but version 2.1.0 is installed")
+	})
+
+	t.Run("Should fail if the plugin requires another version of Grafana", func(t *testing.T) {
+		newApp := writePluginArchive(t, archiveDir, "new-app", `{
+			"id": "new-app", "type": "app", "info": {"version": "1.0.0"}, "dependencies": {"grafanaVersion": ">=8.0.0"}
+		}`)
+		err := InstallPluginArchive(newApp, c, client)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requires Grafana >=8.0.0, but this is Grafana 7.3.0")
+
+		_, err = os.Stat(filepath.Join(pluginsDir, "new-app"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+// writePluginArchive writes a plugin archive with the plugin.json in the directory of the plugin.
+func writePluginArchive(t *testing.T, dir string, pluginID string, pluginJSON string) string {
+	archive := filepath.Join(dir, pluginID+".zip")
+	f, err := os.Create(archive)
+	require.NoError(t, err)
+	defer f.Close()
+
+	w := zip.NewWriter(f)
+	_, err = w.Create(pluginID + "/")
+	require.NoError(t, err)
+	pw, err := w.Create(pluginID + "/plugin.json")
+	require.NoError(t, err)
+	_, err = pw.Write([]byte(pluginJSON))
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	return archive
+}
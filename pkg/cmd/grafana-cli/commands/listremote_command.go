@@ -8,7 +8,7 @@ import (
 // listRemoteCommand prints out all plugins in the remote repo with latest version supported on current platform.
 // If there are no supported versions for plugin it is skipped.
 func (cmd Command) listRemoteCommand(c utils.CommandLine) error {
-	plugin, err := cmd.Client.ListAllPlugins(c.RepoDirectory())
+	plugin, err := cmd.apiClient(c).ListAllPlugins(c.RepoDirectory())
 	if err != nil {
 		return err
 	}
@@ -23,7 +23,7 @@ func (cmd Command) listVersionsCommand(c utils.CommandLine) error {
 
 	pluginToList := c.Args().First()
 
-	plugin, err := cmd.Client.GetPlugin(pluginToList, c.String("repo"))
+	plugin, err := cmd.apiClient(c).GetPlugin(pluginToList, c.String("repo"))
 	if err != nil {
 		return err
 	}
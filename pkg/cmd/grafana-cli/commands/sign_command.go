@@ -0,0 +1,184 @@
+package commands
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/models"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/utils"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/clearsign"
+	"golang.org/x/crypto/openpgp/packet"
+	"golang.org/x/xerrors"
+)
+
+// manifestFile is the file with the signed hashes of the files of a plugin.
+const manifestFile = "MANIFEST.txt"
+
+// pluginManifest is the manifest that Grafana verifies with the key the plugin is signed with.
+type pluginManifest struct {
+	Plugin  string            `json:"plugin"`
+	Version string            `json:"version"`
+	KeyID   string            `json:"keyId"`
+	Time    int64             `json:"time"`
+	Files   map[string]string `json:"files"`
+}
+
+// signCommand signs a plugin directory with a private key, for Grafana servers that trust the
+// public key in trusted_signing_keys.
+func (cmd Command) signCommand(c utils.CommandLine) error {
+	pluginDir := c.Args().First()
+	if pluginDir == "" {
+		return errors.New("please specify the plugin directory to sign")
+	}
+	keyFile := c.String("signingKey")
+	if keyFile == "" {
+		return errors.New("please specify the private key to sign the plugin with, with --signingKey")
+	}
+
+	key, err := readSigningKey(keyFile, c.String("signingKeyPassphrase"))
+	if err != nil {
+		return errutil.Wrapf(err, "failed to read signing key %q", keyFile)
+	}
+
+	manifest, err := signPlugin(pluginDir, key, time.Now())
+	if err != nil {
+		return errutil.Wrapf(err, "failed to sign plugin %q", pluginDir)
+	}
+
+	logger.Infof("%s Signed %s @ %s with key %s\n", color.GreenString("✔"), manifest.Plugin, manifest.Version, manifest.KeyID)
+	return nil
+}
+
+// readSigningKey reads the first private key of an armored key file, decrypting it with the
+// passphrase if it is encrypted.
+func readSigningKey(keyFile, passphrase string) (*packet.PrivateKey, error) {
+	f, err := os.Open(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	entities, err := openpgp.ReadArmoredKeyRing(f)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entity := range entities {
+		key := entity.PrivateKey
+		if key == nil {
+			continue
+		}
+		if key.Encrypted {
+			if passphrase == "" {
+				return nil, errors.New("the key is encrypted, specify its passphrase with --signingKeyPassphrase")
+			}
+			if err := key.Decrypt([]byte(passphrase)); err != nil {
+				return nil, errutil.Wrap("failed to decrypt key", err)
+			}
+		}
+		return key, nil
+	}
+	return nil, errors.New("the file has no private key")
+}
+
+// signPlugin writes the manifest of the files of the plugin in the plugin directory, signed with
+// the key.
+func signPlugin(pluginDir string, key *packet.PrivateKey, now time.Time) (*pluginManifest, error) {
+	data, err := ioutil.ReadFile(filepath.Join(pluginDir, "plugin.json"))
+	if err != nil {
+		return nil, err
+	}
+	var plugin models.InstalledPlugin
+	if err := json.Unmarshal(data, &plugin); err != nil {
+		return nil, errutil.Wrap("failed to read plugin.json", err)
+	}
+	if plugin.Id == "" || plugin.Info.Version == "" {
+		return nil, xerrors.New("plugin.json has no plugin id or version")
+	}
+
+	manifest := &pluginManifest{
+		Plugin:  plugin.Id,
+		Version: plugin.Info.Version,
+		KeyID:   fmt.Sprintf("%016x", key.KeyId),
+		Time:    now.UnixNano() / int64(time.Millisecond),
+		Files:   make(map[string]string),
+	}
+
+	err = filepath.Walk(pluginDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		name, err := filepath.Rel(pluginDir, path)
+		if err != nil {
+			return err
+		}
+		name = filepath.ToSlash(name)
+		if name == manifestFile {
+			return nil
+		}
+
+		hash, err := fileSHA256(path)
+		if err != nil {
+			return err
+		}
+		manifest.Files[name] = hash
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var signed bytes.Buffer
+	w, err := clearsign.Encode(&signed, key, &packet.Config{DefaultHash: crypto.SHA512})
+	if err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(pluginDir, manifestFile), signed.Bytes(), 0644); err != nil {
+		return nil, err
+	}
+	return manifest, nil
+}
+
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -0,0 +1,77 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/clearsign"
+	"golang.org/x/crypto/openpgp/packet"
+)
+
+func TestSignPlugin(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "plugin-sign")
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	entity, err := openpgp.NewEntity("Acme Corp", "", "plugins@acme.com", &packet.Config{RSABits: 1024})
+	require.NoError(t, err)
+	keyFile := filepath.Join(tmpDir, "private.key")
+	f, err := os.Create(keyFile)
+	require.NoError(t, err)
+	w, err := armor.Encode(f, openpgp.PrivateKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.SerializePrivate(w, nil))
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+
+	pluginDir := filepath.Join(tmpDir, "plugin")
+	require.NoError(t, os.MkdirAll(filepath.Join(pluginDir, "img"), 0750))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pluginDir, "plugin.json"), []byte(`{"id": "acme-panel", "type": "panel", "info": {"version": "1.2.0"}}`), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pluginDir, "img", "logo.svg"), []byte("<svg></svg>"), 0600))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(pluginDir, manifestFile), []byte("outdated manifest"), 0600))
+
+	key, err := readSigningKey(keyFile, "")
+	require.NoError(t, err)
+
+	now := time.Unix(1590000000, 0)
+	manifest, err := signPlugin(pluginDir, key, now)
+	require.NoError(t, err)
+
+	signed, err := ioutil.ReadFile(filepath.Join(pluginDir, manifestFile))
+	require.NoError(t, err)
+	block, _ := clearsign.Decode(signed)
+	require.NotNil(t, block)
+	signer, err := openpgp.CheckDetachedSignature(openpgp.EntityList{entity}, bytes.NewBuffer(block.Bytes), block.ArmoredSignature.Body)
+	require.NoError(t, err)
+	assert.Equal(t, entity.PrimaryKey.KeyId, signer.PrimaryKey.KeyId)
+
+	var read pluginManifest
+	require.NoError(t, json.Unmarshal(block.Plaintext, &read))
+	assert.Equal(t, *manifest, read)
+	assert.Equal(t, "acme-panel", read.Plugin)
+	assert.Equal(t, "1.2.0", read.Version)
+	assert.Equal(t, int64(1590000000000), read.Time)
+	assert.Len(t, read.KeyID, 16)
+	assert.Equal(t, map[string]string{
+		"plugin.json":  sha256Hex(t, filepath.Join(pluginDir, "plugin.json")),
+		"img/logo.svg": sha256Hex(t, filepath.Join(pluginDir, "img", "logo.svg")),
+	}, read.Files)
+
+	_, err = readSigningKey(filepath.Join(pluginDir, "plugin.json"), "")
+	assert.Error(t, err)
+}
+
+func sha256Hex(t *testing.T, path string) string {
+	hash, err := fileSHA256(path)
+	require.NoError(t, err)
+	return hash
+}
@@ -27,7 +27,8 @@ func (cmd Command) upgradeAllCommand(c utils.CommandLine) error {
 
 	localPlugins := services.GetLocalPlugins(pluginsDir)
 
-	remotePlugins, err := cmd.Client.ListAllPlugins(c.String("repo"))
+	client := cmd.apiClient(c)
+	remotePlugins, err := client.ListAllPlugins(c.String("repo"))
 	if err != nil {
 		return err
 	}
@@ -52,7 +53,7 @@ func (cmd Command) upgradeAllCommand(c utils.CommandLine) error {
 			return err
 		}
 
-		err = InstallPlugin(p.Id, "", c, cmd.Client)
+		err = InstallPlugin(p.Id, "", c, client)
 		if err != nil {
 			return err
 		}
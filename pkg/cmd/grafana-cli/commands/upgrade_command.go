@@ -18,7 +18,8 @@ func (cmd Command) upgradeCommand(c utils.CommandLine) error {
 		return err
 	}
 
-	plugin, err2 := cmd.Client.GetPlugin(pluginName, c.RepoDirectory())
+	client := cmd.apiClient(c)
+	plugin, err2 := client.GetPlugin(pluginName, c.RepoDirectory())
 	if err2 != nil {
 		return err2
 	}
@@ -28,7 +29,7 @@ func (cmd Command) upgradeCommand(c utils.CommandLine) error {
 			return errutil.Wrapf(err, "failed to remove plugin '%s'", pluginName)
 		}
 
-		return InstallPlugin(pluginName, "", c, cmd.Client)
+		return InstallPlugin(pluginName, "", c, client)
 	}
 
 	logger.Infof("%s %s is up to date \n", color.GreenString("✔"), pluginName)
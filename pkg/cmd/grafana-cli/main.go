@@ -40,6 +40,18 @@ func main() {
 				Value:   "https://grafana.com/api/plugins",
 				EnvVars: []string{"GF_PLUGIN_REPO"},
 			},
+			&cli.StringFlag{
+				Name:    "repoToken",
+				Usage:   "Bearer token to authenticate to a private plugin repository",
+				Value:   "",
+				EnvVars: []string{"GF_PLUGIN_REPO_TOKEN"},
+			},
+			&cli.StringFlag{
+				Name:    "bundle",
+				Usage:   "Path to a plugin bundle to install plugins from instead of the plugin repository",
+				Value:   "",
+				EnvVars: []string{"GF_PLUGIN_BUNDLE"},
+			},
 			&cli.StringFlag{
 				Name:    "pluginUrl",
 				Usage:   "Full url to the plugin zip file instead of downloading the plugin from grafana.com/api",
@@ -73,6 +85,7 @@ func main() {
 
 	app.Before = func(c *cli.Context) error {
 		services.Init(version, c.Bool("insecure"))
+		services.InitRepoAuth(c.String("repo"), c.String("repoToken"))
 		return nil
 	}
 
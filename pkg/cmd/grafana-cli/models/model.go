@@ -14,8 +14,17 @@ type InstalledPlugin struct {
 }
 
 type Dependencies struct {
-	GrafanaVersion string   `json:"grafanaVersion"`
-	Plugins        []Plugin `json:"plugins"`
+	GrafanaVersion string             `json:"grafanaVersion"`
+	Plugins        []PluginDependency `json:"plugins"`
+}
+
+// PluginDependency is a plugin that a plugin depends on. Version is either the minimum version,
+// e.g. 1.2.0, a version with wildcards, e.g. 1.x.x, or a constraint, e.g. >=1.2.0, <2.0.0.
+type PluginDependency struct {
+	Id      string `json:"id"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+	Version string `json:"version"`
 }
 
 type PluginInfo struct {
@@ -12,6 +12,7 @@ import (
 	"os"
 	"path"
 	"runtime"
+	"strings"
 
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/models"
@@ -162,9 +163,19 @@ func createRequest(repoUrl string, subPaths ...string) (*http.Request, error) {
 	req.Header.Set("grafana-arch", runtime.GOARCH)
 	req.Header.Set("User-Agent", "grafana "+grafanaVersion)
 
+	if repoToken != "" && isRepoURL(u.String()) {
+		req.Header.Set("Authorization", "Bearer "+repoToken)
+	}
+
 	return req, err
 }
 
+// isRepoURL returns true if the url is a url of the plugin repository, so that the repository
+// token is not sent to other hosts, e.g. the download urls of plugins.
+func isRepoURL(u string) bool {
+	return repoURL != "" && (u == repoURL || strings.HasPrefix(u, repoURL+"/"))
+}
+
 func handleResponse(res *http.Response) (io.ReadCloser, error) {
 	if res.StatusCode == 404 {
 		return nil, ErrNotFoundError
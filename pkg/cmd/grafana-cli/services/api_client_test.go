@@ -67,3 +67,24 @@ func asBadRequestError(t *testing.T, err error) *BadRequestError {
 	assert.FailNow(t, "Error was not of type BadRequestError")
 	return nil
 }
+
+func TestCreateRequest(t *testing.T) {
+	InitRepoAuth("https://plugins.example.com/api/plugins/", "token")
+	defer InitRepoAuth("", "")
+
+	t.Run("Sends the repository token to the repository", func(t *testing.T) {
+		req, err := createRequest("https://plugins.example.com/api/plugins", "repo", "test-app")
+		assert.NoError(t, err)
+		assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+	})
+
+	t.Run("Does not send the repository token to other urls", func(t *testing.T) {
+		req, err := createRequest("https://plugins.example.com/api/plugins-other/test-app.zip")
+		assert.NoError(t, err)
+		assert.Empty(t, req.Header.Get("Authorization"))
+
+		req, err = createRequest("https://github.com/test/test-app/archive/v1.0.0.zip")
+		assert.NoError(t, err)
+		assert.Empty(t, req.Header.Get("Authorization"))
+	})
+}
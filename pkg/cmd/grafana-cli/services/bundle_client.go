@@ -0,0 +1,144 @@
+package services
+
+import (
+	"archive/zip"
+	"crypto/md5"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
+	"github.com/grafana/grafana/pkg/cmd/grafana-cli/models"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"golang.org/x/xerrors"
+)
+
+// BundleManifestFile is the file in a plugin bundle that lists the bundled plugins in the
+// format of the plugin repository. The url of a version is the path of its archive in the bundle.
+const BundleManifestFile = "bundle.json"
+
+// BundleClient reads plugins from a bundle created with grafana-cli plugins bundle instead of
+// the plugin repository, so that plugins can be installed on hosts without internet access.
+type BundleClient struct {
+	path string
+}
+
+// NewBundleClient creates a client for the plugin bundle at path.
+func NewBundleClient(path string) *BundleClient {
+	return &BundleClient{path: path}
+}
+
+func (client *BundleClient) open() (*zip.ReadCloser, models.PluginRepo, error) {
+	var manifest models.PluginRepo
+
+	r, err := zip.OpenReader(client.path)
+	if err != nil {
+		return nil, manifest, errutil.Wrap("Failed to open plugin bundle", err)
+	}
+
+	f, err := findZipFile(r, BundleManifestFile)
+	if err != nil {
+		r.Close()
+		return nil, manifest, err
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		r.Close()
+		return nil, manifest, errutil.Wrap("Failed to read plugin bundle manifest", err)
+	}
+	defer src.Close()
+
+	if err := json.NewDecoder(src).Decode(&manifest); err != nil {
+		r.Close()
+		return nil, manifest, errutil.Wrap("Failed to read plugin bundle manifest", err)
+	}
+	return r, manifest, nil
+}
+
+func (client *BundleClient) GetPlugin(pluginId, repoUrl string) (models.Plugin, error) {
+	logger.Debugf("getting plugin metadata from bundle: %v pluginId: %v \n", client.path, pluginId)
+	r, manifest, err := client.open()
+	if err != nil {
+		return models.Plugin{}, err
+	}
+	defer r.Close()
+
+	for _, plugin := range manifest.Plugins {
+		if plugin.Id == pluginId {
+			return plugin, nil
+		}
+	}
+	return models.Plugin{}, errutil.Wrap("Failed to find requested plugin in the bundle", ErrNotFoundError)
+}
+
+// DownloadFile copies the archive of a plugin version from the bundle. The url is a download url
+// of the plugin repository, <repo>/<plugin id>/versions/<version>/download.
+func (client *BundleClient) DownloadFile(pluginName string, tmpFile *os.File, url string, checksum string) error {
+	parts := strings.Split(url, "/")
+	n := len(parts)
+	if n < 4 || parts[n-1] != "download" || parts[n-3] != "versions" || parts[n-4] != pluginName {
+		return fmt.Errorf("plugin url %s can not be installed from a bundle", url)
+	}
+	version := parts[n-2]
+
+	r, manifest, err := client.open()
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	var archive string
+	for _, plugin := range manifest.Plugins {
+		if plugin.Id != pluginName {
+			continue
+		}
+		for _, v := range plugin.Versions {
+			if v.Version == version {
+				archive = v.Url
+			}
+		}
+	}
+	if archive == "" {
+		return errutil.Wrapf(ErrNotFoundError, "Failed to find version %s of %s in the bundle", version, pluginName)
+	}
+
+	f, err := findZipFile(r, archive)
+	if err != nil {
+		return err
+	}
+	src, err := f.Open()
+	if err != nil {
+		return errutil.Wrap("Failed to read plugin archive", err)
+	}
+	defer src.Close()
+
+	h := md5.New()
+	if _, err := io.Copy(tmpFile, io.TeeReader(src, h)); err != nil {
+		return errutil.Wrap("Failed to copy plugin archive", err)
+	}
+	if len(checksum) > 0 && checksum != fmt.Sprintf("%x", h.Sum(nil)) {
+		return xerrors.New("Expected MD5 checksum does not match the plugin archive in the bundle")
+	}
+	return nil
+}
+
+func (client *BundleClient) ListAllPlugins(repoUrl string) (models.PluginRepo, error) {
+	r, manifest, err := client.open()
+	if err != nil {
+		return models.PluginRepo{}, err
+	}
+	r.Close()
+	return manifest, nil
+}
+
+func findZipFile(r *zip.ReadCloser, name string) (*zip.File, error) {
+	for _, f := range r.File {
+		if f.Name == name {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("plugin bundle does not contain %s", name)
+}
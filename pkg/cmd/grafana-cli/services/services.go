@@ -8,6 +8,7 @@ import (
 	"net"
 	"net/http"
 	"path"
+	"strings"
 	"time"
 
 	"github.com/grafana/grafana/pkg/cmd/grafana-cli/logger"
@@ -19,6 +20,8 @@ var (
 	HttpClient          http.Client
 	HttpClientNoTimeout http.Client
 	grafanaVersion      string
+	repoURL             string
+	repoToken           string
 	ErrNotFoundError    = errors.New("404 not found error")
 )
 
@@ -41,6 +44,18 @@ func Init(version string, skipTLSVerify bool) {
 	HttpClientNoTimeout = makeHttpClient(skipTLSVerify, 0)
 }
 
+// InitRepoAuth sets the token that is sent as bearer token with the requests to the plugin
+// repository at url, e.g. a private plugin repository.
+func InitRepoAuth(url string, token string) {
+	repoURL = strings.TrimSuffix(url, "/")
+	repoToken = token
+}
+
+// GrafanaVersion returns the version of Grafana the plugins are installed for.
+func GrafanaVersion() string {
+	return grafanaVersion
+}
+
 func makeHttpClient(skipTLSVerify bool, timeout time.Duration) http.Client {
 	tr := &http.Transport{
 		Proxy: http.ProxyFromEnvironment,
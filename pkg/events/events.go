@@ -89,3 +89,22 @@ type UserUpdated struct {
 	Login     string    `json:"login"`
 	Email     string    `json:"email"`
 }
+
+type DashboardSaved struct {
+	Timestamp   time.Time `json:"timestamp"`
+	Id          int64     `json:"id"`
+	OrgId       int64     `json:"org_id"`
+	Uid         string    `json:"uid"`
+	Title       string    `json:"title"`
+	Version     int       `json:"version"`
+	UserId      int64     `json:"user_id"`
+	Message     string    `json:"message"`
+	Provisioned bool      `json:"provisioned"`
+}
+
+type DashboardDeleted struct {
+	Timestamp time.Time `json:"timestamp"`
+	Id        int64     `json:"id"`
+	OrgId     int64     `json:"org_id"`
+	Uid       string    `json:"uid"`
+}
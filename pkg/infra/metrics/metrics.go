@@ -104,6 +104,12 @@ var (
 
 	// MRenderingQueue is a metric gauge for image rendering queue size
 	MRenderingQueue prometheus.Gauge
+
+	// MDataSourceProxyInFlightRequests is a metric gauge for dataproxy requests in flight per data source
+	MDataSourceProxyInFlightRequests *prometheus.GaugeVec
+
+	// MDataSourceProxyRejectedRequests is a metric counter for dataproxy requests rejected by the limits of a data source
+	MDataSourceProxyRejectedRequests *prometheus.CounterVec
 )
 
 // Timers
@@ -380,6 +386,18 @@ func init() {
 		Namespace: ExporterName,
 	})
 
+	MDataSourceProxyInFlightRequests = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name:      "api_dataproxy_in_flight_requests",
+		Help:      "amount of dataproxy requests in flight",
+		Namespace: ExporterName,
+	}, []string{"datasource", "datasource_type"})
+
+	MDataSourceProxyRejectedRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name:      "api_dataproxy_rejected_requests_total",
+		Help:      "dataproxy requests rejected by the limits of a data source",
+		Namespace: ExporterName,
+	}, []string{"datasource", "datasource_type", "reason"})
+
 	MDataSourceProxyReqTimer = prometheus.NewSummary(prometheus.SummaryOpts{
 		Name:       "api_dataproxy_request_all_milliseconds",
 		Help:       "summary for dataproxy request duration",
@@ -514,6 +532,8 @@ func initMetricVars() {
 		MApiDashboardGet,
 		MApiDashboardSearch,
 		MDataSourceProxyReqTimer,
+		MDataSourceProxyInFlightRequests,
+		MDataSourceProxyRejectedRequests,
 		MAlertingExecutionTime,
 		MApiAdminUserCreate,
 		MApiLoginPost,
@@ -77,11 +77,21 @@ func parseRedisConnStr(connStr string) (*redis.Options, error) {
 }
 
 func newRedisStorage(opts *setting.RemoteCacheOptions) (*redisStorage, error) {
-	opt, err := parseRedisConnStr(opts.ConnStr)
+	c, err := NewRedisClient(opts.ConnStr)
 	if err != nil {
 		return nil, err
 	}
-	return &redisStorage{c: redis.NewClient(opt)}, nil
+	return &redisStorage{c: c}, nil
+}
+
+// NewRedisClient creates a redis client from a connection string in the format of the
+// remote cache, e.g. `addr=127.0.0.1:6379,pool_size=100,db=0,ssl=false`
+func NewRedisClient(connStr string) (*redis.Client, error) {
+	opt, err := parseRedisConnStr(connStr)
+	if err != nil {
+		return nil, err
+	}
+	return redis.NewClient(opt), nil
 }
 
 // Set sets value to given key in session.
@@ -7,6 +7,7 @@ import (
 	macaron "gopkg.in/macaron.v1"
 
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/accesscontrol"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/util"
 )
@@ -77,6 +78,21 @@ func RoleAuth(roles ...models.RoleType) macaron.Handler {
 	}
 }
 
+// ReqPermission only lets the request through if the signed in user
+// has been granted the action, either by its built-in role or a custom role.
+func ReqPermission(action string) macaron.Handler {
+	return func(c *models.ReqContext) {
+		ok, err := accesscontrol.ContextHasPermission(c, action)
+		if err != nil {
+			c.JsonApiErr(500, "Failed to evaluate permissions", err)
+			return
+		}
+		if !ok {
+			accessForbidden(c)
+		}
+	}
+}
+
 func Auth(options *AuthOptions) macaron.Handler {
 	return func(c *models.ReqContext) {
 		if !c.IsSignedIn && options.ReqSignedIn && !c.AllowAnonymous {
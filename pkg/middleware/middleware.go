@@ -41,6 +41,7 @@ func GetContextHandler(
 	ats models.UserTokenService,
 	remoteCache *remotecache.RemoteCache,
 	renderService rendering.Service,
+	cfg *setting.Cfg,
 ) macaron.Handler {
 	return func(c *macaron.Context) {
 		ctx := &models.ReqContext{
@@ -66,7 +67,7 @@ func GetContextHandler(
 		switch {
 		case initContextWithRenderAuth(ctx, renderService):
 		case initContextWithApiKey(ctx):
-		case initContextWithBasicAuth(ctx, orgId):
+		case initContextWithBasicAuth(ctx, cfg, orgId):
 		case initContextWithAuthProxy(remoteCache, ctx, orgId):
 		case initContextWithToken(ats, ctx, orgId):
 		case initContextWithAnonymousUser(ctx):
@@ -154,7 +155,7 @@ func initContextWithApiKey(ctx *models.ReqContext) bool {
 	return true
 }
 
-func initContextWithBasicAuth(ctx *models.ReqContext, orgId int64) bool {
+func initContextWithBasicAuth(ctx *models.ReqContext, cfg *setting.Cfg, orgId int64) bool {
 	if !setting.BasicAuthEnabled {
 		return false
 	}
@@ -186,6 +187,20 @@ func initContextWithBasicAuth(ctx *models.ReqContext, orgId int64) bool {
 
 	user := authQuery.User
 
+	// basic auth can't be used to complete a two-factor login
+	if cfg.TwoFactorEnabled {
+		totpQuery := models.GetUserTotpQuery{UserId: user.Id}
+		if err := bus.Dispatch(&totpQuery); err != nil && err != models.ErrUserTotpNotFound {
+			ctx.JsonApiErr(500, "Failed to check two-factor authentication", err)
+			return true
+		}
+
+		if totpQuery.Result != nil && totpQuery.Result.Enabled {
+			ctx.JsonApiErr(401, "Basic auth is not supported for users with two-factor authentication, use an API key instead", nil)
+			return true
+		}
+	}
+
 	query := models.GetSignedInUserQuery{UserId: user.Id, OrgId: orgId}
 	if err := bus.Dispatch(&query); err != nil {
 		ctx.Logger.Error(
@@ -225,6 +240,12 @@ func initContextWithToken(authTokenService models.UserTokenService, ctx *models.
 		return false
 	}
 
+	if err := authTokenService.ValidateSessionPolicy(ctx.Req.Context(), token, query.Result.OrgId, query.Result.OrgRole); err != nil {
+		ctx.Logger.Info("User session rejected by session policy", "userId", token.UserId, "error", err)
+		WriteSessionCookie(ctx, "", -1)
+		return false
+	}
+
 	ctx.SignedInUser = query.Result
 	ctx.IsSignedIn = true
 	ctx.UserToken = token
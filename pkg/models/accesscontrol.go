@@ -0,0 +1,142 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Typed errors
+var (
+	ErrRoleNotFound           = errors.New("Role not found")
+	ErrRoleWithSameNameExists = errors.New("A role with the same name already exists")
+	ErrRoleAssignmentNotFound = errors.New("Role assignment not found")
+	ErrRoleAssignmentExists   = errors.New("Role is already assigned")
+	ErrRoleInvalidPermission  = errors.New("Invalid permission")
+	ErrRoleInvalidSubject     = errors.New("Invalid role assignment subject")
+)
+
+type RoleSubjectType string
+
+const (
+	RoleSubjectUser   RoleSubjectType = "user"
+	RoleSubjectTeam   RoleSubjectType = "team"
+	RoleSubjectApiKey RoleSubjectType = "api_key"
+)
+
+func (s RoleSubjectType) IsValid() bool {
+	return s == RoleSubjectUser || s == RoleSubjectTeam || s == RoleSubjectApiKey
+}
+
+// Role is a custom role composed of permissions
+type Role struct {
+	Id          int64
+	OrgId       int64
+	Name        string
+	Description string
+
+	Created time.Time
+	Updated time.Time
+}
+
+type RolePermission struct {
+	Id     int64
+	RoleId int64
+	Action string
+
+	Created time.Time
+}
+
+// RoleAssignment assigns a custom role to a user, team or API key
+type RoleAssignment struct {
+	Id          int64           `json:"id"`
+	OrgId       int64           `json:"orgId"`
+	RoleId      int64           `json:"roleId"`
+	SubjectType RoleSubjectType `json:"subjectType"`
+	SubjectId   int64           `json:"subjectId"`
+
+	Created time.Time `json:"created"`
+}
+
+// ---------------------
+// COMMANDS
+
+type CreateRoleCommand struct {
+	Name        string   `json:"name" binding:"Required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+
+	OrgId  int64    `json:"-"`
+	Result *RoleDTO `json:"-"`
+}
+
+type UpdateRoleCommand struct {
+	Name        string   `json:"name" binding:"Required"`
+	Description string   `json:"description"`
+	Permissions []string `json:"permissions"`
+
+	Id    int64 `json:"-"`
+	OrgId int64 `json:"-"`
+}
+
+type DeleteRoleCommand struct {
+	Id    int64
+	OrgId int64
+}
+
+type AddRoleAssignmentCommand struct {
+	SubjectType RoleSubjectType `json:"subjectType" binding:"Required"`
+	SubjectId   int64           `json:"subjectId" binding:"Required"`
+
+	OrgId  int64           `json:"-"`
+	RoleId int64           `json:"-"`
+	Result *RoleAssignment `json:"-"`
+}
+
+type RemoveRoleAssignmentCommand struct {
+	Id     int64
+	OrgId  int64
+	RoleId int64
+}
+
+// ---------------------
+// QUERIES
+
+type GetRolesQuery struct {
+	OrgId  int64
+	Result []*RoleDTO
+}
+
+type GetRoleByIdQuery struct {
+	Id     int64
+	OrgId  int64
+	Result *RoleDTO
+}
+
+type GetRoleAssignmentsQuery struct {
+	OrgId  int64
+	RoleId int64
+	Result []*RoleAssignment
+}
+
+// GetCustomPermissionsQuery returns the permissions granted through
+// custom roles to a user, the user's teams or an API key.
+type GetCustomPermissionsQuery struct {
+	OrgId    int64
+	UserId   int64
+	TeamIds  []int64
+	ApiKeyId int64
+	Result   []string
+}
+
+// ---------------------
+// DTO
+
+type RoleDTO struct {
+	Id          int64     `json:"id"`
+	OrgId       int64     `json:"orgId"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Permissions []string  `json:"permissions"`
+	Created     time.Time `json:"created"`
+	Updated     time.Time `json:"updated"`
+}
@@ -0,0 +1,68 @@
+package models
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+type AuditActorType string
+
+const (
+	AuditActorUser      AuditActorType = "user"
+	AuditActorApiKey    AuditActorType = "api_key"
+	AuditActorRender    AuditActorType = "render"
+	AuditActorAnonymous AuditActorType = "anonymous"
+)
+
+type AuditEntry struct {
+	Id           int64            `json:"id"`
+	OrgId        int64            `json:"orgId"`
+	ActorType    AuditActorType   `json:"actorType"`
+	ActorId      int64            `json:"actorId"`
+	ActorLogin   string           `json:"actorLogin"`
+	Action       string           `json:"action"`
+	ResourceType string           `json:"resourceType"`
+	ResourceId   string           `json:"resourceId"`
+	BeforeState  *simplejson.Json `json:"before"`
+	AfterState   *simplejson.Json `json:"after"`
+	ClientIp     string           `json:"clientIp"`
+	Status       int              `json:"status"`
+	Created      time.Time        `json:"created"`
+}
+
+// ---------------------
+// COMMANDS
+
+type CreateAuditEntryCommand struct {
+	Entry *AuditEntry
+}
+
+type DeleteOldAuditEntriesCommand struct {
+	OlderThan   time.Time
+	DeletedRows int64
+}
+
+// ---------------------
+// QUERIES
+
+type SearchAuditEntriesQuery struct {
+	OrgId        int64
+	ActorId      int64
+	ActorType    AuditActorType
+	ResourceType string
+	ResourceId   string
+	From         time.Time
+	To           time.Time
+	Page         int
+	Limit        int
+
+	Result SearchAuditEntriesQueryResult
+}
+
+type SearchAuditEntriesQueryResult struct {
+	TotalCount int64         `json:"totalCount"`
+	Entries    []*AuditEntry `json:"entries"`
+	Page       int           `json:"page"`
+	PerPage    int           `json:"perPage"`
+}
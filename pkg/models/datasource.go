@@ -25,6 +25,7 @@ const (
 	DS_STACKDRIVER   = "stackdriver"
 	DS_AZURE_MONITOR = "grafana-azure-monitor-datasource"
 	DS_LOKI          = "loki"
+	DS_JSON_API      = "jsonapi"
 )
 
 var (
@@ -61,6 +62,10 @@ type DataSource struct {
 	ReadOnly          bool
 	Uid               string
 
+	// PermissionsEnabled restricts querying the data source to org admins
+	// and the users and teams in the data source ACL.
+	PermissionsEnabled bool
+
 	Created time.Time
 	Updated time.Time
 }
@@ -100,6 +105,7 @@ var knownDatasourcePlugins = map[string]bool{
 	DS_STACKDRIVER:                           true,
 	DS_AZURE_MONITOR:                         true,
 	DS_LOKI:                                  true,
+	DS_JSON_API:                              true,
 	"opennms":                                true,
 	"abhisant-druid-datasource":              true,
 	"dalmatinerdb-datasource":                true,
@@ -229,10 +235,12 @@ type DsPermissionType int
 const (
 	DsPermissionNoAccess DsPermissionType = iota
 	DsPermissionQuery
+	DsPermissionEdit
 )
 
 func (p DsPermissionType) String() string {
 	names := map[int]string{
+		int(DsPermissionEdit):     "Edit",
 		int(DsPermissionQuery):    "Query",
 		int(DsPermissionNoAccess): "No Access",
 	}
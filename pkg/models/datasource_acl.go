@@ -0,0 +1,111 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Typed errors
+var (
+	ErrDataSourceAclInfoMissing        = errors.New("User id and team id cannot both be empty for a data source permission")
+	ErrDataSourceAclInfoAmbiguous      = errors.New("Data source permission can either be set for a user or a team, not both")
+	ErrDataSourcePermissionInvalid     = errors.New("Invalid data source permission")
+	ErrDataSourcePermissionExists      = errors.New("Data source permission already exists for the user or team")
+	ErrDataSourcePermissionNotFound    = errors.New("Data source permission not found")
+	ErrDataSourcePermissionsNotEnabled = errors.New("Data source permissions are not enabled")
+)
+
+func (p DsPermissionType) IsValid() bool {
+	return p == DsPermissionQuery || p == DsPermissionEdit
+}
+
+// DataSourceAcl grants a user or team permission to a data source
+type DataSourceAcl struct {
+	Id           int64
+	OrgId        int64
+	DataSourceId int64
+
+	UserId     int64
+	TeamId     int64
+	Permission DsPermissionType
+
+	Created time.Time
+	Updated time.Time
+}
+
+type DataSourceAclInfoDTO struct {
+	Id           int64 `json:"id"`
+	OrgId        int64 `json:"-"`
+	DataSourceId int64 `json:"datasourceId"`
+
+	UserId         int64            `json:"userId,omitempty"`
+	UserLogin      string           `json:"userLogin,omitempty"`
+	UserEmail      string           `json:"userEmail,omitempty"`
+	UserAvatarUrl  string           `json:"userAvatarUrl,omitempty"`
+	TeamId         int64            `json:"teamId,omitempty"`
+	Team           string           `json:"team,omitempty"`
+	TeamEmail      string           `json:"-"`
+	TeamAvatarUrl  string           `json:"teamAvatarUrl,omitempty"`
+	Permission     DsPermissionType `json:"permission"`
+	PermissionName string           `json:"permissionName"`
+
+	Created time.Time `json:"created"`
+	Updated time.Time `json:"updated"`
+}
+
+type DataSourcePermissionsDTO struct {
+	DataSourceId int64                   `json:"datasourceId"`
+	Enabled      bool                    `json:"enabled"`
+	Permissions  []*DataSourceAclInfoDTO `json:"permissions"`
+}
+
+//
+// COMMANDS
+//
+
+type EnableDataSourcePermissionsCommand struct {
+	OrgId        int64
+	DataSourceId int64
+}
+
+// DisableDataSourcePermissionsCommand disables permissions and
+// removes all existing permissions of the data source.
+type DisableDataSourcePermissionsCommand struct {
+	OrgId        int64
+	DataSourceId int64
+}
+
+type AddDataSourcePermissionCommand struct {
+	UserId     int64            `json:"userId"`
+	TeamId     int64            `json:"teamId"`
+	Permission DsPermissionType `json:"permission"`
+
+	OrgId        int64          `json:"-"`
+	DataSourceId int64          `json:"-"`
+	Result       *DataSourceAcl `json:"-"`
+}
+
+type RemoveDataSourcePermissionCommand struct {
+	Id           int64
+	OrgId        int64
+	DataSourceId int64
+}
+
+//
+// QUERIES
+//
+
+type GetDataSourcePermissionsQuery struct {
+	OrgId        int64
+	DataSourceId int64
+	Result       *DataSourcePermissionsDTO
+}
+
+// GetDataSourceUserPermissionQuery returns the highest permission the user
+// has been granted on the data source, directly or through one of the user's teams.
+type GetDataSourceUserPermissionQuery struct {
+	OrgId        int64
+	DataSourceId int64
+	UserId       int64
+	Result       DsPermissionType
+}
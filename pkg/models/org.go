@@ -23,6 +23,10 @@ type Org struct {
 	State    string
 	Country  string
 
+	// RequireTwoFactor requires users with a Grafana login to
+	// set up two-factor authentication to use the organization.
+	RequireTwoFactor bool
+
 	Created time.Time
 	Updated time.Time
 }
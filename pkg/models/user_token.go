@@ -8,6 +8,7 @@ import (
 // Typed errors
 var (
 	ErrUserTokenNotFound = errors.New("user token not found")
+	ErrUserTokenExpired  = errors.New("user token expired by session policy")
 )
 
 // UserToken represents a user token
@@ -37,6 +38,9 @@ type UserTokenService interface {
 	TryRotateToken(ctx context.Context, token *UserToken, clientIP, userAgent string) (bool, error)
 	RevokeToken(ctx context.Context, token *UserToken) error
 	RevokeAllUserTokens(ctx context.Context, userId int64) error
+	RevokeAllOrgUserTokens(ctx context.Context, orgId int64) error
+	RevokeAllTokens(ctx context.Context) error
+	ValidateSessionPolicy(ctx context.Context, token *UserToken, orgId int64, role RoleType) error
 	ActiveTokenCount(ctx context.Context) (int64, error)
 	GetUserToken(ctx context.Context, userId, userTokenId int64) (*UserToken, error)
 	GetUserTokens(ctx context.Context, userId int64) ([]*UserToken, error)
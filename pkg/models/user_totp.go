@@ -0,0 +1,89 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+// Typed errors
+var (
+	ErrUserTotpNotFound         = errors.New("Two-factor authentication is not set up for user")
+	ErrUserTotpAlreadyEnabled   = errors.New("Two-factor authentication is already enabled for user")
+	ErrTotpRecoveryCodeNotFound = errors.New("Invalid recovery code")
+	ErrUserTotpCodeUsed         = errors.New("Two-factor authentication code has already been used")
+)
+
+// UserTotp holds the TOTP secret of a user. The secret is stored encrypted and
+// is only used to verify codes once Enabled is set.
+type UserTotp struct {
+	Id           int64
+	UserId       int64
+	Secret       string
+	Enabled      bool
+	LastUsedStep int64
+
+	Created time.Time
+	Updated time.Time
+}
+
+// UserTotpRecoveryCode is a hashed single use code that can be used instead of a TOTP code.
+type UserTotpRecoveryCode struct {
+	Id     int64
+	UserId int64
+	Code   string
+	Salt   string
+
+	Created time.Time
+}
+
+// ---------------------
+// COMMANDS
+
+// SaveUserTotpCommand stores a new, not yet enabled, secret for the user.
+type SaveUserTotpCommand struct {
+	UserId int64
+	Secret string
+}
+
+// EnableUserTotpCommand enables TOTP for the user and replaces the user's recovery codes.
+type EnableUserTotpCommand struct {
+	UserId        int64
+	LastUsedStep  int64
+	RecoveryCodes []*UserTotpRecoveryCode
+}
+
+// UpdateUserTotpLastUsedStepCommand records the time step of a used code. It fails with
+// ErrUserTotpCodeUsed if a code of the same or a later step has been used already.
+type UpdateUserTotpLastUsedStepCommand struct {
+	UserId       int64
+	LastUsedStep int64
+}
+
+// DeleteUserTotpCommand disables TOTP for the user and removes the secret and recovery codes.
+type DeleteUserTotpCommand struct {
+	UserId int64
+}
+
+// UseUserTotpRecoveryCodeCommand consumes a recovery code of the user.
+type UseUserTotpRecoveryCodeCommand struct {
+	UserId int64
+	Id     int64
+}
+
+type SetOrgRequireTwoFactorCommand struct {
+	OrgId            int64
+	RequireTwoFactor bool
+}
+
+// ---------------------
+// QUERIES
+
+type GetUserTotpQuery struct {
+	UserId int64
+	Result *UserTotp
+}
+
+type GetUserTotpRecoveryCodesQuery struct {
+	UserId int64
+	Result []*UserTotpRecoveryCode
+}
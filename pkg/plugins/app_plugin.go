@@ -67,7 +67,7 @@ func (app *AppPlugin) Load(decoder *json.Decoder, pluginDir string, backendPlugi
 		return err
 	}
 
-	Apps[app.Id] = app
+	addApp(app)
 	return nil
 }
 
@@ -75,7 +75,7 @@ func (app *AppPlugin) initApp() {
 	app.initFrontendPlugin()
 
 	// check if we have child panels
-	for _, panel := range Panels {
+	for _, panel := range Panels() {
 		if strings.HasPrefix(panel.PluginDir, app.PluginDir) {
 			panel.setPathsBasedOnApp(app)
 			app.FoundChildPlugins = append(app.FoundChildPlugins, &PluginInclude{
@@ -87,7 +87,7 @@ func (app *AppPlugin) initApp() {
 	}
 
 	// check if we have child datasources
-	for _, ds := range DataSources {
+	for _, ds := range DataSources() {
 		if strings.HasPrefix(ds.PluginDir, app.PluginDir) {
 			ds.setPathsBasedOnApp(app)
 			app.FoundChildPlugins = append(app.FoundChildPlugins, &PluginInclude{
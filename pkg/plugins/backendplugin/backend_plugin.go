@@ -22,12 +22,30 @@ type BackendPlugin struct {
 	id             string
 	executablePath string
 	managed        bool
-	clientFactory  func() *plugin.Client
-	client         *plugin.Client
+	clientFactory  func() pluginClient
+	client         pluginClient
 	logger         log.Logger
 	startFns       PluginStartFuncs
 	diagnostics    DiagnosticsPlugin
 	resource       ResourcePlugin
+	stream         pluginextensionv2.StreamPlugin
+	// cancel stops restarting the plugin when its process is killed.
+	cancel context.CancelFunc
+	// limits are the resource limits of the plugin process, enforced by its cgroup if it has one.
+	limits  processLimits
+	cgroup  string
+	process processStatus
+	// restart passes restart requests to the goroutine supervising the plugin process.
+	restart chan struct{}
+}
+
+// pluginClient is the client of a plugin process, implemented by *plugin.Client.
+type pluginClient interface {
+	Client() (plugin.ClientProtocol, error)
+	NegotiatedVersion() int
+	ReattachConfig() *plugin.ReattachConfig
+	Exited() bool
+	Kill()
 }
 
 func (p *BackendPlugin) start(ctx context.Context) error {
@@ -66,6 +84,11 @@ func (p *BackendPlugin) start(ctx context.Context) error {
 			return err
 		}
 
+		rawStream, err := rpcClient.Dispense("stream")
+		if err != nil {
+			return err
+		}
+
 		if rawDiagnostics != nil {
 			if plugin, ok := rawDiagnostics.(DiagnosticsPlugin); ok {
 				p.diagnostics = plugin
@@ -97,6 +120,13 @@ func (p *BackendPlugin) start(ctx context.Context) error {
 				client.RendererPlugin = plugin
 			}
 		}
+
+		if rawStream != nil {
+			if plugin, ok := rawStream.(pluginextensionv2.StreamPlugin); ok {
+				p.stream = plugin
+				client.StreamPlugin = plugin
+			}
+		}
 	} else {
 		p.logger.Warn("Plugin uses a deprecated version of Grafana's backend plugin system which will be removed in a future release. " +
 			"Consider upgrading to a newer plugin version or reach out to the plugin repository/developer and request an upgrade.")
@@ -77,6 +77,7 @@ func getV2PluginSet() goplugin.PluginSet {
 		"data":        &grpcplugin.DataGRPCPlugin{},
 		"transform":   &grpcplugin.TransformGRPCPlugin{},
 		"renderer":    &pluginextensionv2.RendererGRPCPlugin{},
+		"stream":      &pluginextensionv2.StreamGRPCPlugin{},
 	}
 }
 
@@ -142,4 +143,5 @@ type Client struct {
 	DataPlugin      DataPlugin
 	TransformPlugin TransformPlugin
 	RendererPlugin  pluginextensionv2.RendererPlugin
+	StreamPlugin    pluginextensionv2.StreamPlugin
 }
@@ -45,3 +45,57 @@ func InstrumentPluginRequest(pluginID string, endpoint string, fn func() error)
 
 	return err
 }
+
+var (
+	processUpDesc = prometheus.NewDesc("grafana_plugin_process_up",
+		"Whether the process of the backend plugin is running", []string{"plugin_id"}, nil)
+	processCrashLoopDesc = prometheus.NewDesc("grafana_plugin_process_crash_loop",
+		"Whether the process of the backend plugin exited too many times in a row", []string{"plugin_id"}, nil)
+	processRestartsDesc = prometheus.NewDesc("grafana_plugin_process_restarts_total",
+		"The total amount of restarts of the process of the backend plugin", []string{"plugin_id"}, nil)
+	processHealthCheckFailuresDesc = prometheus.NewDesc("grafana_plugin_process_health_check_failures",
+		"The amount of failed health checks of the process of the backend plugin in a row", []string{"plugin_id"}, nil)
+	processMemoryDesc = prometheus.NewDesc("grafana_plugin_process_resident_memory_bytes",
+		"Resident memory size of the process of the backend plugin in bytes", []string{"plugin_id"}, nil)
+	processCPUDesc = prometheus.NewDesc("grafana_plugin_process_cpu_seconds_total",
+		"Total user and system CPU time of the process of the backend plugin in seconds", []string{"plugin_id"}, nil)
+)
+
+// processCollector collects the status of the processes of the registered backend plugins.
+type processCollector struct {
+	manager Manager
+}
+
+func newProcessCollector(manager Manager) prometheus.Collector {
+	return &processCollector{manager: manager}
+}
+
+func (c *processCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- processUpDesc
+	ch <- processCrashLoopDesc
+	ch <- processRestartsDesc
+	ch <- processHealthCheckFailuresDesc
+	ch <- processMemoryDesc
+	ch <- processCPUDesc
+}
+
+func (c *processCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, status := range c.manager.PluginStatuses() {
+		up, crashLoop := 0.0, 0.0
+		switch status.State {
+		case PluginStateRunning:
+			up = 1
+		case PluginStateCrashLoop:
+			crashLoop = 1
+		}
+
+		ch <- prometheus.MustNewConstMetric(processUpDesc, prometheus.GaugeValue, up, status.PluginID)
+		ch <- prometheus.MustNewConstMetric(processCrashLoopDesc, prometheus.GaugeValue, crashLoop, status.PluginID)
+		ch <- prometheus.MustNewConstMetric(processRestartsDesc, prometheus.CounterValue, float64(status.Restarts), status.PluginID)
+		ch <- prometheus.MustNewConstMetric(processHealthCheckFailuresDesc, prometheus.GaugeValue, float64(status.HealthCheckFailures), status.PluginID)
+		if status.Pid != 0 && (status.MemoryBytes != 0 || status.CPUSeconds != 0) {
+			ch <- prometheus.MustNewConstMetric(processMemoryDesc, prometheus.GaugeValue, float64(status.MemoryBytes), status.PluginID)
+			ch <- prometheus.MustNewConstMetric(processCPUDesc, prometheus.CounterValue, status.CPUSeconds, status.PluginID)
+		}
+	}
+}
@@ -6,8 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"sort"
 	"sync"
-	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 	"github.com/grafana/grafana/pkg/models"
@@ -18,7 +18,7 @@ import (
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/registry"
 	plugin "github.com/hashicorp/go-plugin"
-	"golang.org/x/xerrors"
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 var (
@@ -42,6 +42,8 @@ func init() {
 type Manager interface {
 	// Register registers a backend plugin
 	Register(descriptor PluginDescriptor) error
+	// Unregister stops and unregisters a backend plugin.
+	Unregister(pluginID string) error
 	// StartPlugin starts a non-managed backend plugin
 	StartPlugin(ctx context.Context, pluginID string) error
 	// CollectMetrics collects metrics from a registered backend plugin.
@@ -50,21 +52,42 @@ type Manager interface {
 	CheckHealth(ctx context.Context, pCtx backend.PluginContext) (*CheckHealthResult, error)
 	// CallResource calls a plugin resource.
 	CallResource(pluginConfig backend.PluginContext, ctx *models.ReqContext, path string)
+	// RunStream runs a stream of a plugin until the context is done or the plugin ends the stream.
+	RunStream(ctx context.Context, pCtx backend.PluginContext, path string, send func(*StreamMessage) error) error
+	// PublishStream sends a message published by a client to a stream of a plugin.
+	PublishStream(ctx context.Context, pCtx backend.PluginContext, path string, data []byte) ([]byte, error)
+	// PluginStatus returns the process status of a registered backend plugin.
+	PluginStatus(pluginID string) (*PluginStatus, error)
+	// PluginStatuses returns the process status of all registered backend plugins.
+	PluginStatuses() []*PluginStatus
+	// RestartPlugin restarts the process of a running backend plugin without restart backoff.
+	RestartPlugin(pluginID string) error
 }
 
 type manager struct {
-	Cfg            *setting.Cfg     `inject:""`
-	License        models.Licensing `inject:""`
-	pluginsMu      sync.RWMutex
-	plugins        map[string]*BackendPlugin
+	Cfg       *setting.Cfg     `inject:""`
+	License   models.Licensing `inject:""`
+	pluginsMu sync.RWMutex
+	plugins   map[string]*BackendPlugin
+	// ctx is the context of the running manager, managed plugins registered after the manager
+	// started are started with it.
+	ctx            context.Context
 	logger         log.Logger
 	pluginSettings map[string]pluginSettings
+	supervisor     supervisorSettings
 }
 
 func (m *manager) Init() error {
 	m.plugins = make(map[string]*BackendPlugin)
 	m.logger = log.New("plugins.backend")
 	m.pluginSettings = extractPluginSettings(m.Cfg)
+	m.supervisor = newSupervisorSettings(m.Cfg)
+
+	if err := prometheus.Register(newProcessCollector(m)); err != nil {
+		if _, registered := err.(prometheus.AlreadyRegisteredError); !registered {
+			return err
+		}
+	}
 
 	return nil
 }
@@ -76,13 +99,19 @@ func (m *manager) Run(ctx context.Context) error {
 	return ctx.Err()
 }
 
-// Register registers a backend plugin
+// Register registers a backend plugin. Managed plugins that are registered after the
+// manager started, e.g. plugins installed at runtime, are started right away.
 func (m *manager) Register(descriptor PluginDescriptor) error {
 	m.logger.Debug("Registering backend plugin", "pluginId", descriptor.pluginID, "executablePath", descriptor.executablePath)
+	limits, err := extractProcessLimits(m.Cfg, descriptor.pluginID)
+	if err != nil {
+		return errutil.Wrapf(err, "Invalid resource limits of backend plugin")
+	}
+
 	m.pluginsMu.Lock()
-	defer m.pluginsMu.Unlock()
 
 	if _, exists := m.plugins[descriptor.pluginID]; exists {
+		m.pluginsMu.Unlock()
 		return errors.New("Backend plugin already registered")
 	}
 
@@ -107,28 +136,99 @@ func (m *manager) Register(descriptor PluginDescriptor) error {
 		id:             descriptor.pluginID,
 		executablePath: descriptor.executablePath,
 		managed:        descriptor.managed,
-		clientFactory: func() *plugin.Client {
+		clientFactory: func() pluginClient {
 			return plugin.NewClient(newClientConfig(descriptor.executablePath, env, pluginLogger, descriptor.versionedPlugins))
 		},
 		startFns: descriptor.startFns,
 		logger:   pluginLogger,
+		limits:   limits,
+		restart:  make(chan struct{}, 1),
 	}
+	plugin.cgroup = m.setupCgroup(plugin)
 
 	m.plugins[descriptor.pluginID] = plugin
+	ctx := m.ctx
+	m.pluginsMu.Unlock()
 	m.logger.Debug("Backend plugin registered", "pluginId", descriptor.pluginID, "executablePath", descriptor.executablePath)
+
+	if ctx == nil || !plugin.managed {
+		return nil
+	}
+
+	if err := startPluginAndRestartKilledProcesses(ctx, plugin, m.supervisor); err != nil {
+		m.pluginsMu.Lock()
+		delete(m.plugins, descriptor.pluginID)
+		m.pluginsMu.Unlock()
+		if plugin.cgroup != "" {
+			if err := removeCgroup(plugin.cgroup); err != nil {
+				plugin.logger.Warn("Failed to remove plugin cgroup", "cgroup", plugin.cgroup, "error", err)
+			}
+		}
+		return errutil.Wrapf(err, "Failed to start backend plugin")
+	}
+
 	return nil
 }
 
+// Unregister stops and unregisters a backend plugin.
+func (m *manager) Unregister(pluginID string) error {
+	m.pluginsMu.Lock()
+	p, registered := m.plugins[pluginID]
+	delete(m.plugins, pluginID)
+	m.pluginsMu.Unlock()
+
+	if !registered {
+		return ErrPluginNotRegistered
+	}
+
+	p.logger.Debug("Stopping plugin")
+	if p.cancel != nil {
+		p.cancel()
+	}
+	if err := p.stop(); err != nil {
+		return errutil.Wrapf(err, "Failed to stop backend plugin")
+	}
+	if p.cgroup != "" {
+		if err := removeCgroup(p.cgroup); err != nil {
+			p.logger.Warn("Failed to remove plugin cgroup", "cgroup", p.cgroup, "error", err)
+		}
+	}
+	m.logger.Debug("Backend plugin unregistered", "pluginId", pluginID)
+	return nil
+}
+
+// setupCgroup creates the cgroup of a plugin with resource limits, if a cgroup parent is configured.
+// Without a cgroup the memory limit is enforced by the supervisor and the CPU limit is ignored.
+func (m *manager) setupCgroup(p *BackendPlugin) string {
+	if p.limits.memoryBytes <= 0 && p.limits.cpu <= 0 {
+		return ""
+	}
+
+	if m.supervisor.cgroupParent != "" {
+		cgroup, err := setupCgroup(m.supervisor.cgroupParent, p.id, p.limits)
+		if err == nil {
+			return cgroup
+		}
+		p.logger.Warn("Failed to create plugin cgroup, falling back to supervising its memory usage", "error", err)
+	}
+
+	if p.limits.cpu > 0 {
+		p.logger.Warn("Plugin CPU limit is only enforced in a cgroup, set backend_cgroup_parent to enforce it")
+	}
+	return ""
+}
+
 // start starts all managed backend plugins
 func (m *manager) start(ctx context.Context) {
-	m.pluginsMu.RLock()
-	defer m.pluginsMu.RUnlock()
+	m.pluginsMu.Lock()
+	defer m.pluginsMu.Unlock()
+	m.ctx = ctx
 	for _, p := range m.plugins {
 		if !p.managed {
 			continue
 		}
 
-		if err := startPluginAndRestartKilledProcesses(ctx, p); err != nil {
+		if err := startPluginAndRestartKilledProcesses(ctx, p, m.supervisor); err != nil {
 			p.logger.Error("Failed to start plugin", "error", err)
 			continue
 		}
@@ -148,7 +248,7 @@ func (m *manager) StartPlugin(ctx context.Context, pluginID string) error {
 		return errors.New("Backend plugin is managed and cannot be manually started")
 	}
 
-	return startPluginAndRestartKilledProcesses(ctx, p)
+	return startPluginAndRestartKilledProcesses(ctx, p, m.supervisor)
 }
 
 // stop stops all managed backend plugins
@@ -211,6 +311,63 @@ func (m *manager) CheckHealth(ctx context.Context, pluginConfig backend.PluginCo
 	return checkHealthResultFromProto(res), nil
 }
 
+// PluginStatus returns the process status of a registered backend plugin.
+func (m *manager) PluginStatus(pluginID string) (*PluginStatus, error) {
+	m.pluginsMu.RLock()
+	p, registered := m.plugins[pluginID]
+	m.pluginsMu.RUnlock()
+
+	if !registered {
+		return nil, ErrPluginNotRegistered
+	}
+
+	return p.status(), nil
+}
+
+// PluginStatuses returns the process status of all registered backend plugins, sorted by plugin ID.
+func (m *manager) PluginStatuses() []*PluginStatus {
+	m.pluginsMu.RLock()
+	plugins := make([]*BackendPlugin, 0, len(m.plugins))
+	for _, p := range m.plugins {
+		plugins = append(plugins, p)
+	}
+	m.pluginsMu.RUnlock()
+
+	statuses := make([]*PluginStatus, 0, len(plugins))
+	for _, p := range plugins {
+		statuses = append(statuses, p.status())
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].PluginID < statuses[j].PluginID
+	})
+	return statuses
+}
+
+// RestartPlugin restarts the process of a running backend plugin. Plugins waiting for a restart,
+// e.g. in a crash loop, are restarted right away.
+func (m *manager) RestartPlugin(pluginID string) error {
+	m.pluginsMu.RLock()
+	p, registered := m.plugins[pluginID]
+	m.pluginsMu.RUnlock()
+
+	if !registered {
+		return ErrPluginNotRegistered
+	}
+
+	switch p.process.status().State {
+	case PluginStateNotStarted, PluginStateStopped:
+		return ErrPluginNotRunning
+	}
+
+	p.logger.Info("Restarting plugin on request")
+	select {
+	case p.restart <- struct{}{}:
+	default:
+		// a restart is already pending
+	}
+	return nil
+}
+
 type keepCookiesJSONModel struct {
 	KeepCookies []string `json:"keepCookies"`
 }
@@ -318,41 +475,21 @@ func flushStream(plugin *BackendPlugin, stream callResourceResultStream, reqCtx
 	}
 }
 
-func startPluginAndRestartKilledProcesses(ctx context.Context, p *BackendPlugin) error {
+func startPluginAndRestartKilledProcesses(ctx context.Context, p *BackendPlugin, settings supervisorSettings) error {
+	ctx, cancel := context.WithCancel(ctx)
 	if err := p.start(ctx); err != nil {
+		cancel()
+		p.process.stopped(err)
 		return err
 	}
+	p.cancel = cancel
+	p.started()
 
 	go func(ctx context.Context, p *BackendPlugin) {
-		if err := restartKilledProcess(ctx, p); err != nil {
+		if err := supervise(ctx, p, settings); err != nil {
 			p.logger.Error("Attempt to restart killed plugin process failed", "error", err)
 		}
 	}(ctx, p)
 
 	return nil
 }
-
-func restartKilledProcess(ctx context.Context, p *BackendPlugin) error {
-	ticker := time.NewTicker(time.Second * 1)
-
-	for {
-		select {
-		case <-ctx.Done():
-			if err := ctx.Err(); err != nil && !xerrors.Is(err, context.Canceled) {
-				return err
-			}
-			return nil
-		case <-ticker.C:
-			if !p.client.Exited() {
-				continue
-			}
-
-			p.logger.Debug("Restarting plugin")
-			if err := p.start(ctx); err != nil {
-				p.logger.Error("Failed to restart plugin", "error", err)
-				continue
-			}
-			p.logger.Debug("Plugin restarted")
-		}
-	}
-}
@@ -2,9 +2,16 @@ package backendplugin
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/grafana/grafana/pkg/setting"
+	"golang.org/x/xerrors"
+)
+
+const (
+	memoryLimitSetting = "memory_limit_mb"
+	cpuLimitSetting    = "cpu_limit"
 )
 
 type pluginSettings map[string]string
@@ -25,7 +32,7 @@ func extractPluginSettings(cfg *setting.Cfg) map[string]pluginSettings {
 	for pluginID, settings := range cfg.PluginSettings {
 		ps := pluginSettings{}
 		for k, v := range settings {
-			if k == "path" || strings.ToLower(k) == "id" {
+			if k == "path" || strings.ToLower(k) == "id" || k == memoryLimitSetting || k == cpuLimitSetting {
 				continue
 			}
 
@@ -37,3 +44,36 @@ func extractPluginSettings(cfg *setting.Cfg) map[string]pluginSettings {
 
 	return psMap
 }
+
+// processLimits are the resource limits of the process of a backend plugin. Zero means unlimited.
+type processLimits struct {
+	memoryBytes int64
+	cpu         float64
+}
+
+// extractProcessLimits returns the limits of a plugin, from the memory_limit_mb and cpu_limit
+// settings of the plugin or else the backend plugin limits of the plugins section.
+func extractProcessLimits(cfg *setting.Cfg, pluginID string) (processLimits, error) {
+	limits := processLimits{
+		memoryBytes: cfg.PluginMemoryLimitMB * 1024 * 1024,
+		cpu:         cfg.PluginCPULimit,
+	}
+
+	settings := cfg.PluginSettings[pluginID]
+	if v, ok := settings[memoryLimitSetting]; ok && v != "" {
+		mb, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || mb < 0 {
+			return limits, xerrors.Errorf("invalid %s %q", memoryLimitSetting, v)
+		}
+		limits.memoryBytes = mb * 1024 * 1024
+	}
+	if v, ok := settings[cpuLimitSetting]; ok && v != "" {
+		cpu, err := strconv.ParseFloat(v, 64)
+		if err != nil || cpu < 0 {
+			return limits, xerrors.Errorf("invalid %s %q", cpuLimitSetting, v)
+		}
+		limits.cpu = cpu
+	}
+
+	return limits, nil
+}
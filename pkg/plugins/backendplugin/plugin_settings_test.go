@@ -35,6 +35,13 @@ func TestPluginSettings(t *testing.T) {
 			require.Len(t, ps["plugin"], 2)
 		})
 
+		t.Run("Should skip resource limit settings", func(t *testing.T) {
+			cfg.PluginSettings["plugin"]["memory_limit_mb"] = "256"
+			cfg.PluginSettings["plugin"]["cpu_limit"] = "0.5"
+			ps := extractPluginSettings(cfg)
+			require.Len(t, ps["plugin"], 2)
+		})
+
 		t.Run("Should return expected environment variables from plugin settings ", func(t *testing.T) {
 			ps := extractPluginSettings(cfg)
 			env := ps["plugin"].ToEnv("GF_PLUGIN", []string{"GF_VERSION=6.7.0"})
@@ -44,3 +51,36 @@ func TestPluginSettings(t *testing.T) {
 		})
 	})
 }
+
+func TestProcessLimits(t *testing.T) {
+	cfg := &setting.Cfg{
+		PluginMemoryLimitMB: 512,
+		PluginCPULimit:      2,
+		PluginSettings: setting.PluginSettings{
+			"limited": map[string]string{
+				"memory_limit_mb": "128",
+				"cpu_limit":       "0.5",
+			},
+			"invalid": map[string]string{
+				"memory_limit_mb": "128MB",
+			},
+		},
+	}
+
+	t.Run("Should use limits of plugins section by default", func(t *testing.T) {
+		limits, err := extractProcessLimits(cfg, "plugin")
+		require.NoError(t, err)
+		require.Equal(t, processLimits{memoryBytes: 512 * 1024 * 1024, cpu: 2}, limits)
+	})
+
+	t.Run("Should use limits of plugin settings", func(t *testing.T) {
+		limits, err := extractProcessLimits(cfg, "limited")
+		require.NoError(t, err)
+		require.Equal(t, processLimits{memoryBytes: 128 * 1024 * 1024, cpu: 0.5}, limits)
+	})
+
+	t.Run("Should fail on invalid limits", func(t *testing.T) {
+		_, err := extractProcessLimits(cfg, "invalid")
+		require.Error(t, err)
+	})
+}
@@ -13,4 +13,8 @@ DIR="$( cd -P "$( dirname "$SOURCE" )" && pwd )"
 
 cd "$DIR"
 
-protoc -I ./ rendererv2.proto --go_out=plugins=grpc:./
\ No newline at end of file
+protoc -I ./ rendererv2.proto --go_out=plugins=grpc:./
+# streamv2.proto uses the plugin context of the plugin SDK
+SDK_PROTO_DIR="$(go list -m -f '{{.Dir}}' github.com/grafana/grafana-plugin-sdk-go)/proto"
+protoc -I ./ -I "$SDK_PROTO_DIR" streamv2.proto \
+  --go_out=plugins=grpc,Mbackend.proto=github.com/grafana/grafana-plugin-sdk-go/genproto/pluginv2:./
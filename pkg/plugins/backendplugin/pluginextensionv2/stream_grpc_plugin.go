@@ -0,0 +1,39 @@
+package pluginextensionv2
+
+import (
+	"context"
+
+	"github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+)
+
+type StreamPlugin interface {
+	StreamClient
+}
+
+type StreamGRPCPlugin struct {
+	plugin.NetRPCUnsupportedPlugin
+}
+
+func (p *StreamGRPCPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	return nil
+}
+
+func (p *StreamGRPCPlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
+	return &StreamGRPCClient{NewStreamClient(c)}, nil
+}
+
+type StreamGRPCClient struct {
+	StreamClient
+}
+
+func (m *StreamGRPCClient) RunStream(ctx context.Context, req *RunStreamRequest, opts ...grpc.CallOption) (Stream_RunStreamClient, error) {
+	return m.StreamClient.RunStream(ctx, req)
+}
+
+func (m *StreamGRPCClient) PublishStream(ctx context.Context, req *PublishStreamRequest, opts ...grpc.CallOption) (*PublishStreamResponse, error) {
+	return m.StreamClient.PublishStream(ctx, req)
+}
+
+var _ StreamClient = &StreamGRPCClient{}
+var _ plugin.GRPCPlugin = &StreamGRPCPlugin{}
@@ -0,0 +1,428 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: streamv2.proto
+
+package pluginextensionv2
+
+import (
+	context "context"
+	fmt "fmt"
+	proto "github.com/golang/protobuf/proto"
+	pluginv2 "github.com/grafana/grafana-plugin-sdk-go/genproto/pluginv2"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	math "math"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type PublishStreamResponse_Status int32
+
+const (
+	PublishStreamResponse_OK                PublishStreamResponse_Status = 0
+	PublishStreamResponse_PERMISSION_DENIED PublishStreamResponse_Status = 1
+)
+
+var PublishStreamResponse_Status_name = map[int32]string{
+	0: "OK",
+	1: "PERMISSION_DENIED",
+}
+
+var PublishStreamResponse_Status_value = map[string]int32{
+	"OK":                0,
+	"PERMISSION_DENIED": 1,
+}
+
+func (x PublishStreamResponse_Status) String() string {
+	return proto.EnumName(PublishStreamResponse_Status_name, int32(x))
+}
+
+func (PublishStreamResponse_Status) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_d678f1d7ff20dddb, []int{3, 0}
+}
+
+type RunStreamRequest struct {
+	PluginContext        *pluginv2.PluginContext `protobuf:"bytes,1,opt,name=pluginContext,proto3" json:"pluginContext,omitempty"`
+	Path                 string                  `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
+	XXX_unrecognized     []byte                  `json:"-"`
+	XXX_sizecache        int32                   `json:"-"`
+}
+
+func (m *RunStreamRequest) Reset()         { *m = RunStreamRequest{} }
+func (m *RunStreamRequest) String() string { return proto.CompactTextString(m) }
+func (*RunStreamRequest) ProtoMessage()    {}
+func (*RunStreamRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d678f1d7ff20dddb, []int{0}
+}
+
+func (m *RunStreamRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RunStreamRequest.Unmarshal(m, b)
+}
+func (m *RunStreamRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RunStreamRequest.Marshal(b, m, deterministic)
+}
+func (m *RunStreamRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RunStreamRequest.Merge(m, src)
+}
+func (m *RunStreamRequest) XXX_Size() int {
+	return xxx_messageInfo_RunStreamRequest.Size(m)
+}
+func (m *RunStreamRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_RunStreamRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RunStreamRequest proto.InternalMessageInfo
+
+func (m *RunStreamRequest) GetPluginContext() *pluginv2.PluginContext {
+	if m != nil {
+		return m.PluginContext
+	}
+	return nil
+}
+
+func (m *RunStreamRequest) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+type StreamPacket struct {
+	ContentType          string   `protobuf:"bytes,1,opt,name=contentType,proto3" json:"contentType,omitempty"`
+	Data                 []byte   `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *StreamPacket) Reset()         { *m = StreamPacket{} }
+func (m *StreamPacket) String() string { return proto.CompactTextString(m) }
+func (*StreamPacket) ProtoMessage()    {}
+func (*StreamPacket) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d678f1d7ff20dddb, []int{1}
+}
+
+func (m *StreamPacket) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_StreamPacket.Unmarshal(m, b)
+}
+func (m *StreamPacket) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_StreamPacket.Marshal(b, m, deterministic)
+}
+func (m *StreamPacket) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_StreamPacket.Merge(m, src)
+}
+func (m *StreamPacket) XXX_Size() int {
+	return xxx_messageInfo_StreamPacket.Size(m)
+}
+func (m *StreamPacket) XXX_DiscardUnknown() {
+	xxx_messageInfo_StreamPacket.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_StreamPacket proto.InternalMessageInfo
+
+func (m *StreamPacket) GetContentType() string {
+	if m != nil {
+		return m.ContentType
+	}
+	return ""
+}
+
+func (m *StreamPacket) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type PublishStreamRequest struct {
+	PluginContext        *pluginv2.PluginContext `protobuf:"bytes,1,opt,name=pluginContext,proto3" json:"pluginContext,omitempty"`
+	Path                 string                  `protobuf:"bytes,2,opt,name=path,proto3" json:"path,omitempty"`
+	Data                 []byte                  `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                `json:"-"`
+	XXX_unrecognized     []byte                  `json:"-"`
+	XXX_sizecache        int32                   `json:"-"`
+}
+
+func (m *PublishStreamRequest) Reset()         { *m = PublishStreamRequest{} }
+func (m *PublishStreamRequest) String() string { return proto.CompactTextString(m) }
+func (*PublishStreamRequest) ProtoMessage()    {}
+func (*PublishStreamRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d678f1d7ff20dddb, []int{2}
+}
+
+func (m *PublishStreamRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PublishStreamRequest.Unmarshal(m, b)
+}
+func (m *PublishStreamRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PublishStreamRequest.Marshal(b, m, deterministic)
+}
+func (m *PublishStreamRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PublishStreamRequest.Merge(m, src)
+}
+func (m *PublishStreamRequest) XXX_Size() int {
+	return xxx_messageInfo_PublishStreamRequest.Size(m)
+}
+func (m *PublishStreamRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_PublishStreamRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PublishStreamRequest proto.InternalMessageInfo
+
+func (m *PublishStreamRequest) GetPluginContext() *pluginv2.PluginContext {
+	if m != nil {
+		return m.PluginContext
+	}
+	return nil
+}
+
+func (m *PublishStreamRequest) GetPath() string {
+	if m != nil {
+		return m.Path
+	}
+	return ""
+}
+
+func (m *PublishStreamRequest) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+type PublishStreamResponse struct {
+	Status               PublishStreamResponse_Status `protobuf:"varint,1,opt,name=status,proto3,enum=pluginextensionv2.PublishStreamResponse_Status" json:"status,omitempty"`
+	Data                 []byte                       `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}                     `json:"-"`
+	XXX_unrecognized     []byte                       `json:"-"`
+	XXX_sizecache        int32                        `json:"-"`
+}
+
+func (m *PublishStreamResponse) Reset()         { *m = PublishStreamResponse{} }
+func (m *PublishStreamResponse) String() string { return proto.CompactTextString(m) }
+func (*PublishStreamResponse) ProtoMessage()    {}
+func (*PublishStreamResponse) Descriptor() ([]byte, []int) {
+	return fileDescriptor_d678f1d7ff20dddb, []int{3}
+}
+
+func (m *PublishStreamResponse) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PublishStreamResponse.Unmarshal(m, b)
+}
+func (m *PublishStreamResponse) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PublishStreamResponse.Marshal(b, m, deterministic)
+}
+func (m *PublishStreamResponse) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PublishStreamResponse.Merge(m, src)
+}
+func (m *PublishStreamResponse) XXX_Size() int {
+	return xxx_messageInfo_PublishStreamResponse.Size(m)
+}
+func (m *PublishStreamResponse) XXX_DiscardUnknown() {
+	xxx_messageInfo_PublishStreamResponse.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PublishStreamResponse proto.InternalMessageInfo
+
+func (m *PublishStreamResponse) GetStatus() PublishStreamResponse_Status {
+	if m != nil {
+		return m.Status
+	}
+	return PublishStreamResponse_OK
+}
+
+func (m *PublishStreamResponse) GetData() []byte {
+	if m != nil {
+		return m.Data
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("pluginextensionv2.PublishStreamResponse_Status", PublishStreamResponse_Status_name, PublishStreamResponse_Status_value)
+	proto.RegisterType((*RunStreamRequest)(nil), "pluginextensionv2.RunStreamRequest")
+	proto.RegisterType((*StreamPacket)(nil), "pluginextensionv2.StreamPacket")
+	proto.RegisterType((*PublishStreamRequest)(nil), "pluginextensionv2.PublishStreamRequest")
+	proto.RegisterType((*PublishStreamResponse)(nil), "pluginextensionv2.PublishStreamResponse")
+}
+
+func init() {
+	proto.RegisterFile("streamv2.proto", fileDescriptor_d678f1d7ff20dddb)
+}
+
+var fileDescriptor_d678f1d7ff20dddb = []byte{
+	// 328 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x92, 0xcd, 0x4e, 0xc2, 0x40,
+	0x14, 0x85, 0x1d, 0x34, 0x4d, 0xb8, 0xfc, 0x04, 0xae, 0x12, 0x09, 0x1b, 0x09, 0x2e, 0x60, 0x35,
+	0x9a, 0xba, 0x34, 0x6e, 0x14, 0x62, 0x88, 0x11, 0x9a, 0xa9, 0x2b, 0x37, 0xa6, 0x85, 0x89, 0x10,
+	0x71, 0x5a, 0x99, 0x5b, 0x82, 0x0b, 0x1f, 0xc5, 0x87, 0xf1, 0xcd, 0x0c, 0x33, 0x86, 0x14, 0x68,
+	0xa2, 0x1b, 0x77, 0x93, 0xdb, 0x73, 0xce, 0x77, 0x7f, 0x0a, 0x65, 0x4d, 0x73, 0x19, 0xbc, 0x2e,
+	0x5c, 0x1e, 0xcf, 0x23, 0x8a, 0xb0, 0x1a, 0xcf, 0x92, 0xe7, 0xa9, 0x92, 0x4b, 0x92, 0x4a, 0x4f,
+	0x23, 0xb5, 0x70, 0x1b, 0xa5, 0x30, 0x18, 0xbd, 0x48, 0x35, 0xb6, 0x8a, 0x96, 0x84, 0x8a, 0x48,
+	0x94, 0x6f, 0x6c, 0x42, 0xbe, 0x25, 0x52, 0x13, 0x5e, 0x41, 0xc9, 0xfa, 0x6e, 0x22, 0x45, 0x72,
+	0x49, 0x75, 0xd6, 0x64, 0x9d, 0x82, 0x7b, 0xcc, 0x6d, 0x75, 0xe1, 0x72, 0x2f, 0xfd, 0x59, 0x6c,
+	0xaa, 0x11, 0xe1, 0x20, 0x0e, 0x68, 0x52, 0xcf, 0x35, 0x59, 0x27, 0x2f, 0xcc, 0xbb, 0xd5, 0x85,
+	0xa2, 0x65, 0x78, 0x2b, 0x3a, 0x61, 0x13, 0x0a, 0xa3, 0x95, 0x5c, 0xd1, 0xc3, 0x7b, 0x2c, 0x0d,
+	0x20, 0x2f, 0xd2, 0xa5, 0x55, 0xca, 0x38, 0xa0, 0xc0, 0xa4, 0x14, 0x85, 0x79, 0xb7, 0x3e, 0xe0,
+	0xc8, 0x4b, 0xc2, 0xd9, 0x54, 0x4f, 0xfe, 0xbb, 0xe1, 0x35, 0x7e, 0x3f, 0x85, 0xff, 0x64, 0x50,
+	0xdb, 0xe2, 0xeb, 0x38, 0x52, 0x5a, 0xe2, 0x2d, 0x38, 0x9a, 0x02, 0x4a, 0xb4, 0x21, 0x97, 0xdd,
+	0x33, 0xbe, 0xb3, 0x78, 0x9e, 0xe9, 0xe4, 0xbe, 0xb1, 0x89, 0x1f, 0x7b, 0xe6, 0xd4, 0x6d, 0x70,
+	0xac, 0x0a, 0x1d, 0xc8, 0x0d, 0xef, 0x2a, 0x7b, 0x58, 0x83, 0xaa, 0xd7, 0x13, 0xf7, 0x7d, 0xdf,
+	0xef, 0x0f, 0x07, 0x4f, 0xdd, 0xde, 0xa0, 0xdf, 0xeb, 0x56, 0x98, 0xfb, 0xc5, 0xc0, 0xb1, 0xf1,
+	0xe8, 0x43, 0x7e, 0x7d, 0x56, 0x3c, 0xcd, 0xe8, 0x66, 0xfb, 0xe8, 0x8d, 0x93, 0x0c, 0x51, 0xfa,
+	0x64, 0xe7, 0x0c, 0x43, 0x28, 0x6d, 0x0c, 0x81, 0xed, 0xdf, 0xc7, 0xb4, 0xe1, 0x9d, 0xbf, 0xee,
+	0xe3, 0xba, 0xf6, 0x78, 0xc8, 0x2f, 0x77, 0xc4, 0xa1, 0x63, 0xfe, 0xd6, 0x8b, 0xef, 0x01, 0x00,
+	0x67, 0x2f, 0x04, 0xf5, 0xe1, 0x02, 0x00, 0x00,
+}
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ context.Context
+var _ grpc.ClientConnInterface
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+const _ = grpc.SupportPackageIsVersion6
+
+// StreamClient is the client API for Stream service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://godoc.org/google.golang.org/grpc#ClientConn.NewStream.
+type StreamClient interface {
+	RunStream(ctx context.Context, in *RunStreamRequest, opts ...grpc.CallOption) (Stream_RunStreamClient, error)
+	PublishStream(ctx context.Context, in *PublishStreamRequest, opts ...grpc.CallOption) (*PublishStreamResponse, error)
+}
+
+type streamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewStreamClient(cc grpc.ClientConnInterface) StreamClient {
+	return &streamClient{cc}
+}
+
+func (c *streamClient) RunStream(ctx context.Context, in *RunStreamRequest, opts ...grpc.CallOption) (Stream_RunStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Stream_serviceDesc.Streams[0], "/pluginextensionv2.Stream/RunStream", opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &streamRunStreamClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type Stream_RunStreamClient interface {
+	Recv() (*StreamPacket, error)
+	grpc.ClientStream
+}
+
+type streamRunStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *streamRunStreamClient) Recv() (*StreamPacket, error) {
+	m := new(StreamPacket)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *streamClient) PublishStream(ctx context.Context, in *PublishStreamRequest, opts ...grpc.CallOption) (*PublishStreamResponse, error) {
+	out := new(PublishStreamResponse)
+	err := c.cc.Invoke(ctx, "/pluginextensionv2.Stream/PublishStream", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// StreamServer is the server API for Stream service.
+type StreamServer interface {
+	RunStream(*RunStreamRequest, Stream_RunStreamServer) error
+	PublishStream(context.Context, *PublishStreamRequest) (*PublishStreamResponse, error)
+}
+
+// UnimplementedStreamServer can be embedded to have forward compatible implementations.
+type UnimplementedStreamServer struct {
+}
+
+func (*UnimplementedStreamServer) RunStream(req *RunStreamRequest, srv Stream_RunStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method RunStream not implemented")
+}
+func (*UnimplementedStreamServer) PublishStream(ctx context.Context, req *PublishStreamRequest) (*PublishStreamResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PublishStream not implemented")
+}
+
+func RegisterStreamServer(s *grpc.Server, srv StreamServer) {
+	s.RegisterService(&_Stream_serviceDesc, srv)
+}
+
+func _Stream_RunStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(RunStreamRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(StreamServer).RunStream(m, &streamRunStreamServer{stream})
+}
+
+type Stream_RunStreamServer interface {
+	Send(*StreamPacket) error
+	grpc.ServerStream
+}
+
+type streamRunStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *streamRunStreamServer) Send(m *StreamPacket) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Stream_PublishStream_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PublishStreamRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(StreamServer).PublishStream(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/pluginextensionv2.Stream/PublishStream",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(StreamServer).PublishStream(ctx, req.(*PublishStreamRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Stream_serviceDesc = grpc.ServiceDesc{
+	ServiceName: "pluginextensionv2.Stream",
+	HandlerType: (*StreamServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "PublishStream",
+			Handler:    _Stream_PublishStream_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "RunStream",
+			Handler:       _Stream_RunStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "streamv2.proto",
+}
@@ -0,0 +1,110 @@
+// +build linux
+
+package backendplugin
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+// userHZ is the number of clock ticks per second the kernel reports CPU time in.
+const userHZ = 100
+
+// cpuPeriod is the period of the CPU quota of plugin cgroups, in microseconds.
+const cpuPeriod = 100000
+
+// processStats is the resource usage of a plugin process.
+type processStats struct {
+	residentMemoryBytes uint64
+	cpuSeconds          float64
+}
+
+// readProcessStats reads the resource usage of a process from /proc.
+func readProcessStats(pid int) (processStats, error) {
+	data, err := ioutil.ReadFile(filepath.Join("/proc", strconv.Itoa(pid), "stat"))
+	if err != nil {
+		return processStats{}, err
+	}
+	return parseProcessStat(string(data))
+}
+
+// parseProcessStat parses the contents of /proc/<pid>/stat. The fields after the command name,
+// which may contain spaces, start with the third field.
+func parseProcessStat(stat string) (processStats, error) {
+	end := strings.LastIndex(stat, ")")
+	if end < 0 {
+		return processStats{}, xerrors.New("invalid process stat")
+	}
+	fields := strings.Fields(stat[end+1:])
+	if len(fields) < 22 {
+		return processStats{}, xerrors.New("invalid process stat")
+	}
+
+	utime, err := strconv.ParseUint(fields[11], 10, 64)
+	if err != nil {
+		return processStats{}, err
+	}
+	stime, err := strconv.ParseUint(fields[12], 10, 64)
+	if err != nil {
+		return processStats{}, err
+	}
+	rss, err := strconv.ParseInt(fields[21], 10, 64)
+	if err != nil {
+		return processStats{}, err
+	}
+	if rss < 0 {
+		rss = 0
+	}
+
+	return processStats{
+		residentMemoryBytes: uint64(rss) * uint64(os.Getpagesize()),
+		cpuSeconds:          float64(utime+stime) / userHZ,
+	}, nil
+}
+
+// setupCgroup creates the cgroup v2 of a plugin in the parent cgroup and sets its limits. The parent
+// must be delegated to the user Grafana runs as.
+func setupCgroup(parent string, pluginID string, limits processLimits) (string, error) {
+	if err := ioutil.WriteFile(filepath.Join(parent, "cgroup.subtree_control"), []byte("+memory +cpu"), 0644); err != nil {
+		return "", xerrors.Errorf("failed to enable the memory and cpu controllers of %s: %w", parent, err)
+	}
+
+	dir := filepath.Join(parent, "plugin-"+pluginID)
+	if err := os.Mkdir(dir, 0755); err != nil && !os.IsExist(err) {
+		return "", err
+	}
+
+	memoryMax := "max"
+	if limits.memoryBytes > 0 {
+		memoryMax = strconv.FormatInt(limits.memoryBytes, 10)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "memory.max"), []byte(memoryMax), 0644); err != nil {
+		return "", err
+	}
+
+	cpuMax := fmt.Sprintf("max %d", cpuPeriod)
+	if limits.cpu > 0 {
+		cpuMax = fmt.Sprintf("%d %d", int64(limits.cpu*cpuPeriod), cpuPeriod)
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, "cpu.max"), []byte(cpuMax), 0644); err != nil {
+		return "", err
+	}
+
+	return dir, nil
+}
+
+// addToCgroup moves a process to the cgroup.
+func addToCgroup(dir string, pid int) error {
+	return ioutil.WriteFile(filepath.Join(dir, "cgroup.procs"), []byte(strconv.Itoa(pid)), 0644)
+}
+
+// removeCgroup removes the cgroup of a plugin once its process exited.
+func removeCgroup(dir string) error {
+	return os.Remove(dir)
+}
@@ -0,0 +1,29 @@
+package backendplugin
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessStats(t *testing.T) {
+	t.Run("Should parse process stat", func(t *testing.T) {
+		stat := "4312 (my plugin) S 1 4312 4312 0 -1 4194560 2455 0 0 0 150 50 0 0 20 0 12 0 6000 1000000 300 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 3 0 0 0 0 0"
+		stats, err := parseProcessStat(stat)
+		require.NoError(t, err)
+		require.Equal(t, 2.0, stats.cpuSeconds)
+		require.Equal(t, uint64(300*os.Getpagesize()), stats.residentMemoryBytes)
+	})
+
+	t.Run("Should fail to parse invalid process stat", func(t *testing.T) {
+		_, err := parseProcessStat("4312 (my plugin) S 1")
+		require.Error(t, err)
+	})
+
+	t.Run("Should read stats of running process", func(t *testing.T) {
+		stats, err := readProcessStats(os.Getpid())
+		require.NoError(t, err)
+		require.NotZero(t, stats.residentMemoryBytes)
+	})
+}
@@ -0,0 +1,33 @@
+// +build !linux
+
+package backendplugin
+
+import (
+	"golang.org/x/xerrors"
+)
+
+// processStats is the resource usage of a plugin process.
+type processStats struct {
+	residentMemoryBytes uint64
+	cpuSeconds          float64
+}
+
+var errProcessStatsNotSupported = xerrors.New("process stats are only supported on Linux")
+
+// readProcessStats is only supported on Linux.
+func readProcessStats(pid int) (processStats, error) {
+	return processStats{}, errProcessStatsNotSupported
+}
+
+// setupCgroup is only supported on Linux.
+func setupCgroup(parent string, pluginID string, limits processLimits) (string, error) {
+	return "", xerrors.New("cgroups are only supported on Linux")
+}
+
+func addToCgroup(dir string, pid int) error {
+	return nil
+}
+
+func removeCgroup(dir string) error {
+	return nil
+}
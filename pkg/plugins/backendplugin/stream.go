@@ -0,0 +1,118 @@
+package backendplugin
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/pluginextensionv2"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrStreamPublishDenied is returned when a plugin rejects a message published to a stream.
+var ErrStreamPublishDenied = errors.New("Plugin denied publishing to the stream")
+
+// ErrStreamNotImplemented is returned when a plugin doesn't implement the stream service.
+var ErrStreamNotImplemented = errors.New("Plugin does not implement streams")
+
+// StreamMessage is a message of a plugin stream.
+type StreamMessage struct {
+	// ContentType is the content type of the stream, e.g. application/json.
+	ContentType string
+	Data        []byte
+}
+
+// RunStream runs a stream of a plugin and sends its messages to send, until the context
+// is done or the plugin ends the stream. Plugins implement streams with the stream
+// service of the plugin protocol, which is separate from the resources of the plugin.
+func (m *manager) RunStream(ctx context.Context, pCtx backend.PluginContext, path string, send func(*StreamMessage) error) error {
+	p, err := m.streamPlugin(pCtx.PluginID)
+	if err != nil {
+		return err
+	}
+
+	stream, err := p.stream.RunStream(ctx, &pluginextensionv2.RunStreamRequest{
+		PluginContext: toProto.PluginContext(pCtx),
+		Path:          path,
+	})
+	if err != nil {
+		return streamError("Failed to run stream", err)
+	}
+
+	for {
+		packet, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return streamError("Failed to receive stream message", err)
+		}
+
+		if len(packet.Data) == 0 {
+			continue
+		}
+
+		if err := send(&StreamMessage{ContentType: packet.ContentType, Data: packet.Data}); err != nil {
+			return err
+		}
+	}
+}
+
+// PublishStream sends a message published by a client to a stream of a plugin, and returns
+// the message the plugin sends to the subscribers of the stream.
+func (m *manager) PublishStream(ctx context.Context, pCtx backend.PluginContext, path string, data []byte) ([]byte, error) {
+	p, err := m.streamPlugin(pCtx.PluginID)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []byte
+	err = InstrumentPluginRequest(p.id, "stream", func() error {
+		resp, err := p.stream.PublishStream(ctx, &pluginextensionv2.PublishStreamRequest{
+			PluginContext: toProto.PluginContext(pCtx),
+			Path:          path,
+			Data:          data,
+		})
+		if err != nil {
+			return streamError("Failed to publish to stream", err)
+		}
+
+		if resp.Status == pluginextensionv2.PublishStreamResponse_PERMISSION_DENIED {
+			return ErrStreamPublishDenied
+		}
+
+		result = resp.Data
+		return nil
+	})
+
+	return result, err
+}
+
+func (m *manager) streamPlugin(pluginID string) (*BackendPlugin, error) {
+	m.pluginsMu.RLock()
+	p, registered := m.plugins[pluginID]
+	m.pluginsMu.RUnlock()
+
+	if !registered {
+		return nil, ErrPluginNotRegistered
+	}
+
+	if p.stream == nil || p.client == nil || p.client.Exited() {
+		return nil, errors.New("plugin not running, cannot run stream")
+	}
+
+	return p, nil
+}
+
+func streamError(msg string, err error) error {
+	if st, ok := status.FromError(err); ok && st.Code() == codes.Unimplemented {
+		return ErrStreamNotImplemented
+	}
+	return errutil.Wrap(msg, err)
+}
@@ -0,0 +1,112 @@
+package backendplugin
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin/pluginextensionv2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestStreams(t *testing.T) {
+	stream := &fakeStreamPlugin{}
+	m := &manager{plugins: map[string]*BackendPlugin{
+		"test": {id: "test", client: &fakePluginClient{}, stream: stream, logger: log.New("test")},
+	}}
+	pCtx := backend.PluginContext{OrgID: 2, PluginID: "test"}
+
+	t.Run("should send the messages of a stream", func(t *testing.T) {
+		stream.packets = []*pluginextensionv2.StreamPacket{
+			{ContentType: "application/json", Data: []byte(`{"value": 1}`)},
+			{ContentType: "application/json"},
+			{ContentType: "application/json", Data: []byte(`{"value": 2}`)},
+		}
+
+		var messages []*StreamMessage
+		err := m.RunStream(context.Background(), pCtx, "random", func(msg *StreamMessage) error {
+			messages = append(messages, msg)
+			return nil
+		})
+		require.NoError(t, err)
+
+		assert.Equal(t, "random", stream.runRequest.Path)
+		assert.Equal(t, int64(2), stream.runRequest.PluginContext.OrgId)
+		require.Len(t, messages, 2)
+		assert.Equal(t, `{"value": 1}`, string(messages[0].Data))
+		assert.Equal(t, `{"value": 2}`, string(messages[1].Data))
+	})
+
+	t.Run("should return an error if the plugin doesn't implement streams", func(t *testing.T) {
+		stream.err = status.Error(codes.Unimplemented, "unknown service pluginextensionv2.Stream")
+		defer func() { stream.err = nil }()
+
+		err := m.RunStream(context.Background(), pCtx, "random", func(msg *StreamMessage) error { return nil })
+		assert.Equal(t, ErrStreamNotImplemented, err)
+
+		_, err = m.PublishStream(context.Background(), pCtx, "random", []byte(`{}`))
+		assert.Equal(t, ErrStreamNotImplemented, err)
+	})
+
+	t.Run("should send published messages to the plugin", func(t *testing.T) {
+		stream.publishResponse = &pluginextensionv2.PublishStreamResponse{Data: []byte(`{"value": 2}`)}
+		data, err := m.PublishStream(context.Background(), pCtx, "random", []byte(`{"value": 1}`))
+		require.NoError(t, err)
+		assert.Equal(t, `{"value": 2}`, string(data))
+		assert.Equal(t, `{"value": 1}`, string(stream.publishRequest.Data))
+
+		stream.publishResponse = &pluginextensionv2.PublishStreamResponse{Status: pluginextensionv2.PublishStreamResponse_PERMISSION_DENIED}
+		_, err = m.PublishStream(context.Background(), pCtx, "random", []byte(`{"value": 1}`))
+		assert.Equal(t, ErrStreamPublishDenied, err)
+	})
+
+	t.Run("should return an error for unregistered plugins", func(t *testing.T) {
+		_, err := m.PublishStream(context.Background(), backend.PluginContext{PluginID: "unknown"}, "random", nil)
+		assert.Equal(t, ErrPluginNotRegistered, err)
+	})
+}
+
+// fakeStreamPlugin is a plugin implementing the stream service.
+type fakeStreamPlugin struct {
+	err             error
+	packets         []*pluginextensionv2.StreamPacket
+	runRequest      *pluginextensionv2.RunStreamRequest
+	publishRequest  *pluginextensionv2.PublishStreamRequest
+	publishResponse *pluginextensionv2.PublishStreamResponse
+}
+
+func (p *fakeStreamPlugin) RunStream(ctx context.Context, req *pluginextensionv2.RunStreamRequest, opts ...grpc.CallOption) (pluginextensionv2.Stream_RunStreamClient, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.runRequest = req
+	return &fakeRunStreamClient{packets: p.packets}, nil
+}
+
+func (p *fakeStreamPlugin) PublishStream(ctx context.Context, req *pluginextensionv2.PublishStreamRequest, opts ...grpc.CallOption) (*pluginextensionv2.PublishStreamResponse, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.publishRequest = req
+	return p.publishResponse, nil
+}
+
+type fakeRunStreamClient struct {
+	grpc.ClientStream
+	packets []*pluginextensionv2.StreamPacket
+}
+
+func (c *fakeRunStreamClient) Recv() (*pluginextensionv2.StreamPacket, error) {
+	if len(c.packets) == 0 {
+		return nil, io.EOF
+	}
+	packet := c.packets[0]
+	c.packets = c.packets[1:]
+	return packet, nil
+}
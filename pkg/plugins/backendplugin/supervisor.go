@@ -0,0 +1,435 @@
+package backendplugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana/pkg/setting"
+	"golang.org/x/xerrors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// PluginState is the state of the process of a backend plugin.
+type PluginState string
+
+const (
+	// PluginStateNotStarted is the state of plugins that have not been started yet.
+	PluginStateNotStarted PluginState = "not_started"
+	// PluginStateRunning is the state of plugins with a running process.
+	PluginStateRunning PluginState = "running"
+	// PluginStateRestarting is the state of plugins waiting to be restarted after their process exited.
+	PluginStateRestarting PluginState = "restarting"
+	// PluginStateCrashLoop is the state of plugins that exited too many times in a row.
+	PluginStateCrashLoop PluginState = "crash_loop"
+	// PluginStateStopped is the state of plugins that are no longer supervised.
+	PluginStateStopped PluginState = "stopped"
+)
+
+// ErrPluginNotRunning error returned when a plugin is not supervised.
+var ErrPluginNotRunning = errors.New("Plugin not running")
+
+// supervisionInterval is how often the process of a plugin is checked.
+var supervisionInterval = time.Second
+
+const (
+	// stableRunPeriod is how long the process of a plugin must run before its previous crashes are forgotten.
+	stableRunPeriod = 10 * time.Minute
+	// maxHealthCheckTimeout is the longest a health check of the supervisor may take.
+	maxHealthCheckTimeout = 10 * time.Second
+)
+
+// supervisorSettings configures how the processes of backend plugins are supervised.
+type supervisorSettings struct {
+	minBackoff          time.Duration
+	maxBackoff          time.Duration
+	crashLoopThreshold  int
+	healthCheckInterval time.Duration
+	healthCheckFailures int
+	cgroupParent        string
+}
+
+func newSupervisorSettings(cfg *setting.Cfg) supervisorSettings {
+	s := supervisorSettings{
+		minBackoff:          cfg.PluginRestartMinBackoff,
+		maxBackoff:          cfg.PluginRestartMaxBackoff,
+		crashLoopThreshold:  cfg.PluginCrashLoopThreshold,
+		healthCheckInterval: cfg.PluginHealthCheckInterval,
+		healthCheckFailures: cfg.PluginHealthCheckFailures,
+		cgroupParent:        cfg.PluginCgroupParent,
+	}
+	if s.minBackoff <= 0 {
+		s.minBackoff = time.Second
+	}
+	if s.maxBackoff < s.minBackoff {
+		s.maxBackoff = s.minBackoff
+	}
+	if s.crashLoopThreshold <= 0 {
+		s.crashLoopThreshold = 5
+	}
+	if s.healthCheckFailures <= 0 {
+		s.healthCheckFailures = 3
+	}
+	return s
+}
+
+// restartBackoff returns how long to wait before restarting a plugin after its nth consecutive crash.
+// The backoff doubles on every crash, from the minimum to the maximum backoff.
+func (s supervisorSettings) restartBackoff(crashes int) time.Duration {
+	backoff := s.minBackoff
+	for i := 1; i < crashes && backoff < s.maxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > s.maxBackoff {
+		backoff = s.maxBackoff
+	}
+	return backoff
+}
+
+// PluginStatus is the status of the process of a backend plugin.
+type PluginStatus struct {
+	PluginID            string      `json:"pluginId"`
+	Managed             bool        `json:"managed"`
+	State               PluginState `json:"state"`
+	Pid                 int         `json:"pid,omitempty"`
+	StartedAt           *time.Time  `json:"startedAt,omitempty"`
+	Restarts            int         `json:"restarts"`
+	ConsecutiveCrashes  int         `json:"consecutiveCrashes"`
+	NextRestart         *time.Time  `json:"nextRestart,omitempty"`
+	HealthCheckFailures int         `json:"healthCheckFailures"`
+	LastError           string      `json:"lastError,omitempty"`
+	MemoryBytes         uint64      `json:"memoryBytes,omitempty"`
+	CPUSeconds          float64     `json:"cpuSeconds,omitempty"`
+	MemoryLimitBytes    int64       `json:"memoryLimitBytes,omitempty"`
+	CPULimit            float64     `json:"cpuLimit,omitempty"`
+	Cgroup              string      `json:"cgroup,omitempty"`
+}
+
+// processStatus tracks the process of a backend plugin between restarts.
+type processStatus struct {
+	mu                  sync.Mutex
+	state               PluginState
+	pid                 int
+	startedAt           time.Time
+	restarts            int
+	crashes             int
+	nextRestart         time.Time
+	healthCheckFailures int
+	lastError           string
+	// killReason is the reason the supervisor killed the process, reported once it exited.
+	killReason string
+	// restartRequested is set when the process is restarted on request, which isn't a crash.
+	restartRequested bool
+}
+
+// running records that the process was (re)started.
+func (s *processStatus) running(now time.Time, pid int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.state != PluginStateNotStarted && s.state != "" {
+		s.restarts++
+	}
+	s.state = PluginStateRunning
+	s.pid = pid
+	s.startedAt = now
+	s.nextRestart = time.Time{}
+	s.healthCheckFailures = 0
+}
+
+// exited records that the process exited and returns when to restart it.
+func (s *processStatus) exited(now time.Time, settings supervisorSettings) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.restartRequested {
+		s.restartRequested = false
+		s.crashes = 0
+		s.state = PluginStateRestarting
+		s.pid = 0
+		s.nextRestart = now
+		return now
+	}
+
+	if s.state != PluginStateRunning {
+		return s.nextRestart
+	}
+
+	reason := "Plugin process exited"
+	if s.killReason != "" {
+		reason = s.killReason
+		s.killReason = ""
+	}
+	if now.Sub(s.startedAt) >= stableRunPeriod {
+		s.crashes = 0
+	}
+	s.crash(now, reason, settings)
+	return s.nextRestart
+}
+
+// startFailed records that the process could not be restarted and returns when to try again.
+func (s *processStatus) startFailed(now time.Time, err error, settings supervisorSettings) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.crash(now, err.Error(), settings)
+	return s.nextRestart
+}
+
+func (s *processStatus) crash(now time.Time, reason string, settings supervisorSettings) {
+	s.crashes++
+	s.pid = 0
+	s.lastError = reason
+	s.nextRestart = now.Add(settings.restartBackoff(s.crashes))
+	s.state = PluginStateRestarting
+	if s.crashes >= settings.crashLoopThreshold {
+		s.state = PluginStateCrashLoop
+	}
+}
+
+// healthCheckFailed records a failed health check and returns true if the process should be restarted.
+func (s *processStatus) healthCheckFailed(err error, settings supervisorSettings) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.healthCheckFailures++
+	s.lastError = err.Error()
+	return s.healthCheckFailures >= settings.healthCheckFailures
+}
+
+func (s *processStatus) healthCheckSucceeded() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.healthCheckFailures = 0
+}
+
+// kill records why the supervisor kills the process.
+func (s *processStatus) kill(reason string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.killReason = reason
+}
+
+// requestRestart makes the supervisor restart the process right away, without backoff.
+func (s *processStatus) requestRestart() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.crashes = 0
+	if s.state == PluginStateRunning {
+		s.restartRequested = true
+		return
+	}
+	s.nextRestart = time.Time{}
+}
+
+func (s *processStatus) stopped(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = PluginStateStopped
+	s.pid = 0
+	s.nextRestart = time.Time{}
+	if err != nil {
+		s.lastError = err.Error()
+	}
+}
+
+func (s *processStatus) currentPid() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.pid
+}
+
+func (s *processStatus) restartDue(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return !now.Before(s.nextRestart)
+}
+
+func (s *processStatus) status() PluginStatus {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := PluginStatus{
+		State:               s.state,
+		Pid:                 s.pid,
+		Restarts:            s.restarts,
+		ConsecutiveCrashes:  s.crashes,
+		HealthCheckFailures: s.healthCheckFailures,
+		LastError:           s.lastError,
+	}
+	if status.State == "" {
+		status.State = PluginStateNotStarted
+	}
+	if !s.startedAt.IsZero() && s.state == PluginStateRunning {
+		startedAt := s.startedAt
+		status.StartedAt = &startedAt
+	}
+	if !s.nextRestart.IsZero() {
+		nextRestart := s.nextRestart
+		status.NextRestart = &nextRestart
+	}
+	return status
+}
+
+// status returns the status of the plugin process, with its resource usage if it is running.
+func (p *BackendPlugin) status() *PluginStatus {
+	status := p.process.status()
+	status.PluginID = p.id
+	status.Managed = p.managed
+	status.MemoryLimitBytes = p.limits.memoryBytes
+	status.CPULimit = p.limits.cpu
+	status.Cgroup = p.cgroup
+
+	if status.Pid != 0 {
+		if stats, err := readProcessStats(status.Pid); err == nil {
+			status.MemoryBytes = stats.residentMemoryBytes
+			status.CPUSeconds = stats.cpuSeconds
+		}
+	}
+	return &status
+}
+
+// started records that the plugin process was started, and moves it to the cgroup of the plugin.
+func (p *BackendPlugin) started() {
+	pid := 0
+	if rc := p.client.ReattachConfig(); rc != nil {
+		pid = rc.Pid
+	}
+	p.process.running(time.Now(), pid)
+
+	if p.cgroup != "" && pid != 0 {
+		if err := addToCgroup(p.cgroup, pid); err != nil {
+			p.logger.Warn("Failed to move plugin process to its cgroup", "cgroup", p.cgroup, "error", err)
+		}
+	}
+}
+
+// supervise restarts the plugin process with backoff when it exits, and kills it when it fails
+// its health checks or uses more memory than allowed, until the context is done. Restart requests
+// are handled here too, as the process and its client are only replaced by this goroutine.
+func supervise(ctx context.Context, p *BackendPlugin, settings supervisorSettings) error {
+	ticker := time.NewTicker(supervisionInterval)
+	defer ticker.Stop()
+	lastHealthCheck := time.Now()
+
+	for {
+		select {
+		case <-ctx.Done():
+			p.process.stopped(nil)
+			if err := ctx.Err(); err != nil && !xerrors.Is(err, context.Canceled) {
+				return err
+			}
+			return nil
+		case <-p.restart:
+			p.process.requestRestart()
+			if !p.client.Exited() {
+				if err := p.stop(); err != nil {
+					p.logger.Error("Failed to stop plugin", "error", err)
+				}
+			}
+		case now := <-ticker.C:
+			// the plugin might have been unregistered while the ticker fired
+			if ctx.Err() != nil {
+				continue
+			}
+
+			if !p.client.Exited() {
+				if settings.healthCheckInterval > 0 && now.Sub(lastHealthCheck) >= settings.healthCheckInterval {
+					lastHealthCheck = now
+					checkProcessHealth(ctx, p, settings)
+				}
+				checkProcessMemory(p)
+				continue
+			}
+
+			p.process.exited(now, settings)
+			if !p.process.restartDue(now) {
+				continue
+			}
+
+			p.logger.Debug("Restarting plugin")
+			if err := p.start(ctx); err != nil {
+				restartAt := p.process.startFailed(now, err, settings)
+				p.logger.Error("Failed to restart plugin", "error", err, "nextRestart", restartAt)
+				if err := p.stop(); err != nil {
+					p.logger.Debug("Failed to stop plugin", "error", err)
+				}
+				continue
+			}
+			p.started()
+			lastHealthCheck = now
+			p.logger.Debug("Plugin restarted")
+		}
+	}
+}
+
+// checkProcessHealth checks that plugins supporting diagnostics answer health checks, and kills their
+// process if it couldn't be reached too many times in a row. The health check has no instance settings,
+// so only transport errors count: a plugin answering with an error, or reporting that it is unhealthy,
+// is alive and is not restarted.
+func checkProcessHealth(ctx context.Context, p *BackendPlugin, settings supervisorSettings) {
+	if !p.supportsDiagnostics() {
+		return
+	}
+
+	timeout := settings.healthCheckInterval
+	if timeout > maxHealthCheckTimeout {
+		timeout = maxHealthCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if _, err := p.checkHealth(ctx, backend.PluginContext{PluginID: p.id}); err != nil {
+		if ctx.Err() != nil && xerrors.Is(ctx.Err(), context.Canceled) {
+			return
+		}
+		if !isTransportError(ctx, err) {
+			p.logger.Debug("Plugin answered health check with an error", "error", err)
+			p.process.healthCheckSucceeded()
+			return
+		}
+		p.logger.Warn("Plugin failed health check", "error", err)
+		if p.process.healthCheckFailed(err, settings) {
+			p.logger.Error("Killing plugin after failed health checks", "failures", settings.healthCheckFailures)
+			p.process.kill(fmt.Sprintf("Plugin failed %d health checks in a row: %v", settings.healthCheckFailures, err))
+			p.client.Kill()
+		}
+		return
+	}
+	p.process.healthCheckSucceeded()
+}
+
+// isTransportError returns true if the plugin process could not be reached or did not answer in time.
+func isTransportError(ctx context.Context, err error) bool {
+	if xerrors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	}
+	return false
+}
+
+// checkProcessMemory kills the plugin process when it uses more memory than its limit. Processes in
+// a cgroup are limited by the kernel instead.
+func checkProcessMemory(p *BackendPlugin) {
+	if p.limits.memoryBytes <= 0 || p.cgroup != "" {
+		return
+	}
+	pid := p.process.currentPid()
+	if pid == 0 {
+		return
+	}
+
+	stats, err := readProcessStats(pid)
+	if err != nil {
+		return
+	}
+	if stats.residentMemoryBytes > uint64(p.limits.memoryBytes) {
+		p.logger.Error("Killing plugin that exceeded its memory limit", "memoryBytes", stats.residentMemoryBytes, "limitBytes", p.limits.memoryBytes)
+		p.process.kill("Plugin process exceeded its memory limit")
+		p.client.Kill()
+	}
+}
@@ -0,0 +1,300 @@
+package backendplugin
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/genproto/pluginv2"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	plugin "github.com/hashicorp/go-plugin"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestRestartBackoff(t *testing.T) {
+	settings := newSupervisorSettings(&setting.Cfg{
+		PluginRestartMinBackoff: time.Second,
+		PluginRestartMaxBackoff: 10 * time.Second,
+	})
+
+	require.Equal(t, time.Second, settings.restartBackoff(1))
+	require.Equal(t, 2*time.Second, settings.restartBackoff(2))
+	require.Equal(t, 8*time.Second, settings.restartBackoff(4))
+	require.Equal(t, 10*time.Second, settings.restartBackoff(5))
+	require.Equal(t, 10*time.Second, settings.restartBackoff(100))
+}
+
+func TestProcessStatus(t *testing.T) {
+	settings := newSupervisorSettings(&setting.Cfg{
+		PluginRestartMinBackoff:  time.Second,
+		PluginRestartMaxBackoff:  time.Minute,
+		PluginCrashLoopThreshold: 3,
+	})
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	t.Run("Should restart with backoff and report a crash loop", func(t *testing.T) {
+		s := &processStatus{}
+		s.running(now, 10)
+		require.Equal(t, PluginStateRunning, s.status().State)
+		require.Equal(t, 0, s.status().Restarts)
+
+		require.Equal(t, now.Add(time.Second), s.exited(now, settings))
+		require.Equal(t, PluginStateRestarting, s.status().State)
+		require.Equal(t, 0, s.status().Pid)
+		require.False(t, s.restartDue(now))
+		require.True(t, s.restartDue(now.Add(time.Second)))
+
+		s.running(now, 11)
+		require.Equal(t, now.Add(2*time.Second), s.exited(now, settings))
+		require.Equal(t, now.Add(4*time.Second), s.startFailed(now, errors.New("failed"), settings))
+
+		status := s.status()
+		require.Equal(t, PluginStateCrashLoop, status.State)
+		require.Equal(t, 3, status.ConsecutiveCrashes)
+		require.Equal(t, 1, status.Restarts)
+		require.Equal(t, "failed", status.LastError)
+
+		t.Run("Exits of exited processes should not count", func(t *testing.T) {
+			require.Equal(t, now.Add(4*time.Second), s.exited(now.Add(time.Second), settings))
+			require.Equal(t, 3, s.status().ConsecutiveCrashes)
+		})
+	})
+
+	t.Run("Should forget crashes of processes that ran long enough", func(t *testing.T) {
+		s := &processStatus{}
+		s.running(now, 10)
+		s.exited(now, settings)
+		s.running(now, 11)
+		s.exited(now.Add(stableRunPeriod), settings)
+		require.Equal(t, 1, s.status().ConsecutiveCrashes)
+	})
+
+	t.Run("Should report why the supervisor killed the process", func(t *testing.T) {
+		s := &processStatus{}
+		s.running(now, 10)
+		require.False(t, s.healthCheckFailed(errors.New("timeout"), settings))
+		require.False(t, s.healthCheckFailed(errors.New("timeout"), settings))
+		require.True(t, s.healthCheckFailed(errors.New("timeout"), settings))
+		require.Equal(t, 3, s.status().HealthCheckFailures)
+
+		s.kill("Plugin failed health checks")
+		s.exited(now, settings)
+		require.Equal(t, "Plugin failed health checks", s.status().LastError)
+
+		s.running(now, 11)
+		require.Equal(t, 0, s.status().HealthCheckFailures)
+	})
+
+	t.Run("Requested restarts should not count as crashes", func(t *testing.T) {
+		s := &processStatus{}
+		s.running(now, 10)
+		s.exited(now, settings)
+		s.running(now, 11)
+		s.requestRestart()
+		require.Equal(t, now, s.exited(now, settings))
+		require.Equal(t, 0, s.status().ConsecutiveCrashes)
+		require.Equal(t, PluginStateRestarting, s.status().State)
+	})
+
+	t.Run("Requested restarts should end the backoff of exited processes", func(t *testing.T) {
+		s := &processStatus{}
+		s.running(now, 10)
+		s.exited(now, settings)
+		require.False(t, s.restartDue(now))
+		s.requestRestart()
+		require.True(t, s.restartDue(now))
+	})
+}
+
+func TestSupervise(t *testing.T) {
+	origInterval := supervisionInterval
+	supervisionInterval = 5 * time.Millisecond
+	t.Cleanup(func() { supervisionInterval = origInterval })
+
+	settings := newSupervisorSettings(&setting.Cfg{
+		PluginRestartMinBackoff:   time.Millisecond,
+		PluginRestartMaxBackoff:   time.Millisecond,
+		PluginHealthCheckInterval: 5 * time.Millisecond,
+		PluginHealthCheckFailures: 2,
+	})
+
+	// run supervises a plugin whose health checks fail with healthErr, and returns the
+	// plugin and the clients of its processes.
+	run := func(t *testing.T, healthErr error) (*BackendPlugin, *fakeClients) {
+		clients := &fakeClients{healthErr: healthErr}
+		p := &BackendPlugin{
+			id:            "test",
+			clientFactory: clients.newClient,
+			logger:        log.New("test"),
+			restart:       make(chan struct{}, 1),
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		require.NoError(t, p.start(ctx))
+		p.started()
+
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			require.NoError(t, supervise(ctx, p, settings))
+		}()
+		t.Cleanup(func() {
+			cancel()
+			<-done
+		})
+		return p, clients
+	}
+
+	t.Run("Should restart exited processes", func(t *testing.T) {
+		p, clients := run(t, nil)
+		clients.current().exit()
+
+		waitFor(t, func() bool { return clients.started() == 2 })
+		waitFor(t, func() bool { return p.status().State == PluginStateRunning })
+		require.Equal(t, 1, p.status().Restarts)
+		require.Equal(t, 1, p.status().ConsecutiveCrashes)
+	})
+
+	t.Run("Should not kill processes that answer health checks with an error", func(t *testing.T) {
+		_, clients := run(t, errors.New("data source not configured"))
+
+		waitFor(t, func() bool { return clients.current().healthChecks() >= 5 })
+		require.Equal(t, 1, clients.started())
+		require.False(t, clients.current().Exited())
+	})
+
+	t.Run("Should kill processes that can't be reached", func(t *testing.T) {
+		p, clients := run(t, status.Error(codes.Unavailable, "connection refused"))
+
+		waitFor(t, func() bool { return clients.started() >= 2 })
+		require.Contains(t, p.status().LastError, "health checks")
+	})
+
+	t.Run("Should restart processes on request without counting a crash", func(t *testing.T) {
+		p, clients := run(t, nil)
+		p.restart <- struct{}{}
+
+		waitFor(t, func() bool { return clients.started() == 2 })
+		waitFor(t, func() bool { return p.status().State == PluginStateRunning })
+		require.Equal(t, 0, p.status().ConsecutiveCrashes)
+	})
+}
+
+// waitFor waits up to a second for the condition to become true.
+func waitFor(t *testing.T, condition func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !condition(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met within a second")
+		}
+	}
+}
+
+// fakeClients creates the clients of the processes of a plugin.
+type fakeClients struct {
+	mu        sync.Mutex
+	clients   []*fakePluginClient
+	healthErr error
+}
+
+func (c *fakeClients) newClient() pluginClient {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	client := &fakePluginClient{healthErr: c.healthErr}
+	c.clients = append(c.clients, client)
+	return client
+}
+
+func (c *fakeClients) started() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.clients)
+}
+
+func (c *fakeClients) current() *fakePluginClient {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.clients[len(c.clients)-1]
+}
+
+// fakePluginClient is the client of a plugin process that implements diagnostics.
+type fakePluginClient struct {
+	mu        sync.Mutex
+	exited    bool
+	checks    int
+	healthErr error
+}
+
+func (c *fakePluginClient) Client() (plugin.ClientProtocol, error) {
+	return &fakeClientProtocol{client: c}, nil
+}
+
+func (c *fakePluginClient) NegotiatedVersion() int {
+	return 2
+}
+
+func (c *fakePluginClient) ReattachConfig() *plugin.ReattachConfig {
+	return nil
+}
+
+func (c *fakePluginClient) Exited() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.exited
+}
+
+func (c *fakePluginClient) Kill() {
+	c.exit()
+}
+
+func (c *fakePluginClient) exit() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.exited = true
+}
+
+func (c *fakePluginClient) healthChecks() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.checks
+}
+
+func (c *fakePluginClient) CheckHealth(ctx context.Context, req *pluginv2.CheckHealthRequest, opts ...grpc.CallOption) (*pluginv2.CheckHealthResponse, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks++
+	if c.healthErr != nil {
+		return nil, c.healthErr
+	}
+	return &pluginv2.CheckHealthResponse{Status: pluginv2.CheckHealthResponse_OK}, nil
+}
+
+func (c *fakePluginClient) CollectMetrics(ctx context.Context, req *pluginv2.CollectMetricsRequest, opts ...grpc.CallOption) (*pluginv2.CollectMetricsResponse, error) {
+	return &pluginv2.CollectMetricsResponse{}, nil
+}
+
+// fakeClientProtocol dispenses the diagnostics of the fake client, and no other plugin implementations.
+type fakeClientProtocol struct {
+	client *fakePluginClient
+}
+
+func (p *fakeClientProtocol) Dispense(name string) (interface{}, error) {
+	if name == "diagnostics" {
+		return p.client, nil
+	}
+	return nil, nil
+}
+
+func (p *fakeClientProtocol) Ping() error {
+	return nil
+}
+
+func (p *fakeClientProtocol) Close() error {
+	return nil
+}
@@ -26,7 +26,7 @@ type PluginDashboardInfoDTO struct {
 }
 
 func GetPluginDashboards(orgId int64, pluginId string) ([]*PluginDashboardInfoDTO, error) {
-	plugin, exists := Plugins[pluginId]
+	plugin, exists := Plugins()[pluginId]
 
 	if !exists {
 		return nil, PluginNotFoundError{pluginId}
@@ -90,7 +90,7 @@ func GetPluginDashboards(orgId int64, pluginId string) ([]*PluginDashboardInfoDT
 }
 
 func loadPluginDashboard(pluginId, path string) (*models.Dashboard, error) {
-	plugin, exists := Plugins[pluginId]
+	plugin, exists := Plugins()[pluginId]
 
 	if !exists {
 		return nil, PluginNotFoundError{pluginId}
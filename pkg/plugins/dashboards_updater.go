@@ -25,7 +25,7 @@ func (pm *PluginManager) updateAppDashboards() {
 			continue
 		}
 
-		if pluginDef, exist := Plugins[pluginSetting.PluginId]; exist {
+		if pluginDef, exist := Plugins()[pluginSetting.PluginId]; exist {
 			if pluginDef.Info.Version != pluginSetting.PluginVersion {
 				syncPluginDashboards(pluginDef, pluginSetting.OrgId)
 			}
@@ -109,7 +109,7 @@ func handlePluginStateChanged(event *models.PluginStateChangedEvent) error {
 	plog.Info("Plugin state changed", "pluginId", event.PluginId, "enabled", event.Enabled)
 
 	if event.Enabled {
-		syncPluginDashboards(Plugins[event.PluginId], event.OrgId)
+		syncPluginDashboards(Plugins()[event.PluginId], event.OrgId)
 	} else {
 		query := models.GetDashboardsByPluginIdQuery{PluginId: event.PluginId, OrgId: event.OrgId}
 
@@ -56,7 +56,7 @@ func (p *DataSourcePlugin) Load(decoder *json.Decoder, pluginDir string, backend
 		}
 	}
 
-	DataSources[p.Id] = p
+	addDataSource(p)
 	return nil
 }
 
@@ -15,7 +15,7 @@ type FrontendPluginBase struct {
 
 func (fp *FrontendPluginBase) initFrontendPlugin() {
 	if isExternalPlugin(fp.PluginDir) {
-		StaticRoutes = append(StaticRoutes, &PluginStaticRoute{
+		addStaticRoute(&PluginStaticRoute{
 			Directory: fp.PluginDir,
 			PluginId:  fp.Id,
 		})
@@ -0,0 +1,700 @@
+package plugins
+
+import (
+	"archive/zip"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/fs"
+	"github.com/grafana/grafana/pkg/infra/metrics"
+	"github.com/grafana/grafana/pkg/plugins/backendplugin"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+	"github.com/grafana/grafana/pkg/util/errutil"
+	"golang.org/x/xerrors"
+)
+
+var (
+	// ErrPluginAdminDisabled is returned when plugins are changed while the plugin admin is disabled.
+	ErrPluginAdminDisabled = errors.New("plugin admin is disabled")
+	// ErrPluginAlreadyInstalled is returned when installing a plugin that is already installed.
+	ErrPluginAlreadyInstalled = errors.New("plugin is already installed")
+	// ErrPluginVersionNotFound is returned when the plugin repository has no version of the
+	// plugin that matches the requested version and supports the OS and architecture.
+	ErrPluginVersionNotFound = errors.New("plugin version not found")
+	// ErrPluginNotInPluginsDir is returned when changing a core or bundled plugin, or a plugin
+	// loaded from a path configured for the plugin.
+	ErrPluginNotInPluginsDir = errors.New("plugin is not installed in the plugins directory")
+	// ErrPluginRequiresRestart is returned when changing a renderer or transform plugin, which are
+	// only loaded when Grafana starts.
+	ErrPluginRequiresRestart = errors.New("plugins of this type can only be changed while Grafana is stopped")
+)
+
+// InvalidPluginError is returned for plugin archives that can not be installed, e.g. because
+// a plugin has an invalid signature.
+type InvalidPluginError struct {
+	Err error
+}
+
+func (e InvalidPluginError) Error() string {
+	return fmt.Sprintf("invalid plugin: %s", e.Err)
+}
+
+const (
+	// installStagingPrefix is the prefix of the directories plugins are extracted to before they
+	// replace the installed version. They are skipped when scanning the plugins directory.
+	installStagingPrefix = ".plugin-install-"
+	// maxPluginArchiveSize is the maximum size of downloaded and uploaded plugin archives.
+	maxPluginArchiveSize = 512 << 20
+)
+
+var (
+	pluginIDPattern = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+	// maxExtractedPluginSize is the maximum total size of the files extracted from a plugin archive.
+	maxExtractedPluginSize int64 = 2 << 30
+
+	repoHTTPClient = http.Client{Timeout: 5 * time.Minute}
+)
+
+type repoPlugin struct {
+	ID       string              `json:"id"`
+	Versions []repoPluginVersion `json:"versions"`
+}
+
+type repoPluginVersion struct {
+	Version string `json:"version"`
+	// Arch maps the os-arch of the version to the md5 checksum of the archive
+	Arch map[string]struct {
+		Md5 string `json:"md5"`
+	} `json:"arch"`
+}
+
+// InstallPlugin downloads a version of a plugin from the plugin repository and loads it. The latest
+// version that supports the OS and architecture is installed if the version is empty.
+func (pm *PluginManager) InstallPlugin(pluginID, version string) (*PluginBase, error) {
+	return pm.installFromRepo(pluginID, version, false)
+}
+
+// UpgradePlugin replaces an installed plugin with a version from the plugin repository, the latest
+// version if the version is empty.
+func (pm *PluginManager) UpgradePlugin(pluginID, version string) (*PluginBase, error) {
+	return pm.installFromRepo(pluginID, version, true)
+}
+
+func (pm *PluginManager) installFromRepo(pluginID, version string, upgrade bool) (*PluginBase, error) {
+	if !pm.Cfg.PluginAdminEnabled {
+		return nil, ErrPluginAdminDisabled
+	}
+	if !pluginIDPattern.MatchString(pluginID) {
+		return nil, PluginNotFoundError{pluginID}
+	}
+
+	pm.installMu.Lock()
+	defer pm.installMu.Unlock()
+
+	installed, exists := Plugins()[pluginID]
+	if exists && !upgrade {
+		return nil, ErrPluginAlreadyInstalled
+	}
+	if !exists && upgrade {
+		return nil, PluginNotFoundError{pluginID}
+	}
+	if exists {
+		if _, err := changeablePluginDir(installed); err != nil {
+			return nil, err
+		}
+	}
+
+	archive, err := ioutil.TempFile("", "grafana-plugin-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	if err := pm.downloadPlugin(pluginID, version, archive); err != nil {
+		return nil, err
+	}
+
+	return pm.installArchive(archive.Name(), pluginID)
+}
+
+// InstallPluginArchive installs the plugin of a zip archive and loads it. An installed version of
+// the plugin is replaced.
+func (pm *PluginManager) InstallPluginArchive(r io.Reader) (*PluginBase, error) {
+	if !pm.Cfg.PluginAdminEnabled {
+		return nil, ErrPluginAdminDisabled
+	}
+
+	pm.installMu.Lock()
+	defer pm.installMu.Unlock()
+
+	archive, err := ioutil.TempFile("", "grafana-plugin-*.zip")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	n, err := io.Copy(archive, io.LimitReader(r, maxPluginArchiveSize+1))
+	if err != nil {
+		return nil, errutil.Wrap("failed to read plugin archive", err)
+	}
+	if n > maxPluginArchiveSize {
+		return nil, InvalidPluginError{fmt.Errorf("archive is larger than %d bytes", maxPluginArchiveSize)}
+	}
+
+	return pm.installArchive(archive.Name(), "")
+}
+
+// UninstallPlugin unloads a plugin and removes it from the plugins directory.
+func (pm *PluginManager) UninstallPlugin(pluginID string) error {
+	if !pm.Cfg.PluginAdminEnabled {
+		return ErrPluginAdminDisabled
+	}
+
+	pm.installMu.Lock()
+	defer pm.installMu.Unlock()
+
+	plugin, exists := Plugins()[pluginID]
+	if !exists {
+		return PluginNotFoundError{pluginID}
+	}
+	dir, err := changeablePluginDir(plugin)
+	if err != nil {
+		return err
+	}
+
+	pm.unloadPluginDir(dir)
+	if err := os.RemoveAll(dir); err != nil {
+		return errutil.Wrapf(err, "failed to remove plugin directory %q", dir)
+	}
+
+	pm.log.Info("Plugin uninstalled", "pluginId", pluginID, "dir", dir)
+	return nil
+}
+
+// ReloadPlugin loads a plugin again from the plugins directory, e.g. after it was installed or
+// upgraded with grafana-cli.
+func (pm *PluginManager) ReloadPlugin(pluginID string) (*PluginBase, error) {
+	if !pm.Cfg.PluginAdminEnabled {
+		return nil, ErrPluginAdminDisabled
+	}
+	if !pluginIDPattern.MatchString(pluginID) {
+		return nil, PluginNotFoundError{pluginID}
+	}
+
+	pm.installMu.Lock()
+	defer pm.installMu.Unlock()
+
+	dir := filepath.Join(setting.PluginsPath, pluginID)
+	if plugin, exists := Plugins()[pluginID]; exists {
+		var err error
+		if dir, err = changeablePluginDir(plugin); err != nil {
+			return nil, err
+		}
+		pm.unloadPluginDir(dir)
+	}
+
+	if exists, err := fs.Exists(dir); err != nil || !exists {
+		return nil, PluginNotFoundError{pluginID}
+	}
+
+	if err := pm.verifyPluginDir(dir); err != nil {
+		return nil, err
+	}
+	if err := pm.loadPluginDir(dir); err != nil {
+		pm.unloadPluginDir(dir)
+		return nil, err
+	}
+
+	plugin, exists := Plugins()[pluginID]
+	if !exists {
+		return nil, PluginNotFoundError{pluginID}
+	}
+	pm.log.Info("Plugin reloaded", "pluginId", pluginID, "version", plugin.Info.Version)
+	return plugin, nil
+}
+
+// installArchive extracts a plugin archive to the plugins directory and loads the plugin. If
+// pluginID is not empty, the archive must contain that plugin. An installed version of the
+// plugin is restored if the new version fails to load.
+func (pm *PluginManager) installArchive(archivePath, pluginID string) (*PluginBase, error) {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, InvalidPluginError{errutil.Wrap("failed to open archive", err)}
+	}
+	defer zr.Close()
+
+	root, plugin, err := readArchivePlugin(&zr.Reader)
+	if err != nil {
+		return nil, InvalidPluginError{err}
+	}
+	if pluginID != "" && plugin.Id != pluginID {
+		return nil, InvalidPluginError{fmt.Errorf("archive contains plugin %q instead of %q", plugin.Id, pluginID)}
+	}
+	if plugin.Type == "renderer" || plugin.Type == "transform" {
+		return nil, ErrPluginRequiresRestart
+	}
+
+	dir := filepath.Join(setting.PluginsPath, plugin.Id)
+	if installed, exists := Plugins()[plugin.Id]; exists {
+		if dir, err = changeablePluginDir(installed); err != nil {
+			return nil, err
+		}
+	}
+
+	staging, err := ioutil.TempDir(setting.PluginsPath, installStagingPrefix)
+	if err != nil {
+		return nil, errutil.Wrap("failed to create staging directory", err)
+	}
+	defer func() {
+		if err := os.RemoveAll(staging); err != nil {
+			pm.log.Warn("Failed to remove staging directory", "dir", staging, "error", err)
+		}
+	}()
+
+	staged := filepath.Join(staging, "plugin")
+	if err := extractArchive(&zr.Reader, root, staged); err != nil {
+		return nil, err
+	}
+	if err := pm.verifyPluginDir(staged); err != nil {
+		return nil, err
+	}
+
+	previous := ""
+	if exists, err := fs.Exists(dir); err != nil {
+		return nil, err
+	} else if exists {
+		pm.unloadPluginDir(dir)
+		previous = filepath.Join(staging, "previous")
+		if err := os.Rename(dir, previous); err != nil {
+			pm.restorePluginDir(dir)
+			return nil, errutil.Wrapf(err, "failed to move installed plugin %q", plugin.Id)
+		}
+	}
+
+	if err := os.Rename(staged, dir); err != nil {
+		pm.restorePrevious(dir, previous)
+		return nil, errutil.Wrapf(err, "failed to move plugin %q to the plugins directory", plugin.Id)
+	}
+
+	if err := pm.loadPluginDir(dir); err != nil {
+		pm.unloadPluginDir(dir)
+		if removeErr := os.RemoveAll(dir); removeErr != nil {
+			pm.log.Error("Failed to remove plugin that could not be loaded", "dir", dir, "error", removeErr)
+		}
+		pm.restorePrevious(dir, previous)
+		return nil, err
+	}
+
+	installed, exists := Plugins()[plugin.Id]
+	if !exists {
+		return nil, InvalidPluginError{fmt.Errorf("plugin %q was not loaded", plugin.Id)}
+	}
+	pm.log.Info("Plugin installed", "pluginId", installed.Id, "version", installed.Info.Version, "dir", dir)
+	return installed, nil
+}
+
+// restorePrevious moves the previously installed version of a plugin back and loads it.
+func (pm *PluginManager) restorePrevious(dir, previous string) {
+	if previous == "" {
+		return
+	}
+	if err := os.Rename(previous, dir); err != nil {
+		pm.log.Error("Failed to restore previous version of plugin", "dir", dir, "error", err)
+		return
+	}
+	pm.restorePluginDir(dir)
+}
+
+func (pm *PluginManager) restorePluginDir(dir string) {
+	if err := pm.loadPluginDir(dir); err != nil {
+		pm.log.Error("Failed to load previous version of plugin", "dir", dir, "error", err)
+	}
+}
+
+// loadPluginDir scans a directory of the plugins directory for plugins and initializes the
+// plugins that were found, like Init does for the plugins found at startup. Backend plugins
+// are started by the backend plugin manager when they are registered.
+func (pm *PluginManager) loadPluginDir(dir string) error {
+	previous := Plugins()
+	scanner := &PluginScanner{
+		pluginPath:           dir,
+		backendPluginManager: pm.BackendPluginManager,
+		cfg:                  pm.Cfg,
+		requireSigned:        true,
+		log:                  pm.log,
+	}
+	if err := util.Walk(dir, true, true, scanner.walker); err != nil {
+		return errutil.Wrapf(err, "failed to scan plugin directory %q", dir)
+	}
+	if len(scanner.errors) > 0 {
+		return InvalidPluginError{scanner.errors[0]}
+	}
+
+	var loaded []*PluginBase
+	for id, p := range Plugins() {
+		if _, exists := previous[id]; !exists {
+			loaded = append(loaded, p)
+		}
+	}
+
+	for _, p := range loaded {
+		if panel, exists := Panels()[p.Id]; exists {
+			panel.initFrontendPlugin()
+		}
+		if ds, exists := DataSources()[p.Id]; exists {
+			ds.initFrontendPlugin()
+		}
+	}
+	for _, p := range loaded {
+		if app, exists := Apps()[p.Id]; exists {
+			app.initApp()
+		}
+	}
+	for _, p := range loaded {
+		setPluginSignature(pm.log, p)
+		metrics.SetPluginBuildInformation(p.Id, p.Type, p.Info.Version)
+	}
+
+	return nil
+}
+
+// unloadPluginDir unregisters the plugins in a directory and stops their backend plugins.
+func (pm *PluginManager) unloadPluginDir(dir string) {
+	ids := make(map[string]bool)
+	for id, p := range Plugins() {
+		if filepath.Clean(p.PluginDir) == filepath.Clean(dir) || isInDir(p.PluginDir, dir) {
+			ids[id] = true
+		}
+	}
+
+	for id := range ids {
+		err := pm.BackendPluginManager.Unregister(id)
+		if err != nil && !xerrors.Is(err, backendplugin.ErrPluginNotRegistered) {
+			pm.log.Error("Failed to stop backend plugin", "pluginId", id, "error", err)
+		}
+	}
+
+	removePlugins(ids)
+}
+
+// verifyPluginDir checks the signatures of the plugins in a directory before they are loaded.
+// Unsigned plugins are handled like plugins found at startup, but plugins with an invalid or
+// modified signature are always rejected.
+func (pm *PluginManager) verifyPluginDir(dir string) error {
+	scanner := &PluginScanner{
+		pluginPath:    dir,
+		cfg:           pm.Cfg,
+		requireSigned: true,
+		log:           pm.log,
+	}
+
+	return util.Walk(dir, true, true, func(currentPath string, f os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if f.IsDir() || f.Name() != "plugin.json" {
+			return nil
+		}
+
+		plugin, err := readPluginJSON(currentPath)
+		if err != nil {
+			return InvalidPluginError{err}
+		}
+		plugin.PluginDir = filepath.Dir(currentPath)
+
+		switch getPluginSignatureState(pm.log, plugin) {
+		case PluginSignatureInvalid:
+			return InvalidPluginError{fmt.Errorf("plugin %q has an invalid signature", plugin.Id)}
+		case PluginSignatureModified:
+			return InvalidPluginError{fmt.Errorf("plugin %q's signature has been modified", plugin.Id)}
+		}
+		if err := scanner.validateSignature(plugin); err != nil {
+			return InvalidPluginError{err}
+		}
+		return nil
+	})
+}
+
+// downloadPlugin downloads the archive of a plugin version from the plugin repository.
+func (pm *PluginManager) downloadPlugin(pluginID, version string, dst io.Writer) error {
+	res, err := pm.repoRequest(pluginID, "repo", pluginID)
+	if err != nil {
+		return err
+	}
+	var plugin repoPlugin
+	err = json.NewDecoder(res.Body).Decode(&plugin)
+	res.Body.Close()
+	if err != nil {
+		return errutil.Wrap("failed to read plugin from repository", err)
+	}
+
+	var selected *repoPluginVersion
+	for i, v := range plugin.Versions {
+		if (version == "" || v.Version == version) && supportsCurrentArch(v) {
+			selected = &plugin.Versions[i]
+			break
+		}
+	}
+	if selected == nil {
+		return ErrPluginVersionNotFound
+	}
+
+	checksum := selected.Arch["any"].Md5
+	if meta, exists := selected.Arch[osAndArch()]; exists {
+		checksum = meta.Md5
+	}
+
+	pm.log.Info("Downloading plugin", "pluginId", pluginID, "version", selected.Version)
+	res, err = pm.repoRequest(pluginID, pluginID, "versions", selected.Version, "download")
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	h := md5.New()
+	n, err := io.Copy(io.MultiWriter(dst, h), io.LimitReader(res.Body, maxPluginArchiveSize+1))
+	if err != nil {
+		return errutil.Wrap("failed to download plugin archive", err)
+	}
+	if n > maxPluginArchiveSize {
+		return InvalidPluginError{fmt.Errorf("archive is larger than %d bytes", maxPluginArchiveSize)}
+	}
+	if checksum != "" && checksum != hex.EncodeToString(h.Sum(nil)) {
+		return InvalidPluginError{errors.New("checksum of the downloaded archive does not match the repository")}
+	}
+	return nil
+}
+
+// repoRequest sends a request to the plugin repository. The caller must close the body of the
+// response.
+func (pm *PluginManager) repoRequest(pluginID string, subPaths ...string) (*http.Response, error) {
+	u, err := url.Parse(pm.Cfg.PluginRepositoryURL)
+	if err != nil {
+		return nil, errutil.Wrap("invalid plugin repository url", err)
+	}
+	u.Path = path.Join(append([]string{u.Path}, subPaths...)...)
+
+	req, err := http.NewRequest("GET", u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("grafana-version", setting.BuildVersion)
+	req.Header.Set("grafana-os", runtime.GOOS)
+	req.Header.Set("grafana-arch", runtime.GOARCH)
+	req.Header.Set("User-Agent", "grafana "+setting.BuildVersion)
+	if pm.Cfg.PluginRepositoryToken != "" {
+		req.Header.Set("Authorization", "Bearer "+pm.Cfg.PluginRepositoryToken)
+	}
+
+	res, err := repoHTTPClient.Do(req)
+	if err != nil {
+		return nil, errutil.Wrap("failed to send request to plugin repository", err)
+	}
+	if res.StatusCode == 404 {
+		res.Body.Close()
+		return nil, PluginNotFoundError{pluginID}
+	}
+	if res.StatusCode/100 != 2 {
+		res.Body.Close()
+		return nil, fmt.Errorf("plugin repository responded with status %d", res.StatusCode)
+	}
+	return res, nil
+}
+
+func osAndArch() string {
+	return strings.ToLower(runtime.GOOS) + "-" + runtime.GOARCH
+}
+
+func supportsCurrentArch(v repoPluginVersion) bool {
+	if v.Arch == nil {
+		return true
+	}
+	_, anyArch := v.Arch["any"]
+	_, currentArch := v.Arch[osAndArch()]
+	return anyArch || currentArch
+}
+
+// readArchivePlugin returns the directory of the archive that is the plugin directory and the
+// plugin.json of the plugin. Like with grafana-cli, the plugin directory is either the root of
+// the archive or its top level directory, with the plugin.json in it or in its dist directory.
+func readArchivePlugin(zr *zip.Reader) (string, *PluginBase, error) {
+	var root string
+	var pluginJSON *zip.File
+	rank := 0
+	for _, f := range zr.File {
+		name := strings.TrimPrefix(path.Clean("/"+f.Name), "/")
+		dir := ""
+		if i := strings.Index(name, "/"); i >= 0 {
+			dir = name[:i+1]
+		}
+
+		// the plugin.json of the dist directory is the one of the built plugin
+		var r int
+		switch name {
+		case "dist/plugin.json":
+			dir, r = "", 4
+		case "plugin.json":
+			dir, r = "", 3
+		case dir + "dist/plugin.json":
+			r = 2
+		case dir + "plugin.json":
+			r = 1
+		default:
+			continue
+		}
+		if r > rank {
+			root, pluginJSON, rank = dir, f, r
+		}
+	}
+	if pluginJSON == nil {
+		return "", nil, errors.New("archive has no plugin.json")
+	}
+
+	src, err := pluginJSON.Open()
+	if err != nil {
+		return "", nil, err
+	}
+	defer src.Close()
+
+	var plugin PluginBase
+	if err := json.NewDecoder(src).Decode(&plugin); err != nil {
+		return "", nil, errutil.Wrap("failed to read plugin.json", err)
+	}
+	if plugin.Id == "" || plugin.Type == "" {
+		return "", nil, errors.New("did not find type or id properties in plugin.json")
+	}
+	if !pluginIDPattern.MatchString(plugin.Id) {
+		return "", nil, fmt.Errorf("invalid plugin id %q", plugin.Id)
+	}
+	return root, &plugin, nil
+}
+
+// extractArchive extracts the files of the root directory of the archive to dst.
+func extractArchive(zr *zip.Reader, root, dst string) error {
+	remaining := maxExtractedPluginSize
+	for _, f := range zr.File {
+		name := strings.TrimPrefix(path.Clean("/"+f.Name), "/")
+		if name == "" || !strings.HasPrefix(name, root) || name == strings.TrimSuffix(root, "/") {
+			continue
+		}
+
+		target := filepath.Join(dst, filepath.FromSlash(strings.TrimPrefix(name, root)))
+		if !isInDir(target, dst) {
+			return InvalidPluginError{fmt.Errorf("archive file %q is outside of the plugin directory", f.Name)}
+		}
+		if f.Mode()&os.ModeSymlink != 0 {
+			return InvalidPluginError{fmt.Errorf("archive file %q is a symbolic link", f.Name)}
+		}
+
+		if f.FileInfo().IsDir() {
+			if err := os.MkdirAll(target, 0750); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0750); err != nil {
+			return err
+		}
+		written, err := extractArchiveFile(f, target, remaining)
+		if err != nil {
+			return errutil.Wrapf(err, "failed to extract %q", f.Name)
+		}
+		if written > remaining {
+			return InvalidPluginError{fmt.Errorf("archive files exceed the maximum size of %d bytes", maxExtractedPluginSize)}
+		}
+		remaining -= written
+	}
+	return nil
+}
+
+// extractArchiveFile writes the archive file to the target and returns the number of bytes
+// written, which is at most limit+1, so archives can't fill the disk with highly compressed files.
+func extractArchiveFile(f *zip.File, target string, limit int64) (int64, error) {
+	mode := f.Mode().Perm() | 0600
+	// the executables of backend plugins must be executable
+	if strings.Contains(filepath.Base(target), "_linux_") || strings.Contains(filepath.Base(target), "_darwin_") {
+		mode |= 0100
+	}
+
+	src, err := f.Open()
+	if err != nil {
+		return 0, err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return 0, err
+	}
+	written, err := io.Copy(dst, io.LimitReader(src, limit+1))
+	if err != nil {
+		dst.Close()
+		return written, err
+	}
+	return written, dst.Close()
+}
+
+func readPluginJSON(pluginJSONPath string) (*PluginBase, error) {
+	data, err := ioutil.ReadFile(pluginJSONPath)
+	if err != nil {
+		return nil, err
+	}
+	var plugin PluginBase
+	if err := json.Unmarshal(data, &plugin); err != nil {
+		return nil, errutil.Wrapf(err, "failed to read %q", pluginJSONPath)
+	}
+	if plugin.Id == "" || plugin.Type == "" {
+		return nil, errors.New("did not find type or id properties in plugin.json")
+	}
+	return &plugin, nil
+}
+
+// changeablePluginDir returns the directory in the plugins directory the plugin is installed in,
+// which is the plugin directory or contains it, e.g. for a plugin.json in a dist directory.
+func changeablePluginDir(plugin *PluginBase) (string, error) {
+	if plugin.Type == "renderer" || plugin.Type == "transform" {
+		return "", ErrPluginRequiresRestart
+	}
+
+	pluginsPath, err := filepath.Abs(setting.PluginsPath)
+	if err != nil {
+		return "", err
+	}
+	pluginDir, err := filepath.Abs(plugin.PluginDir)
+	if err != nil {
+		return "", err
+	}
+	if plugin.IsCorePlugin || !isInDir(pluginDir, pluginsPath) {
+		return "", ErrPluginNotInPluginsDir
+	}
+
+	rel, err := filepath.Rel(pluginsPath, pluginDir)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(setting.PluginsPath, strings.Split(rel, string(filepath.Separator))[0]), nil
+}
+
+// isInDir returns true if the path is a path in the directory, but not the directory itself.
+func isInDir(p, dir string) bool {
+	p, dir = filepath.Clean(p), filepath.Clean(dir)
+	return p != dir && strings.HasPrefix(p, dir+string(filepath.Separator))
+}
@@ -0,0 +1,358 @@
+package plugins
+
+import (
+	"archive/zip"
+	"bytes"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestPluginManager_InstallPluginArchive(t *testing.T) {
+	t.Run("Installs and loads a panel plugin", func(t *testing.T) {
+		pm := setupInstallerTest(t)
+
+		plugin, err := pm.InstallPluginArchive(bytes.NewReader(pluginArchive(t, "test-panel", "panel", "1.0.0")))
+		require.NoError(t, err)
+
+		assert.Equal(t, "1.0.0", plugin.Info.Version)
+		require.Contains(t, Panels(), "test-panel")
+		assert.Equal(t, "public/plugins/test-panel", Panels()["test-panel"].BaseUrl)
+		assert.Equal(t, PluginSignatureUnsigned, Plugins()["test-panel"].Signature)
+		assert.Equal(t, []*PluginStaticRoute{{Directory: filepath.Join(setting.PluginsPath, "test-panel", "dist"), PluginId: "test-panel"}}, StaticRoutes())
+		assert.FileExists(t, filepath.Join(setting.PluginsPath, "test-panel", "dist", "module.js"))
+	})
+
+	t.Run("Replaces an installed version", func(t *testing.T) {
+		pm := setupInstallerTest(t)
+
+		_, err := pm.InstallPluginArchive(bytes.NewReader(pluginArchive(t, "test-panel", "panel", "1.0.0")))
+		require.NoError(t, err)
+		plugin, err := pm.InstallPluginArchive(bytes.NewReader(pluginArchive(t, "test-panel", "panel", "2.0.0")))
+		require.NoError(t, err)
+
+		assert.Equal(t, "2.0.0", plugin.Info.Version)
+		assert.Equal(t, "2.0.0", Panels()["test-panel"].Info.Version)
+		assert.Len(t, StaticRoutes(), 1)
+		assertPluginsDir(t, "test-panel")
+	})
+
+	t.Run("Keeps the installed version if the new version is invalid", func(t *testing.T) {
+		pm := setupInstallerTest(t)
+
+		_, err := pm.InstallPluginArchive(bytes.NewReader(pluginArchive(t, "test-ds", "datasource", "1.0.0")))
+		require.NoError(t, err)
+
+		archive := pluginArchive(t, "test-ds", "datasource", "2.0.0", "MANIFEST.txt", "this is not a signed manifest")
+		_, err = pm.InstallPluginArchive(bytes.NewReader(archive))
+		var invalid InvalidPluginError
+		require.True(t, xerrors.As(err, &invalid), err)
+
+		assert.Equal(t, "1.0.0", DataSources()["test-ds"].Info.Version)
+		assertPluginsDir(t, "test-ds")
+	})
+
+	t.Run("Rejects unsigned backend plugins", func(t *testing.T) {
+		pm := setupInstallerTest(t)
+
+		archive := pluginArchive(t, "test-backend", "datasource", "1.0.0", "plugin.json",
+			`{"id": "test-backend", "type": "datasource", "backend": true, "executable": "test", "info": {"version": "1.0.0"}}`)
+		_, err := pm.InstallPluginArchive(bytes.NewReader(archive))
+		assert.EqualError(t, err, `invalid plugin: plugin "test-backend" is unsigned`)
+		assert.NotContains(t, Plugins(), "test-backend")
+		assertPluginsDir(t)
+	})
+
+	t.Run("Rejects files outside of the plugin directory", func(t *testing.T) {
+		pm := setupInstallerTest(t)
+
+		archive := pluginArchive(t, "test-panel", "panel", "1.0.0", "../../evil.js", "evil")
+		_, err := pm.InstallPluginArchive(bytes.NewReader(archive))
+		require.NoError(t, err)
+
+		_, err = os.Stat(filepath.Join(filepath.Dir(setting.PluginsPath), "evil.js"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("Rejects archives exceeding the maximum extracted size", func(t *testing.T) {
+		pm := setupInstallerTest(t)
+		origMaxSize := maxExtractedPluginSize
+		maxExtractedPluginSize = 1024
+		t.Cleanup(func() { maxExtractedPluginSize = origMaxSize })
+
+		archive := pluginArchive(t, "test-panel", "panel", "1.0.0", "big.js", strings.Repeat("a", 2048))
+		_, err := pm.InstallPluginArchive(bytes.NewReader(archive))
+		var invalid InvalidPluginError
+		require.True(t, xerrors.As(err, &invalid), err)
+		assert.NotContains(t, Plugins(), "test-panel")
+		assertPluginsDir(t)
+	})
+
+	t.Run("Rejects renderer plugins", func(t *testing.T) {
+		pm := setupInstallerTest(t)
+
+		_, err := pm.InstallPluginArchive(bytes.NewReader(pluginArchive(t, "test-renderer", "renderer", "1.0.0")))
+		assert.Equal(t, ErrPluginRequiresRestart, err)
+	})
+
+	t.Run("Fails if the plugin admin is disabled", func(t *testing.T) {
+		pm := setupInstallerTest(t)
+		pm.Cfg.PluginAdminEnabled = false
+
+		_, err := pm.InstallPluginArchive(bytes.NewReader(pluginArchive(t, "test-panel", "panel", "1.0.0")))
+		assert.Equal(t, ErrPluginAdminDisabled, err)
+	})
+}
+
+func TestPluginManager_UninstallPlugin(t *testing.T) {
+	t.Run("Unloads and removes a plugin with its child plugins", func(t *testing.T) {
+		pm := setupInstallerTest(t)
+		fm := pm.BackendPluginManager.(*fakeBackendPluginManager)
+		fm.registeredPlugins = []string{"test-app"}
+
+		archive := pluginArchive(t, "test-app", "app", "1.0.0", "panels/child/plugin.json",
+			`{"id": "test-child-panel", "type": "panel", "info": {"version": "1.0.0"}}`)
+		_, err := pm.InstallPluginArchive(bytes.NewReader(archive))
+		require.NoError(t, err)
+		require.Contains(t, Panels(), "test-child-panel")
+		assert.Equal(t, "test-app", Panels()["test-child-panel"].IncludedInAppId)
+
+		err = pm.UninstallPlugin("test-app")
+		require.NoError(t, err)
+
+		assert.Empty(t, Plugins())
+		assert.Empty(t, Apps())
+		assert.Empty(t, Panels())
+		assert.Empty(t, StaticRoutes())
+		assert.Empty(t, fm.registeredPlugins)
+		assertPluginsDir(t)
+	})
+
+	t.Run("Fails for plugins that are not installed", func(t *testing.T) {
+		pm := setupInstallerTest(t)
+
+		err := pm.UninstallPlugin("unknown")
+		assert.Equal(t, PluginNotFoundError{"unknown"}, err)
+	})
+
+	t.Run("Fails for core plugins", func(t *testing.T) {
+		pm := setupInstallerTest(t)
+		addPlugin(&PluginBase{Id: "graph", Type: "panel", PluginDir: "public/app/plugins/panel/graph", IsCorePlugin: true})
+
+		err := pm.UninstallPlugin("graph")
+		assert.Equal(t, ErrPluginNotInPluginsDir, err)
+	})
+}
+
+func TestPluginManager_ReloadPlugin(t *testing.T) {
+	pm := setupInstallerTest(t)
+
+	_, err := pm.InstallPluginArchive(bytes.NewReader(pluginArchive(t, "test-panel", "panel", "1.0.0")))
+	require.NoError(t, err)
+
+	pluginJSON := filepath.Join(setting.PluginsPath, "test-panel", "dist", "plugin.json")
+	err = ioutil.WriteFile(pluginJSON, []byte(`{"id": "test-panel", "type": "panel", "info": {"version": "1.1.0"}}`), 0600)
+	require.NoError(t, err)
+
+	plugin, err := pm.ReloadPlugin("test-panel")
+	require.NoError(t, err)
+	assert.Equal(t, "1.1.0", plugin.Info.Version)
+	assert.Equal(t, "1.1.0", Panels()["test-panel"].Info.Version)
+	assert.Len(t, StaticRoutes(), 1)
+
+	_, err = pm.ReloadPlugin("unknown")
+	assert.Equal(t, PluginNotFoundError{"unknown"}, err)
+}
+
+func TestPluginManager_InstallPlugin(t *testing.T) {
+	archive := pluginArchive(t, "test-panel", "panel", "1.1.0")
+	sum := md5.Sum(archive)
+
+	var authorization string
+	repo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/api/plugins/repo/test-panel":
+			err := json.NewEncoder(w).Encode(repoPlugin{
+				ID: "test-panel",
+				Versions: []repoPluginVersion{
+					{Version: "2.0.0", Arch: map[string]struct {
+						Md5 string `json:"md5"`
+					}{"unknown-arch": {}}},
+					{Version: "1.1.0", Arch: map[string]struct {
+						Md5 string `json:"md5"`
+					}{"any": {Md5: hex.EncodeToString(sum[:])}}},
+					{Version: "1.0.0"},
+				},
+			})
+			require.NoError(t, err)
+		case "/api/plugins/test-panel/versions/1.1.0/download":
+			_, err := w.Write(archive)
+			require.NoError(t, err)
+		default:
+			w.WriteHeader(404)
+		}
+	}))
+	t.Cleanup(repo.Close)
+
+	t.Run("Installs the latest version supporting the architecture", func(t *testing.T) {
+		pm := setupInstallerTest(t)
+		pm.Cfg.PluginRepositoryURL = repo.URL + "/api/plugins"
+		pm.Cfg.PluginRepositoryToken = "token"
+
+		plugin, err := pm.InstallPlugin("test-panel", "")
+		require.NoError(t, err)
+		assert.Equal(t, "1.1.0", plugin.Info.Version)
+		assert.Equal(t, "Bearer token", authorization)
+
+		_, err = pm.InstallPlugin("test-panel", "")
+		assert.Equal(t, ErrPluginAlreadyInstalled, err)
+	})
+
+	t.Run("Fails for unknown plugins and versions", func(t *testing.T) {
+		pm := setupInstallerTest(t)
+		pm.Cfg.PluginRepositoryURL = repo.URL + "/api/plugins"
+
+		_, err := pm.InstallPlugin("unknown", "")
+		assert.Equal(t, PluginNotFoundError{"unknown"}, err)
+
+		_, err = pm.InstallPlugin("test-panel", "2.0.0")
+		assert.Equal(t, ErrPluginVersionNotFound, err)
+	})
+
+	t.Run("Upgrades installed plugins only", func(t *testing.T) {
+		pm := setupInstallerTest(t)
+		pm.Cfg.PluginRepositoryURL = repo.URL + "/api/plugins"
+
+		_, err := pm.UpgradePlugin("test-panel", "")
+		assert.Equal(t, PluginNotFoundError{"test-panel"}, err)
+
+		_, err = pm.InstallPluginArchive(bytes.NewReader(pluginArchive(t, "test-panel", "panel", "1.0.0")))
+		require.NoError(t, err)
+		plugin, err := pm.UpgradePlugin("test-panel", "")
+		require.NoError(t, err)
+		assert.Equal(t, "1.1.0", plugin.Info.Version)
+	})
+}
+
+func TestPluginMapsConcurrentAccess(t *testing.T) {
+	setupInstallerTest(t)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			addPlugin(&PluginBase{Id: "test-panel"})
+			addPanel(&PanelPlugin{FrontendPluginBase: FrontendPluginBase{PluginBase: PluginBase{Id: "test-panel"}}})
+			addStaticRoute(&PluginStaticRoute{PluginId: "test-panel"})
+			removePlugins(map[string]bool{"test-panel": true})
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		_ = Plugins()["test-panel"]
+		_ = Panels()["test-panel"]
+		_ = StaticRoutes()
+	}
+	<-done
+
+	assert.Empty(t, Plugins())
+	assert.Empty(t, StaticRoutes())
+}
+
+// setupInstallerTest resets the plugins and sets up an empty plugins directory.
+func setupInstallerTest(t *testing.T) *PluginManager {
+	origPluginsPath := setting.PluginsPath
+	origRootPath := setting.StaticRootPath
+	origEnv := setting.Env
+	origPlugins := loadedPlugins
+	t.Cleanup(func() {
+		setting.PluginsPath = origPluginsPath
+		setting.StaticRootPath = origRootPath
+		setting.Env = origEnv
+		setLoadedPlugins(origPlugins)
+	})
+
+	dir, err := ioutil.TempDir("", "plugins")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := os.RemoveAll(dir)
+		assert.NoError(t, err)
+	})
+	setting.PluginsPath = filepath.Join(dir, "plugins")
+	setting.StaticRootPath, err = filepath.Abs("../../public/")
+	require.NoError(t, err)
+	setting.Env = setting.PROD
+	require.NoError(t, os.Mkdir(setting.PluginsPath, 0750))
+
+	setLoadedPlugins(pluginMaps{
+		plugins:      map[string]*PluginBase{},
+		dataSources:  map[string]*DataSourcePlugin{},
+		panels:       map[string]*PanelPlugin{},
+		apps:         map[string]*AppPlugin{},
+		staticRoutes: []*PluginStaticRoute{},
+	})
+	PluginTypes = map[string]interface{}{
+		"panel":      PanelPlugin{},
+		"datasource": DataSourcePlugin{},
+		"app":        AppPlugin{},
+		"renderer":   RendererPlugin{},
+		"transform":  TransformPlugin{},
+	}
+	plog = log.New("plugins")
+
+	return &PluginManager{
+		Cfg:                  &setting.Cfg{PluginAdminEnabled: true},
+		BackendPluginManager: &fakeBackendPluginManager{},
+		log:                  log.New("plugins"),
+	}
+}
+
+// pluginArchive returns a zip archive of a plugin built to a dist directory, like the archives
+// of the plugin repository. Files are pairs of names and contents in the dist directory.
+func pluginArchive(t *testing.T, id, pluginType, version string, files ...string) []byte {
+	contents := map[string]string{
+		"plugin.json": fmt.Sprintf(`{"id": %q, "type": %q, "info": {"version": %q}}`, id, pluginType, version),
+		"module.js":   "define([], function() {});",
+	}
+	for i := 0; i+1 < len(files); i += 2 {
+		contents[files[i]] = files[i+1]
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range contents {
+		w, err := zw.Create(id + "-abc123/dist/" + name)
+		require.NoError(t, err)
+		_, err = w.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, zw.Close())
+	return buf.Bytes()
+}
+
+// assertPluginsDir asserts that the plugins directory only contains the directories of the plugins.
+func assertPluginsDir(t *testing.T, ids ...string) {
+	infos, err := ioutil.ReadDir(setting.PluginsPath)
+	require.NoError(t, err)
+
+	names := []string{}
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	assert.ElementsMatch(t, ids, names)
+}
@@ -44,6 +44,29 @@ N1c5v9v/4h6qeA==
 -----END PGP PUBLIC KEY BLOCK-----
 `
 
+// trustedKeyring holds the public keys of trusted_signing_keys, which private plugins are signed with.
+var trustedKeyring openpgp.EntityList
+
+// loadTrustedSigningKeys reads the armored public keys of the files.
+func loadTrustedSigningKeys(files []string) error {
+	keyring := openpgp.EntityList{}
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		entities, err := openpgp.ReadArmoredKeyRing(f)
+		f.Close()
+		if err != nil {
+			return errutil.Wrapf(err, "failed to read public key %q", file)
+		}
+		keyring = append(keyring, entities...)
+	}
+
+	trustedKeyring = keyring
+	return nil
+}
+
 // pluginManifest holds details for the file manifest
 type pluginManifest struct {
 	Plugin  string            `json:"plugin"`
@@ -51,6 +74,10 @@ type pluginManifest struct {
 	KeyID   string            `json:"keyId"`
 	Time    int64             `json:"time"`
 	Files   map[string]string `json:"files"`
+
+	// signatureType and signedBy, the owner of the key, are set when the signature is checked
+	signatureType PluginSignatureType
+	signedBy      string
 }
 
 // readPluginManifest attempts to read and verify the plugin manifest
@@ -75,31 +102,78 @@ func readPluginManifest(body []byte) (*pluginManifest, error) {
 
 	if _, err := openpgp.CheckDetachedSignature(keyring,
 		bytes.NewBuffer(block.Bytes),
-		block.ArmoredSignature.Body); err != nil {
+		block.ArmoredSignature.Body); err == nil {
+		manifest.signatureType = PluginSignatureTypeGrafana
+		return manifest, nil
+	} else if len(trustedKeyring) == 0 {
+		return nil, errutil.Wrap("failed to check signature", err)
+	}
+
+	// the signature body is read by the first check
+	block, _ = clearsign.Decode(body)
+	signer, err := openpgp.CheckDetachedSignature(trustedKeyring,
+		bytes.NewBuffer(block.Bytes),
+		block.ArmoredSignature.Body)
+	if err != nil {
 		return nil, errutil.Wrap("failed to check signature", err)
 	}
 
+	manifest.signatureType = PluginSignatureTypePrivate
+	manifest.signedBy = signerName(signer)
 	return manifest, nil
 }
 
+// signerName returns the name of the primary identity of the key, or else of any identity.
+func signerName(signer *openpgp.Entity) string {
+	name := ""
+	for _, identity := range signer.Identities {
+		if identity.SelfSignature != nil && identity.SelfSignature.IsPrimaryId != nil && *identity.SelfSignature.IsPrimaryId {
+			return identity.UserId.Name
+		}
+		if name == "" || identity.UserId.Name < name {
+			name = identity.UserId.Name
+		}
+	}
+	return name
+}
+
 // getPluginSignatureState returns the signature state for a plugin.
 func getPluginSignatureState(log log.Logger, plugin *PluginBase) PluginSignature {
+	state, _ := verifyPluginSignature(log, plugin)
+	return state
+}
+
+// setPluginSignature sets the signature state of a plugin and, if the signature is valid, the
+// type of the signature and the owner of the signing key.
+func setPluginSignature(log log.Logger, plugin *PluginBase) {
+	state, manifest := verifyPluginSignature(log, plugin)
+	plugin.Signature = state
+	plugin.SignatureType, plugin.SignatureOrg = "", ""
+	if manifest != nil {
+		plugin.SignatureType = manifest.signatureType
+		plugin.SignatureOrg = manifest.signedBy
+	}
+}
+
+// verifyPluginSignature returns the signature state for a plugin and its manifest if the
+// signature is valid.
+func verifyPluginSignature(log log.Logger, plugin *PluginBase) (PluginSignature, *pluginManifest) {
 	log.Debug("Getting signature state of plugin", "plugin", plugin.Id)
 	manifestPath := path.Join(plugin.PluginDir, "MANIFEST.txt")
 
 	byteValue, err := ioutil.ReadFile(manifestPath)
 	if err != nil || len(byteValue) < 10 {
-		return PluginSignatureUnsigned
+		return PluginSignatureUnsigned, nil
 	}
 
 	manifest, err := readPluginManifest(byteValue)
 	if err != nil {
-		return PluginSignatureInvalid
+		return PluginSignatureInvalid, nil
 	}
 
 	// Make sure the versions all match
 	if manifest.Plugin != plugin.Id || manifest.Version != plugin.Info.Version {
-		return PluginSignatureModified
+		return PluginSignatureModified, nil
 	}
 
 	// Verify the manifest contents
@@ -109,22 +183,22 @@ func getPluginSignatureState(log log.Logger, plugin *PluginBase) PluginSignature
 		fp := path.Join(plugin.PluginDir, p)
 		f, err := os.Open(fp)
 		if err != nil {
-			return PluginSignatureModified
+			return PluginSignatureModified, nil
 		}
 		defer f.Close()
 
 		h := sha256.New()
 		if _, err := io.Copy(h, f); err != nil {
 			log.Warn("Couldn't read plugin file", "plugin", plugin.Id, "filename", fp)
-			return PluginSignatureModified
+			return PluginSignatureModified, nil
 		}
 		sum := hex.EncodeToString(h.Sum(nil))
 		if sum != hash {
 			log.Warn("Plugin file's signature has been modified versus manifest", "plugin", plugin.Id, "filename", fp)
-			return PluginSignatureModified
+			return PluginSignatureModified, nil
 		}
 	}
 
 	// Everything OK
-	return PluginSignatureValid
+	return PluginSignatureValid, manifest
 }
@@ -1,11 +1,23 @@
 package plugins
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/armor"
+	"golang.org/x/crypto/openpgp/clearsign"
+	"golang.org/x/crypto/openpgp/packet"
 )
 
 func TestReadPluginManifest(t *testing.T) {
@@ -46,6 +58,7 @@ NR7DnB0CCQHO+4FlSPtXFTzNepoc+CytQyDAeOLMLmf2Tqhk2YShk+G/YlVX
 		require.NoError(t, err)
 		require.NotNil(t, manifest)
 		assert.Equal(t, "grafana-googlesheets-datasource", manifest.Plugin)
+		assert.Equal(t, PluginSignatureTypeGrafana, manifest.signatureType)
 	})
 
 	t.Run("invalid manifest", func(t *testing.T) {
@@ -54,3 +67,68 @@ NR7DnB0CCQHO+4FlSPtXFTzNepoc+CytQyDAeOLMLmf2Tqhk2YShk+G/YlVX
 		require.Error(t, err)
 	})
 }
+
+func TestPrivatePluginSignature(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "plugin-signature")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		err := os.RemoveAll(tmpDir)
+		assert.NoError(t, err)
+	})
+	origKeyring := trustedKeyring
+	t.Cleanup(func() {
+		trustedKeyring = origKeyring
+	})
+
+	entity, err := openpgp.NewEntity("Acme Corp", "", "plugins@acme.com", &packet.Config{RSABits: 1024})
+	require.NoError(t, err)
+	publicKey := filepath.Join(tmpDir, "public.key")
+	f, err := os.Create(publicKey)
+	require.NoError(t, err)
+	w, err := armor.Encode(f, openpgp.PublicKeyType, nil)
+	require.NoError(t, err)
+	require.NoError(t, entity.Serialize(w))
+	require.NoError(t, w.Close())
+	require.NoError(t, f.Close())
+
+	pluginJSON := []byte(`{"id": "acme-panel", "type": "panel", "info": {"version": "1.0.0"}}`)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "plugin.json"), pluginJSON, 0600))
+	hash := sha256.Sum256(pluginJSON)
+	manifest, err := json.Marshal(pluginManifest{
+		Plugin:  "acme-panel",
+		Version: "1.0.0",
+		Files:   map[string]string{"plugin.json": hex.EncodeToString(hash[:])},
+	})
+	require.NoError(t, err)
+	var signed bytes.Buffer
+	w, err = clearsign.Encode(&signed, entity.PrivateKey, nil)
+	require.NoError(t, err)
+	_, err = w.Write(manifest)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, ioutil.WriteFile(filepath.Join(tmpDir, "MANIFEST.txt"), signed.Bytes(), 0600))
+
+	plugin := &PluginBase{Id: "acme-panel", Info: PluginInfo{Version: "1.0.0"}, PluginDir: tmpDir}
+
+	t.Run("is invalid without the trusted key", func(t *testing.T) {
+		require.NoError(t, loadTrustedSigningKeys(nil))
+
+		setPluginSignature(log.New("test"), plugin)
+		assert.Equal(t, PluginSignatureInvalid, plugin.Signature)
+		assert.Empty(t, plugin.SignatureType)
+	})
+
+	t.Run("is valid with the trusted key", func(t *testing.T) {
+		require.NoError(t, loadTrustedSigningKeys([]string{publicKey}))
+
+		setPluginSignature(log.New("test"), plugin)
+		assert.Equal(t, PluginSignatureValid, plugin.Signature)
+		assert.Equal(t, PluginSignatureTypePrivate, plugin.SignatureType)
+		assert.Equal(t, "Acme Corp", plugin.SignatureOrg)
+	})
+
+	t.Run("fails for invalid key files", func(t *testing.T) {
+		err := loadTrustedSigningKeys([]string{filepath.Join(tmpDir, "plugin.json")})
+		assert.Error(t, err)
+	})
+}
@@ -34,6 +34,14 @@ const (
 	PluginSignatureUnsigned PluginSignature = "unsigned" // no MANIFEST file
 )
 
+// PluginSignatureType is the type of the key a plugin is signed with.
+type PluginSignatureType string
+
+const (
+	PluginSignatureTypeGrafana PluginSignatureType = "grafana" // signed with Grafana's key
+	PluginSignatureTypePrivate PluginSignatureType = "private" // signed with a key of trusted_signing_keys
+)
+
 type PluginNotFoundError struct {
 	PluginId string
 }
@@ -60,7 +68,10 @@ type PluginBase struct {
 	Preload      bool               `json:"preload"`
 	State        PluginState        `json:"state,omitempty"`
 	Signature    PluginSignature    `json:"signature"`
-	Backend      bool               `json:"backend"`
+	// SignatureType and SignatureOrg, the owner of the signing key, are only set for valid signatures
+	SignatureType PluginSignatureType `json:"signatureType,omitempty"`
+	SignatureOrg  string              `json:"signatureOrg,omitempty"`
+	Backend       bool                `json:"backend"`
 
 	IncludedInAppId string `json:"-"`
 	PluginDir       string `json:"-"`
@@ -72,7 +83,7 @@ type PluginBase struct {
 }
 
 func (pb *PluginBase) registerPlugin(pluginDir string) error {
-	if _, exists := Plugins[pb.Id]; exists {
+	if _, exists := Plugins()[pb.Id]; exists {
 		return fmt.Errorf("Plugin with ID %q already exists", pb.Id)
 	}
 
@@ -95,7 +106,7 @@ func (pb *PluginBase) registerPlugin(pluginDir string) error {
 	}
 
 	pb.PluginDir = pluginDir
-	Plugins[pb.Id] = pb
+	addPlugin(pb)
 	return nil
 }
 
@@ -20,6 +20,6 @@ func (p *PanelPlugin) Load(decoder *json.Decoder, pluginDir string, backendPlugi
 		return err
 	}
 
-	Panels[p.Id] = p
+	addPanel(p)
 	return nil
 }
@@ -11,6 +11,7 @@ import (
 	"path/filepath"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/grafana/grafana/pkg/infra/fs"
@@ -25,20 +26,169 @@ import (
 )
 
 var (
-	DataSources  map[string]*DataSourcePlugin
-	Panels       map[string]*PanelPlugin
-	StaticRoutes []*PluginStaticRoute
-	Apps         map[string]*AppPlugin
-	Plugins      map[string]*PluginBase
-	PluginTypes  map[string]interface{}
-	Renderer     *RendererPlugin
-	Transform    *TransformPlugin
+	PluginTypes map[string]interface{}
+	Renderer    *RendererPlugin
+	Transform   *TransformPlugin
 
 	GrafanaLatestVersion string
 	GrafanaHasUpdate     bool
 	plog                 log.Logger
 )
 
+// loadedPlugins are the plugins found at startup and installed at runtime. The maps and the static
+// routes are replaced rather than modified when plugins are added or removed, so the accessors
+// return them without copying while plugins are installed and uninstalled.
+var (
+	loadedPluginsMu sync.RWMutex
+	loadedPlugins   = pluginMaps{}
+)
+
+type pluginMaps struct {
+	plugins      map[string]*PluginBase
+	dataSources  map[string]*DataSourcePlugin
+	panels       map[string]*PanelPlugin
+	apps         map[string]*AppPlugin
+	staticRoutes []*PluginStaticRoute
+}
+
+// Plugins returns the loaded plugins by ID. The map must not be modified.
+func Plugins() map[string]*PluginBase {
+	loadedPluginsMu.RLock()
+	defer loadedPluginsMu.RUnlock()
+	return loadedPlugins.plugins
+}
+
+// DataSources returns the loaded data source plugins by ID. The map must not be modified.
+func DataSources() map[string]*DataSourcePlugin {
+	loadedPluginsMu.RLock()
+	defer loadedPluginsMu.RUnlock()
+	return loadedPlugins.dataSources
+}
+
+// Panels returns the loaded panel plugins by ID. The map must not be modified.
+func Panels() map[string]*PanelPlugin {
+	loadedPluginsMu.RLock()
+	defer loadedPluginsMu.RUnlock()
+	return loadedPlugins.panels
+}
+
+// Apps returns the loaded app plugins by ID. The map must not be modified.
+func Apps() map[string]*AppPlugin {
+	loadedPluginsMu.RLock()
+	defer loadedPluginsMu.RUnlock()
+	return loadedPlugins.apps
+}
+
+// StaticRoutes returns the static routes of the loaded plugins. The slice must not be modified.
+func StaticRoutes() []*PluginStaticRoute {
+	loadedPluginsMu.RLock()
+	defer loadedPluginsMu.RUnlock()
+	return loadedPlugins.staticRoutes
+}
+
+func setLoadedPlugins(maps pluginMaps) {
+	loadedPluginsMu.Lock()
+	defer loadedPluginsMu.Unlock()
+	loadedPlugins = maps
+}
+
+func addPlugin(p *PluginBase) {
+	loadedPluginsMu.Lock()
+	defer loadedPluginsMu.Unlock()
+
+	plugins := make(map[string]*PluginBase, len(loadedPlugins.plugins)+1)
+	for id, plugin := range loadedPlugins.plugins {
+		plugins[id] = plugin
+	}
+	plugins[p.Id] = p
+	loadedPlugins.plugins = plugins
+}
+
+func addDataSource(p *DataSourcePlugin) {
+	loadedPluginsMu.Lock()
+	defer loadedPluginsMu.Unlock()
+
+	dataSources := make(map[string]*DataSourcePlugin, len(loadedPlugins.dataSources)+1)
+	for id, ds := range loadedPlugins.dataSources {
+		dataSources[id] = ds
+	}
+	dataSources[p.Id] = p
+	loadedPlugins.dataSources = dataSources
+}
+
+func addPanel(p *PanelPlugin) {
+	loadedPluginsMu.Lock()
+	defer loadedPluginsMu.Unlock()
+
+	panels := make(map[string]*PanelPlugin, len(loadedPlugins.panels)+1)
+	for id, panel := range loadedPlugins.panels {
+		panels[id] = panel
+	}
+	panels[p.Id] = p
+	loadedPlugins.panels = panels
+}
+
+func addApp(p *AppPlugin) {
+	loadedPluginsMu.Lock()
+	defer loadedPluginsMu.Unlock()
+
+	apps := make(map[string]*AppPlugin, len(loadedPlugins.apps)+1)
+	for id, app := range loadedPlugins.apps {
+		apps[id] = app
+	}
+	apps[p.Id] = p
+	loadedPlugins.apps = apps
+}
+
+func addStaticRoute(route *PluginStaticRoute) {
+	loadedPluginsMu.Lock()
+	defer loadedPluginsMu.Unlock()
+
+	routes := make([]*PluginStaticRoute, 0, len(loadedPlugins.staticRoutes)+1)
+	loadedPlugins.staticRoutes = append(append(routes, loadedPlugins.staticRoutes...), route)
+}
+
+// removePlugins removes the plugins with the IDs from the plugin maps and static routes.
+func removePlugins(ids map[string]bool) {
+	loadedPluginsMu.Lock()
+	defer loadedPluginsMu.Unlock()
+
+	maps := pluginMaps{
+		plugins:      make(map[string]*PluginBase, len(loadedPlugins.plugins)),
+		dataSources:  make(map[string]*DataSourcePlugin, len(loadedPlugins.dataSources)),
+		panels:       make(map[string]*PanelPlugin, len(loadedPlugins.panels)),
+		apps:         make(map[string]*AppPlugin, len(loadedPlugins.apps)),
+		staticRoutes: make([]*PluginStaticRoute, 0, len(loadedPlugins.staticRoutes)),
+	}
+	for id, plugin := range loadedPlugins.plugins {
+		if !ids[id] {
+			maps.plugins[id] = plugin
+		}
+	}
+	for id, ds := range loadedPlugins.dataSources {
+		if !ids[id] {
+			maps.dataSources[id] = ds
+		}
+	}
+	for id, panel := range loadedPlugins.panels {
+		if !ids[id] {
+			maps.panels[id] = panel
+		}
+	}
+	for id, app := range loadedPlugins.apps {
+		if !ids[id] {
+			maps.apps[id] = app
+		}
+	}
+	for _, route := range loadedPlugins.staticRoutes {
+		if !ids[route.PluginId] {
+			maps.staticRoutes = append(maps.staticRoutes, route)
+		}
+	}
+
+	loadedPlugins = maps
+}
+
 type PluginScanner struct {
 	pluginPath           string
 	errors               []error
@@ -53,6 +203,8 @@ type PluginManager struct {
 	Cfg                  *setting.Cfg          `inject:""`
 	log                  log.Logger
 	scanningErrors       []error
+	// installMu serializes installing, upgrading and uninstalling plugins at runtime
+	installMu sync.Mutex
 }
 
 func init() {
@@ -63,11 +215,13 @@ func (pm *PluginManager) Init() error {
 	pm.log = log.New("plugins")
 	plog = log.New("plugins")
 
-	DataSources = map[string]*DataSourcePlugin{}
-	StaticRoutes = []*PluginStaticRoute{}
-	Panels = map[string]*PanelPlugin{}
-	Apps = map[string]*AppPlugin{}
-	Plugins = map[string]*PluginBase{}
+	setLoadedPlugins(pluginMaps{
+		plugins:      map[string]*PluginBase{},
+		dataSources:  map[string]*DataSourcePlugin{},
+		panels:       map[string]*PanelPlugin{},
+		apps:         map[string]*AppPlugin{},
+		staticRoutes: []*PluginStaticRoute{},
+	})
 	PluginTypes = map[string]interface{}{
 		"panel":      PanelPlugin{},
 		"datasource": DataSourcePlugin{},
@@ -76,6 +230,10 @@ func (pm *PluginManager) Init() error {
 		"transform":  TransformPlugin{},
 	}
 
+	if err := loadTrustedSigningKeys(pm.Cfg.PluginTrustedSigningKeys); err != nil {
+		return errutil.Wrap("failed to load trusted plugin signing keys", err)
+	}
+
 	pm.log.Info("Starting plugin search")
 
 	plugDir := path.Join(setting.StaticRootPath, "app/plugins")
@@ -120,15 +278,15 @@ func (pm *PluginManager) Init() error {
 		return err
 	}
 
-	for _, panel := range Panels {
+	for _, panel := range Panels() {
 		panel.initFrontendPlugin()
 	}
 
-	for _, ds := range DataSources {
+	for _, ds := range DataSources() {
 		ds.initFrontendPlugin()
 	}
 
-	for _, app := range Apps {
+	for _, app := range Apps() {
 		app.initApp()
 	}
 
@@ -136,11 +294,11 @@ func (pm *PluginManager) Init() error {
 		Renderer.initFrontendPlugin()
 	}
 
-	for _, p := range Plugins {
+	for _, p := range Plugins() {
 		if p.IsCorePlugin {
 			p.Signature = PluginSignatureInternal
 		} else {
-			p.Signature = getPluginSignatureState(pm.log, p)
+			setPluginSignature(pm.log, p)
 			metrics.SetPluginBuildInformation(p.Id, p.Type, p.Info.Version)
 		}
 	}
@@ -217,10 +375,10 @@ func (pm *PluginManager) scan(pluginDir string, requireSigned bool) error {
 
 // GetDatasource returns a datasource based on passed pluginID if it exists
 //
-// This function fetches the datasource from the global variable DataSources in this package.
+// This function fetches the datasource from the loaded DataSources of this package.
 // Rather then refactor all dependencies on the global variable we can use this as an transition.
 func (pm *PluginManager) GetDatasource(pluginID string) (*DataSourcePlugin, bool) {
-	ds, exist := DataSources[pluginID]
+	ds, exist := DataSources()[pluginID]
 	return ds, exist
 }
 
@@ -232,7 +390,7 @@ func (scanner *PluginScanner) walker(currentPath string, f os.FileInfo, err erro
 		return err
 	}
 
-	if f.Name() == "node_modules" || f.Name() == "Chromium.app" {
+	if f.Name() == "node_modules" || f.Name() == "Chromium.app" || strings.HasPrefix(f.Name(), installStagingPrefix) {
 		return util.ErrWalkSkipDir
 	}
 
@@ -281,35 +439,8 @@ func (scanner *PluginScanner) loadPlugin(pluginJsonFilePath string) error {
 
 	pluginCommon.PluginDir = filepath.Dir(pluginJsonFilePath)
 
-	// For the time being, we choose to only require back-end plugins to be signed
-	// NOTE: the state is calculated again when setting metadata on the object
-	if pluginCommon.Backend && scanner.requireSigned {
-		sig := getPluginSignatureState(scanner.log, &pluginCommon)
-		if sig != PluginSignatureValid {
-			scanner.log.Debug("Invalid Plugin Signature", "pluginID", pluginCommon.Id, "pluginDir", pluginCommon.PluginDir, "state", sig)
-			if sig == PluginSignatureUnsigned {
-				allowUnsigned := false
-				for _, plug := range scanner.cfg.PluginsAllowUnsigned {
-					if plug == pluginCommon.Id {
-						allowUnsigned = true
-						break
-					}
-				}
-				if setting.Env != setting.DEV && !allowUnsigned {
-					return fmt.Errorf("plugin %q is unsigned", pluginCommon.Id)
-				}
-				scanner.log.Warn("Running an unsigned backend plugin", "pluginID", pluginCommon.Id, "pluginDir", pluginCommon.PluginDir)
-			} else {
-				switch sig {
-				case PluginSignatureInvalid:
-					return fmt.Errorf("plugin %q has an invalid signature", pluginCommon.Id)
-				case PluginSignatureModified:
-					return fmt.Errorf("plugin %q's signature has been modified", pluginCommon.Id)
-				default:
-					return fmt.Errorf("unrecognized plugin signature state %v", sig)
-				}
-			}
-		}
+	if err := scanner.validateSignature(&pluginCommon); err != nil {
+		return err
 	}
 
 	pluginGoType, exists := PluginTypes[pluginCommon.Type]
@@ -340,12 +471,48 @@ func (scanner *PluginScanner) loadPlugin(pluginJsonFilePath string) error {
 	return loader.Load(jsonParser, currentDir, scanner.backendPluginManager)
 }
 
+// validateSignature returns an error if the plugin must be signed and its signature is not valid.
+func (scanner *PluginScanner) validateSignature(plugin *PluginBase) error {
+	// For the time being, we choose to only require back-end plugins to be signed
+	// NOTE: the state is calculated again when setting metadata on the object
+	if plugin.Backend && scanner.requireSigned {
+		sig := getPluginSignatureState(scanner.log, plugin)
+		if sig != PluginSignatureValid {
+			scanner.log.Debug("Invalid Plugin Signature", "pluginID", plugin.Id, "pluginDir", plugin.PluginDir, "state", sig)
+			if sig == PluginSignatureUnsigned {
+				allowUnsigned := false
+				for _, plug := range scanner.cfg.PluginsAllowUnsigned {
+					if plug == plugin.Id {
+						allowUnsigned = true
+						break
+					}
+				}
+				if setting.Env != setting.DEV && !allowUnsigned {
+					return fmt.Errorf("plugin %q is unsigned", plugin.Id)
+				}
+				scanner.log.Warn("Running an unsigned backend plugin", "pluginID", plugin.Id, "pluginDir", plugin.PluginDir)
+			} else {
+				switch sig {
+				case PluginSignatureInvalid:
+					return fmt.Errorf("plugin %q has an invalid signature", plugin.Id)
+				case PluginSignatureModified:
+					return fmt.Errorf("plugin %q's signature has been modified", plugin.Id)
+				default:
+					return fmt.Errorf("unrecognized plugin signature state %v", sig)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
 func (scanner *PluginScanner) IsBackendOnlyPlugin(pluginType string) bool {
 	return pluginType == "renderer" || pluginType == "transform"
 }
 
 func GetPluginMarkdown(pluginId string, name string) ([]byte, error) {
-	plug, exists := Plugins[pluginId]
+	plug, exists := Plugins()[pluginId]
 	if !exists {
 		return nil, PluginNotFoundError{pluginId}
 	}
@@ -45,12 +45,12 @@ func TestPluginManager_Init(t *testing.T) {
 		require.NoError(t, err)
 
 		assert.Empty(t, pm.scanningErrors)
-		assert.Greater(t, len(DataSources), 1)
-		assert.Greater(t, len(Panels), 1)
-		assert.Equal(t, "app/plugins/datasource/graphite/module", DataSources["graphite"].Module)
-		assert.NotEmpty(t, Apps)
-		assert.Equal(t, "public/plugins/test-app/img/logo_large.png", Apps["test-app"].Info.Logos.Large)
-		assert.Equal(t, "public/plugins/test-app/img/screenshot2.png", Apps["test-app"].Info.Screenshots[1].Path)
+		assert.Greater(t, len(DataSources()), 1)
+		assert.Greater(t, len(Panels()), 1)
+		assert.Equal(t, "app/plugins/datasource/graphite/module", DataSources()["graphite"].Module)
+		assert.NotEmpty(t, Apps())
+		assert.Equal(t, "public/plugins/test-app/img/logo_large.png", Apps()["test-app"].Info.Logos.Large)
+		assert.Equal(t, "public/plugins/test-app/img/screenshot2.png", Apps()["test-app"].Info.Screenshots[1].Path)
 	})
 
 	t.Run("With external back-end plugin lacking signature", func(t *testing.T) {
@@ -194,6 +194,16 @@ func (f *fakeBackendPluginManager) Register(descriptor backendplugin.PluginDescr
 	return nil
 }
 
+func (f *fakeBackendPluginManager) Unregister(pluginID string) error {
+	for i, id := range f.registeredPlugins {
+		if id == pluginID {
+			f.registeredPlugins = append(f.registeredPlugins[:i], f.registeredPlugins[i+1:]...)
+			return nil
+		}
+	}
+	return backendplugin.ErrPluginNotRegistered
+}
+
 func (f *fakeBackendPluginManager) StartPlugin(ctx context.Context, pluginID string) error {
 	return nil
 }
@@ -208,3 +218,23 @@ func (f *fakeBackendPluginManager) CheckHealth(ctx context.Context, pCtx backend
 
 func (f *fakeBackendPluginManager) CallResource(pluginConfig backend.PluginContext, ctx *models.ReqContext, path string) {
 }
+
+func (f *fakeBackendPluginManager) RunStream(ctx context.Context, pCtx backend.PluginContext, path string, send func(*backendplugin.StreamMessage) error) error {
+	return nil
+}
+
+func (f *fakeBackendPluginManager) PublishStream(ctx context.Context, pCtx backend.PluginContext, path string, data []byte) ([]byte, error) {
+	return nil, nil
+}
+
+func (f *fakeBackendPluginManager) PluginStatus(pluginID string) (*backendplugin.PluginStatus, error) {
+	return nil, backendplugin.ErrPluginNotRegistered
+}
+
+func (f *fakeBackendPluginManager) PluginStatuses() []*backendplugin.PluginStatus {
+	return nil
+}
+
+func (f *fakeBackendPluginManager) RestartPlugin(pluginID string) error {
+	return nil
+}
@@ -17,7 +17,7 @@ func GetPluginSettings(orgId int64) (map[string]*models.PluginSettingInfoDTO, er
 		pluginMap[plug.PluginId] = plug
 	}
 
-	for _, pluginDef := range Plugins {
+	for _, pluginDef := range Plugins() {
 		// ignore entries that exists
 		if _, ok := pluginMap[pluginDef.Id]; ok {
 			continue
@@ -63,7 +63,7 @@ func GetEnabledPlugins(orgId int64) (*EnabledPlugins, error) {
 		return ok
 	}
 
-	for pluginId, app := range Apps {
+	for pluginId, app := range Apps() {
 		if b, ok := pluginSettingMap[pluginId]; ok {
 			app.Pinned = b.Pinned
 			enabledPlugins.Apps = append(enabledPlugins.Apps, app)
@@ -71,13 +71,13 @@ func GetEnabledPlugins(orgId int64) (*EnabledPlugins, error) {
 	}
 
 	// add all plugins that are not part of an App.
-	for dsId, ds := range DataSources {
+	for dsId, ds := range DataSources() {
 		if isPluginEnabled(ds.Id) {
 			enabledPlugins.DataSources[dsId] = ds
 		}
 	}
 
-	for _, panel := range Panels {
+	for _, panel := range Panels() {
 		if isPluginEnabled(panel.Id) {
 			enabledPlugins.Panels = append(enabledPlugins.Panels, panel)
 		}
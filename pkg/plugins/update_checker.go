@@ -28,7 +28,7 @@ type GithubLatest struct {
 
 func getAllExternalPluginSlugs() string {
 	var result []string
-	for _, plug := range Plugins {
+	for _, plug := range Plugins() {
 		if plug.IsCorePlugin {
 			continue
 		}
@@ -69,7 +69,7 @@ func (pm *PluginManager) checkForUpdates() {
 		return
 	}
 
-	for _, plug := range Plugins {
+	for _, plug := range Plugins() {
 		for _, gplug := range gNetPlugins {
 			if gplug.Slug == plug.Id {
 				plug.GrafanaNetVersion = gplug.Version
@@ -0,0 +1,256 @@
+// Package accesscontrol evaluates fine-grained permissions of signed in users.
+//
+// Every user has the permissions of their built-in org role. Custom roles
+// add permissions on top of that and can be assigned to users, teams and API keys.
+package accesscontrol
+
+import (
+	"sort"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+// Actions that can be granted by roles.
+const (
+	ActionDatasourcesRead  = "datasources:read"
+	ActionDatasourcesWrite = "datasources:write"
+	ActionDatasourcesQuery = "datasources:query"
+
+	ActionOrgWrite                = "org:write"
+	ActionOrgPreferencesRead      = "org.preferences:read"
+	ActionOrgPreferencesWrite     = "org.preferences:write"
+	ActionOrgUsersRead            = "org.users:read"
+	ActionOrgUsersWrite           = "org.users:write"
+	ActionApiKeysRead             = "apikeys:read"
+	ActionApiKeysWrite            = "apikeys:write"
+	ActionPluginsWrite            = "plugins:write"
+	ActionAnnotationsCreate       = "annotations:create"
+	ActionAnnotationsWrite        = "annotations:write"
+	ActionAnnotationsDelete       = "annotations:delete"
+	ActionAlertsPause             = "alerts:pause"
+	ActionAlertNotificationsRead  = "alert.notifications:read"
+	ActionAlertNotificationsWrite = "alert.notifications:write"
+	ActionPlaylistsWrite          = "playlists:write"
+	ActionRolesRead               = "roles:read"
+	ActionRolesWrite              = "roles:write"
+
+	// Server wide actions, granted to Grafana Admins
+	ActionUsersRead  = "users:read"
+	ActionUsersWrite = "users:write"
+	ActionOrgsRead   = "orgs:read"
+	ActionOrgsWrite  = "orgs:write"
+)
+
+// RoleGrafanaAdmin is the built-in role of Grafana server admins.
+const RoleGrafanaAdmin = "Grafana Admin"
+
+var viewerPermissions = []string{
+	ActionDatasourcesQuery,
+}
+
+var editorPermissions = append([]string{
+	ActionAnnotationsCreate,
+	ActionAnnotationsWrite,
+	ActionAlertsPause,
+	ActionAlertNotificationsRead,
+	ActionAlertNotificationsWrite,
+	ActionPlaylistsWrite,
+}, viewerPermissions...)
+
+var adminPermissions = append([]string{
+	ActionDatasourcesRead,
+	ActionDatasourcesWrite,
+	ActionOrgWrite,
+	ActionOrgPreferencesRead,
+	ActionOrgPreferencesWrite,
+	ActionOrgUsersRead,
+	ActionOrgUsersWrite,
+	ActionApiKeysRead,
+	ActionApiKeysWrite,
+	ActionPluginsWrite,
+	ActionAnnotationsDelete,
+	ActionRolesRead,
+	ActionRolesWrite,
+}, editorPermissions...)
+
+var grafanaAdminPermissions = []string{
+	ActionUsersRead,
+	ActionUsersWrite,
+	ActionOrgsRead,
+	ActionOrgsWrite,
+}
+
+// BuiltInRoles maps the built-in roles to their permissions, matching
+// the access the roles had before custom roles were introduced.
+var BuiltInRoles = map[string][]string{
+	string(models.ROLE_VIEWER): viewerPermissions,
+	string(models.ROLE_EDITOR): editorPermissions,
+	string(models.ROLE_ADMIN):  adminPermissions,
+	RoleGrafanaAdmin:           grafanaAdminPermissions,
+}
+
+// Actions returns the actions custom roles can grant, sorted by name. Server wide
+// actions are only granted to Grafana Admins and can't be part of org roles.
+func Actions() []string {
+	seen := make(map[string]bool)
+	actions := []string{}
+	for _, permissions := range BuiltInRoles {
+		for _, action := range permissions {
+			if !seen[action] && !IsServerWideAction(action) {
+				seen[action] = true
+				actions = append(actions, action)
+			}
+		}
+	}
+
+	sort.Strings(actions)
+	return actions
+}
+
+// IsServerWideAction returns true if the action applies to all organizations.
+func IsServerWideAction(action string) bool {
+	for _, serverWide := range grafanaAdminPermissions {
+		if serverWide == action {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidAction returns true if the action is a known action custom roles can grant.
+func IsValidAction(action string) bool {
+	for _, known := range Actions() {
+		if known == action {
+			return true
+		}
+	}
+	return false
+}
+
+func builtInPermissions(user *models.SignedInUser) []string {
+	permissions := append([]string{}, BuiltInRoles[string(user.OrgRole)]...)
+	if user.IsGrafanaAdmin {
+		permissions = append(permissions, BuiltInRoles[RoleGrafanaAdmin]...)
+	}
+	return permissions
+}
+
+// HasPermission returns true if the user is allowed to perform the action.
+// Custom roles are only looked up when the built-in roles don't grant the action.
+func HasPermission(user *models.SignedInUser, action string) (bool, error) {
+	return hasPermission(user, action, customPermissions)
+}
+
+// GetPermissions returns all permissions of the user, sorted by name.
+func GetPermissions(user *models.SignedInUser) ([]string, error) {
+	return getPermissions(user, customPermissions)
+}
+
+// ContextHasPermission is HasPermission for the signed in user of a request. The custom
+// permissions of the user are looked up at most once per request.
+func ContextHasPermission(c *models.ReqContext, action string) (bool, error) {
+	return hasPermission(c.SignedInUser, action, contextCustomPermissions(c))
+}
+
+// ContextGetPermissions is GetPermissions for the signed in user of a request. The custom
+// permissions of the user are looked up at most once per request.
+func ContextGetPermissions(c *models.ReqContext) ([]string, error) {
+	return getPermissions(c.SignedInUser, contextCustomPermissions(c))
+}
+
+const customPermissionsDataKey = "accesscontrol.customPermissions"
+
+// contextCustomPermissions returns a lookup of custom permissions that caches its result
+// in the data of the request.
+func contextCustomPermissions(c *models.ReqContext) func(*models.SignedInUser) ([]string, error) {
+	return func(user *models.SignedInUser) ([]string, error) {
+		if c.Context == nil {
+			return customPermissions(user)
+		}
+		if cached, ok := c.Data[customPermissionsDataKey].([]string); ok {
+			return cached, nil
+		}
+
+		permissions, err := customPermissions(user)
+		if err != nil {
+			return nil, err
+		}
+		if c.Data == nil {
+			c.Data = make(map[string]interface{})
+		}
+		c.Data[customPermissionsDataKey] = permissions
+		return permissions, nil
+	}
+}
+
+func hasPermission(user *models.SignedInUser, action string, custom func(*models.SignedInUser) ([]string, error)) (bool, error) {
+	for _, permission := range builtInPermissions(user) {
+		if permission == action {
+			return true, nil
+		}
+	}
+
+	if user.UserId == 0 && user.ApiKeyId == 0 {
+		return false, nil
+	}
+
+	permissions, err := custom(user)
+	if err != nil {
+		return false, err
+	}
+
+	for _, permission := range permissions {
+		if permission == action {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func getPermissions(user *models.SignedInUser, custom func(*models.SignedInUser) ([]string, error)) ([]string, error) {
+	permissions := builtInPermissions(user)
+
+	if user.UserId != 0 || user.ApiKeyId != 0 {
+		customs, err := custom(user)
+		if err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, customs...)
+	}
+
+	seen := make(map[string]bool)
+	result := []string{}
+	for _, permission := range permissions {
+		if !seen[permission] {
+			seen[permission] = true
+			result = append(result, permission)
+		}
+	}
+
+	sort.Strings(result)
+	return result, nil
+}
+
+func customPermissions(user *models.SignedInUser) ([]string, error) {
+	query := models.GetCustomPermissionsQuery{
+		OrgId:    user.OrgId,
+		UserId:   user.UserId,
+		TeamIds:  user.Teams,
+		ApiKeyId: user.ApiKeyId,
+	}
+
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	// roles are scoped to an org, so they never grant server wide actions
+	permissions := make([]string, 0, len(query.Result))
+	for _, permission := range query.Result {
+		if !IsServerWideAction(permission) {
+			permissions = append(permissions, permission)
+		}
+	}
+	return permissions, nil
+}
@@ -0,0 +1,130 @@
+package accesscontrol
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/macaron.v1"
+)
+
+func TestHasPermission(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+
+	custom := map[int64][]string{}
+	bus.AddHandler("test", func(query *models.GetCustomPermissionsQuery) error {
+		if query.UserId == 99 {
+			return errors.New("database is down")
+		}
+		query.Result = custom[query.UserId]
+		return nil
+	})
+
+	t.Run("Built-in roles keep their existing access", func(t *testing.T) {
+		viewer := &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_VIEWER}
+		editor := &models.SignedInUser{UserId: 2, OrgId: 1, OrgRole: models.ROLE_EDITOR}
+		admin := &models.SignedInUser{UserId: 3, OrgId: 1, OrgRole: models.ROLE_ADMIN}
+
+		cases := []struct {
+			user    *models.SignedInUser
+			action  string
+			allowed bool
+		}{
+			{viewer, ActionDatasourcesQuery, true},
+			{viewer, ActionAnnotationsCreate, false},
+			{editor, ActionAnnotationsCreate, true},
+			{editor, ActionDatasourcesWrite, false},
+			{admin, ActionDatasourcesWrite, true},
+			{admin, ActionDatasourcesQuery, true},
+			{admin, ActionUsersWrite, false},
+		}
+
+		for _, tc := range cases {
+			ok, err := HasPermission(tc.user, tc.action)
+			require.NoError(t, err)
+			assert.Equal(t, tc.allowed, ok, "%s %s", tc.user.OrgRole, tc.action)
+		}
+	})
+
+	t.Run("Grafana Admins get server wide permissions", func(t *testing.T) {
+		user := &models.SignedInUser{UserId: 4, OrgId: 1, OrgRole: models.ROLE_VIEWER, IsGrafanaAdmin: true}
+
+		ok, err := HasPermission(user, ActionOrgsWrite)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("Custom roles add permissions", func(t *testing.T) {
+		custom[5] = []string{ActionDatasourcesRead}
+		user := &models.SignedInUser{UserId: 5, OrgId: 1, OrgRole: models.ROLE_VIEWER}
+
+		ok, err := HasPermission(user, ActionDatasourcesRead)
+		require.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = HasPermission(user, ActionDatasourcesWrite)
+		require.NoError(t, err)
+		assert.False(t, ok)
+
+		permissions, err := GetPermissions(user)
+		require.NoError(t, err)
+		assert.Equal(t, []string{ActionDatasourcesQuery, ActionDatasourcesRead}, permissions)
+	})
+
+	t.Run("Should return error when custom permissions can't be loaded", func(t *testing.T) {
+		user := &models.SignedInUser{UserId: 99, OrgId: 1, OrgRole: models.ROLE_VIEWER}
+
+		_, err := HasPermission(user, ActionDatasourcesWrite)
+		assert.Error(t, err)
+	})
+
+	t.Run("Actions are all known", func(t *testing.T) {
+		assert.True(t, IsValidAction(ActionRolesWrite))
+		assert.False(t, IsValidAction("dashboards:fly"))
+	})
+
+	t.Run("Server wide actions can't be granted by custom roles", func(t *testing.T) {
+		assert.False(t, IsValidAction(ActionUsersWrite))
+		assert.NotContains(t, Actions(), ActionOrgsWrite)
+
+		custom[6] = []string{ActionDatasourcesRead, ActionUsersWrite}
+		user := &models.SignedInUser{UserId: 6, OrgId: 1, OrgRole: models.ROLE_ADMIN}
+
+		ok, err := HasPermission(user, ActionUsersWrite)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
+
+func TestContextHasPermission(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+
+	lookups := 0
+	bus.AddHandler("test", func(query *models.GetCustomPermissionsQuery) error {
+		lookups++
+		query.Result = []string{ActionDatasourcesRead}
+		return nil
+	})
+
+	c := &models.ReqContext{
+		Context:      &macaron.Context{Data: map[string]interface{}{}},
+		SignedInUser: &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_VIEWER},
+	}
+
+	ok, err := ContextHasPermission(c, ActionDatasourcesWrite)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	ok, err = ContextHasPermission(c, ActionDatasourcesRead)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	permissions, err := ContextGetPermissions(c)
+	require.NoError(t, err)
+	assert.Contains(t, permissions, ActionDatasourcesRead)
+
+	assert.Equal(t, 1, lookups, "custom permissions should be looked up once per request")
+}
@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/inconshreveable/log15"
+)
+
+func init() {
+	registry.RegisterService(&AuditService{})
+}
+
+// AuditService records security relevant and configuration changing actions
+// to the configured sinks.
+type AuditService struct {
+	Cfg *setting.Cfg `inject:""`
+
+	log   log.Logger
+	sinks []sink
+}
+
+func (s *AuditService) Init() error {
+	s.log = log.New("audit")
+
+	if !s.Cfg.AuditEnabled {
+		return nil
+	}
+
+	for _, name := range s.Cfg.AuditSinks {
+		sink, err := s.newSink(name)
+		if err != nil {
+			return err
+		}
+		s.sinks = append(s.sinks, sink)
+	}
+
+	return nil
+}
+
+func (s *AuditService) newSink(name string) (sink, error) {
+	switch name {
+	case "database":
+		return &databaseSink{}, nil
+	case "file":
+		sec := s.Cfg.Raw.Section("audit.file")
+		fileName := sec.Key("file_name").MustString(filepath.Join(s.Cfg.LogsPath, "audit.log"))
+		if err := os.MkdirAll(filepath.Dir(fileName), os.ModePerm); err != nil {
+			return nil, fmt.Errorf("failed to create audit log directory: %w", err)
+		}
+
+		fileHandler := log.NewFileWriter()
+		fileHandler.Filename = fileName
+		fileHandler.Format = log15.JsonFormat()
+		fileHandler.Rotate = sec.Key("log_rotate").MustBool(true)
+		fileHandler.Maxlines = sec.Key("max_lines").MustInt(1000000)
+		fileHandler.Maxsize = 1 << uint(sec.Key("max_size_shift").MustInt(28))
+		fileHandler.Daily = sec.Key("daily_rotate").MustBool(true)
+		fileHandler.Maxdays = sec.Key("max_days").MustInt64(7)
+		if err := fileHandler.Init(); err != nil {
+			return nil, fmt.Errorf("failed to initialize audit log file: %w", err)
+		}
+
+		return newLogSink(fileHandler), nil
+	case "syslog":
+		sec := s.Cfg.Raw.Section("audit.syslog")
+		return newLogSink(log.NewSyslog(sec, log15.JsonFormat())), nil
+	default:
+		return nil, fmt.Errorf("unknown audit sink %q", name)
+	}
+}
+
+// Record writes the entry to all configured sinks. Failures are logged
+// but do not affect the request being audited.
+func (s *AuditService) Record(entry *models.AuditEntry) {
+	for _, sink := range s.sinks {
+		if err := sink.Write(entry); err != nil {
+			s.log.Error("Failed to write audit entry", "sink", sink.Name(), "action", entry.Action, "error", err)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package audit
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	macaron "gopkg.in/macaron.v1"
+)
+
+func TestShouldAudit(t *testing.T) {
+	assert.True(t, shouldAudit(http.MethodPost, "/api/datasources"))
+	assert.True(t, shouldAudit(http.MethodDelete, "/api/dashboards/uid/abc"))
+	assert.False(t, shouldAudit(http.MethodGet, "/api/datasources"))
+	assert.False(t, shouldAudit(http.MethodPost, "/api/ds/query"))
+	assert.False(t, shouldAudit(http.MethodPost, "/api/datasources/proxy/1/api/v1/query"))
+	assert.False(t, shouldAudit(http.MethodPost, "/login"))
+}
+
+func TestParseResource(t *testing.T) {
+	tests := []struct {
+		path         string
+		resourceType string
+		resourceID   string
+	}{
+		{"/api/datasources/3", "datasources", "3"},
+		{"/api/datasources/name/prom", "datasources", "prom"},
+		{"/api/dashboards/uid/abc/permissions", "dashboards", "abc"},
+		{"/api/dashboards/db", "dashboards", ""},
+		{"/api/admin/users/2/permissions", "users", "2"},
+		{"/api/org/users/7", "org_users", "7"},
+		{"/api/admin/pause-all-alerts", "pause-all-alerts", ""},
+		{"/api/auth/keys", "auth", ""},
+	}
+
+	for _, tc := range tests {
+		res := parseResource(tc.path)
+		assert.Equal(t, tc.resourceType, res.Type, tc.path)
+		assert.Equal(t, tc.resourceID, res.Id, tc.path)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	json := simplejson.NewFromAny(map[string]interface{}{
+		"name":           "prometheus",
+		"password":       "secret",
+		"secureJsonData": map[string]interface{}{"apiKey": "key"},
+		"jsonData": map[string]interface{}{
+			"tlsAuth":      true,
+			"clientSecret": "secret",
+		},
+		"users": []interface{}{
+			map[string]interface{}{"login": "admin", "oldPassword": "admin"},
+		},
+	})
+
+	redact(json)
+
+	assert.Equal(t, "prometheus", json.Get("name").MustString())
+	assert.Equal(t, redactedValue, json.Get("password").MustString())
+	assert.Equal(t, redactedValue, json.Get("secureJsonData").MustString())
+	assert.Equal(t, redactedValue, json.GetPath("jsonData", "clientSecret").MustString())
+	assert.True(t, json.GetPath("jsonData", "tlsAuth").MustBool())
+	assert.Equal(t, redactedValue, json.Get("users").GetIndex(0).Get("oldPassword").MustString())
+}
+
+func TestReadRequestBody(t *testing.T) {
+	body := `{"name":"test","basicAuthPassword":"pwd"}`
+	req, err := http.NewRequest(http.MethodPost, "/api/datasources", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json;charset=UTF-8")
+
+	json := readRequestBody(req)
+	require.NotNil(t, json)
+	assert.Equal(t, "test", json.Get("name").MustString())
+	assert.Equal(t, redactedValue, json.Get("basicAuthPassword").MustString())
+
+	restored, err := ioutil.ReadAll(req.Body)
+	require.NoError(t, err)
+	assert.Equal(t, body, string(restored))
+}
+
+type fakeSink struct {
+	entries []*models.AuditEntry
+}
+
+func (s *fakeSink) Name() string {
+	return "fake"
+}
+
+func (s *fakeSink) Write(entry *models.AuditEntry) error {
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+	t.Cleanup(bus.ClearBusHandlers)
+
+	version := 1
+	loads := 0
+	bus.AddHandler("test", func(query *models.GetDashboardQuery) error {
+		loads++
+		query.Result = &models.Dashboard{
+			Data: simplejson.NewFromAny(map[string]interface{}{"uid": query.Uid, "version": version}),
+		}
+		return nil
+	})
+
+	setup := func(authorized bool) (*macaron.Macaron, *fakeSink) {
+		fake := &fakeSink{}
+		s := &AuditService{Cfg: &setting.Cfg{AuditEnabled: true}, sinks: []sink{fake}}
+
+		m := macaron.New()
+		m.Use(macaron.Renderer())
+		m.Use(func(c *macaron.Context) {
+			c.Map(&models.ReqContext{Context: c, SignedInUser: &models.SignedInUser{OrgId: 1}})
+		})
+		m.Use(s.Middleware())
+		m.Post("/api/dashboards/db", func(c *models.ReqContext) {
+			if !authorized {
+				c.JsonApiErr(403, "Permission denied", nil)
+			}
+		}, func(c *models.ReqContext) {
+			CaptureBeforeState(c)
+			version++
+			c.JSON(200, map[string]interface{}{"status": "success"})
+		})
+
+		return m, fake
+	}
+
+	request := func(m *macaron.Macaron) {
+		req, err := http.NewRequest(http.MethodPost, "/api/dashboards/db",
+			bytes.NewBufferString(`{"dashboard":{"uid":"abc","title":"test"}}`))
+		require.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+		m.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	t.Run("records the dashboard state before and after a save", func(t *testing.T) {
+		m, sink := setup(true)
+		request(m)
+
+		require.Len(t, sink.entries, 1)
+		entry := sink.entries[0]
+		assert.Equal(t, "dashboards", entry.ResourceType)
+		assert.Equal(t, "abc", entry.ResourceId)
+		require.NotNil(t, entry.BeforeState)
+		require.NotNil(t, entry.AfterState)
+		assert.Equal(t, entry.BeforeState.Get("version").MustInt()+1, entry.AfterState.Get("version").MustInt())
+	})
+
+	t.Run("doesn't load the state of resources for rejected requests", func(t *testing.T) {
+		loads = 0
+		m, sink := setup(false)
+		request(m)
+
+		require.Len(t, sink.entries, 1)
+		assert.Equal(t, 403, sink.entries[0].Status)
+		assert.Nil(t, sink.entries[0].BeforeState)
+		assert.Nil(t, sink.entries[0].AfterState)
+		assert.Equal(t, 0, loads)
+	})
+}
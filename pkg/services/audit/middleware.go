@@ -0,0 +1,166 @@
+package audit
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/util"
+	macaron "gopkg.in/macaron.v1"
+)
+
+// maxAuditedBodySize is the maximum size of a request body that is recorded
+// as the after state of a resource.
+const maxAuditedBodySize = 64 * 1024
+
+// excludedPaths are API paths using mutating methods that don't change any state.
+var excludedPaths = []string{
+	"/api/tsdb/",
+	"/api/ds/query",
+	"/api/datasources/proxy/",
+	"/api/plugin-proxy/",
+	"/api/dashboards/calculate-diff",
+	"/api/alerts/test",
+	"/api/alert-notifications/test",
+	"/api/frontend-metrics",
+}
+
+// pendingEntryKey is the context data key of the request currently being audited.
+const pendingEntryKey = "audit.pendingEntry"
+
+type pendingEntry struct {
+	resource resource
+	before   *simplejson.Json
+	captured bool
+}
+
+// Middleware returns a handler recording all mutating API requests to the audit log.
+// It needs to be added after the context handler.
+func (s *AuditService) Middleware() macaron.Handler {
+	return func(c *models.ReqContext) {
+		if !s.Cfg.AuditEnabled || !shouldAudit(c.Req.Method, c.Req.URL.Path) {
+			return
+		}
+
+		body := readRequestBody(c.Req.Request)
+		res := parseResource(c.Req.URL.Path)
+		if res.Id == "" {
+			res = resourceFromBody(res, c.Req.URL.Path, body)
+		}
+
+		pending := &pendingEntry{resource: res}
+		c.Data[pendingEntryKey] = pending
+
+		c.Next()
+
+		status := c.Resp.Status()
+		before := pending.before
+		if status == http.StatusUnauthorized || status == http.StatusForbidden {
+			before = nil
+		}
+
+		var after *simplejson.Json
+		if status < 400 {
+			after = res.load(c.OrgId)
+			if after == nil && c.Req.Method != http.MethodDelete {
+				after = body
+			}
+		}
+
+		actorType, actorID, actorLogin := actorFromContext(c)
+		clientIP, err := util.ParseIPAddress(c.RemoteAddr())
+		if err != nil {
+			clientIP = ""
+		}
+
+		s.Record(&models.AuditEntry{
+			OrgId:        c.OrgId,
+			ActorType:    actorType,
+			ActorId:      actorID,
+			ActorLogin:   actorLogin,
+			Action:       c.Req.Method + " " + c.Req.URL.Path,
+			ResourceType: res.Type,
+			ResourceId:   res.Id,
+			BeforeState:  before,
+			AfterState:   after,
+			ClientIp:     clientIP,
+			Status:       status,
+		})
+	}
+}
+
+// CaptureBeforeState records the state of the resource changed by the
+// request. It's called by the route handler once the route's authorization
+// middlewares have run, so the state of resources is never loaded for
+// requests that are rejected.
+func CaptureBeforeState(c *models.ReqContext) {
+	if c.Context == nil {
+		return
+	}
+
+	pending, ok := c.Data[pendingEntryKey].(*pendingEntry)
+	if !ok || pending.captured {
+		return
+	}
+
+	pending.before = pending.resource.load(c.OrgId)
+	pending.captured = true
+}
+
+func shouldAudit(method, path string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+	default:
+		return false
+	}
+
+	if !strings.HasPrefix(path, "/api/") {
+		return false
+	}
+
+	for _, excluded := range excludedPaths {
+		if strings.HasPrefix(path, excluded) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func actorFromContext(c *models.ReqContext) (models.AuditActorType, int64, string) {
+	switch {
+	case c.IsRenderCall:
+		return models.AuditActorRender, c.UserId, c.Login
+	case c.ApiKeyId > 0:
+		return models.AuditActorApiKey, c.ApiKeyId, ""
+	case c.IsSignedIn:
+		return models.AuditActorUser, c.UserId, c.Login
+	default:
+		return models.AuditActorAnonymous, 0, ""
+	}
+}
+
+// readRequestBody returns the redacted JSON request body and restores
+// the body so it can be read by the request handler.
+func readRequestBody(req *http.Request) *simplejson.Json {
+	if req.Body == nil || !strings.HasPrefix(req.Header.Get("Content-Type"), "application/json") {
+		return nil
+	}
+
+	body, err := ioutil.ReadAll(io.LimitReader(req.Body, maxAuditedBodySize+1))
+	req.Body = ioutil.NopCloser(io.MultiReader(bytes.NewReader(body), req.Body))
+	if err != nil || len(body) == 0 || len(body) > maxAuditedBodySize {
+		return nil
+	}
+
+	json, err := simplejson.NewJson(body)
+	if err != nil {
+		return nil
+	}
+
+	return redact(json)
+}
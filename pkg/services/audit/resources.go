@@ -0,0 +1,257 @@
+package audit
+
+import (
+	"encoding/json"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+const redactedValue = "*********"
+
+// sensitiveKeys are substrings of JSON keys whose values are never written to the audit log.
+var sensitiveKeys = []string{"password", "secret", "token", "salt", "rands"}
+
+type resourceLoader func(orgID int64, id string) (interface{}, error)
+
+type resourceRoute struct {
+	pattern      *regexp.Regexp
+	resourceType string
+	load         resourceLoader
+}
+
+// resourceRoutes maps API paths to the resource they change. The first
+// submatch of the pattern is the id of the resource. Resources with a loader
+// get their state recorded before and after the change.
+var resourceRoutes = []resourceRoute{
+	{regexp.MustCompile(`^/api/datasources/name/([^/]+)`), "datasources", loadDataSourceByName},
+	{regexp.MustCompile(`^/api/datasources/(\d+)`), "datasources", loadDataSource},
+	{regexp.MustCompile(`^/api/dashboards/uid/([^/]+)`), "dashboards", loadDashboard},
+	{regexp.MustCompile(`^/api/dashboards/id/(\d+)`), "dashboards", nil},
+	{regexp.MustCompile(`^/api/folders/([^/]+)`), "folders", loadDashboard},
+	{regexp.MustCompile(`^/api/teams/(\d+)`), "teams", loadTeam},
+	{regexp.MustCompile(`^/api/admin/users/(\d+)`), "users", loadUser},
+	{regexp.MustCompile(`^/api/users/(\d+)`), "users", loadUser},
+	{regexp.MustCompile(`^/api/org/users/(\d+)`), "org_users", loadOrgUser},
+	{regexp.MustCompile(`^/api/orgs/(\d+)`), "orgs", nil},
+	{regexp.MustCompile(`^/api/auth/keys/(\d+)`), "api_keys", nil},
+	{regexp.MustCompile(`^/api/alert-notifications/uid/([^/]+)`), "alert_notifications", nil},
+	{regexp.MustCompile(`^/api/alert-notifications/(\d+)`), "alert_notifications", nil},
+	{regexp.MustCompile(`^/api/annotations/(\d+)`), "annotations", nil},
+	{regexp.MustCompile(`^/api/playlists/(\d+)`), "playlists", nil},
+	{regexp.MustCompile(`^/api/plugins/([^/]+)`), "plugins", nil},
+	{regexp.MustCompile(`^/api/snapshots/([^/]+)`), "snapshots", nil},
+}
+
+type resource struct {
+	Type   string
+	Id     string
+	loader resourceLoader
+}
+
+func parseResource(path string) resource {
+	for _, route := range resourceRoutes {
+		if match := route.pattern.FindStringSubmatch(path); match != nil {
+			return resource{Type: route.resourceType, Id: match[1], loader: route.load}
+		}
+	}
+
+	segments := strings.Split(strings.TrimPrefix(path, "/api/"), "/")
+	if segments[0] == "admin" && len(segments) > 1 {
+		return resource{Type: segments[1]}
+	}
+
+	return resource{Type: segments[0]}
+}
+
+// resourceFromBody returns the resource changed by requests that pass the id
+// of the resource in the request body instead of the path.
+func resourceFromBody(res resource, path string, body *simplejson.Json) resource {
+	if body == nil {
+		return res
+	}
+
+	switch path {
+	case "/api/dashboards/db":
+		dashboard := body.Get("dashboard")
+		if uid := dashboard.Get("uid").MustString(); uid != "" {
+			return resource{Type: "dashboards", Id: uid, loader: loadDashboard}
+		}
+		if id := dashboard.Get("id").MustInt64(); id > 0 {
+			return resource{Type: "dashboards", Id: strconv.FormatInt(id, 10), loader: loadDashboardByID}
+		}
+	}
+
+	return res
+}
+
+// load returns the redacted current state of the resource, or nil if the
+// resource doesn't exist or its state isn't audited.
+func (r resource) load(orgID int64) *simplejson.Json {
+	if r.loader == nil || r.Id == "" {
+		return nil
+	}
+
+	state, err := r.loader(orgID, r.Id)
+	if err != nil || state == nil {
+		return nil
+	}
+
+	data, err := json.Marshal(state)
+	if err != nil {
+		return nil
+	}
+
+	json, err := simplejson.NewJson(data)
+	if err != nil {
+		return nil
+	}
+
+	return redact(json)
+}
+
+// redact replaces the values of sensitive keys and all secure JSON data.
+func redact(json *simplejson.Json) *simplejson.Json {
+	redactValue(json.Interface())
+	return json
+}
+
+func redactValue(value interface{}) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if isSensitiveKey(key) {
+				v[key] = redactedValue
+				continue
+			}
+			redactValue(child)
+		}
+	case []interface{}:
+		for _, child := range v {
+			redactValue(child)
+		}
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	lower := strings.ToLower(key)
+	if strings.HasPrefix(lower, "securejson") {
+		return true
+	}
+
+	for _, sensitive := range sensitiveKeys {
+		if strings.Contains(lower, sensitive) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func loadDataSource(orgID int64, id string) (interface{}, error) {
+	dsID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	query := models.GetDataSourceByIdQuery{Id: dsID, OrgId: orgID}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	return query.Result, nil
+}
+
+func loadDataSourceByName(orgID int64, name string) (interface{}, error) {
+	query := models.GetDataSourceByNameQuery{Name: name, OrgId: orgID}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	return query.Result, nil
+}
+
+func loadDashboard(orgID int64, uid string) (interface{}, error) {
+	query := models.GetDashboardQuery{Uid: uid, OrgId: orgID}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	return query.Result.Data, nil
+}
+
+func loadDashboardByID(orgID int64, id string) (interface{}, error) {
+	dashboardID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	query := models.GetDashboardQuery{Id: dashboardID, OrgId: orgID}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	return query.Result.Data, nil
+}
+
+func loadTeam(orgID int64, id string) (interface{}, error) {
+	teamID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	query := models.GetTeamByIdQuery{Id: teamID, OrgId: orgID}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	return query.Result, nil
+}
+
+func loadUser(orgID int64, id string) (interface{}, error) {
+	userID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	query := models.GetUserByIdQuery{Id: userID}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	user := query.Result
+	return map[string]interface{}{
+		"login":          user.Login,
+		"email":          user.Email,
+		"name":           user.Name,
+		"isGrafanaAdmin": user.IsAdmin,
+		"isDisabled":     user.IsDisabled,
+	}, nil
+}
+
+func loadOrgUser(orgID int64, id string) (interface{}, error) {
+	userID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	query := models.GetSignedInUserQuery{UserId: userID, OrgId: orgID}
+	if err := bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	// users that aren't members of the org are returned without an org
+	if query.Result.OrgId != orgID {
+		return nil, nil
+	}
+
+	return map[string]interface{}{
+		"login": query.Result.Login,
+		"orgId": query.Result.OrgId,
+		"role":  query.Result.OrgRole,
+	}, nil
+}
@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/inconshreveable/log15"
+)
+
+type sink interface {
+	Name() string
+	Write(entry *models.AuditEntry) error
+}
+
+// databaseSink stores audit entries in the Grafana database, which makes
+// them available through the audit API.
+type databaseSink struct{}
+
+func (s *databaseSink) Name() string {
+	return "database"
+}
+
+func (s *databaseSink) Write(entry *models.AuditEntry) error {
+	return bus.Dispatch(&models.CreateAuditEntryCommand{Entry: entry})
+}
+
+// logSink writes audit entries as JSON records to a log15 handler,
+// for example a file or syslog.
+type logSink struct {
+	logger log15.Logger
+}
+
+func newLogSink(handler log15.Handler) *logSink {
+	logger := log15.New()
+	logger.SetHandler(handler)
+	return &logSink{logger: logger}
+}
+
+func (s *logSink) Name() string {
+	return "log"
+}
+
+func (s *logSink) Write(entry *models.AuditEntry) error {
+	s.logger.Info("audit",
+		"orgId", entry.OrgId,
+		"actorType", entry.ActorType,
+		"actorId", entry.ActorId,
+		"actorLogin", entry.ActorLogin,
+		"action", entry.Action,
+		"resourceType", entry.ResourceType,
+		"resourceId", entry.ResourceId,
+		"before", entry.BeforeState,
+		"after", entry.AfterState,
+		"clientIp", entry.ClientIp,
+		"status", entry.Status)
+	return nil
+}
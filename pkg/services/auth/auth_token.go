@@ -82,9 +82,12 @@ func (s *UserAuthTokenService) CreateToken(ctx context.Context, userId int64, cl
 		AuthTokenSeen: false,
 	}
 
-	err = s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
-		_, err = dbSession.Insert(&userAuthToken)
-		return err
+	err = s.SQLStore.WithTransactionalDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		if _, err := dbSession.Insert(&userAuthToken); err != nil {
+			return err
+		}
+
+		return s.enforceConcurrentSessionLimit(dbSession, userId)
 	})
 
 	if err != nil {
@@ -101,6 +104,39 @@ func (s *UserAuthTokenService) CreateToken(ctx context.Context, userId int64, cl
 	return &userToken, err
 }
 
+// enforceConcurrentSessionLimit revokes the oldest tokens of the user when the user
+// has more active sessions than allowed by login_maximum_concurrent_sessions.
+func (s *UserAuthTokenService) enforceConcurrentSessionLimit(dbSession *sqlstore.DBSession, userId int64) error {
+	if s.Cfg.LoginMaxConcurrentSessions <= 0 {
+		return nil
+	}
+
+	var tokens []*userAuthToken
+	err := dbSession.Where("user_id = ?", userId).Desc("created_at").Desc("id").Cols("id").Find(&tokens)
+	if err != nil {
+		return err
+	}
+
+	if len(tokens) <= s.Cfg.LoginMaxConcurrentSessions {
+		return nil
+	}
+
+	evicted := tokens[s.Cfg.LoginMaxConcurrentSessions:]
+	ids := make([]int64, 0, len(evicted))
+	for _, token := range evicted {
+		ids = append(ids, token.Id)
+	}
+
+	affected, err := dbSession.In("id", ids).Delete(&userAuthToken{})
+	if err != nil {
+		return err
+	}
+
+	s.log.Debug("revoked user auth tokens exceeding concurrent session limit", "userId", userId, "count", affected)
+
+	return nil
+}
+
 func (s *UserAuthTokenService) LookupToken(ctx context.Context, unhashedToken string) (*models.UserToken, error) {
 	hashedToken := hashToken(unhashedToken)
 	if setting.Env == setting.DEV {
@@ -353,6 +389,81 @@ func (s *UserAuthTokenService) BatchRevokeAllUserTokens(ctx context.Context, use
 	})
 }
 
+// RevokeAllOrgUserTokens revokes the tokens of the members of the organization. Tokens don't
+// belong to an organization, so the members are logged out of all their organizations.
+func (s *UserAuthTokenService) RevokeAllOrgUserTokens(ctx context.Context, orgId int64) error {
+	return s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		sql := `DELETE from user_auth_token WHERE user_id IN (SELECT user_id FROM org_user WHERE org_id = ?)`
+		res, err := dbSession.Exec(sql, orgId)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		s.log.Info("all user tokens for org revoked", "orgId", orgId, "count", affected)
+
+		return nil
+	})
+}
+
+func (s *UserAuthTokenService) RevokeAllTokens(ctx context.Context) error {
+	return s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
+		res, err := dbSession.Exec(`DELETE from user_auth_token`)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		s.log.Info("all user tokens revoked", "count", affected)
+
+		return nil
+	})
+}
+
+// ValidateSessionPolicy checks the token against the session policy configured for the
+// role of the user in the organization, falling back to the policy of the role in every organization.
+// Tokens that have outlived the policy are revoked and ErrUserTokenExpired is returned.
+func (s *UserAuthTokenService) ValidateSessionPolicy(ctx context.Context, token *models.UserToken, orgId int64, role models.RoleType) error {
+	policy, exists := s.Cfg.LoginRoleSessionPolicies[setting.SessionPolicyKey{OrgId: orgId, Role: string(role)}]
+	if !exists {
+		policy, exists = s.Cfg.LoginRoleSessionPolicies[setting.SessionPolicyKey{Role: string(role)}]
+	}
+	if !exists || token == nil {
+		return nil
+	}
+
+	now := getTime()
+	expired := false
+	if policy.MaxLifetimeDays > 0 {
+		maxLifetime := time.Duration(policy.MaxLifetimeDays) * 24 * time.Hour
+		expired = token.CreatedAt <= now.Add(-maxLifetime).Unix()
+	}
+	if policy.MaxInactiveLifetimeDays > 0 {
+		maxInactiveLifetime := time.Duration(policy.MaxInactiveLifetimeDays) * 24 * time.Hour
+		expired = expired || token.RotatedAt <= now.Add(-maxInactiveLifetime).Unix()
+	}
+
+	if !expired {
+		return nil
+	}
+
+	s.log.Debug("user auth token expired by session policy", "tokenId", token.Id, "userId", token.UserId, "orgId", orgId, "role", role)
+
+	if err := s.RevokeToken(ctx, token); err != nil && err != models.ErrUserTokenNotFound {
+		return err
+	}
+
+	return models.ErrUserTokenExpired
+}
+
 func (s *UserAuthTokenService) GetUserToken(ctx context.Context, userId, userTokenId int64) (*models.UserToken, error) {
 	var result models.UserToken
 	err := s.SQLStore.WithDbSession(ctx, func(dbSession *sqlstore.DBSession) error {
@@ -140,6 +140,103 @@ func TestUserAuthToken(t *testing.T) {
 			})
 		})
 
+		Convey("When revoking all tokens", func() {
+			userToken, err := userAuthTokenService.CreateToken(context.Background(), userID, "192.168.10.11:1234", "some user agent")
+			So(err, ShouldBeNil)
+			userToken2, err := userAuthTokenService.CreateToken(context.Background(), userID+1, "192.168.10.11:1234", "some user agent")
+			So(err, ShouldBeNil)
+
+			err = userAuthTokenService.RevokeAllTokens(context.Background())
+			So(err, ShouldBeNil)
+
+			count, err := userAuthTokenService.ActiveTokenCount(context.Background())
+			So(err, ShouldBeNil)
+			So(count, ShouldEqual, 0)
+
+			model, err := ctx.getAuthTokenByID(userToken.Id)
+			So(err, ShouldBeNil)
+			So(model, ShouldBeNil)
+
+			model2, err := ctx.getAuthTokenByID(userToken2.Id)
+			So(err, ShouldBeNil)
+			So(model2, ShouldBeNil)
+		})
+
+		Convey("When concurrent session limit is configured", func() {
+			userAuthTokenService.Cfg.LoginMaxConcurrentSessions = 2
+
+			tokens := []*models.UserToken{}
+			for i := 0; i < 3; i++ {
+				getTime = func() time.Time {
+					return t.Add(time.Duration(i) * time.Minute)
+				}
+				userToken, err := userAuthTokenService.CreateToken(context.Background(), userID, "192.168.10.11:1234", "some user agent")
+				So(err, ShouldBeNil)
+				tokens = append(tokens, userToken)
+			}
+
+			Convey("should revoke the oldest tokens", func() {
+				model, err := ctx.getAuthTokenByID(tokens[0].Id)
+				So(err, ShouldBeNil)
+				So(model, ShouldBeNil)
+
+				userTokens, err := userAuthTokenService.GetUserTokens(context.Background(), userID)
+				So(err, ShouldBeNil)
+				So(userTokens, ShouldHaveLength, 2)
+				So(userTokens[0].Id, ShouldEqual, tokens[1].Id)
+				So(userTokens[1].Id, ShouldEqual, tokens[2].Id)
+			})
+		})
+
+		Convey("When a session policy is configured for a role", func() {
+			userAuthTokenService.Cfg.LoginRoleSessionPolicies = map[setting.SessionPolicyKey]setting.SessionPolicy{
+				{Role: string(models.ROLE_ADMIN)}:           {MaxInactiveLifetimeDays: 1},
+				{OrgId: 2, Role: string(models.ROLE_ADMIN)}: {MaxInactiveLifetimeDays: 2},
+			}
+
+			userToken, err := userAuthTokenService.CreateToken(context.Background(), userID, "192.168.10.11:1234", "some user agent")
+			So(err, ShouldBeNil)
+
+			getTime = func() time.Time {
+				return t.Add(25 * time.Hour)
+			}
+
+			Convey("should not affect other roles", func() {
+				err := userAuthTokenService.ValidateSessionPolicy(context.Background(), userToken, 1, models.ROLE_VIEWER)
+				So(err, ShouldBeNil)
+			})
+
+			Convey("should expire and revoke token for role", func() {
+				err := userAuthTokenService.ValidateSessionPolicy(context.Background(), userToken, 1, models.ROLE_ADMIN)
+				So(err, ShouldEqual, models.ErrUserTokenExpired)
+
+				model, err := ctx.getAuthTokenByID(userToken.Id)
+				So(err, ShouldBeNil)
+				So(model, ShouldBeNil)
+			})
+
+			Convey("should not expire token within the policy lifetime", func() {
+				getTime = func() time.Time {
+					return t.Add(23 * time.Hour)
+				}
+
+				err := userAuthTokenService.ValidateSessionPolicy(context.Background(), userToken, 1, models.ROLE_ADMIN)
+				So(err, ShouldBeNil)
+			})
+
+			Convey("should use the policy of the organization", func() {
+				err := userAuthTokenService.ValidateSessionPolicy(context.Background(), userToken, 2, models.ROLE_ADMIN)
+				So(err, ShouldBeNil)
+
+				getTime = func() time.Time {
+					return t.Add(49 * time.Hour)
+				}
+
+				err = userAuthTokenService.ValidateSessionPolicy(context.Background(), userToken, 2, models.ROLE_ADMIN)
+				So(err, ShouldEqual, models.ErrUserTokenExpired)
+			})
+		})
+
 		Convey("expires correctly", func() {
 			userToken, err := userAuthTokenService.CreateToken(context.Background(), userID, "192.168.10.11:1234", "some user agent")
 			So(err, ShouldBeNil)
@@ -12,6 +12,9 @@ type FakeUserAuthTokenService struct {
 	LookupTokenProvider         func(ctx context.Context, unhashedToken string) (*models.UserToken, error)
 	RevokeTokenProvider         func(ctx context.Context, token *models.UserToken) error
 	RevokeAllUserTokensProvider func(ctx context.Context, userId int64) error
+	RevokeAllOrgTokensProvider  func(ctx context.Context, orgId int64) error
+	RevokeAllTokensProvider     func(ctx context.Context) error
+	ValidateSessionProvider     func(ctx context.Context, token *models.UserToken, orgId int64, role models.RoleType) error
 	ActiveAuthTokenCount        func(ctx context.Context) (int64, error)
 	GetUserTokenProvider        func(ctx context.Context, userId, userTokenId int64) (*models.UserToken, error)
 	GetUserTokensProvider       func(ctx context.Context, userId int64) ([]*models.UserToken, error)
@@ -41,6 +44,15 @@ func NewFakeUserAuthTokenService() *FakeUserAuthTokenService {
 		RevokeAllUserTokensProvider: func(ctx context.Context, userId int64) error {
 			return nil
 		},
+		RevokeAllOrgTokensProvider: func(ctx context.Context, orgId int64) error {
+			return nil
+		},
+		RevokeAllTokensProvider: func(ctx context.Context) error {
+			return nil
+		},
+		ValidateSessionProvider: func(ctx context.Context, token *models.UserToken, orgId int64, role models.RoleType) error {
+			return nil
+		},
 		BatchRevokedTokenProvider: func(ctx context.Context, userIds []int64) error {
 			return nil
 		},
@@ -76,6 +88,18 @@ func (s *FakeUserAuthTokenService) RevokeAllUserTokens(ctx context.Context, user
 	return s.RevokeAllUserTokensProvider(context.Background(), userId)
 }
 
+func (s *FakeUserAuthTokenService) RevokeAllOrgUserTokens(ctx context.Context, orgId int64) error {
+	return s.RevokeAllOrgTokensProvider(context.Background(), orgId)
+}
+
+func (s *FakeUserAuthTokenService) RevokeAllTokens(ctx context.Context) error {
+	return s.RevokeAllTokensProvider(context.Background())
+}
+
+func (s *FakeUserAuthTokenService) ValidateSessionPolicy(ctx context.Context, token *models.UserToken, orgId int64, role models.RoleType) error {
+	return s.ValidateSessionProvider(context.Background(), token, orgId, role)
+}
+
 func (s *FakeUserAuthTokenService) ActiveTokenCount(ctx context.Context) (int64, error) {
 	return s.ActiveAuthTokenCount(context.Background())
 }
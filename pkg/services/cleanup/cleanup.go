@@ -47,6 +47,13 @@ func (srv *CleanUpService) Run(ctx context.Context) error {
 			if err != nil {
 				srv.log.Error("failed to lock and execute cleanup of old login attempts", "error", err)
 			}
+			err = srv.ServerLockService.LockAndExecute(ctx, "delete old audit entries",
+				time.Minute*10, func() {
+					srv.deleteOldAuditEntries()
+				})
+			if err != nil {
+				srv.log.Error("failed to lock and execute cleanup of old audit entries", "error", err)
+			}
 		case <-ctx.Done():
 			return ctx.Err()
 		}
@@ -124,3 +131,18 @@ func (srv *CleanUpService) deleteOldLoginAttempts() {
 		srv.log.Debug("Deleted expired login attempts", "rows affected", cmd.DeletedRows)
 	}
 }
+
+func (srv *CleanUpService) deleteOldAuditEntries() {
+	if !srv.Cfg.AuditEnabled || srv.Cfg.AuditMaxAgeDays <= 0 {
+		return
+	}
+
+	cmd := models.DeleteOldAuditEntriesCommand{
+		OlderThan: time.Now().AddDate(0, 0, -srv.Cfg.AuditMaxAgeDays),
+	}
+	if err := bus.Dispatch(&cmd); err != nil {
+		srv.log.Error("Problem deleting old audit entries", "error", err.Error())
+	} else {
+		srv.log.Debug("Deleted old audit entries", "rows affected", cmd.DeletedRows)
+	}
+}
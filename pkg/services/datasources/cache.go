@@ -32,6 +32,19 @@ func (dc *CacheServiceImpl) Init() error {
 }
 
 func (dc *CacheServiceImpl) GetDatasource(datasourceID int64, user *models.SignedInUser, skipCache bool) (*models.DataSource, error) {
+	ds, err := dc.getDatasource(datasourceID, user, skipCache)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := dc.checkQueryPermission(ds, user); err != nil {
+		return nil, err
+	}
+
+	return ds, nil
+}
+
+func (dc *CacheServiceImpl) getDatasource(datasourceID int64, user *models.SignedInUser, skipCache bool) (*models.DataSource, error) {
 	cacheKey := fmt.Sprintf("ds-%d", datasourceID)
 
 	if !skipCache {
@@ -51,3 +64,30 @@ func (dc *CacheServiceImpl) GetDatasource(datasourceID int64, user *models.Signe
 	dc.CacheService.Set(cacheKey, query.Result, time.Second*5)
 	return query.Result, nil
 }
+
+// checkQueryPermission returns ErrDataSourceAccessDenied if the data source has
+// permissions enabled and the user has not been granted access to it.
+func (dc *CacheServiceImpl) checkQueryPermission(ds *models.DataSource, user *models.SignedInUser) error {
+	if !ds.PermissionsEnabled {
+		return nil
+	}
+
+	query := models.DatasourcesPermissionFilterQuery{
+		User:        user,
+		Datasources: []*models.DataSource{ds},
+	}
+
+	if err := dc.Bus.Dispatch(&query); err != nil {
+		if err == bus.ErrHandlerNotFound {
+			// permissions can't be evaluated, so access is denied
+			return models.ErrDataSourceAccessDenied
+		}
+		return err
+	}
+
+	if len(query.Result) == 0 {
+		return models.ErrDataSourceAccessDenied
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package datasources
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCacheService_GetDatasource(t *testing.T) {
+	user := &models.SignedInUser{UserId: 1, OrgId: 1, OrgRole: models.ROLE_VIEWER}
+
+	newService := func(ds *models.DataSource) (*CacheServiceImpl, bus.Bus) {
+		b := bus.New()
+		b.AddHandler(func(query *models.GetDataSourceByIdQuery) error {
+			query.Result = ds
+			return nil
+		})
+		return &CacheServiceImpl{Bus: b, CacheService: localcache.New(time.Minute, time.Minute)}, b
+	}
+
+	t.Run("Should return data sources without permissions", func(t *testing.T) {
+		dc, _ := newService(&models.DataSource{Id: 1, OrgId: 1})
+		ds, err := dc.GetDatasource(1, user, false)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), ds.Id)
+	})
+
+	t.Run("Should check permissions of data sources with permissions enabled", func(t *testing.T) {
+		dc, b := newService(&models.DataSource{Id: 1, OrgId: 1, PermissionsEnabled: true})
+		b.AddHandler(func(query *models.DatasourcesPermissionFilterQuery) error {
+			query.Result = []*models.DataSource{}
+			return nil
+		})
+
+		_, err := dc.GetDatasource(1, user, false)
+		assert.Equal(t, models.ErrDataSourceAccessDenied, err)
+	})
+
+	t.Run("Should deny access if permissions can't be evaluated", func(t *testing.T) {
+		dc, _ := newService(&models.DataSource{Id: 1, OrgId: 1, PermissionsEnabled: true})
+
+		_, err := dc.GetDatasource(1, user, false)
+		assert.Equal(t, models.ErrDataSourceAccessDenied, err)
+	})
+}
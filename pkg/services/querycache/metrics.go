@@ -0,0 +1,26 @@
+package querycache
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	cacheHits   *prometheus.CounterVec
+	cacheMisses *prometheus.CounterVec
+)
+
+func init() {
+	cacheHits = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "query_cache_hits_total",
+		Help:      "The total number of query results served from the query cache",
+	}, []string{"datasource_type"})
+
+	cacheMisses = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "grafana",
+		Name:      "query_cache_misses_total",
+		Help:      "The total number of queries of data sources with query caching enabled that were not in the cache",
+	}, []string{"datasource_type"})
+
+	prometheus.MustRegister(cacheHits, cacheMisses)
+}
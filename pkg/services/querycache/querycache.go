@@ -0,0 +1,268 @@
+// Package querycache caches the results of data source queries in the remote cache.
+package querycache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+const (
+	// Cache status values for the X-Cache response header
+	StatusHit     = "HIT"
+	StatusMiss    = "MISS"
+	StatusPartial = "PARTIAL"
+)
+
+// minAlignment is the smallest step relative time ranges are aligned to
+const minAlignment = int64(time.Second / time.Millisecond)
+
+// volatileModelKeys are query model properties that change between requests without changing the result
+var volatileModelKeys = []string{"requestId", "key"}
+
+var getTime = time.Now
+
+func init() {
+	registry.RegisterService(&QueryCacheService{})
+}
+
+// QueryCacheService caches query results of data sources that have query caching enabled.
+// Results are cached per query, keyed on the data source, the query model and the time range.
+type QueryCacheService struct {
+	Cfg         *setting.Cfg             `inject:""`
+	RemoteCache *remotecache.RemoteCache `inject:""`
+
+	log           log.Logger
+	handleRequest tsdb.HandleRequestFunc
+}
+
+func (s *QueryCacheService) Init() error {
+	s.log = log.New("querycache")
+	s.handleRequest = tsdb.HandleRequest
+	return nil
+}
+
+// HandleRequest returns the cached results of the queries in the request and runs the
+// remaining queries against the data source. It can be used in place of tsdb.HandleRequest.
+func (s *QueryCacheService) HandleRequest(ctx context.Context, ds *models.DataSource, req *tsdb.TsdbQuery) (*tsdb.Response, error) {
+	if !s.isEnabled(ds, req) {
+		return s.handleRequest(ctx, ds, req)
+	}
+
+	ttl := s.TTL(ds, req.TimeRange)
+	keys := make(map[string]string, len(req.Queries))
+	result := &tsdb.Response{Results: make(map[string]*tsdb.QueryResult)}
+	missed := make([]*tsdb.Query, 0)
+
+	for _, query := range req.Queries {
+		key, err := cacheKey(ds, req.TimeRange, query)
+		if err != nil {
+			s.log.Warn("Failed to create query cache key", "datasource", ds.Name, "refId", query.RefId, "error", err)
+			missed = append(missed, query)
+			continue
+		}
+		keys[query.RefId] = key
+
+		if !req.NoCache {
+			if cached := s.get(key); cached != nil {
+				cacheHits.WithLabelValues(ds.Type).Inc()
+				result.Results[query.RefId] = cached
+				continue
+			}
+		}
+
+		cacheMisses.WithLabelValues(ds.Type).Inc()
+		missed = append(missed, query)
+	}
+
+	if len(missed) == 0 {
+		return result, nil
+	}
+
+	missedReq := *req
+	missedReq.Queries = missed
+
+	res, err := s.handleRequest(ctx, ds, &missedReq)
+	if err != nil {
+		return nil, err
+	}
+
+	for refID, queryResult := range res.Results {
+		result.Results[refID] = queryResult
+
+		if key, exists := keys[refID]; exists && queryResult.Error == nil {
+			s.set(key, queryResult, ttl)
+		}
+	}
+	result.Message = res.Message
+
+	return result, nil
+}
+
+// Status returns the value of the X-Cache header for a response, or an
+// empty string if none of the results could have been cached.
+func Status(resp *tsdb.Response, enabled bool) string {
+	if !enabled || len(resp.Results) == 0 {
+		return ""
+	}
+
+	hits := 0
+	for _, res := range resp.Results {
+		if res.Cached {
+			hits++
+		}
+	}
+
+	switch hits {
+	case 0:
+		return StatusMiss
+	case len(resp.Results):
+		return StatusHit
+	default:
+		return StatusPartial
+	}
+}
+
+// IsEnabled returns true if results of queries against the data source are cached.
+func (s *QueryCacheService) IsEnabled(ds *models.DataSource) bool {
+	if !s.Cfg.QueryCachingEnabled || ds == nil || ds.JsonData == nil {
+		return false
+	}
+
+	// results of data sources queried with the token of the user can't be shared
+	if ds.JsonData.Get("oauthPassThru").MustBool(false) {
+		return false
+	}
+
+	return ds.JsonData.Get("queryCachingEnabled").MustBool(false)
+}
+
+func (s *QueryCacheService) isEnabled(ds *models.DataSource, req *tsdb.TsdbQuery) bool {
+	// headers are used to forward user specific information to the data source
+	return s.IsEnabled(ds) && len(req.Headers) == 0 && req.TimeRange != nil
+}
+
+// TTL returns how long results are cached. Results of absolute time ranges in the past don't
+// change and are kept longer than results of time ranges relative to now.
+func (s *QueryCacheService) TTL(ds *models.DataSource, tr *tsdb.TimeRange) time.Duration {
+	if !isRelative(tr) {
+		return s.Cfg.QueryCachingAbsoluteTTL
+	}
+
+	if value := ds.JsonData.Get("queryCachingTTL").MustString(""); value != "" {
+		ttl, err := time.ParseDuration(value)
+		if err == nil && ttl > 0 {
+			return ttl
+		}
+		s.log.Warn("Invalid query caching TTL of data source", "datasource", ds.Name, "ttl", value)
+	}
+
+	return s.Cfg.QueryCachingTTL
+}
+
+func (s *QueryCacheService) get(key string) *tsdb.QueryResult {
+	value, err := s.RemoteCache.Get(key)
+	if err != nil {
+		if err != remotecache.ErrCacheItemNotFound {
+			s.log.Warn("Failed to get cached query result", "error", err)
+		}
+		return nil
+	}
+
+	data, ok := value.([]byte)
+	if !ok {
+		return nil
+	}
+
+	var result tsdb.QueryResult
+	if err := json.Unmarshal(data, &result); err != nil {
+		s.log.Warn("Failed to decode cached query result", "error", err)
+		return nil
+	}
+
+	result.Cached = true
+	return &result
+}
+
+func (s *QueryCacheService) set(key string, result *tsdb.QueryResult, ttl time.Duration) {
+	// query results are stored as json since not all values of table rows can be encoded with gob
+	data, err := json.Marshal(result)
+	if err != nil {
+		s.log.Warn("Failed to encode query result", "error", err)
+		return
+	}
+
+	if len(data) > s.Cfg.QueryCachingMaxValueSize {
+		s.log.Debug("Query result too large to be cached", "refId", result.RefId, "size", len(data))
+		return
+	}
+
+	if err := s.RemoteCache.Set(key, data, ttl); err != nil {
+		s.log.Warn("Failed to cache query result", "error", err)
+	}
+}
+
+func isRelative(tr *tsdb.TimeRange) bool {
+	if strings.HasPrefix(tr.From, "now") || strings.HasPrefix(tr.To, "now") {
+		return true
+	}
+
+	// absolute time ranges reaching into the future still get new data
+	return tr.MustGetTo().After(getTime())
+}
+
+// cacheKey identifies the result of a query. The query model is normalized and relative time
+// ranges are aligned to the interval of the query, so requests issued by refreshing dashboards
+// share the cached result until the time range has moved by a full interval.
+func cacheKey(ds *models.DataSource, tr *tsdb.TimeRange, query *tsdb.Query) (string, error) {
+	model := []byte("{}")
+	if query.Model != nil {
+		normalized, err := query.Model.Map()
+		if err != nil {
+			return "", err
+		}
+
+		copied := make(map[string]interface{}, len(normalized))
+		for k, v := range normalized {
+			copied[k] = v
+		}
+		for _, k := range volatileModelKeys {
+			delete(copied, k)
+		}
+
+		// map keys are sorted by the encoder
+		if model, err = json.Marshal(copied); err != nil {
+			return "", err
+		}
+	}
+
+	from := tr.GetFromAsMsEpoch()
+	to := tr.GetToAsMsEpoch()
+	if isRelative(tr) {
+		step := query.IntervalMs
+		if step < minAlignment {
+			step = minAlignment
+		}
+		from -= from % step
+		to -= to % step
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%d|%d|%d|%s|%s|%d|%d|%d|%d|", ds.OrgId, ds.Id, ds.Version, query.RefId, query.QueryType, query.IntervalMs, query.MaxDataPoints, from, to)
+	if _, err := h.Write(model); err != nil {
+		return "", err
+	}
+
+	return "query-cache-" + hex.EncodeToString(h.Sum(nil)), nil
+}
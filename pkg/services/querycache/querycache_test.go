@@ -0,0 +1,139 @@
+package querycache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCacheService(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	getTime = func() time.Time { return now }
+	defer func() { getTime = time.Now }()
+
+	service := &QueryCacheService{
+		Cfg: &setting.Cfg{
+			QueryCachingEnabled:      true,
+			QueryCachingTTL:          time.Minute,
+			QueryCachingAbsoluteTTL:  time.Hour,
+			QueryCachingMaxValueSize: 1024,
+		},
+		RemoteCache: remotecache.NewFakeStore(t),
+	}
+	require.NoError(t, service.Init())
+
+	executed := []string{}
+	var queryErr error
+	service.handleRequest = func(ctx context.Context, ds *models.DataSource, req *tsdb.TsdbQuery) (*tsdb.Response, error) {
+		if queryErr != nil {
+			return nil, queryErr
+		}
+
+		res := &tsdb.Response{Results: map[string]*tsdb.QueryResult{}}
+		for _, q := range req.Queries {
+			executed = append(executed, q.RefId)
+			res.Results[q.RefId] = &tsdb.QueryResult{
+				RefId:  q.RefId,
+				Series: tsdb.TimeSeriesSlice{{Name: q.Model.Get("expr").MustString(), Points: tsdb.NewTimeSeriesPointsFromArgs(1, 1000)}},
+			}
+		}
+		return res, nil
+	}
+
+	ds := &models.DataSource{Id: 1, OrgId: 1, Type: "prometheus", JsonData: simplejson.NewFromAny(map[string]interface{}{"queryCachingEnabled": true})}
+
+	newRequest := func(from, to string, exprs ...string) *tsdb.TsdbQuery {
+		req := &tsdb.TsdbQuery{TimeRange: tsdb.NewFakeTimeRange(from, to, now)}
+		for i, expr := range exprs {
+			req.Queries = append(req.Queries, &tsdb.Query{
+				RefId:      string(rune('A' + i)),
+				IntervalMs: 15000,
+				Model:      simplejson.NewFromAny(map[string]interface{}{"expr": expr, "requestId": now.String()}),
+			})
+		}
+		return req
+	}
+
+	t.Run("Should serve repeated queries from the cache", func(t *testing.T) {
+		executed = nil
+
+		res, err := service.HandleRequest(context.Background(), ds, newRequest("now-1h", "now", "up"))
+		require.NoError(t, err)
+		assert.Equal(t, StatusMiss, Status(res, true))
+
+		now = now.Add(5 * time.Second)
+		res, err = service.HandleRequest(context.Background(), ds, newRequest("now-1h", "now", "up", "down"))
+		require.NoError(t, err)
+		assert.Equal(t, StatusPartial, Status(res, true))
+		assert.Equal(t, "up", res.Results["A"].Series[0].Name)
+		assert.True(t, res.Results["A"].Cached)
+		assert.Equal(t, []string{"A", "B"}, executed)
+
+		res, err = service.HandleRequest(context.Background(), ds, newRequest("now-1h", "now", "up", "down"))
+		require.NoError(t, err)
+		assert.Equal(t, StatusHit, Status(res, true))
+		assert.Len(t, res.Results["B"].Series[0].Points, 1)
+		assert.Equal(t, []string{"A", "B"}, executed)
+	})
+
+	t.Run("Should run the query when the time range has moved by an interval", func(t *testing.T) {
+		executed = nil
+		now = now.Add(15 * time.Second)
+
+		_, err := service.HandleRequest(context.Background(), ds, newRequest("now-1h", "now", "up"))
+		require.NoError(t, err)
+		assert.Equal(t, []string{"A"}, executed)
+	})
+
+	t.Run("Should not read the cache when asked not to", func(t *testing.T) {
+		executed = nil
+
+		req := newRequest("now-1h", "now", "up")
+		req.NoCache = true
+		_, err := service.HandleRequest(context.Background(), ds, req)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"A"}, executed)
+	})
+
+	t.Run("Should not cache data sources without query caching", func(t *testing.T) {
+		executed = nil
+		other := &models.DataSource{Id: 2, OrgId: 1, Type: "prometheus", JsonData: simplejson.New()}
+
+		for i := 0; i < 2; i++ {
+			_, err := service.HandleRequest(context.Background(), other, newRequest("now-1h", "now", "up"))
+			require.NoError(t, err)
+		}
+		assert.Equal(t, []string{"A", "A"}, executed)
+		assert.False(t, service.IsEnabled(other))
+	})
+
+	t.Run("Should return errors of the data source", func(t *testing.T) {
+		queryErr = errors.New("bad gateway")
+		defer func() { queryErr = nil }()
+
+		_, err := service.HandleRequest(context.Background(), ds, newRequest("now-2h", "now", "up"))
+		assert.Equal(t, queryErr, err)
+	})
+
+	t.Run("Should use the ttl of the time range", func(t *testing.T) {
+		assert.Equal(t, time.Minute, service.TTL(ds, tsdb.NewFakeTimeRange("now-1h", "now", now)))
+
+		from := now.Add(-2*time.Hour).UnixNano() / int64(time.Millisecond)
+		to := now.Add(-time.Hour).UnixNano() / int64(time.Millisecond)
+		absolute := tsdb.NewFakeTimeRange(fmt.Sprint(from), fmt.Sprint(to), now)
+		assert.Equal(t, time.Hour, service.TTL(ds, absolute))
+
+		withTTL := &models.DataSource{JsonData: simplejson.NewFromAny(map[string]interface{}{"queryCachingTTL": "10s"})}
+		assert.Equal(t, 10*time.Second, service.TTL(withTTL, tsdb.NewFakeTimeRange("now-1h", "now", now)))
+	})
+}
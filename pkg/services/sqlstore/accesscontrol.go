@@ -0,0 +1,325 @@
+package sqlstore
+
+import (
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", CreateRole)
+	bus.AddHandler("sql", UpdateRole)
+	bus.AddHandler("sql", DeleteRole)
+	bus.AddHandler("sql", GetRoles)
+	bus.AddHandler("sql", GetRoleById)
+
+	bus.AddHandler("sql", AddRoleAssignment)
+	bus.AddHandler("sql", RemoveRoleAssignment)
+	bus.AddHandler("sql", GetRoleAssignments)
+	bus.AddHandler("sql", GetCustomPermissions)
+}
+
+func CreateRole(cmd *models.CreateRoleCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if isNameTaken, err := isRoleNameTaken(sess, cmd.OrgId, cmd.Name, 0); err != nil {
+			return err
+		} else if isNameTaken {
+			return models.ErrRoleWithSameNameExists
+		}
+
+		role := models.Role{
+			OrgId:       cmd.OrgId,
+			Name:        cmd.Name,
+			Description: cmd.Description,
+			Created:     time.Now(),
+			Updated:     time.Now(),
+		}
+
+		if _, err := sess.Insert(&role); err != nil {
+			return err
+		}
+
+		if err := setRolePermissions(sess, role.Id, cmd.Permissions); err != nil {
+			return err
+		}
+
+		permissions, err := getRolePermissions(sess, role.Id)
+		if err != nil {
+			return err
+		}
+
+		cmd.Result = roleToDTO(&role, permissions)
+		return nil
+	})
+}
+
+func UpdateRole(cmd *models.UpdateRoleCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if isNameTaken, err := isRoleNameTaken(sess, cmd.OrgId, cmd.Name, cmd.Id); err != nil {
+			return err
+		} else if isNameTaken {
+			return models.ErrRoleWithSameNameExists
+		}
+
+		role := models.Role{
+			Name:        cmd.Name,
+			Description: cmd.Description,
+			Updated:     time.Now(),
+		}
+
+		affectedRows, err := sess.Where("id = ? AND org_id = ?", cmd.Id, cmd.OrgId).Cols("name", "description", "updated").Update(&role)
+		if err != nil {
+			return err
+		}
+
+		if affectedRows == 0 {
+			return models.ErrRoleNotFound
+		}
+
+		if _, err := sess.Exec("DELETE FROM role_permission WHERE role_id = ?", cmd.Id); err != nil {
+			return err
+		}
+
+		return setRolePermissions(sess, cmd.Id, cmd.Permissions)
+	})
+}
+
+func DeleteRole(cmd *models.DeleteRoleCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := getRole(sess, cmd.OrgId, cmd.Id); err != nil {
+			return err
+		}
+
+		deletes := []string{
+			"DELETE FROM role_permission WHERE role_id = ?",
+			"DELETE FROM role_assignment WHERE role_id = ?",
+			"DELETE FROM role WHERE id = ?",
+		}
+
+		for _, sql := range deletes {
+			if _, err := sess.Exec(sql, cmd.Id); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func GetRoles(query *models.GetRolesQuery) error {
+	var roles []*models.Role
+	if err := x.Where("org_id = ?", query.OrgId).Asc("name").Find(&roles); err != nil {
+		return err
+	}
+
+	query.Result = make([]*models.RoleDTO, 0, len(roles))
+	for _, role := range roles {
+		permissions, err := getRolePermissions(newSession(), role.Id)
+		if err != nil {
+			return err
+		}
+		query.Result = append(query.Result, roleToDTO(role, permissions))
+	}
+
+	return nil
+}
+
+func GetRoleById(query *models.GetRoleByIdQuery) error {
+	sess := newSession()
+	role, err := getRole(sess, query.OrgId, query.Id)
+	if err != nil {
+		return err
+	}
+
+	permissions, err := getRolePermissions(sess, role.Id)
+	if err != nil {
+		return err
+	}
+
+	query.Result = roleToDTO(role, permissions)
+	return nil
+}
+
+func AddRoleAssignment(cmd *models.AddRoleAssignmentCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := getRole(sess, cmd.OrgId, cmd.RoleId); err != nil {
+			return err
+		}
+
+		if err := verifyRoleSubject(sess, cmd.OrgId, cmd.SubjectType, cmd.SubjectId); err != nil {
+			return err
+		}
+
+		exists, err := sess.Where("role_id = ? AND subject_type = ? AND subject_id = ?", cmd.RoleId, cmd.SubjectType, cmd.SubjectId).Get(&models.RoleAssignment{})
+		if err != nil {
+			return err
+		}
+		if exists {
+			return models.ErrRoleAssignmentExists
+		}
+
+		assignment := models.RoleAssignment{
+			OrgId:       cmd.OrgId,
+			RoleId:      cmd.RoleId,
+			SubjectType: cmd.SubjectType,
+			SubjectId:   cmd.SubjectId,
+			Created:     time.Now(),
+		}
+
+		if _, err := sess.Insert(&assignment); err != nil {
+			return err
+		}
+
+		cmd.Result = &assignment
+		return nil
+	})
+}
+
+func RemoveRoleAssignment(cmd *models.RemoveRoleAssignmentCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		res, err := sess.Exec("DELETE FROM role_assignment WHERE id = ? AND org_id = ? AND role_id = ?", cmd.Id, cmd.OrgId, cmd.RoleId)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return models.ErrRoleAssignmentNotFound
+		}
+
+		return nil
+	})
+}
+
+func GetRoleAssignments(query *models.GetRoleAssignmentsQuery) error {
+	query.Result = make([]*models.RoleAssignment, 0)
+	return x.Where("org_id = ? AND role_id = ?", query.OrgId, query.RoleId).Asc("id").Find(&query.Result)
+}
+
+func GetCustomPermissions(query *models.GetCustomPermissionsQuery) error {
+	subjectFilters := []string{
+		"(role_assignment.subject_type = ? AND role_assignment.subject_id = ?)",
+		"(role_assignment.subject_type = ? AND role_assignment.subject_id = ?)",
+	}
+	params := []interface{}{query.OrgId, models.RoleSubjectUser, query.UserId, models.RoleSubjectApiKey, query.ApiKeyId}
+
+	if len(query.TeamIds) > 0 {
+		subjectFilters = append(subjectFilters, "(role_assignment.subject_type = ? AND role_assignment.subject_id IN (?"+strings.Repeat(",?", len(query.TeamIds)-1)+"))")
+		params = append(params, models.RoleSubjectTeam)
+		for _, teamId := range query.TeamIds {
+			params = append(params, teamId)
+		}
+	}
+
+	sql := `SELECT DISTINCT role_permission.action
+		FROM role_permission
+		INNER JOIN role_assignment ON role_assignment.role_id = role_permission.role_id
+		WHERE role_assignment.org_id = ? AND (` + strings.Join(subjectFilters, " OR ") + `)`
+
+	query.Result = make([]string, 0)
+	return x.SQL(sql, params...).Find(&query.Result)
+}
+
+func isRoleNameTaken(sess *DBSession, orgId int64, name string, existingId int64) (bool, error) {
+	var role models.Role
+	exists, err := sess.Where("org_id = ? AND name = ?", orgId, name).Get(&role)
+	if err != nil {
+		return false, err
+	}
+
+	return exists && role.Id != existingId, nil
+}
+
+func getRole(sess *DBSession, orgId int64, id int64) (*models.Role, error) {
+	var role models.Role
+	exists, err := sess.Where("id = ? AND org_id = ?", id, orgId).Get(&role)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, models.ErrRoleNotFound
+	}
+
+	return &role, nil
+}
+
+func getRolePermissions(sess *DBSession, roleId int64) ([]string, error) {
+	var permissions []*models.RolePermission
+	if err := sess.Where("role_id = ?", roleId).Asc("action").Find(&permissions); err != nil {
+		return nil, err
+	}
+
+	result := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		result = append(result, p.Action)
+	}
+
+	return result, nil
+}
+
+func setRolePermissions(sess *DBSession, roleId int64, actions []string) error {
+	seen := make(map[string]bool)
+	for _, action := range actions {
+		if seen[action] {
+			continue
+		}
+		seen[action] = true
+
+		permission := models.RolePermission{RoleId: roleId, Action: action, Created: time.Now()}
+		if _, err := sess.Insert(&permission); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func verifyRoleSubject(sess *DBSession, orgId int64, subjectType models.RoleSubjectType, subjectId int64) error {
+	var sql string
+	var notFound error
+
+	switch subjectType {
+	case models.RoleSubjectUser:
+		sql, notFound = "SELECT 1 FROM org_user WHERE org_id = ? AND user_id = ?", models.ErrOrgUserNotFound
+	case models.RoleSubjectTeam:
+		sql, notFound = "SELECT 1 FROM team WHERE org_id = ? AND id = ?", models.ErrTeamNotFound
+	case models.RoleSubjectApiKey:
+		sql, notFound = "SELECT 1 FROM api_key WHERE org_id = ? AND id = ?", models.ErrInvalidApiKey
+	default:
+		return models.ErrRoleInvalidSubject
+	}
+
+	res, err := sess.Query(sql, orgId, subjectId)
+	if err != nil {
+		return err
+	}
+
+	if len(res) == 0 {
+		return notFound
+	}
+
+	return nil
+}
+
+func roleToDTO(role *models.Role, permissions []string) *models.RoleDTO {
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	return &models.RoleDTO{
+		Id:          role.Id,
+		OrgId:       role.OrgId,
+		Name:        role.Name,
+		Description: role.Description,
+		Permissions: permissions,
+		Created:     role.Created,
+		Updated:     role.Updated,
+	}
+}
@@ -0,0 +1,142 @@
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessControlRoles(t *testing.T) {
+	InitTestDB(t)
+
+	setting.AutoAssignOrg = true
+	setting.AutoAssignOrgId = 1
+	setting.AutoAssignOrgRole = string(models.ROLE_VIEWER)
+
+	userCmd := models.CreateUserCommand{Login: "viewer", Email: "viewer@test.com"}
+	require.NoError(t, CreateUser(context.Background(), &userCmd))
+	user := userCmd.Result
+
+	teamCmd := models.CreateTeamCommand{Name: "team", OrgId: 1}
+	require.NoError(t, CreateTeam(&teamCmd))
+	team := teamCmd.Result
+
+	keyCmd := models.AddApiKeyCommand{Name: "key", Key: "secret", Role: models.ROLE_VIEWER, OrgId: 1}
+	require.NoError(t, AddApiKey(&keyCmd))
+	key := keyCmd.Result
+
+	createRole := func(t *testing.T, name string, permissions ...string) *models.RoleDTO {
+		cmd := models.CreateRoleCommand{OrgId: 1, Name: name, Permissions: permissions}
+		require.NoError(t, CreateRole(&cmd))
+		return cmd.Result
+	}
+
+	assign := func(t *testing.T, role *models.RoleDTO, subjectType models.RoleSubjectType, subjectId int64) *models.RoleAssignment {
+		cmd := models.AddRoleAssignmentCommand{OrgId: 1, RoleId: role.Id, SubjectType: subjectType, SubjectId: subjectId}
+		require.NoError(t, AddRoleAssignment(&cmd))
+		return cmd.Result
+	}
+
+	t.Run("Should create role with deduplicated permissions", func(t *testing.T) {
+		role := createRole(t, "datasource reader", "datasources:read", "datasources:read", "datasources:query")
+		assert.Equal(t, []string{"datasources:query", "datasources:read"}, role.Permissions)
+
+		err := CreateRole(&models.CreateRoleCommand{OrgId: 1, Name: "datasource reader"})
+		assert.Equal(t, models.ErrRoleWithSameNameExists, err)
+
+		query := models.GetRoleByIdQuery{Id: role.Id, OrgId: 1}
+		require.NoError(t, GetRoleById(&query))
+		assert.Equal(t, "datasource reader", query.Result.Name)
+
+		query = models.GetRoleByIdQuery{Id: role.Id, OrgId: 2}
+		assert.Equal(t, models.ErrRoleNotFound, GetRoleById(&query))
+	})
+
+	t.Run("Should update role permissions", func(t *testing.T) {
+		role := createRole(t, "annotator", "annotations:create")
+
+		err := UpdateRole(&models.UpdateRoleCommand{Id: role.Id, OrgId: 1, Name: "annotator", Description: "edits annotations", Permissions: []string{"annotations:write"}})
+		require.NoError(t, err)
+
+		query := models.GetRoleByIdQuery{Id: role.Id, OrgId: 1}
+		require.NoError(t, GetRoleById(&query))
+		assert.Equal(t, "edits annotations", query.Result.Description)
+		assert.Equal(t, []string{"annotations:write"}, query.Result.Permissions)
+	})
+
+	t.Run("Should return permissions of roles assigned to user, team and api key", func(t *testing.T) {
+		userRole := createRole(t, "user role", "users:read")
+		teamRole := createRole(t, "team role", "datasources:write")
+		keyRole := createRole(t, "key role", "apikeys:write")
+
+		assign(t, userRole, models.RoleSubjectUser, user.Id)
+		assign(t, teamRole, models.RoleSubjectTeam, team.Id)
+		assign(t, keyRole, models.RoleSubjectApiKey, key.Id)
+
+		err := AddRoleAssignment(&models.AddRoleAssignmentCommand{OrgId: 1, RoleId: userRole.Id, SubjectType: models.RoleSubjectUser, SubjectId: user.Id})
+		assert.Equal(t, models.ErrRoleAssignmentExists, err)
+
+		query := models.GetCustomPermissionsQuery{OrgId: 1, UserId: user.Id}
+		require.NoError(t, GetCustomPermissions(&query))
+		assert.ElementsMatch(t, []string{"users:read"}, query.Result)
+
+		query = models.GetCustomPermissionsQuery{OrgId: 1, UserId: user.Id, TeamIds: []int64{team.Id}}
+		require.NoError(t, GetCustomPermissions(&query))
+		assert.ElementsMatch(t, []string{"users:read", "datasources:write"}, query.Result)
+
+		query = models.GetCustomPermissionsQuery{OrgId: 1, ApiKeyId: key.Id}
+		require.NoError(t, GetCustomPermissions(&query))
+		assert.ElementsMatch(t, []string{"apikeys:write"}, query.Result)
+
+		query = models.GetCustomPermissionsQuery{OrgId: 2, UserId: user.Id, TeamIds: []int64{team.Id}}
+		require.NoError(t, GetCustomPermissions(&query))
+		assert.Empty(t, query.Result)
+	})
+
+	t.Run("Should not assign roles to subjects outside of the org", func(t *testing.T) {
+		role := createRole(t, "outsider")
+
+		err := AddRoleAssignment(&models.AddRoleAssignmentCommand{OrgId: 1, RoleId: role.Id, SubjectType: models.RoleSubjectUser, SubjectId: 1000})
+		assert.Equal(t, models.ErrOrgUserNotFound, err)
+
+		err = AddRoleAssignment(&models.AddRoleAssignmentCommand{OrgId: 1, RoleId: role.Id, SubjectType: "group", SubjectId: user.Id})
+		assert.Equal(t, models.ErrRoleInvalidSubject, err)
+	})
+
+	t.Run("Should remove assignments", func(t *testing.T) {
+		role := createRole(t, "temporary", "plugins:write")
+		assignment := assign(t, role, models.RoleSubjectUser, user.Id)
+
+		query := models.GetRoleAssignmentsQuery{OrgId: 1, RoleId: role.Id}
+		require.NoError(t, GetRoleAssignments(&query))
+		require.Len(t, query.Result, 1)
+
+		require.NoError(t, RemoveRoleAssignment(&models.RemoveRoleAssignmentCommand{Id: assignment.Id, RoleId: role.Id, OrgId: 1}))
+		err := RemoveRoleAssignment(&models.RemoveRoleAssignmentCommand{Id: assignment.Id, RoleId: role.Id, OrgId: 1})
+		assert.Equal(t, models.ErrRoleAssignmentNotFound, err)
+	})
+
+	t.Run("Should delete role with its permissions and assignments", func(t *testing.T) {
+		role := createRole(t, "doomed", "alerts:pause")
+		assign(t, role, models.RoleSubjectUser, user.Id)
+
+		require.NoError(t, DeleteRole(&models.DeleteRoleCommand{Id: role.Id, OrgId: 1}))
+		assert.Equal(t, models.ErrRoleNotFound, DeleteRole(&models.DeleteRoleCommand{Id: role.Id, OrgId: 1}))
+
+		query := models.GetCustomPermissionsQuery{OrgId: 1, UserId: user.Id}
+		require.NoError(t, GetCustomPermissions(&query))
+		assert.NotContains(t, query.Result, "alerts:pause")
+	})
+
+	t.Run("Should remove assignments when the team is deleted", func(t *testing.T) {
+		require.NoError(t, DeleteTeam(&models.DeleteTeamCommand{OrgId: 1, Id: team.Id}))
+
+		query := models.GetCustomPermissionsQuery{OrgId: 1, TeamIds: []int64{team.Id}}
+		require.NoError(t, GetCustomPermissions(&query))
+		assert.Empty(t, query.Result)
+	})
+}
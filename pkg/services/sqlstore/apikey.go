@@ -30,6 +30,11 @@ func GetApiKeys(query *models.GetApiKeysQuery) error {
 func DeleteApiKeyCtx(ctx context.Context, cmd *models.DeleteApiKeyCommand) error {
 	return withDbSession(ctx, func(sess *DBSession) error {
 		var rawSql = "DELETE FROM api_key WHERE id=? and org_id=?"
+		if _, err := sess.Exec(rawSql, cmd.Id, cmd.OrgId); err != nil {
+			return err
+		}
+
+		rawSql = "DELETE FROM role_assignment WHERE subject_type = 'api_key' and subject_id=? and org_id=?"
 		_, err := sess.Exec(rawSql, cmd.Id, cmd.OrgId)
 		return err
 	})
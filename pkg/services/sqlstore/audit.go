@@ -0,0 +1,106 @@
+package sqlstore
+
+import (
+	"strings"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", CreateAuditEntry)
+	bus.AddHandler("sql", SearchAuditEntries)
+	bus.AddHandler("sql", DeleteOldAuditEntries)
+}
+
+func CreateAuditEntry(cmd *models.CreateAuditEntryCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if cmd.Entry.Created.IsZero() {
+			cmd.Entry.Created = getTimeNow()
+		}
+
+		_, err := sess.Insert(cmd.Entry)
+		return err
+	})
+}
+
+func SearchAuditEntries(query *models.SearchAuditEntriesQuery) error {
+	query.Result = models.SearchAuditEntriesQueryResult{
+		Entries: make([]*models.AuditEntry, 0),
+	}
+
+	whereConditions := make([]string, 0)
+	whereParams := make([]interface{}, 0)
+
+	if query.OrgId > 0 {
+		whereConditions = append(whereConditions, "org_id = ?")
+		whereParams = append(whereParams, query.OrgId)
+	}
+
+	if query.ActorId > 0 {
+		whereConditions = append(whereConditions, "actor_id = ?")
+		whereParams = append(whereParams, query.ActorId)
+	}
+
+	if query.ActorType != "" {
+		whereConditions = append(whereConditions, "actor_type = ?")
+		whereParams = append(whereParams, query.ActorType)
+	}
+
+	if query.ResourceType != "" {
+		whereConditions = append(whereConditions, "resource_type = ?")
+		whereParams = append(whereParams, query.ResourceType)
+	}
+
+	if query.ResourceId != "" {
+		whereConditions = append(whereConditions, "resource_id = ?")
+		whereParams = append(whereParams, query.ResourceId)
+	}
+
+	if !query.From.IsZero() {
+		whereConditions = append(whereConditions, "created >= ?")
+		whereParams = append(whereParams, query.From)
+	}
+
+	if !query.To.IsZero() {
+		whereConditions = append(whereConditions, "created <= ?")
+		whereParams = append(whereParams, query.To)
+	}
+
+	sess := x.Table("audit_entry")
+	if len(whereConditions) > 0 {
+		sess.Where(strings.Join(whereConditions, " AND "), whereParams...)
+	}
+
+	if query.Limit > 0 {
+		offset := query.Limit * (query.Page - 1)
+		sess.Limit(query.Limit, offset)
+	}
+
+	sess.Desc("created", "id")
+	if err := sess.Find(&query.Result.Entries); err != nil {
+		return err
+	}
+
+	countSess := x.Table("audit_entry")
+	if len(whereConditions) > 0 {
+		countSess.Where(strings.Join(whereConditions, " AND "), whereParams...)
+	}
+
+	count, err := countSess.Count(&models.AuditEntry{})
+	query.Result.TotalCount = count
+
+	return err
+}
+
+func DeleteOldAuditEntries(cmd *models.DeleteOldAuditEntriesCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		result, err := sess.Exec("DELETE FROM audit_entry WHERE created < ?", cmd.OlderThan)
+		if err != nil {
+			return err
+		}
+
+		cmd.DeletedRows, err = result.RowsAffected()
+		return err
+	})
+}
@@ -0,0 +1,62 @@
+package sqlstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditEntries(t *testing.T) {
+	InitTestDB(t)
+
+	start := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	entries := []*models.AuditEntry{
+		{OrgId: 1, ActorType: models.AuditActorUser, ActorId: 1, ActorLogin: "admin", Action: "PUT /api/datasources/1", ResourceType: "datasources", ResourceId: "1", Status: 200, Created: start},
+		{OrgId: 1, ActorType: models.AuditActorApiKey, ActorId: 5, Action: "DELETE /api/dashboards/uid/abc", ResourceType: "dashboards", ResourceId: "abc", Status: 200, Created: start.Add(time.Minute)},
+		{OrgId: 2, ActorType: models.AuditActorUser, ActorId: 2, ActorLogin: "editor", Action: "POST /api/auth/keys", ResourceType: "auth", Status: 200, Created: start.Add(2 * time.Minute),
+			AfterState: simplejson.NewFromAny(map[string]interface{}{"name": "key", "role": "Admin"})},
+	}
+
+	for _, entry := range entries {
+		err := CreateAuditEntry(&models.CreateAuditEntryCommand{Entry: entry})
+		require.NoError(t, err)
+	}
+
+	t.Run("Should return all entries newest first", func(t *testing.T) {
+		query := models.SearchAuditEntriesQuery{}
+		err := SearchAuditEntries(&query)
+		require.NoError(t, err)
+		require.Len(t, query.Result.Entries, 3)
+		assert.Equal(t, int64(3), query.Result.TotalCount)
+		assert.Equal(t, entries[2].Id, query.Result.Entries[0].Id)
+		assert.Equal(t, "Admin", query.Result.Entries[0].AfterState.Get("role").MustString())
+	})
+
+	t.Run("Should filter by org and resource", func(t *testing.T) {
+		query := models.SearchAuditEntriesQuery{OrgId: 1, ResourceType: "dashboards", ResourceId: "abc"}
+		err := SearchAuditEntries(&query)
+		require.NoError(t, err)
+		require.Len(t, query.Result.Entries, 1)
+		assert.Equal(t, models.AuditActorApiKey, query.Result.Entries[0].ActorType)
+	})
+
+	t.Run("Should filter by time range and page results", func(t *testing.T) {
+		query := models.SearchAuditEntriesQuery{From: start.Add(30 * time.Second), Limit: 1, Page: 2}
+		err := SearchAuditEntries(&query)
+		require.NoError(t, err)
+		require.Len(t, query.Result.Entries, 1)
+		assert.Equal(t, int64(2), query.Result.TotalCount)
+		assert.Equal(t, entries[1].Id, query.Result.Entries[0].Id)
+	})
+
+	t.Run("Should delete old entries", func(t *testing.T) {
+		cmd := models.DeleteOldAuditEntriesCommand{OlderThan: start.Add(90 * time.Second)}
+		err := DeleteOldAuditEntries(&cmd)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), cmd.DeletedRows)
+	})
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 
 	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/events"
 	"github.com/grafana/grafana/pkg/infra/metrics"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/services/search"
@@ -46,7 +47,26 @@ var generateNewUid func() string = util.GenerateShortUID
 
 func SaveDashboard(cmd *models.SaveDashboardCommand) error {
 	return inTransaction(func(sess *DBSession) error {
-		return saveDashboard(sess, cmd)
+		if err := saveDashboard(sess, cmd); err != nil {
+			return err
+		}
+
+		publishDashboardSaved(sess, cmd, false)
+		return nil
+	})
+}
+
+func publishDashboardSaved(sess *DBSession, cmd *models.SaveDashboardCommand, provisioned bool) {
+	sess.publishAfterCommit(&events.DashboardSaved{
+		Timestamp:   cmd.Result.Updated,
+		Id:          cmd.Result.Id,
+		OrgId:       cmd.Result.OrgId,
+		Uid:         cmd.Result.Uid,
+		Title:       cmd.Result.Title,
+		Version:     cmd.Result.Version,
+		UserId:      cmd.UserId,
+		Message:     cmd.Message,
+		Provisioned: provisioned,
 	})
 }
 
@@ -376,9 +396,10 @@ func DeleteDashboard(cmd *models.DeleteDashboardCommand) error {
 			deletes = append(deletes, "DELETE FROM dashboard WHERE folder_id = ?")
 
 			dashIds := []struct {
-				Id int64
+				Id  int64
+				Uid string
 			}{}
-			err := sess.SQL("select id from dashboard where folder_id = ?", dashboard.Id).Find(&dashIds)
+			err := sess.SQL("select id, uid from dashboard where folder_id = ?", dashboard.Id).Find(&dashIds)
 			if err != nil {
 				return err
 			}
@@ -387,6 +408,13 @@ func DeleteDashboard(cmd *models.DeleteDashboardCommand) error {
 				if err := deleteAlertDefinition(id.Id, sess); err != nil {
 					return err
 				}
+
+				sess.publishAfterCommit(&events.DashboardDeleted{
+					Timestamp: time.Now(),
+					Id:        id.Id,
+					OrgId:     dashboard.OrgId,
+					Uid:       id.Uid,
+				})
 			}
 		}
 
@@ -402,6 +430,13 @@ func DeleteDashboard(cmd *models.DeleteDashboardCommand) error {
 			}
 		}
 
+		sess.publishAfterCommit(&events.DashboardDeleted{
+			Timestamp: time.Now(),
+			Id:        dashboard.Id,
+			OrgId:     dashboard.OrgId,
+			Uid:       dashboard.Uid,
+		})
+
 		return nil
 	})
 }
@@ -45,7 +45,12 @@ func SaveProvisionedDashboard(cmd *models.SaveProvisionedDashboardCommand) error
 			cmd.DashboardProvisioning.Updated = cmd.Result.Updated.Unix()
 		}
 
-		return saveProvisionedData(sess, cmd.DashboardProvisioning, cmd.Result)
+		if err := saveProvisionedData(sess, cmd.DashboardProvisioning, cmd.Result); err != nil {
+			return err
+		}
+
+		publishDashboardSaved(sess, cmd.DashboardCmd, true)
+		return nil
 	})
 }
 
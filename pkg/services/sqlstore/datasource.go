@@ -72,6 +72,10 @@ func GetAllDataSources(query *models.GetAllDataSourcesQuery) error {
 
 func DeleteDataSourceById(cmd *models.DeleteDataSourceByIdCommand) error {
 	return inTransaction(func(sess *DBSession) error {
+		if _, err := sess.Exec("DELETE FROM data_source_acl WHERE data_source_id=? and org_id=?", cmd.Id, cmd.OrgId); err != nil {
+			return err
+		}
+
 		var rawSql = "DELETE FROM data_source WHERE id=? and org_id=?"
 		result, err := sess.Exec(rawSql, cmd.Id, cmd.OrgId)
 		affected, _ := result.RowsAffected()
@@ -82,6 +86,11 @@ func DeleteDataSourceById(cmd *models.DeleteDataSourceByIdCommand) error {
 
 func DeleteDataSourceByName(cmd *models.DeleteDataSourceByNameCommand) error {
 	return inTransaction(func(sess *DBSession) error {
+		deleteAcl := "DELETE FROM data_source_acl WHERE data_source_id IN (SELECT id FROM data_source WHERE name=? and org_id=?)"
+		if _, err := sess.Exec(deleteAcl, cmd.Name, cmd.OrgId); err != nil {
+			return err
+		}
+
 		var rawSql = "DELETE FROM data_source WHERE name=? and org_id=?"
 		result, err := sess.Exec(rawSql, cmd.Name, cmd.OrgId)
 		affected, _ := result.RowsAffected()
@@ -0,0 +1,269 @@
+package sqlstore
+
+import (
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", EnableDataSourcePermissions)
+	bus.AddHandler("sql", DisableDataSourcePermissions)
+	bus.AddHandler("sql", AddDataSourcePermission)
+	bus.AddHandler("sql", RemoveDataSourcePermission)
+	bus.AddHandler("sql", GetDataSourcePermissions)
+	bus.AddHandler("sql", GetDataSourceUserPermission)
+	bus.AddHandler("sql", FilterDataSourcesByPermission)
+}
+
+func EnableDataSourcePermissions(cmd *models.EnableDataSourcePermissionsCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := getDataSourceForAcl(sess, cmd.OrgId, cmd.DataSourceId); err != nil {
+			return err
+		}
+
+		_, err := sess.Exec("UPDATE data_source SET permissions_enabled = ? WHERE id = ? AND org_id = ?", true, cmd.DataSourceId, cmd.OrgId)
+		return err
+	})
+}
+
+func DisableDataSourcePermissions(cmd *models.DisableDataSourcePermissionsCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		if _, err := getDataSourceForAcl(sess, cmd.OrgId, cmd.DataSourceId); err != nil {
+			return err
+		}
+
+		if _, err := sess.Exec("DELETE FROM data_source_acl WHERE data_source_id = ? AND org_id = ?", cmd.DataSourceId, cmd.OrgId); err != nil {
+			return err
+		}
+
+		_, err := sess.Exec("UPDATE data_source SET permissions_enabled = ? WHERE id = ? AND org_id = ?", false, cmd.DataSourceId, cmd.OrgId)
+		return err
+	})
+}
+
+func AddDataSourcePermission(cmd *models.AddDataSourcePermissionCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		ds, err := getDataSourceForAcl(sess, cmd.OrgId, cmd.DataSourceId)
+		if err != nil {
+			return err
+		}
+
+		if !ds.PermissionsEnabled {
+			return models.ErrDataSourcePermissionsNotEnabled
+		}
+
+		if cmd.UserId == 0 && cmd.TeamId == 0 {
+			return models.ErrDataSourceAclInfoMissing
+		}
+
+		if cmd.UserId != 0 && cmd.TeamId != 0 {
+			return models.ErrDataSourceAclInfoAmbiguous
+		}
+
+		if !cmd.Permission.IsValid() {
+			return models.ErrDataSourcePermissionInvalid
+		}
+
+		var existing models.DataSourceAcl
+		var exists bool
+		if cmd.UserId != 0 {
+			res, err := sess.Query("SELECT 1 FROM org_user WHERE org_id = ? AND user_id = ?", cmd.OrgId, cmd.UserId)
+			if err != nil {
+				return err
+			}
+			if len(res) == 0 {
+				return models.ErrUserNotFound
+			}
+
+			exists, err = sess.Where("data_source_id = ? AND user_id = ?", cmd.DataSourceId, cmd.UserId).Get(&existing)
+			if err != nil {
+				return err
+			}
+		} else {
+			res, err := sess.Query("SELECT 1 FROM team WHERE org_id = ? AND id = ?", cmd.OrgId, cmd.TeamId)
+			if err != nil {
+				return err
+			}
+			if len(res) == 0 {
+				return models.ErrTeamNotFound
+			}
+
+			exists, err = sess.Where("data_source_id = ? AND team_id = ?", cmd.DataSourceId, cmd.TeamId).Get(&existing)
+			if err != nil {
+				return err
+			}
+		}
+
+		if exists {
+			return models.ErrDataSourcePermissionExists
+		}
+
+		acl := models.DataSourceAcl{
+			OrgId:        cmd.OrgId,
+			DataSourceId: cmd.DataSourceId,
+			UserId:       cmd.UserId,
+			TeamId:       cmd.TeamId,
+			Permission:   cmd.Permission,
+			Created:      time.Now(),
+			Updated:      time.Now(),
+		}
+
+		sess.Nullable("user_id", "team_id")
+		if _, err := sess.Insert(&acl); err != nil {
+			return err
+		}
+
+		cmd.Result = &acl
+		return nil
+	})
+}
+
+func RemoveDataSourcePermission(cmd *models.RemoveDataSourcePermissionCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		res, err := sess.Exec("DELETE FROM data_source_acl WHERE id = ? AND data_source_id = ? AND org_id = ?", cmd.Id, cmd.DataSourceId, cmd.OrgId)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return models.ErrDataSourcePermissionNotFound
+		}
+
+		return nil
+	})
+}
+
+func GetDataSourcePermissions(query *models.GetDataSourcePermissionsQuery) error {
+	ds, err := getDataSourceForAcl(newSession(), query.OrgId, query.DataSourceId)
+	if err != nil {
+		return err
+	}
+
+	rawSQL := `SELECT
+			dsa.id,
+			dsa.org_id,
+			dsa.data_source_id,
+			dsa.user_id,
+			dsa.team_id,
+			dsa.permission,
+			dsa.created,
+			dsa.updated,
+			u.login AS user_login,
+			u.email AS user_email,
+			t.name AS team,
+			t.email AS team_email
+		FROM data_source_acl AS dsa
+			LEFT JOIN ` + dialect.Quote("user") + ` AS u ON u.id = dsa.user_id
+			LEFT JOIN team t ON t.id = dsa.team_id
+		WHERE dsa.org_id = ? AND dsa.data_source_id = ?
+		ORDER BY dsa.id ASC`
+
+	permissions := make([]*models.DataSourceAclInfoDTO, 0)
+	if err := x.SQL(rawSQL, query.OrgId, query.DataSourceId).Find(&permissions); err != nil {
+		return err
+	}
+
+	for _, p := range permissions {
+		p.PermissionName = p.Permission.String()
+	}
+
+	query.Result = &models.DataSourcePermissionsDTO{
+		DataSourceId: ds.Id,
+		Enabled:      ds.PermissionsEnabled,
+		Permissions:  permissions,
+	}
+
+	return nil
+}
+
+func GetDataSourceUserPermission(query *models.GetDataSourceUserPermissionQuery) error {
+	var acls []*models.DataSourceAcl
+	err := x.Where("org_id = ? AND data_source_id = ?", query.OrgId, query.DataSourceId).
+		And("(user_id = ? OR team_id IN (SELECT team_id FROM team_member WHERE user_id = ?))", query.UserId, query.UserId).
+		Find(&acls)
+	if err != nil {
+		return err
+	}
+
+	query.Result = models.DsPermissionNoAccess
+	for _, acl := range acls {
+		if acl.Permission > query.Result {
+			query.Result = acl.Permission
+		}
+	}
+
+	return nil
+}
+
+// FilterDataSourcesByPermission removes the data sources the user is not allowed to query.
+// Data sources without permissions enabled can be queried by everyone in the org and
+// org admins can query all data sources.
+func FilterDataSourcesByPermission(query *models.DatasourcesPermissionFilterQuery) error {
+	if query.User == nil || query.User.OrgRole == models.ROLE_ADMIN {
+		query.Result = query.Datasources
+		return nil
+	}
+
+	restricted := make([]interface{}, 0)
+	for _, ds := range query.Datasources {
+		if ds.PermissionsEnabled {
+			restricted = append(restricted, ds.Id)
+		}
+	}
+
+	if len(restricted) == 0 {
+		query.Result = query.Datasources
+		return nil
+	}
+
+	allowed := make(map[int64]bool)
+	if query.User.UserId != 0 {
+		rawSQL := `SELECT DISTINCT data_source_id FROM data_source_acl
+			WHERE org_id = ? AND data_source_id IN (?` + strings.Repeat(",?", len(restricted)-1) + `)
+			AND (user_id = ? OR team_id IN (SELECT team_id FROM team_member WHERE user_id = ?))`
+
+		params := []interface{}{query.User.OrgId}
+		params = append(params, restricted...)
+		params = append(params, query.User.UserId, query.User.UserId)
+
+		var ids []int64
+		if err := x.SQL(rawSQL, params...).Find(&ids); err != nil {
+			return err
+		}
+
+		for _, id := range ids {
+			allowed[id] = true
+		}
+	}
+
+	query.Result = make([]*models.DataSource, 0, len(query.Datasources))
+	for _, ds := range query.Datasources {
+		if !ds.PermissionsEnabled || allowed[ds.Id] {
+			query.Result = append(query.Result, ds)
+		}
+	}
+
+	return nil
+}
+
+func getDataSourceForAcl(sess *DBSession, orgId int64, id int64) (*models.DataSource, error) {
+	ds := models.DataSource{}
+	exists, err := sess.Where("id = ? AND org_id = ?", id, orgId).Get(&ds)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists {
+		return nil, models.ErrDataSourceNotFound
+	}
+
+	return &ds, nil
+}
@@ -0,0 +1,146 @@
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDataSourceAcl(t *testing.T) {
+	InitTestDB(t)
+
+	setting.AutoAssignOrg = true
+	setting.AutoAssignOrgId = 1
+	setting.AutoAssignOrgRole = string(models.ROLE_VIEWER)
+
+	createViewer := func(t *testing.T, login string) *models.SignedInUser {
+		cmd := models.CreateUserCommand{Login: login, Email: login + "@test.com"}
+		require.NoError(t, CreateUser(context.Background(), &cmd))
+		return &models.SignedInUser{UserId: cmd.Result.Id, OrgId: 1, OrgRole: models.ROLE_VIEWER}
+	}
+
+	createDataSource := func(t *testing.T, name string) *models.DataSource {
+		cmd := models.AddDataSourceCommand{OrgId: 1, Name: name, Type: models.DS_MYSQL, Access: models.DS_ACCESS_PROXY, Url: "localhost:3306"}
+		require.NoError(t, AddDataSource(&cmd))
+		return cmd.Result
+	}
+
+	getDataSources := func(t *testing.T) []*models.DataSource {
+		query := models.GetDataSourcesQuery{OrgId: 1}
+		require.NoError(t, GetDataSources(&query))
+		return query.Result
+	}
+
+	filter := func(t *testing.T, user *models.SignedInUser) []string {
+		query := models.DatasourcesPermissionFilterQuery{User: user, Datasources: getDataSources(t)}
+		require.NoError(t, FilterDataSourcesByPermission(&query))
+
+		names := []string{}
+		for _, ds := range query.Result {
+			names = append(names, ds.Name)
+		}
+		return names
+	}
+
+	viewer := createViewer(t, "viewer")
+	teamMember := createViewer(t, "member")
+	outsider := createViewer(t, "outsider")
+	admin := &models.SignedInUser{UserId: 1000, OrgId: 1, OrgRole: models.ROLE_ADMIN}
+
+	teamCmd := models.CreateTeamCommand{Name: "analysts", OrgId: 1}
+	require.NoError(t, CreateTeam(&teamCmd))
+	require.NoError(t, AddTeamMember(&models.AddTeamMemberCommand{OrgId: 1, TeamId: teamCmd.Result.Id, UserId: teamMember.UserId}))
+
+	open := createDataSource(t, "open")
+	restricted := createDataSource(t, "restricted")
+
+	t.Run("Should allow everyone when permissions are not enabled", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{"open", "restricted"}, filter(t, outsider))
+	})
+
+	t.Run("Should not add permissions before permissions are enabled", func(t *testing.T) {
+		err := AddDataSourcePermission(&models.AddDataSourcePermissionCommand{OrgId: 1, DataSourceId: restricted.Id, UserId: viewer.UserId, Permission: models.DsPermissionQuery})
+		assert.Equal(t, models.ErrDataSourcePermissionsNotEnabled, err)
+	})
+
+	t.Run("Should only allow admins, users and teams in the acl when enabled", func(t *testing.T) {
+		require.NoError(t, EnableDataSourcePermissions(&models.EnableDataSourcePermissionsCommand{OrgId: 1, DataSourceId: restricted.Id}))
+
+		assert.ElementsMatch(t, []string{"open"}, filter(t, viewer))
+
+		require.NoError(t, AddDataSourcePermission(&models.AddDataSourcePermissionCommand{OrgId: 1, DataSourceId: restricted.Id, UserId: viewer.UserId, Permission: models.DsPermissionQuery}))
+		require.NoError(t, AddDataSourcePermission(&models.AddDataSourcePermissionCommand{OrgId: 1, DataSourceId: restricted.Id, TeamId: teamCmd.Result.Id, Permission: models.DsPermissionEdit}))
+
+		assert.ElementsMatch(t, []string{"open", "restricted"}, filter(t, viewer))
+		assert.ElementsMatch(t, []string{"open", "restricted"}, filter(t, teamMember))
+		assert.ElementsMatch(t, []string{"open", "restricted"}, filter(t, admin))
+		assert.ElementsMatch(t, []string{"open"}, filter(t, outsider))
+		assert.ElementsMatch(t, []string{"open"}, filter(t, &models.SignedInUser{OrgId: 1, OrgRole: models.ROLE_VIEWER}))
+	})
+
+	t.Run("Should validate permissions", func(t *testing.T) {
+		err := AddDataSourcePermission(&models.AddDataSourcePermissionCommand{OrgId: 1, DataSourceId: restricted.Id, UserId: viewer.UserId, Permission: models.DsPermissionQuery})
+		assert.Equal(t, models.ErrDataSourcePermissionExists, err)
+
+		err = AddDataSourcePermission(&models.AddDataSourcePermissionCommand{OrgId: 1, DataSourceId: restricted.Id, Permission: models.DsPermissionQuery})
+		assert.Equal(t, models.ErrDataSourceAclInfoMissing, err)
+
+		err = AddDataSourcePermission(&models.AddDataSourcePermissionCommand{OrgId: 1, DataSourceId: restricted.Id, UserId: outsider.UserId, Permission: 5})
+		assert.Equal(t, models.ErrDataSourcePermissionInvalid, err)
+
+		err = AddDataSourcePermission(&models.AddDataSourcePermissionCommand{OrgId: 1, DataSourceId: restricted.Id, UserId: 12345, Permission: models.DsPermissionQuery})
+		assert.Equal(t, models.ErrUserNotFound, err)
+	})
+
+	t.Run("Should return the highest permission of the user", func(t *testing.T) {
+		query := models.GetDataSourceUserPermissionQuery{OrgId: 1, DataSourceId: restricted.Id, UserId: viewer.UserId}
+		require.NoError(t, GetDataSourceUserPermission(&query))
+		assert.Equal(t, models.DsPermissionQuery, query.Result)
+
+		query = models.GetDataSourceUserPermissionQuery{OrgId: 1, DataSourceId: restricted.Id, UserId: teamMember.UserId}
+		require.NoError(t, GetDataSourceUserPermission(&query))
+		assert.Equal(t, models.DsPermissionEdit, query.Result)
+
+		query = models.GetDataSourceUserPermissionQuery{OrgId: 1, DataSourceId: open.Id, UserId: teamMember.UserId}
+		require.NoError(t, GetDataSourceUserPermission(&query))
+		assert.Equal(t, models.DsPermissionNoAccess, query.Result)
+	})
+
+	t.Run("Should list and remove permissions", func(t *testing.T) {
+		query := models.GetDataSourcePermissionsQuery{OrgId: 1, DataSourceId: restricted.Id}
+		require.NoError(t, GetDataSourcePermissions(&query))
+		assert.True(t, query.Result.Enabled)
+		require.Len(t, query.Result.Permissions, 2)
+		assert.Equal(t, "viewer", query.Result.Permissions[0].UserLogin)
+		assert.Equal(t, "Query", query.Result.Permissions[0].PermissionName)
+		assert.Equal(t, "analysts", query.Result.Permissions[1].Team)
+		assert.Equal(t, "Edit", query.Result.Permissions[1].PermissionName)
+
+		require.NoError(t, RemoveDataSourcePermission(&models.RemoveDataSourcePermissionCommand{OrgId: 1, DataSourceId: restricted.Id, Id: query.Result.Permissions[0].Id}))
+		assert.ElementsMatch(t, []string{"open"}, filter(t, viewer))
+
+		err := RemoveDataSourcePermission(&models.RemoveDataSourcePermissionCommand{OrgId: 1, DataSourceId: restricted.Id, Id: query.Result.Permissions[0].Id})
+		assert.Equal(t, models.ErrDataSourcePermissionNotFound, err)
+	})
+
+	t.Run("Should keep permissions enabled when the data source is updated", func(t *testing.T) {
+		err := UpdateDataSource(&models.UpdateDataSourceCommand{Id: restricted.Id, OrgId: 1, Name: "restricted", Type: models.DS_MYSQL, Access: models.DS_ACCESS_PROXY, Url: "localhost:3307"})
+		require.NoError(t, err)
+
+		assert.ElementsMatch(t, []string{"open"}, filter(t, outsider))
+	})
+
+	t.Run("Should remove all permissions when disabled", func(t *testing.T) {
+		require.NoError(t, DisableDataSourcePermissions(&models.DisableDataSourcePermissionsCommand{OrgId: 1, DataSourceId: restricted.Id}))
+
+		query := models.GetDataSourcePermissionsQuery{OrgId: 1, DataSourceId: restricted.Id}
+		require.NoError(t, GetDataSourcePermissions(&query))
+		assert.False(t, query.Result.Enabled)
+		assert.Empty(t, query.Result.Permissions)
+		assert.ElementsMatch(t, []string{"open", "restricted"}, filter(t, outsider))
+	})
+}
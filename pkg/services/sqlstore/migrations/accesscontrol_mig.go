@@ -0,0 +1,62 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAccessControlMigrations(mg *Migrator) {
+	roleV1 := Table{
+		Name: "role",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "name", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "description", Type: DB_Text, Nullable: true},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id"}},
+			{Cols: []string{"org_id", "name"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create role table", NewAddTableMigration(roleV1))
+	addTableIndicesMigrations(mg, "v1", roleV1)
+
+	rolePermissionV1 := Table{
+		Name: "role_permission",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "role_id", Type: DB_BigInt, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"role_id"}},
+			{Cols: []string{"role_id", "action"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create role_permission table", NewAddTableMigration(rolePermissionV1))
+	addTableIndicesMigrations(mg, "v1", rolePermissionV1)
+
+	roleAssignmentV1 := Table{
+		Name: "role_assignment",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "role_id", Type: DB_BigInt, Nullable: false},
+			{Name: "subject_type", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "subject_id", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "subject_type", "subject_id"}},
+			{Cols: []string{"role_id", "subject_type", "subject_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create role_assignment table", NewAddTableMigration(roleAssignmentV1))
+	addTableIndicesMigrations(mg, "v1", roleAssignmentV1)
+}
@@ -0,0 +1,34 @@
+package migrations
+
+import (
+	. "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+)
+
+func addAuditMigrations(mg *Migrator) {
+	auditEntryV1 := Table{
+		Name: "audit_entry",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "org_id", Type: DB_BigInt, Nullable: false},
+			{Name: "actor_type", Type: DB_NVarchar, Length: 20, Nullable: false},
+			{Name: "actor_id", Type: DB_BigInt, Nullable: false},
+			{Name: "actor_login", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "action", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "resource_type", Type: DB_NVarchar, Length: 100, Nullable: false},
+			{Name: "resource_id", Type: DB_NVarchar, Length: 190, Nullable: false},
+			{Name: "before_state", Type: DB_MediumText, Nullable: true},
+			{Name: "after_state", Type: DB_MediumText, Nullable: true},
+			{Name: "client_ip", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "status", Type: DB_Int, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"org_id", "created"}},
+			{Cols: []string{"org_id", "resource_type", "resource_id"}},
+			{Cols: []string{"created"}},
+		},
+	}
+
+	mg.AddMigration("create audit_entry table", NewAddTableMigration(auditEntryV1))
+	addTableIndicesMigrations(mg, "v1", auditEntryV1)
+}
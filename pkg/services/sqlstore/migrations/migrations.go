@@ -34,6 +34,10 @@ func AddMigrations(mg *Migrator) {
 	addServerlockMigrations(mg)
 	addUserAuthTokenMigrations(mg)
 	addCacheMigration(mg)
+	addAuditMigrations(mg)
+	addAccessControlMigrations(mg)
+	addDataSourceAclMigrations(mg)
+	addUserTotpMigrations(mg)
 }
 
 func addMigrationLogMigrations(mg *Migrator) {
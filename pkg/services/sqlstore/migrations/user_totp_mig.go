@@ -0,0 +1,45 @@
+package migrations
+
+import . "github.com/grafana/grafana/pkg/services/sqlstore/migrator"
+
+func addUserTotpMigrations(mg *Migrator) {
+	userTotpV1 := Table{
+		Name: "user_totp",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "secret", Type: DB_Text, Nullable: false},
+			{Name: "enabled", Type: DB_Bool, Nullable: false},
+			{Name: "last_used_step", Type: DB_BigInt, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+			{Name: "updated", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id"}, Type: UniqueIndex},
+		},
+	}
+
+	mg.AddMigration("create user_totp table", NewAddTableMigration(userTotpV1))
+	addTableIndicesMigrations(mg, "v1", userTotpV1)
+
+	recoveryCodeV1 := Table{
+		Name: "user_totp_recovery_code",
+		Columns: []*Column{
+			{Name: "id", Type: DB_BigInt, IsPrimaryKey: true, IsAutoIncrement: true},
+			{Name: "user_id", Type: DB_BigInt, Nullable: false},
+			{Name: "code", Type: DB_NVarchar, Length: 255, Nullable: false},
+			{Name: "salt", Type: DB_NVarchar, Length: 50, Nullable: false},
+			{Name: "created", Type: DB_DateTime, Nullable: false},
+		},
+		Indices: []*Index{
+			{Cols: []string{"user_id"}},
+		},
+	}
+
+	mg.AddMigration("create user_totp_recovery_code table", NewAddTableMigration(recoveryCodeV1))
+	addTableIndicesMigrations(mg, "v1", recoveryCodeV1)
+
+	mg.AddMigration("Add require_two_factor column to org", NewAddColumnMigration(Table{Name: "org"}, &Column{
+		Name: "require_two_factor", Type: DB_Bool, Nullable: false, Default: "0",
+	}))
+}
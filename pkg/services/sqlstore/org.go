@@ -204,9 +204,13 @@ func DeleteOrg(cmd *models.DeleteOrgCommand) error {
 			"DELETE FROM dashboard WHERE org_id = ?",
 			"DELETE FROM api_key WHERE org_id = ?",
 			"DELETE FROM data_source WHERE org_id = ?",
+			"DELETE FROM data_source_acl WHERE org_id = ?",
 			"DELETE FROM org_user WHERE org_id = ?",
 			"DELETE FROM org WHERE id = ?",
 			"DELETE FROM temp_user WHERE org_id = ?",
+			"DELETE FROM role_permission WHERE EXISTS (SELECT 1 FROM role WHERE org_id = ? AND role_permission.role_id = role.id)",
+			"DELETE FROM role_assignment WHERE org_id = ?",
+			"DELETE FROM role WHERE org_id = ?",
 		}
 
 		for _, sql := range deletes {
@@ -156,6 +156,8 @@ func RemoveOrgUser(cmd *models.RemoveOrgUserCommand) error {
 			"DELETE FROM org_user WHERE org_id=? and user_id=?",
 			"DELETE FROM dashboard_acl WHERE org_id=? and user_id = ?",
 			"DELETE FROM team_member WHERE org_id=? and user_id = ?",
+			"DELETE FROM role_assignment WHERE org_id=? and subject_type = 'user' and subject_id = ?",
+			"DELETE FROM data_source_acl WHERE org_id=? and user_id = ?",
 		}
 
 		for _, sql := range deletes {
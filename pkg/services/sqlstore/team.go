@@ -113,6 +113,8 @@ func DeleteTeam(cmd *models.DeleteTeamCommand) error {
 			"DELETE FROM team_member WHERE org_id=? and team_id = ?",
 			"DELETE FROM team WHERE org_id=? and id = ?",
 			"DELETE FROM dashboard_acl WHERE org_id=? and team_id = ?",
+			"DELETE FROM role_assignment WHERE org_id=? and subject_type = 'team' and subject_id = ?",
+			"DELETE FROM data_source_acl WHERE org_id=? and team_id = ?",
 		}
 
 		for _, sql := range deletes {
@@ -577,6 +577,10 @@ func deleteUserInTransaction(sess *DBSession, cmd *models.DeleteUserCommand) err
 		"DELETE FROM user_auth WHERE user_id = ?",
 		"DELETE FROM user_auth_token WHERE user_id = ?",
 		"DELETE FROM quota WHERE user_id = ?",
+		"DELETE FROM role_assignment WHERE subject_type = 'user' AND subject_id = ?",
+		"DELETE FROM data_source_acl WHERE user_id = ?",
+		"DELETE FROM user_totp WHERE user_id = ?",
+		"DELETE FROM user_totp_recovery_code WHERE user_id = ?",
 	}
 
 	for _, sql := range deletes {
@@ -0,0 +1,172 @@
+package sqlstore
+
+import (
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/models"
+)
+
+func init() {
+	bus.AddHandler("sql", SaveUserTotp)
+	bus.AddHandler("sql", EnableUserTotp)
+	bus.AddHandler("sql", UpdateUserTotpLastUsedStep)
+	bus.AddHandler("sql", DeleteUserTotp)
+	bus.AddHandler("sql", GetUserTotp)
+	bus.AddHandler("sql", GetUserTotpRecoveryCodes)
+	bus.AddHandler("sql", UseUserTotpRecoveryCode)
+	bus.AddHandler("sql", SetOrgRequireTwoFactor)
+}
+
+func SaveUserTotp(cmd *models.SaveUserTotpCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		var existing models.UserTotp
+		exists, err := sess.Where("user_id = ?", cmd.UserId).Get(&existing)
+		if err != nil {
+			return err
+		}
+
+		if exists && existing.Enabled {
+			return models.ErrUserTotpAlreadyEnabled
+		}
+
+		if exists {
+			if _, err := sess.Exec("DELETE FROM user_totp WHERE user_id = ?", cmd.UserId); err != nil {
+				return err
+			}
+		}
+
+		totp := models.UserTotp{
+			UserId:  cmd.UserId,
+			Secret:  cmd.Secret,
+			Created: time.Now(),
+			Updated: time.Now(),
+		}
+
+		_, err = sess.Insert(&totp)
+		return err
+	})
+}
+
+func EnableUserTotp(cmd *models.EnableUserTotpCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		res, err := sess.Exec("UPDATE user_totp SET enabled = ?, last_used_step = ?, updated = ? WHERE user_id = ?", true, cmd.LastUsedStep, time.Now(), cmd.UserId)
+		if err != nil {
+			return err
+		}
+
+		if rows, err := res.RowsAffected(); err != nil {
+			return err
+		} else if rows == 0 {
+			return models.ErrUserTotpNotFound
+		}
+
+		if _, err := sess.Exec("DELETE FROM user_totp_recovery_code WHERE user_id = ?", cmd.UserId); err != nil {
+			return err
+		}
+
+		for _, code := range cmd.RecoveryCodes {
+			code.UserId = cmd.UserId
+			code.Created = time.Now()
+			if _, err := sess.Insert(code); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func UpdateUserTotpLastUsedStep(cmd *models.UpdateUserTotpLastUsedStepCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		// only move forward to reject codes that have been used before
+		res, err := sess.Exec("UPDATE user_totp SET last_used_step = ? WHERE user_id = ? AND last_used_step < ?", cmd.LastUsedStep, cmd.UserId, cmd.LastUsedStep)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return models.ErrUserTotpCodeUsed
+		}
+
+		return nil
+	})
+}
+
+func DeleteUserTotp(cmd *models.DeleteUserTotpCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		deletes := []string{
+			"DELETE FROM user_totp WHERE user_id = ?",
+			"DELETE FROM user_totp_recovery_code WHERE user_id = ?",
+		}
+
+		for _, sql := range deletes {
+			if _, err := sess.Exec(sql, cmd.UserId); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func GetUserTotp(query *models.GetUserTotpQuery) error {
+	var totp models.UserTotp
+	exists, err := x.Where("user_id = ?", query.UserId).Get(&totp)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return models.ErrUserTotpNotFound
+	}
+
+	query.Result = &totp
+	return nil
+}
+
+func GetUserTotpRecoveryCodes(query *models.GetUserTotpRecoveryCodesQuery) error {
+	query.Result = make([]*models.UserTotpRecoveryCode, 0)
+	return x.Where("user_id = ?", query.UserId).Find(&query.Result)
+}
+
+func UseUserTotpRecoveryCode(cmd *models.UseUserTotpRecoveryCodeCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		res, err := sess.Exec("DELETE FROM user_totp_recovery_code WHERE id = ? AND user_id = ?", cmd.Id, cmd.UserId)
+		if err != nil {
+			return err
+		}
+
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rows == 0 {
+			return models.ErrTotpRecoveryCodeNotFound
+		}
+
+		return nil
+	})
+}
+
+func SetOrgRequireTwoFactor(cmd *models.SetOrgRequireTwoFactorCommand) error {
+	return inTransaction(func(sess *DBSession) error {
+		exists, err := sess.ID(cmd.OrgId).Get(&models.Org{})
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return models.ErrOrgNotFound
+		}
+
+		_, err = sess.Exec("UPDATE org SET require_two_factor = ?, updated = ? WHERE id = ?", cmd.RequireTwoFactor, time.Now(), cmd.OrgId)
+		return err
+	})
+}
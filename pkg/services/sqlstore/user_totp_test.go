@@ -0,0 +1,100 @@
+package sqlstore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserTotp(t *testing.T) {
+	InitTestDB(t)
+
+	userCmd := models.CreateUserCommand{Login: "totp", Email: "totp@test.com"}
+	require.NoError(t, CreateUser(context.Background(), &userCmd))
+	userID := userCmd.Result.Id
+
+	t.Run("Should return not found before enrollment", func(t *testing.T) {
+		err := GetUserTotp(&models.GetUserTotpQuery{UserId: userID})
+		assert.Equal(t, models.ErrUserTotpNotFound, err)
+
+		err = EnableUserTotp(&models.EnableUserTotpCommand{UserId: userID})
+		assert.Equal(t, models.ErrUserTotpNotFound, err)
+	})
+
+	t.Run("Should replace the secret until enabled", func(t *testing.T) {
+		require.NoError(t, SaveUserTotp(&models.SaveUserTotpCommand{UserId: userID, Secret: "first"}))
+		require.NoError(t, SaveUserTotp(&models.SaveUserTotpCommand{UserId: userID, Secret: "second"}))
+
+		query := models.GetUserTotpQuery{UserId: userID}
+		require.NoError(t, GetUserTotp(&query))
+		assert.Equal(t, "second", query.Result.Secret)
+		assert.False(t, query.Result.Enabled)
+	})
+
+	t.Run("Should enable and store recovery codes", func(t *testing.T) {
+		err := EnableUserTotp(&models.EnableUserTotpCommand{
+			UserId:        userID,
+			LastUsedStep:  100,
+			RecoveryCodes: []*models.UserTotpRecoveryCode{{Code: "a", Salt: "s"}, {Code: "b", Salt: "s"}},
+		})
+		require.NoError(t, err)
+
+		query := models.GetUserTotpQuery{UserId: userID}
+		require.NoError(t, GetUserTotp(&query))
+		assert.True(t, query.Result.Enabled)
+		assert.Equal(t, int64(100), query.Result.LastUsedStep)
+
+		err = SaveUserTotp(&models.SaveUserTotpCommand{UserId: userID, Secret: "third"})
+		assert.Equal(t, models.ErrUserTotpAlreadyEnabled, err)
+	})
+
+	t.Run("Should only move the last used step forward", func(t *testing.T) {
+		err := UpdateUserTotpLastUsedStep(&models.UpdateUserTotpLastUsedStepCommand{UserId: userID, LastUsedStep: 90})
+		assert.Equal(t, models.ErrUserTotpCodeUsed, err)
+		require.NoError(t, UpdateUserTotpLastUsedStep(&models.UpdateUserTotpLastUsedStepCommand{UserId: userID, LastUsedStep: 101}))
+		err = UpdateUserTotpLastUsedStep(&models.UpdateUserTotpLastUsedStepCommand{UserId: userID, LastUsedStep: 101})
+		assert.Equal(t, models.ErrUserTotpCodeUsed, err)
+
+		query := models.GetUserTotpQuery{UserId: userID}
+		require.NoError(t, GetUserTotp(&query))
+		assert.Equal(t, int64(101), query.Result.LastUsedStep)
+	})
+
+	t.Run("Should use recovery codes once", func(t *testing.T) {
+		query := models.GetUserTotpRecoveryCodesQuery{UserId: userID}
+		require.NoError(t, GetUserTotpRecoveryCodes(&query))
+		require.Len(t, query.Result, 2)
+
+		cmd := models.UseUserTotpRecoveryCodeCommand{UserId: userID, Id: query.Result[0].Id}
+		require.NoError(t, UseUserTotpRecoveryCode(&cmd))
+		assert.Equal(t, models.ErrTotpRecoveryCodeNotFound, UseUserTotpRecoveryCode(&cmd))
+
+		require.NoError(t, GetUserTotpRecoveryCodes(&query))
+		assert.Len(t, query.Result, 1)
+	})
+
+	t.Run("Should delete totp and recovery codes", func(t *testing.T) {
+		require.NoError(t, DeleteUserTotp(&models.DeleteUserTotpCommand{UserId: userID}))
+
+		err := GetUserTotp(&models.GetUserTotpQuery{UserId: userID})
+		assert.Equal(t, models.ErrUserTotpNotFound, err)
+
+		query := models.GetUserTotpRecoveryCodesQuery{UserId: userID}
+		require.NoError(t, GetUserTotpRecoveryCodes(&query))
+		assert.Empty(t, query.Result)
+	})
+
+	t.Run("Should set the two-factor policy of an org", func(t *testing.T) {
+		require.NoError(t, SetOrgRequireTwoFactor(&models.SetOrgRequireTwoFactorCommand{OrgId: userCmd.Result.OrgId, RequireTwoFactor: true}))
+
+		query := models.GetOrgByIdQuery{Id: userCmd.Result.OrgId}
+		require.NoError(t, GetOrgById(&query))
+		assert.True(t, query.Result.RequireTwoFactor)
+
+		err := SetOrgRequireTwoFactor(&models.SetOrgRequireTwoFactorCommand{OrgId: 12345, RequireTwoFactor: true})
+		assert.Equal(t, models.ErrOrgNotFound, err)
+	})
+}
@@ -0,0 +1,52 @@
+package twofactor
+
+import (
+	"strings"
+
+	"github.com/grafana/grafana/pkg/models"
+	macaron "gopkg.in/macaron.v1"
+)
+
+// allowedPaths are API paths a user can access before setting up two-factor
+// authentication when it is required by the organization.
+var allowedPaths = []string{
+	"/api/user/two-factor",
+	"/api/user/orgs",
+	"/api/user/using/",
+	"/api/login/ping",
+	"/api/frontend/settings",
+}
+
+// Middleware returns a handler that rejects API requests from users that have not set up
+// two-factor authentication in organizations requiring it. It needs to be added after the context handler.
+func (s *TwoFactorService) Middleware() macaron.Handler {
+	return func(c *models.ReqContext) {
+		if !c.IsSignedIn || c.UserToken == nil || !isEnforcedPath(c.Req.URL.Path) {
+			return
+		}
+
+		required, err := s.IsRequired(c.SignedInUser)
+		if err != nil {
+			c.JsonApiErr(500, "Failed to check two-factor authentication policy", err)
+			return
+		}
+
+		if required {
+			c.JsonApiErr(403, "Two-factor authentication is required by the organization", nil)
+		}
+	}
+}
+
+func isEnforcedPath(path string) bool {
+	if !strings.HasPrefix(path, "/api/") || path == "/api/user" {
+		return false
+	}
+
+	for _, allowed := range allowedPaths {
+		if strings.HasPrefix(path, allowed) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,490 @@
+// Package twofactor implements TOTP two-factor authentication for users with a Grafana login.
+package twofactor
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/totp"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/registry"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/util"
+)
+
+var (
+	ErrDisabled            = errors.New("Two-factor authentication is disabled")
+	ErrInvalidCode         = errors.New("Invalid two-factor authentication code")
+	ErrLoginExpired        = errors.New("Two-factor login expired, please login again")
+	ErrExternalUser        = errors.New("Two-factor authentication can only be set up for users with a Grafana login")
+	ErrTooManyCodeAttempts = errors.New("Too many invalid two-factor authentication codes, please login again")
+	ErrLockedOut           = errors.New("Too many invalid two-factor authentication codes, please try again later")
+)
+
+const (
+	recoveryCodeCount  = 10
+	recoveryCodeLength = 10
+
+	// pendingLoginTTL is how long a user has to enter the code after entering the password
+	pendingLoginTTL = 5 * time.Minute
+	// maxCodeAttempts is the number of codes that can be tried for a single login
+	maxCodeAttempts = 5
+	// maxFailedCodes is the number of invalid codes of a user, across logins, after which
+	// logins of the user are refused until failedCodesWindow has passed without failures
+	maxFailedCodes    = 10
+	failedCodesWindow = 15 * time.Minute
+	// codeSkew is the number of time steps a code is accepted before and after the current one
+	codeSkew = 1
+
+	policyCacheTTL    = 30 * time.Second
+	policyCachePrefix = "two-factor-required-"
+)
+
+var getTime = time.Now
+
+func init() {
+	remotecache.Register(&pendingLogin{})
+	remotecache.Register(&failedCodes{})
+	registry.RegisterService(&TwoFactorService{})
+}
+
+// TwoFactorService enrolls users in TOTP two-factor authentication, verifies
+// codes during login and enforces the two-factor policy of organizations.
+type TwoFactorService struct {
+	Bus          bus.Bus                  `inject:""`
+	Cfg          *setting.Cfg             `inject:""`
+	RemoteCache  *remotecache.RemoteCache `inject:""`
+	CacheService *localcache.CacheService `inject:""`
+
+	log log.Logger
+}
+
+// Enrollment is the secret of a user that has started to set up two-factor authentication.
+type Enrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type pendingLogin struct {
+	UserId   int64
+	Attempts int
+}
+
+// failedCodes counts the invalid codes entered for a user in all of its pending logins.
+type failedCodes struct {
+	Count int
+}
+
+func (s *TwoFactorService) Init() error {
+	s.log = log.New("twofactor")
+	return nil
+}
+
+// IsEnabled returns true if the user has enabled two-factor authentication.
+func (s *TwoFactorService) IsEnabled(userID int64) (bool, error) {
+	query := models.GetUserTotpQuery{UserId: userID}
+	if err := s.Bus.Dispatch(&query); err != nil {
+		if err == models.ErrUserTotpNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return query.Result.Enabled, nil
+}
+
+// Enroll generates a new secret for the user. Two-factor authentication is
+// not enabled until the user has confirmed a code generated from the secret.
+func (s *TwoFactorService) Enroll(user *models.SignedInUser) (*Enrollment, error) {
+	if !s.Cfg.TwoFactorEnabled {
+		return nil, ErrDisabled
+	}
+
+	isExternal, err := s.isExternalUser(user.UserId)
+	if err != nil {
+		return nil, err
+	}
+	if isExternal {
+		return nil, ErrExternalUser
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := util.Encrypt([]byte(secret), setting.SecretKey)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := models.SaveUserTotpCommand{UserId: user.UserId, Secret: base64.StdEncoding.EncodeToString(encrypted)}
+	if err := s.Bus.Dispatch(&cmd); err != nil {
+		return nil, err
+	}
+
+	account := user.Login
+	if user.Email != "" {
+		account = user.Email
+	}
+
+	return &Enrollment{
+		Secret: secret,
+		URI:    totp.URI(s.Cfg.TwoFactorIssuer, account, secret),
+	}, nil
+}
+
+// Activate enables two-factor authentication if the code matches the secret from the
+// enrollment and returns the recovery codes of the user.
+func (s *TwoFactorService) Activate(userID int64, code string) ([]string, error) {
+	query := models.GetUserTotpQuery{UserId: userID}
+	if err := s.Bus.Dispatch(&query); err != nil {
+		return nil, err
+	}
+
+	if query.Result.Enabled {
+		return nil, models.ErrUserTotpAlreadyEnabled
+	}
+
+	step, ok, err := s.validateCode(query.Result, code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, ErrInvalidCode
+	}
+
+	codes, hashed, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := models.EnableUserTotpCommand{UserId: userID, LastUsedStep: step, RecoveryCodes: hashed}
+	if err := s.Bus.Dispatch(&cmd); err != nil {
+		return nil, err
+	}
+
+	s.invalidatePolicyCache(userID)
+	return codes, nil
+}
+
+// Disable removes the two-factor authentication of the user.
+func (s *TwoFactorService) Disable(userID int64) error {
+	if err := s.Bus.Dispatch(&models.DeleteUserTotpCommand{UserId: userID}); err != nil {
+		return err
+	}
+
+	s.invalidatePolicyCache(userID)
+	return nil
+}
+
+// Verify checks a TOTP code or a recovery code of a user with two-factor authentication enabled.
+// Recovery codes can only be used once.
+func (s *TwoFactorService) Verify(userID int64, code string) error {
+	query := models.GetUserTotpQuery{UserId: userID}
+	if err := s.Bus.Dispatch(&query); err != nil {
+		return err
+	}
+
+	if !query.Result.Enabled {
+		return models.ErrUserTotpNotFound
+	}
+
+	step, ok, err := s.validateCode(query.Result, code)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		if step <= query.Result.LastUsedStep {
+			// the code has already been used
+			return ErrInvalidCode
+		}
+
+		err := s.Bus.Dispatch(&models.UpdateUserTotpLastUsedStepCommand{UserId: userID, LastUsedStep: step})
+		if err == models.ErrUserTotpCodeUsed {
+			// a concurrent request used the code first
+			return ErrInvalidCode
+		}
+		return err
+	}
+
+	return s.useRecoveryCode(userID, code)
+}
+
+// StartLogin is called after the password of a user with two-factor authentication has been
+// verified. It returns a token that has to be presented together with the code to complete the login.
+func (s *TwoFactorService) StartLogin(userID int64) (string, error) {
+	token, err := util.GetRandomString(32)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.RemoteCache.Set(pendingLoginKey(token), &pendingLogin{UserId: userID}, pendingLoginTTL); err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// CompleteLogin verifies the code for a login started with StartLogin and
+// returns the id of the user that should be logged in.
+func (s *TwoFactorService) CompleteLogin(token string, code string) (int64, error) {
+	key := pendingLoginKey(token)
+
+	value, err := s.RemoteCache.Get(key)
+	if err != nil {
+		if err == remotecache.ErrCacheItemNotFound {
+			return 0, ErrLoginExpired
+		}
+		return 0, err
+	}
+
+	pending, ok := value.(*pendingLogin)
+	if !ok {
+		return 0, ErrLoginExpired
+	}
+
+	failed, err := s.getFailedCodes(pending.UserId)
+	if err != nil {
+		return 0, err
+	}
+	if failed.Count >= maxFailedCodes {
+		return 0, ErrLockedOut
+	}
+
+	if err := s.Verify(pending.UserId, code); err != nil {
+		if err != ErrInvalidCode {
+			return 0, err
+		}
+
+		failed.Count++
+		if err := s.RemoteCache.Set(failedCodesKey(pending.UserId), failed, failedCodesWindow); err != nil {
+			return 0, err
+		}
+		if failed.Count >= maxFailedCodes {
+			s.log.Warn("Too many invalid two-factor authentication codes, refusing logins", "userId", pending.UserId)
+			if err := s.RemoteCache.Delete(key); err != nil {
+				s.log.Error("Failed to delete pending two-factor login", "error", err)
+			}
+			return 0, ErrLockedOut
+		}
+
+		pending.Attempts++
+		if pending.Attempts >= maxCodeAttempts {
+			if err := s.RemoteCache.Delete(key); err != nil {
+				s.log.Error("Failed to delete pending two-factor login", "error", err)
+			}
+			return 0, ErrTooManyCodeAttempts
+		}
+
+		if err := s.RemoteCache.Set(key, pending, pendingLoginTTL); err != nil {
+			return 0, err
+		}
+		return 0, ErrInvalidCode
+	}
+
+	if err := s.RemoteCache.Delete(key); err != nil {
+		s.log.Error("Failed to delete pending two-factor login", "error", err)
+	}
+	if failed.Count > 0 {
+		if err := s.RemoteCache.Delete(failedCodesKey(pending.UserId)); err != nil {
+			s.log.Error("Failed to reset invalid two-factor codes", "error", err)
+		}
+	}
+
+	return pending.UserId, nil
+}
+
+func (s *TwoFactorService) getFailedCodes(userID int64) (*failedCodes, error) {
+	value, err := s.RemoteCache.Get(failedCodesKey(userID))
+	if err != nil {
+		if err == remotecache.ErrCacheItemNotFound {
+			return &failedCodes{}, nil
+		}
+		return nil, err
+	}
+
+	if failed, ok := value.(*failedCodes); ok {
+		return failed, nil
+	}
+	return &failedCodes{}, nil
+}
+
+// IsRequired returns true if the organization requires two-factor authentication and
+// the user has a Grafana login but has not enabled two-factor authentication.
+func (s *TwoFactorService) IsRequired(user *models.SignedInUser) (bool, error) {
+	if !s.Cfg.TwoFactorEnabled || user.UserId == 0 || user.OrgId == 0 {
+		return false, nil
+	}
+
+	cacheKey := fmt.Sprintf("%s%d-%d", policyCachePrefix, user.UserId, user.OrgId)
+	if cached, found := s.CacheService.Get(cacheKey); found {
+		return cached.(bool), nil
+	}
+
+	required, err := s.isRequired(user)
+	if err != nil {
+		return false, err
+	}
+
+	s.CacheService.Set(cacheKey, required, policyCacheTTL)
+	return required, nil
+}
+
+func (s *TwoFactorService) isRequired(user *models.SignedInUser) (bool, error) {
+	orgQuery := models.GetOrgByIdQuery{Id: user.OrgId}
+	if err := s.Bus.Dispatch(&orgQuery); err != nil {
+		return false, err
+	}
+
+	if !orgQuery.Result.RequireTwoFactor {
+		return false, nil
+	}
+
+	enabled, err := s.IsEnabled(user.UserId)
+	if err != nil || enabled {
+		return false, err
+	}
+
+	isExternal, err := s.isExternalUser(user.UserId)
+	if err != nil {
+		return false, err
+	}
+
+	return !isExternal, nil
+}
+
+// InvalidateOrgPolicy makes sure a changed two-factor policy of an organization is applied to the next request.
+func (s *TwoFactorService) InvalidateOrgPolicy() {
+	s.deleteCachedPolicies(policyCachePrefix)
+}
+
+func (s *TwoFactorService) invalidatePolicyCache(userID int64) {
+	s.deleteCachedPolicies(fmt.Sprintf("%s%d-", policyCachePrefix, userID))
+}
+
+func (s *TwoFactorService) deleteCachedPolicies(prefix string) {
+	for key := range s.CacheService.Items() {
+		if strings.HasPrefix(key, prefix) {
+			s.CacheService.Delete(key)
+		}
+	}
+}
+
+func (s *TwoFactorService) validateCode(userTotp *models.UserTotp, code string) (int64, bool, error) {
+	secret, err := decryptSecret(userTotp.Secret)
+	if err != nil {
+		return 0, false, err
+	}
+
+	step, ok := totp.Validate(secret, code, getTime(), codeSkew)
+	return step, ok, nil
+}
+
+func (s *TwoFactorService) useRecoveryCode(userID int64, code string) error {
+	code = normalizeRecoveryCode(code)
+	if len(code) != recoveryCodeLength {
+		return ErrInvalidCode
+	}
+
+	query := models.GetUserTotpRecoveryCodesQuery{UserId: userID}
+	if err := s.Bus.Dispatch(&query); err != nil {
+		return err
+	}
+
+	for _, recoveryCode := range query.Result {
+		hashed, err := util.EncodePassword(code, recoveryCode.Salt)
+		if err != nil {
+			return err
+		}
+
+		if hashed != recoveryCode.Code {
+			continue
+		}
+
+		if err := s.Bus.Dispatch(&models.UseUserTotpRecoveryCodeCommand{UserId: userID, Id: recoveryCode.Id}); err != nil {
+			if err == models.ErrTotpRecoveryCodeNotFound {
+				return ErrInvalidCode
+			}
+			return err
+		}
+
+		s.log.Info("Recovery code used", "userId", userID)
+		return nil
+	}
+
+	return ErrInvalidCode
+}
+
+func generateRecoveryCodes() ([]string, []*models.UserTotpRecoveryCode, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashed := make([]*models.UserTotpRecoveryCode, 0, recoveryCodeCount)
+
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := util.GetRandomString(recoveryCodeLength, []byte("abcdefghjkmnpqrstuvwxyz23456789")...)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		salt, err := util.GetRandomString(10)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		hash, err := util.EncodePassword(code, salt)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		codes = append(codes, code[:5]+"-"+code[5:])
+		hashed = append(hashed, &models.UserTotpRecoveryCode{Code: hash, Salt: salt})
+	}
+
+	return codes, hashed, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
+
+func decryptSecret(encoded string) (string, error) {
+	encrypted, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+
+	secret, err := util.Decrypt(encrypted, setting.SecretKey)
+	if err != nil {
+		return "", err
+	}
+
+	return string(secret), nil
+}
+
+func (s *TwoFactorService) isExternalUser(userID int64) (bool, error) {
+	query := models.GetAuthInfoQuery{UserId: userID}
+	if err := s.Bus.Dispatch(&query); err != nil {
+		if err == models.ErrUserNotFound {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+func pendingLoginKey(token string) string {
+	return "two-factor-login-" + token
+}
+
+func failedCodesKey(userID int64) string {
+	return fmt.Sprintf("two-factor-failures-%d", userID)
+}
@@ -0,0 +1,172 @@
+package twofactor
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/bus"
+	"github.com/grafana/grafana/pkg/components/totp"
+	"github.com/grafana/grafana/pkg/infra/localcache"
+	"github.com/grafana/grafana/pkg/infra/remotecache"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/services/sqlstore"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTwoFactorService(t *testing.T) {
+	service := &TwoFactorService{
+		Bus:          bus.GetBus(),
+		Cfg:          &setting.Cfg{TwoFactorEnabled: true, TwoFactorIssuer: "Grafana"},
+		RemoteCache:  remotecache.NewFakeStore(t),
+		CacheService: localcache.New(time.Minute, time.Minute),
+	}
+	require.NoError(t, service.Init())
+
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	getTime = func() time.Time { return now }
+	defer func() { getTime = time.Now }()
+
+	userCmd := models.CreateUserCommand{Login: "admin", Email: "admin@localhost"}
+	require.NoError(t, sqlstore.CreateUser(context.Background(), &userCmd))
+	user := &models.SignedInUser{UserId: userCmd.Result.Id, OrgId: userCmd.Result.OrgId, Login: "admin", Email: "admin@localhost"}
+
+	codeAt := func(t *testing.T, secret string, at time.Time) string {
+		code, err := totp.GenerateCode(secret, totp.Step(at))
+		require.NoError(t, err)
+		return code
+	}
+
+	var secret string
+	var recoveryCodes []string
+
+	t.Run("Should enroll and activate", func(t *testing.T) {
+		enrollment, err := service.Enroll(user)
+		require.NoError(t, err)
+		secret = enrollment.Secret
+
+		uri, err := url.Parse(enrollment.URI)
+		require.NoError(t, err)
+		assert.Equal(t, "otpauth", uri.Scheme)
+		assert.Equal(t, secret, uri.Query().Get("secret"))
+
+		_, err = service.Activate(user.UserId, "000000")
+		assert.Equal(t, ErrInvalidCode, err)
+
+		recoveryCodes, err = service.Activate(user.UserId, codeAt(t, secret, now))
+		require.NoError(t, err)
+		assert.Len(t, recoveryCodes, recoveryCodeCount)
+
+		enabled, err := service.IsEnabled(user.UserId)
+		require.NoError(t, err)
+		assert.True(t, enabled)
+	})
+
+	t.Run("Should not accept a code twice", func(t *testing.T) {
+		assert.Equal(t, ErrInvalidCode, service.Verify(user.UserId, codeAt(t, secret, now)))
+
+		now = now.Add(totp.Period * time.Second)
+		assert.NoError(t, service.Verify(user.UserId, codeAt(t, secret, now)))
+		assert.Equal(t, ErrInvalidCode, service.Verify(user.UserId, codeAt(t, secret, now)))
+	})
+
+	t.Run("Should accept a recovery code once", func(t *testing.T) {
+		assert.NoError(t, service.Verify(user.UserId, recoveryCodes[0]))
+		assert.Equal(t, ErrInvalidCode, service.Verify(user.UserId, recoveryCodes[0]))
+	})
+
+	t.Run("Should complete a pending login", func(t *testing.T) {
+		token, err := service.StartLogin(user.UserId)
+		require.NoError(t, err)
+
+		_, err = service.CompleteLogin(token, "000000")
+		assert.Equal(t, ErrInvalidCode, err)
+
+		now = now.Add(totp.Period * time.Second)
+		userID, err := service.CompleteLogin(token, codeAt(t, secret, now))
+		require.NoError(t, err)
+		assert.Equal(t, user.UserId, userID)
+
+		_, err = service.CompleteLogin(token, codeAt(t, secret, now))
+		assert.Equal(t, ErrLoginExpired, err)
+	})
+
+	t.Run("Should limit the attempts of a pending login", func(t *testing.T) {
+		token, err := service.StartLogin(user.UserId)
+		require.NoError(t, err)
+
+		for i := 1; i < maxCodeAttempts; i++ {
+			_, err = service.CompleteLogin(token, "000000")
+			assert.Equal(t, ErrInvalidCode, err)
+		}
+
+		_, err = service.CompleteLogin(token, "000000")
+		assert.Equal(t, ErrTooManyCodeAttempts, err)
+
+		now = now.Add(totp.Period * time.Second)
+		_, err = service.CompleteLogin(token, codeAt(t, secret, now))
+		assert.Equal(t, ErrLoginExpired, err)
+	})
+
+	t.Run("Should lock out a user after too many invalid codes across logins", func(t *testing.T) {
+		require.NoError(t, service.RemoteCache.Delete(failedCodesKey(user.UserId)))
+
+		for i := 1; i < maxFailedCodes; i++ {
+			token, err := service.StartLogin(user.UserId)
+			require.NoError(t, err)
+			_, err = service.CompleteLogin(token, "000000")
+			assert.Equal(t, ErrInvalidCode, err)
+		}
+
+		token, err := service.StartLogin(user.UserId)
+		require.NoError(t, err)
+		_, err = service.CompleteLogin(token, "000000")
+		assert.Equal(t, ErrLockedOut, err)
+
+		now = now.Add(totp.Period * time.Second)
+		token, err = service.StartLogin(user.UserId)
+		require.NoError(t, err)
+		_, err = service.CompleteLogin(token, codeAt(t, secret, now))
+		assert.Equal(t, ErrLockedOut, err)
+
+		require.NoError(t, service.RemoteCache.Delete(failedCodesKey(user.UserId)))
+		userID, err := service.CompleteLogin(token, codeAt(t, secret, now))
+		require.NoError(t, err)
+		assert.Equal(t, user.UserId, userID)
+	})
+
+	t.Run("Should require two-factor when enforced by the org", func(t *testing.T) {
+		require.NoError(t, service.Disable(user.UserId))
+		required, err := service.IsRequired(user)
+		require.NoError(t, err)
+		assert.False(t, required)
+
+		require.NoError(t, bus.Dispatch(&models.SetOrgRequireTwoFactorCommand{OrgId: user.OrgId, RequireTwoFactor: true}))
+		service.InvalidateOrgPolicy()
+
+		required, err = service.IsRequired(user)
+		require.NoError(t, err)
+		assert.True(t, required)
+
+		enrollment, err := service.Enroll(user)
+		require.NoError(t, err)
+		_, err = service.Activate(user.UserId, codeAt(t, enrollment.Secret, now))
+		require.NoError(t, err)
+
+		required, err = service.IsRequired(user)
+		require.NoError(t, err)
+		assert.False(t, required)
+	})
+}
+
+func TestIsEnforcedPath(t *testing.T) {
+	assert.True(t, isEnforcedPath("/api/dashboards/uid/abc"))
+	assert.True(t, isEnforcedPath("/api/user/preferences"))
+	assert.False(t, isEnforcedPath("/api/user"))
+	assert.False(t, isEnforcedPath("/api/user/two-factor/enroll"))
+	assert.False(t, isEnforcedPath("/api/login/ping"))
+	assert.False(t, isEnforcedPath("/d/abc"))
+}
@@ -14,6 +14,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -260,6 +261,18 @@ type Cfg struct {
 	PluginsAppsSkipVerifyTLS         bool
 	PluginSettings                   PluginSettings
 	PluginsAllowUnsigned             []string
+	PluginTrustedSigningKeys         []string
+	PluginAdminEnabled               bool
+	PluginRepositoryURL              string
+	PluginRepositoryToken            string
+	PluginRestartMinBackoff          time.Duration
+	PluginRestartMaxBackoff          time.Duration
+	PluginCrashLoopThreshold         int
+	PluginHealthCheckInterval        time.Duration
+	PluginHealthCheckFailures        int
+	PluginMemoryLimitMB              int64
+	PluginCPULimit                   float64
+	PluginCgroupParent               string
 	DisableSanitizeHtml              bool
 	EnterpriseLicensePath            string
 
@@ -268,19 +281,47 @@ type Cfg struct {
 	LoginMaxInactiveLifetimeDays int
 	LoginMaxLifetimeDays         int
 	TokenRotationIntervalMinutes int
+	LoginMaxConcurrentSessions   int
+	LoginRoleSessionPolicies     map[SessionPolicyKey]SessionPolicy
+
+	// Two-factor authentication
+	TwoFactorEnabled bool
+	TwoFactorIssuer  string
 
 	// OAuth
 	OAuthCookieMaxAge int
 
+	// Audit
+	AuditEnabled    bool
+	AuditSinks      []string
+	AuditMaxAgeDays int
+
 	// SAML Auth
 	SAMLEnabled bool
 
 	// Dataproxy
-	SendUserHeader bool
+	SendUserHeader                 bool
+	DataProxyMaxConcurrentRequests int
+	DataProxyUserRequestsPerSecond float64
+	DataProxyMaxResponseSizeMB     int64
+	DataProxyRequestTimeout        int
 
 	// DistributedCache
 	RemoteCacheOptions *RemoteCacheOptions
 
+	// Query caching
+	QueryCachingEnabled      bool
+	QueryCachingTTL          time.Duration
+	QueryCachingAbsoluteTTL  time.Duration
+	QueryCachingMaxValueSize int
+
+	// Live
+	LiveHAEngine        string
+	LiveHAEngineConnStr string
+	LiveHAEngineChannel string
+	LivePushRateLimit   int
+	LivePushBufferSize  int
+
 	EditorsCanAdmin bool
 
 	ApiKeyMaxSecondsToLive int64
@@ -294,6 +335,20 @@ func (c Cfg) IsExpressionsEnabled() bool {
 	return c.FeatureToggles["expressions"]
 }
 
+// SessionPolicy shortens the global login lifetimes for users with a given org role, the
+// global lifetimes still apply. Zero values only use the global settings.
+type SessionPolicy struct {
+	MaxInactiveLifetimeDays int
+	MaxLifetimeDays         int
+}
+
+// SessionPolicyKey identifies the session policy of an org role. An OrgId of 0 is the
+// policy of the role in every organization without a policy of its own for the role.
+type SessionPolicyKey struct {
+	OrgId int64
+	Role  string
+}
+
 type CommandLineArgs struct {
 	Config   string
 	HomePath string
@@ -711,6 +766,10 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 	DataProxyLogging = dataproxy.Key("logging").MustBool(false)
 	DataProxyTimeout = dataproxy.Key("timeout").MustInt(30)
 	cfg.SendUserHeader = dataproxy.Key("send_user_header").MustBool(false)
+	cfg.DataProxyMaxConcurrentRequests = dataproxy.Key("max_concurrent_requests").MustInt(0)
+	cfg.DataProxyUserRequestsPerSecond = dataproxy.Key("user_requests_per_second").MustFloat64(0)
+	cfg.DataProxyMaxResponseSizeMB = dataproxy.Key("max_response_size_mb").MustInt64(0)
+	cfg.DataProxyRequestTimeout = dataproxy.Key("request_timeout").MustInt(0)
 
 	// read security settings
 	security := iniFile.Section("security")
@@ -855,6 +914,8 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 		cfg.TokenRotationIntervalMinutes = 2
 	}
 
+	cfg.LoginMaxConcurrentSessions = auth.Key("login_maximum_concurrent_sessions").MustInt(0)
+
 	DisableLoginForm = auth.Key("disable_login_form").MustBool(false)
 	DisableSignoutMenu = auth.Key("disable_signout_menu").MustBool(false)
 	OAuthAutoLogin = auth.Key("oauth_auto_login").MustBool(false)
@@ -1001,6 +1062,20 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 		plug = strings.TrimSpace(plug)
 		cfg.PluginsAllowUnsigned = append(cfg.PluginsAllowUnsigned, plug)
 	}
+	for _, key := range util.SplitString(pluginsSection.Key("trusted_signing_keys").MustString("")) {
+		cfg.PluginTrustedSigningKeys = append(cfg.PluginTrustedSigningKeys, makeAbsolute(key, HomePath))
+	}
+	cfg.PluginAdminEnabled = pluginsSection.Key("plugin_admin_enabled").MustBool(false)
+	cfg.PluginRepositoryURL = strings.TrimSuffix(pluginsSection.Key("repository_url").MustString("https://grafana.com/api/plugins"), "/")
+	cfg.PluginRepositoryToken = pluginsSection.Key("repository_token").MustString("")
+	cfg.PluginRestartMinBackoff = pluginsSection.Key("backend_restart_min_backoff").MustDuration(time.Second)
+	cfg.PluginRestartMaxBackoff = pluginsSection.Key("backend_restart_max_backoff").MustDuration(5 * time.Minute)
+	cfg.PluginCrashLoopThreshold = pluginsSection.Key("backend_crash_loop_threshold").MustInt(5)
+	cfg.PluginHealthCheckInterval = pluginsSection.Key("backend_health_check_interval").MustDuration(time.Minute)
+	cfg.PluginHealthCheckFailures = pluginsSection.Key("backend_health_check_failures").MustInt(3)
+	cfg.PluginMemoryLimitMB = pluginsSection.Key("backend_memory_limit_mb").MustInt64(0)
+	cfg.PluginCPULimit = pluginsSection.Key("backend_cpu_limit").MustFloat64(0)
+	cfg.PluginCgroupParent = pluginsSection.Key("backend_cgroup_parent").MustString("")
 
 	// Read and populate feature toggles list
 	featureTogglesSection := iniFile.Section("feature_toggles")
@@ -1021,6 +1096,13 @@ func (cfg *Cfg) Load(args *CommandLineArgs) error {
 
 	cfg.readLDAPConfig()
 	cfg.readSessionConfig()
+	if err := cfg.readRoleSessionPolicies(); err != nil {
+		return err
+	}
+	cfg.readAuditSettings()
+	cfg.readTwoFactorSettings()
+	cfg.readQueryCachingSettings()
+	cfg.readLiveSettings()
 	cfg.readSmtpSettings()
 	cfg.readQuotaSettings()
 
@@ -1103,6 +1185,78 @@ func (cfg *Cfg) readSessionConfig() {
 	}
 }
 
+// readRoleSessionPolicies reads the per role login lifetimes from the [auth.session_policy.<role>]
+// and the per organization [auth.session_policy.org_<orgId>.<role>] sections.
+func (cfg *Cfg) readRoleSessionPolicies() error {
+	cfg.LoginRoleSessionPolicies = make(map[SessionPolicyKey]SessionPolicy)
+	roles := map[string]string{"viewer": "Viewer", "editor": "Editor", "admin": "Admin"}
+
+	for _, sec := range cfg.Raw.Sections() {
+		name := strings.TrimPrefix(sec.Name(), "auth.session_policy.")
+		if name == sec.Name() {
+			continue
+		}
+
+		key := SessionPolicyKey{}
+		if strings.HasPrefix(name, "org_") {
+			parts := strings.SplitN(strings.TrimPrefix(name, "org_"), ".", 2)
+			orgId, err := strconv.ParseInt(parts[0], 10, 64)
+			if err != nil || orgId <= 0 || len(parts) != 2 {
+				return fmt.Errorf("invalid session policy section [%s], expected [auth.session_policy.org_<orgId>.<role>]", sec.Name())
+			}
+			key.OrgId = orgId
+			name = parts[1]
+		}
+
+		role, ok := roles[name]
+		if !ok {
+			return fmt.Errorf("invalid role %q in session policy section [%s]", name, sec.Name())
+		}
+		key.Role = role
+
+		policy := SessionPolicy{
+			MaxInactiveLifetimeDays: sec.Key("login_maximum_inactive_lifetime_days").MustInt(0),
+			MaxLifetimeDays:         sec.Key("login_maximum_lifetime_days").MustInt(0),
+		}
+
+		if policy.MaxInactiveLifetimeDays > 0 || policy.MaxLifetimeDays > 0 {
+			cfg.LoginRoleSessionPolicies[key] = policy
+		}
+	}
+
+	return nil
+}
+
+func (cfg *Cfg) readAuditSettings() {
+	audit := cfg.Raw.Section("audit")
+	cfg.AuditEnabled = audit.Key("enabled").MustBool(false)
+	cfg.AuditSinks = util.SplitString(audit.Key("sinks").MustString("database"))
+	cfg.AuditMaxAgeDays = audit.Key("max_age_days").MustInt(90)
+}
+
+func (cfg *Cfg) readLiveSettings() {
+	live := cfg.Raw.Section("live")
+	cfg.LiveHAEngine = live.Key("ha_engine").MustString("")
+	cfg.LiveHAEngineConnStr = live.Key("ha_engine_connstr").MustString("")
+	cfg.LiveHAEngineChannel = live.Key("ha_engine_channel").MustString("grafana.live")
+	cfg.LivePushRateLimit = live.Key("push_rate_limit").MustInt(10)
+	cfg.LivePushBufferSize = live.Key("push_buffer_size").MustInt(0)
+}
+
+func (cfg *Cfg) readQueryCachingSettings() {
+	queryCaching := cfg.Raw.Section("query_caching")
+	cfg.QueryCachingEnabled = queryCaching.Key("enabled").MustBool(false)
+	cfg.QueryCachingTTL = queryCaching.Key("ttl").MustDuration(time.Minute)
+	cfg.QueryCachingAbsoluteTTL = queryCaching.Key("absolute_ttl").MustDuration(time.Hour)
+	cfg.QueryCachingMaxValueSize = queryCaching.Key("max_value_size").MustInt(1048576)
+}
+
+func (cfg *Cfg) readTwoFactorSettings() {
+	twoFactor := cfg.Raw.Section("auth.two_factor")
+	cfg.TwoFactorEnabled = twoFactor.Key("enabled").MustBool(true)
+	cfg.TwoFactorIssuer = twoFactor.Key("issuer").MustString("Grafana")
+}
+
 func (cfg *Cfg) initLogging(file *ini.File) error {
 	logModeStr, err := valueAsString(file.Section("log"), "mode", "console")
 	if err != nil {
@@ -325,3 +325,37 @@ func TestParseAppUrlAndSubUrl(t *testing.T) {
 		require.Equal(t, tc.expectedAppSubURL, appSubURL)
 	}
 }
+
+func TestReadRoleSessionPolicies(t *testing.T) {
+	t.Run("should read the policies of every organization and of a single organization", func(t *testing.T) {
+		f, err := ini.Load([]byte(`
+[auth.session_policy.admin]
+login_maximum_lifetime_days = 7
+
+[auth.session_policy.org_2.admin]
+login_maximum_lifetime_days = 1
+
+[auth.session_policy.org_2.viewer]
+login_maximum_inactive_lifetime_days = 0
+`))
+		require.NoError(t, err)
+
+		cfg := &Cfg{Raw: f}
+		require.NoError(t, cfg.readRoleSessionPolicies())
+		require.Equal(t, map[SessionPolicyKey]SessionPolicy{
+			{Role: "Admin"}:           {MaxLifetimeDays: 7},
+			{OrgId: 2, Role: "Admin"}: {MaxLifetimeDays: 1},
+		}, cfg.LoginRoleSessionPolicies)
+	})
+
+	t.Run("should return an error for invalid sections", func(t *testing.T) {
+		for _, name := range []string{"auth.session_policy.owner", "auth.session_policy.org_x.admin", "auth.session_policy.org_2"} {
+			f := ini.Empty()
+			_, err := f.NewSection(name)
+			require.NoError(t, err)
+
+			cfg := &Cfg{Raw: f}
+			require.Error(t, cfg.readRoleSessionPolicies(), name)
+		}
+	})
+}
@@ -234,7 +234,7 @@ func (e *ApplicationInsightsDatasource) executeQuery(ctx context.Context, query
 
 func (e *ApplicationInsightsDatasource) createRequest(ctx context.Context, dsInfo *models.DataSource) (*http.Request, error) {
 	// find plugin
-	plugin, ok := plugins.DataSources[dsInfo.Type]
+	plugin, ok := plugins.DataSources()[dsInfo.Type]
 	if !ok {
 		return nil, errors.New("Unable to find datasource plugin Azure Application Insights")
 	}
@@ -177,7 +177,7 @@ func (e *AzureLogAnalyticsDatasource) createRequest(ctx context.Context, dsInfo
 	req.Header.Set("User-Agent", fmt.Sprintf("Grafana/%s", setting.BuildVersion))
 
 	// find plugin
-	plugin, ok := plugins.DataSources[dsInfo.Type]
+	plugin, ok := plugins.DataSources()[dsInfo.Type]
 	if !ok {
 		return nil, errors.New("Unable to find datasource plugin Azure Monitor")
 	}
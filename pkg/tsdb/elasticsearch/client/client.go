@@ -35,6 +35,8 @@ var newDatasourceHttpClient = func(ds *models.DataSource) (*http.Client, error)
 type Client interface {
 	GetVersion() int
 	GetTimeField() string
+	GetLogMessageField() string
+	GetLogLevelField() string
 	GetMinInterval(queryInterval string) (time.Duration, error)
 	ExecuteMultisearch(r *MultiSearchRequest) (*MultiSearchResponse, error)
 	MultiSearch() *MultiSearchRequestBuilder
@@ -99,6 +101,14 @@ func (c *baseClientImpl) GetTimeField() string {
 	return c.timeField
 }
 
+func (c *baseClientImpl) GetLogMessageField() string {
+	return c.ds.JsonData.Get("logMessageField").MustString("")
+}
+
+func (c *baseClientImpl) GetLogLevelField() string {
+	return c.ds.JsonData.Get("logLevelField").MustString("")
+}
+
 func (c *baseClientImpl) GetMinInterval(queryInterval string) (time.Duration, error) {
 	return tsdb.GetIntervalFrom(c.ds, simplejson.NewFromAny(map[string]interface{}{
 		"interval": queryInterval,
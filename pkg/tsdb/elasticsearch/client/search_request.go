@@ -13,6 +13,7 @@ type SearchRequestBuilder struct {
 	index        string
 	size         int
 	sort         map[string]interface{}
+	sortFields   []map[string]interface{}
 	queryBuilder *QueryBuilder
 	aggBuilders  []AggBuilder
 	customProps  map[string]interface{}
@@ -40,6 +41,11 @@ func (b *SearchRequestBuilder) Build() (*SearchRequest, error) {
 		CustomProps: b.customProps,
 	}
 
+	// sorting on multiple fields requires the order of the fields to be kept
+	if len(b.sortFields) > 0 {
+		sr.CustomProps["sort"] = b.sortFields
+	}
+
 	if b.queryBuilder != nil {
 		q, err := b.queryBuilder.Build()
 		if err != nil {
@@ -84,6 +90,45 @@ func (b *SearchRequestBuilder) SortDesc(field, unmappedType string) *SearchReque
 	return b
 }
 
+// AddSortField adds a sort on a field to the search request. Fields are sorted on
+// in the order they are added, which is required when paginating with search after.
+func (b *SearchRequestBuilder) AddSortField(field, order, unmappedType string) *SearchRequestBuilder {
+	props := map[string]interface{}{
+		"order": order,
+	}
+
+	if unmappedType != "" {
+		props["unmapped_type"] = unmappedType
+	}
+
+	b.sortFields = append(b.sortFields, map[string]interface{}{field: props})
+
+	return b
+}
+
+// SearchAfter sets the sort values of the last document of the previous page
+func (b *SearchRequestBuilder) SearchAfter(values []interface{}) *SearchRequestBuilder {
+	if len(values) > 0 {
+		b.customProps["search_after"] = values
+	}
+
+	return b
+}
+
+// AddHighlight highlights the terms of the query in all fields of the returned documents
+func (b *SearchRequestBuilder) AddHighlight() *SearchRequestBuilder {
+	b.customProps["highlight"] = map[string]interface{}{
+		"fields": map[string]interface{}{
+			"*": map[string]interface{}{},
+		},
+		"pre_tags":      []string{HighlightPreTag},
+		"post_tags":     []string{HighlightPostTag},
+		"fragment_size": 2147483647,
+	}
+
+	return b
+}
+
 // AddDocValueField adds a doc value field to the search request
 func (b *SearchRequestBuilder) AddDocValueField(field string) *SearchRequestBuilder {
 	// fields field not supported on version >= 5
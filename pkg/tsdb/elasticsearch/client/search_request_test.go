@@ -142,6 +142,31 @@ func TestSearchRequest(t *testing.T) {
 				})
 			})
 
+			Convey("When adding sort fields, search after and highlight", func() {
+				b.AddSortField(timeField, "desc", "boolean")
+				b.AddSortField("_doc", "desc", "")
+				b.SearchAfter([]interface{}{1000, 1})
+				b.AddHighlight()
+
+				Convey("When marshal to JSON should generate correct json", func() {
+					sr, err := b.Build()
+					So(err, ShouldBeNil)
+					body, err := json.Marshal(sr)
+					So(err, ShouldBeNil)
+					json, err := simplejson.NewJson(body)
+					So(err, ShouldBeNil)
+
+					sort := json.Get("sort").MustArray()
+					So(sort, ShouldHaveLength, 2)
+					So(json.Get("sort").GetIndex(0).GetPath(timeField, "order").MustString(), ShouldEqual, "desc")
+					So(json.Get("sort").GetIndex(0).GetPath(timeField, "unmapped_type").MustString(), ShouldEqual, "boolean")
+					So(json.Get("sort").GetIndex(1).GetPath("_doc", "order").MustString(), ShouldEqual, "desc")
+					So(json.Get("search_after").MustArray(), ShouldHaveLength, 2)
+					So(json.GetPath("highlight", "pre_tags").MustStringArray(), ShouldResemble, []string{HighlightPreTag})
+					So(json.GetPath("highlight", "post_tags").MustStringArray(), ShouldResemble, []string{HighlightPostTag})
+				})
+			})
+
 			Convey("and adding multiple top level aggs", func() {
 				aggBuilder := b.Agg()
 				aggBuilder.Terms("1", "@hostname", nil)
@@ -0,0 +1,293 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/tsdb"
+	es "github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
+)
+
+// Fields of the hits returned in the frames of document queries along with the source of the documents
+var documentMetaFields = []string{"_id", "_index", "_type"}
+
+// processDocuments converts the hits of a raw document, raw data or logs query into a frame
+// with the time of the documents first, followed by the message and level fields and the
+// flattened source of the documents. Logs queries return a second frame with the number of
+// matching documents over time.
+func (rp *responseParser) processDocuments(res *es.SearchResponse, target *Query, queryRes *tsdb.QueryResult) error {
+	hits := []map[string]interface{}{}
+	if res.Hits != nil {
+		hits = res.Hits.Hits
+	}
+
+	docs := make([]map[string]interface{}, 0, len(hits))
+	times := make([]*time.Time, 0, len(hits))
+	columns := map[string]bool{}
+	searchWords := map[string]bool{}
+
+	for _, hit := range hits {
+		doc := map[string]interface{}{}
+		if source, ok := hit["_source"].(map[string]interface{}); ok {
+			flatten("", source, doc)
+		}
+		for _, name := range documentMetaFields {
+			if value, ok := hit[name]; ok {
+				doc[name] = value
+			}
+		}
+
+		times = append(times, documentTime(hit, doc, target.TimeField))
+		delete(doc, target.TimeField)
+
+		for name := range doc {
+			columns[name] = true
+		}
+		docs = append(docs, doc)
+
+		if highlight, ok := hit["highlight"].(map[string]interface{}); ok {
+			addSearchWords(highlight, searchWords)
+		}
+	}
+
+	frame := data.NewFrame("", data.NewField("Time", nil, times))
+	frame.RefID = target.RefID
+
+	for _, name := range documentColumns(columns, target) {
+		values := make([]interface{}, len(docs))
+		for i, doc := range docs {
+			values[i] = doc[name]
+		}
+		frame.Fields = append(frame.Fields, newDocumentField(name, values))
+	}
+
+	custom := map[string]interface{}{}
+	if len(searchWords) > 0 {
+		words := make([]string, 0, len(searchWords))
+		for word := range searchWords {
+			words = append(words, word)
+		}
+		sort.Strings(words)
+		custom["searchWords"] = words
+	}
+	if len(hits) > 0 {
+		if searchAfter, ok := hits[len(hits)-1]["sort"].([]interface{}); ok {
+			custom["searchAfter"] = searchAfter
+		}
+	}
+	if len(custom) > 0 {
+		frame.Meta = &data.FrameMeta{Custom: custom}
+	}
+
+	frames := []*data.Frame{frame}
+	if target.Metrics[0].Type == logsType {
+		frames = append(frames, logsVolumeFrame(res.Aggregations, target))
+	}
+
+	for _, f := range frames {
+		encoded, err := f.MarshalArrow()
+		if err != nil {
+			return err
+		}
+		queryRes.Dataframes = append(queryRes.Dataframes, encoded)
+	}
+
+	return nil
+}
+
+// documentColumns returns the names of the fields of the documents with the message and
+// level fields first, followed by the fields of the hits and the fields of the source in
+// alphabetical order.
+func documentColumns(columns map[string]bool, target *Query) []string {
+	first := []string{}
+	for _, name := range []string{target.LogMessageField, target.LogLevelField} {
+		if name != "" && columns[name] {
+			first = append(first, name)
+			delete(columns, name)
+		}
+	}
+	for _, name := range documentMetaFields {
+		if columns[name] {
+			first = append(first, name)
+			delete(columns, name)
+		}
+	}
+
+	rest := make([]string, 0, len(columns))
+	for name := range columns {
+		rest = append(rest, name)
+	}
+	sort.Strings(rest)
+
+	return append(first, rest...)
+}
+
+// flatten adds the values of nested objects of the source of a document with their path joined by dots
+func flatten(prefix string, source map[string]interface{}, target map[string]interface{}) {
+	for key, value := range source {
+		name := key
+		if prefix != "" {
+			name = prefix + "." + key
+		}
+
+		if nested, ok := value.(map[string]interface{}); ok {
+			flatten(name, nested, target)
+			continue
+		}
+		target[name] = value
+	}
+}
+
+// documentTime returns the time of a document from its source, or from the doc value fields
+// returned for the time field if it's not part of the source.
+func documentTime(hit map[string]interface{}, doc map[string]interface{}, timeField string) *time.Time {
+	if t, ok := parseTime(doc[timeField]); ok {
+		return &t
+	}
+
+	if fields, ok := hit["fields"].(map[string]interface{}); ok {
+		if values, ok := fields[timeField].([]interface{}); ok && len(values) > 0 {
+			if t, ok := parseTime(values[0]); ok {
+				return &t
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseTime parses a time of a document, which is either epoch milliseconds or a date string
+func parseTime(value interface{}) (time.Time, bool) {
+	switch v := value.(type) {
+	case float64:
+		return time.Unix(0, int64(v)*int64(time.Millisecond)).UTC(), true
+	case string:
+		if ms, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return time.Unix(0, ms*int64(time.Millisecond)).UTC(), true
+		}
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// newDocumentField creates a field of numbers or booleans if all values of the field have that
+// type, and a field of strings otherwise. Arrays are added as JSON.
+func newDocumentField(name string, values []interface{}) *data.Field {
+	allFloats, allBools := true, true
+	for _, value := range values {
+		switch value.(type) {
+		case nil:
+		case float64:
+			allBools = false
+		case bool:
+			allFloats = false
+		default:
+			allFloats, allBools = false, false
+		}
+	}
+
+	switch {
+	case allFloats:
+		field := data.NewField(name, nil, make([]*float64, 0, len(values)))
+		for _, value := range values {
+			if v, ok := value.(float64); ok {
+				field.Append(&v)
+			} else {
+				field.Append((*float64)(nil))
+			}
+		}
+		return field
+	case allBools:
+		field := data.NewField(name, nil, make([]*bool, 0, len(values)))
+		for _, value := range values {
+			if v, ok := value.(bool); ok {
+				field.Append(&v)
+			} else {
+				field.Append((*bool)(nil))
+			}
+		}
+		return field
+	}
+
+	field := data.NewField(name, nil, make([]*string, 0, len(values)))
+	for _, value := range values {
+		field.Append(stringValue(value))
+	}
+	return field
+}
+
+func stringValue(value interface{}) *string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return &v
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		s := string(encoded)
+		return &s
+	}
+}
+
+// addSearchWords adds the terms wrapped in highlight tags in the highlighted fragments of a document
+func addSearchWords(highlight map[string]interface{}, words map[string]bool) {
+	for _, fragments := range highlight {
+		list, ok := fragments.([]interface{})
+		if !ok {
+			continue
+		}
+
+		for _, fragment := range list {
+			text, ok := fragment.(string)
+			if !ok {
+				continue
+			}
+
+			for {
+				start := strings.Index(text, es.HighlightPreTag)
+				if start < 0 {
+					break
+				}
+				text = text[start+len(es.HighlightPreTag):]
+
+				end := strings.Index(text, es.HighlightPostTag)
+				if end < 0 {
+					break
+				}
+				words[text[:end]] = true
+				text = text[end+len(es.HighlightPostTag):]
+			}
+		}
+	}
+}
+
+// logsVolumeFrame converts the date histogram of a logs query into a frame with the number of documents over time
+func logsVolumeFrame(aggs map[string]interface{}, target *Query) *data.Frame {
+	buckets := simplejson.NewFromAny(aggs[logsVolumeAggID]).Get("buckets").MustArray()
+
+	times := make([]time.Time, 0, len(buckets))
+	counts := make([]float64, 0, len(buckets))
+	for _, b := range buckets {
+		bucket := simplejson.NewFromAny(b)
+		key, err := bucket.Get("key").Float64()
+		if err != nil {
+			continue
+		}
+		times = append(times, time.Unix(0, int64(key)*int64(time.Millisecond)).UTC())
+		counts = append(counts, bucket.Get("doc_count").MustFloat64())
+	}
+
+	frame := data.NewFrame("Logs volume", data.NewField("Time", nil, times), data.NewField("Count", nil, counts))
+	frame.RefID = target.RefID
+	return frame
+}
@@ -13,6 +13,10 @@ type Query struct {
 	Alias      string       `json:"alias"`
 	Interval   string
 	RefID      string
+
+	// Fields of the documents returned by raw data and logs queries, set from the data source
+	LogMessageField string
+	LogLevelField   string
 }
 
 // BucketAgg represents a bucket aggregation of the time series query model of the datasource
@@ -48,6 +52,8 @@ var metricAggType = map[string]string{
 	"derivative":     "Derivative",
 	"bucket_script":  "Bucket Script",
 	"raw_document":   "Raw Document",
+	"raw_data":       "Raw Data",
+	"logs":           "Logs",
 }
 
 var extendedStats = map[string]string{
@@ -85,6 +91,21 @@ func isPipelineAggWithMultipleBucketPaths(metricType string) bool {
 	return false
 }
 
+var documentQueryType = map[string]string{
+	rawDocumentType: rawDocumentType,
+	rawDataType:     rawDataType,
+	logsType:        logsType,
+}
+
+// isDocumentQuery returns true if the query returns documents instead of aggregations
+func isDocumentQuery(q *Query) bool {
+	if len(q.Metrics) == 0 {
+		return false
+	}
+	_, ok := documentQueryType[q.Metrics[0].Type]
+	return ok
+}
+
 func describeMetric(metricType, field string) string {
 	text := metricAggType[metricType]
 	if metricType == countType {
@@ -18,6 +18,9 @@ const (
 	countType         = "count"
 	percentilesType   = "percentiles"
 	extendedStatsType = "extended_stats"
+	rawDocumentType   = "raw_document"
+	rawDataType       = "raw_data"
+	logsType          = "logs"
 	// Bucket types
 	dateHistType    = "date_histogram"
 	histogramType   = "histogram"
@@ -64,6 +67,15 @@ func (rp *responseParser) getTimeSeries() (*tsdb.Response, error) {
 
 		queryRes := tsdb.NewQueryResult()
 		queryRes.Meta = debugInfo
+
+		if isDocumentQuery(target) {
+			if err := rp.processDocuments(res, target, queryRes); err != nil {
+				return nil, err
+			}
+			result.Results[target.RefID] = queryRes
+			continue
+		}
+
 		props := make(map[string]string)
 		table := tsdb.Table{
 			Columns: make([]tsdb.TableColumn, 0),
@@ -6,6 +6,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
@@ -865,58 +866,125 @@ func TestResponseParser(t *testing.T) {
 			So(seriesThree.Points[1][1].Float64, ShouldEqual, 2000)
 		})
 
-		// Convey("Raw documents query", func() {
-		// 	targets := map[string]string{
-		// 		"A": `{
-		// 			"timeField": "@timestamp",
-		// 			"metrics": [{ "type": "raw_document", "id": "1" }]
-		// 		}`,
-		// 	}
-		// 	response := `{
-		//     "responses": [
-		//       {
-		//         "hits": {
-		//           "total": 100,
-		//           "hits": [
-		//             {
-		//               "_id": "1",
-		//               "_type": "type",
-		//               "_index": "index",
-		//               "_source": { "sourceProp": "asd" },
-		//               "fields": { "fieldProp": "field" }
-		//             },
-		//             {
-		//               "_source": { "sourceProp": "asd2" },
-		//               "fields": { "fieldProp": "field2" }
-		//             }
-		//           ]
-		//         }
-		//       }
-		//     ]
-		// 	}`
-		// 	rp, err := newResponseParserForTest(targets, response)
-		// 	So(err, ShouldBeNil)
-		// 	result, err := rp.getTimeSeries()
-		// 	So(err, ShouldBeNil)
-		// 	So(result.Results, ShouldHaveLength, 1)
-
-		// 	queryRes := result.Results["A"]
-		// 	So(queryRes, ShouldNotBeNil)
-		// 	So(queryRes.Tables, ShouldHaveLength, 1)
-
-		// 	rows := queryRes.Tables[0].Rows
-		// 	So(rows, ShouldHaveLength, 1)
-		// 	cols := queryRes.Tables[0].Columns
-		// 	So(cols, ShouldHaveLength, 3)
-
-		// 	So(cols[0].Text, ShouldEqual, "host")
-		// 	So(cols[1].Text, ShouldEqual, "Average test")
-		// 	So(cols[2].Text, ShouldEqual, "Average test2")
-
-		// 	So(rows[0][0].(string), ShouldEqual, "server-1")
-		// 	So(rows[0][1].(null.Float).Float64, ShouldEqual, 1000)
-		// 	So(rows[0][2].(null.Float).Float64, ShouldEqual, 3000)
-		// })
+		Convey("Raw documents query", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "raw_document", "id": "1" }]
+				}`,
+			}
+			response := `{
+				"responses": [
+					{
+						"hits": {
+							"total": 100,
+							"hits": [
+								{
+									"_id": "1",
+									"_type": "type",
+									"_index": "index",
+									"_source": { "@timestamp": "2018-05-15T17:51:00.000Z", "host": { "name": "server-1" }, "value": 10 },
+									"sort": [1526406660000, 1]
+								},
+								{
+									"_id": "2",
+									"_type": "type",
+									"_index": "index",
+									"_source": { "host": { "name": "server-2" }, "value": true },
+									"fields": { "@timestamp": [1526406600000] },
+									"sort": [1526406600000, 2]
+								}
+							]
+						}
+					}
+				]
+			}`
+			rp, err := newResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+			So(result.Results, ShouldHaveLength, 1)
+
+			queryRes := result.Results["A"]
+			So(queryRes, ShouldNotBeNil)
+			So(queryRes.Dataframes, ShouldHaveLength, 1)
+
+			frame, err := data.UnmarshalArrowFrame(queryRes.Dataframes[0])
+			So(err, ShouldBeNil)
+			So(frame.RefID, ShouldEqual, "A")
+			So(frame.Rows(), ShouldEqual, 2)
+
+			names := []string{}
+			for _, f := range frame.Fields {
+				names = append(names, f.Name)
+			}
+			So(names, ShouldResemble, []string{"Time", "_id", "_index", "_type", "host.name", "value"})
+
+			So(*frame.Fields[0].At(0).(*time.Time), ShouldEqual, time.Date(2018, 5, 15, 17, 51, 0, 0, time.UTC))
+			So(*frame.Fields[0].At(1).(*time.Time), ShouldEqual, time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC))
+			So(*frame.Fields[4].At(1).(*string), ShouldEqual, "server-2")
+			// values of different types are returned as strings
+			So(*frame.Fields[5].At(0).(*string), ShouldEqual, "10")
+			So(*frame.Fields[5].At(1).(*string), ShouldEqual, "true")
+
+			So(frame.Meta.Custom["searchAfter"], ShouldResemble, []interface{}{float64(1526406600000), float64(2)})
+		})
+
+		Convey("Logs query", func() {
+			targets := map[string]string{
+				"A": `{
+					"timeField": "@timestamp",
+					"metrics": [{ "type": "logs", "id": "1" }]
+				}`,
+			}
+			response := `{
+				"responses": [
+					{
+						"aggregations": {
+							"logs_volume": {
+								"buckets": [
+									{ "doc_count": 1, "key": 1526406600000 },
+									{ "doc_count": 0, "key": 1526406630000 }
+								]
+							}
+						},
+						"hits": {
+							"hits": [
+								{
+									"_id": "1",
+									"_source": { "@timestamp": 1526406600000, "message": "request failed with error", "level": "error", "duration": 5 },
+									"highlight": { "message": ["request failed with @HIGHLIGHT@error@/HIGHLIGHT@"] }
+								}
+							]
+						}
+					}
+				]
+			}`
+			rp, err := newResponseParserForTest(targets, response)
+			So(err, ShouldBeNil)
+			rp.Targets[0].LogMessageField = "message"
+			rp.Targets[0].LogLevelField = "level"
+			result, err := rp.getTimeSeries()
+			So(err, ShouldBeNil)
+
+			queryRes := result.Results["A"]
+			So(queryRes.Dataframes, ShouldHaveLength, 2)
+
+			frame, err := data.UnmarshalArrowFrame(queryRes.Dataframes[0])
+			So(err, ShouldBeNil)
+			So(frame.Rows(), ShouldEqual, 1)
+			So(frame.Fields[1].Name, ShouldEqual, "message")
+			So(frame.Fields[2].Name, ShouldEqual, "level")
+			So(frame.Fields[4].Name, ShouldEqual, "duration")
+			So(*frame.Fields[4].At(0).(*float64), ShouldEqual, 5)
+			So(frame.Meta.Custom["searchWords"], ShouldResemble, []interface{}{"error"})
+
+			volume, err := data.UnmarshalArrowFrame(queryRes.Dataframes[1])
+			So(err, ShouldBeNil)
+			So(volume.Rows(), ShouldEqual, 2)
+			So(volume.Fields[0].At(0).(time.Time), ShouldEqual, time.Date(2018, 5, 15, 17, 50, 0, 0, time.UTC))
+			So(volume.Fields[1].At(0).(float64), ShouldEqual, 1)
+		})
 	})
 }
 
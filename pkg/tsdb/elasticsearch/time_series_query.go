@@ -9,6 +9,13 @@ import (
 	"github.com/grafana/grafana/pkg/tsdb/elasticsearch/client"
 )
 
+const (
+	// defaultDocumentSize is the number of documents returned by document queries without a size
+	defaultDocumentSize = 500
+	// logsVolumeAggID is the id of the date histogram counting the documents matching a logs query
+	logsVolumeAggID = "logs_volume"
+)
+
 type timeSeriesQuery struct {
 	client             es.Client
 	tsdbQuery          *tsdb.TsdbQuery
@@ -54,19 +61,21 @@ func (e *timeSeriesQuery) execute() (*tsdb.Response, error) {
 			filters.AddQueryStringFilter(q.RawQuery, true)
 		}
 
+		if isDocumentQuery(q) {
+			// documents are sorted and paginated on the time field configured on the data source
+			q.TimeField = e.client.GetTimeField()
+			q.LogMessageField = e.client.GetLogMessageField()
+			q.LogLevelField = e.client.GetLogLevelField()
+			addDocumentQuery(b, q, from, to)
+			continue
+		}
+
 		if len(q.BucketAggs) == 0 {
-			if len(q.Metrics) == 0 || q.Metrics[0].Type != "raw_document" {
-				result.Results[q.RefID] = &tsdb.QueryResult{
-					RefId:       q.RefID,
-					Error:       fmt.Errorf("invalid query, missing metrics and aggregations"),
-					ErrorString: "invalid query, missing metrics and aggregations",
-				}
-				continue
+			result.Results[q.RefID] = &tsdb.QueryResult{
+				RefId:       q.RefID,
+				Error:       fmt.Errorf("invalid query, missing metrics and aggregations"),
+				ErrorString: "invalid query, missing metrics and aggregations",
 			}
-			metric := q.Metrics[0]
-			b.Size(metric.Settings.Get("size").MustInt(500))
-			b.SortDesc("@timestamp", "boolean")
-			b.AddDocValueField("@timestamp")
 			continue
 		}
 
@@ -167,6 +176,43 @@ func (e *timeSeriesQuery) execute() (*tsdb.Response, error) {
 	return rp.getTimeSeries()
 }
 
+// addDocumentQuery builds the request of raw document, raw data and logs queries. Documents are
+// sorted newest first with the document id as tie breaker, so the sort values of the last document
+// can be sent back in the searchAfter setting to get the next page.
+func addDocumentQuery(b *es.SearchRequestBuilder, q *Query, from, to string) {
+	metric := q.Metrics[0]
+
+	b.AddSortField(q.TimeField, "desc", "boolean")
+	b.AddSortField("_doc", "desc", "")
+	b.AddDocValueField(q.TimeField)
+	b.SearchAfter(metric.Settings.Get("searchAfter").MustArray())
+
+	if metric.Type != logsType {
+		b.Size(intSetting(metric.Settings, "size", defaultDocumentSize))
+		return
+	}
+
+	b.Size(intSetting(metric.Settings, "limit", defaultDocumentSize))
+	b.AddHighlight()
+	b.Agg().DateHistogram(logsVolumeAggID, q.TimeField, func(a *es.DateHistogramAgg, _ es.AggBuilder) {
+		a.Interval = "$__interval"
+		a.MinDocCount = 0
+		a.ExtendedBounds = &es.ExtendedBounds{Min: from, Max: to}
+		a.Format = es.DateFormatEpochMS
+	})
+}
+
+// intSetting returns a setting that is either a number or a string holding a number
+func intSetting(settings *simplejson.Json, key string, defaultValue int) int {
+	if value, err := settings.Get(key).Int(); err == nil && value > 0 {
+		return value
+	}
+	if value, err := strconv.Atoi(settings.Get(key).MustString()); err == nil && value > 0 {
+		return value
+	}
+	return defaultValue
+}
+
 func addDateHistogramAgg(aggBuilder es.AggBuilder, bucketAgg *BucketAgg, timeFrom, timeTo string) es.AggBuilder {
 	aggBuilder.DateHistogram(bucketAgg.ID, bucketAgg.Field, func(a *es.DateHistogramAgg, b es.AggBuilder) {
 		a.Interval = bucketAgg.Settings.Get("interval").MustString("auto")
@@ -1,6 +1,7 @@
 package elasticsearch
 
 import (
+	"encoding/json"
 	"fmt"
 	"testing"
 	"time"
@@ -302,6 +303,50 @@ func TestExecuteTimeSeriesQuery(t *testing.T) {
 			So(sr.Size, ShouldEqual, 1337)
 		})
 
+		Convey("With raw data metric", func() {
+			c := newFakeClient(5)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"bucketAggs": [],
+				"metrics": [{ "id": "1", "type": "raw_data", "settings": { "size": "100", "searchAfter": [1000, 3] }	}]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, 100)
+			So(sr.Aggs, ShouldHaveLength, 0)
+
+			sort := sr.CustomProps["sort"].([]map[string]interface{})
+			So(sort, ShouldHaveLength, 2)
+			So(sort[0]["@timestamp"].(map[string]interface{})["order"], ShouldEqual, "desc")
+			So(sort[1]["_doc"].(map[string]interface{})["order"], ShouldEqual, "desc")
+			So(sr.CustomProps["search_after"], ShouldResemble, []interface{}{json.Number("1000"), json.Number("3")})
+			So(sr.CustomProps["highlight"], ShouldBeNil)
+		})
+
+		Convey("With logs metric", func() {
+			c := newFakeClient(70)
+			_, err := executeTsdbQuery(c, `{
+				"timeField": "@timestamp",
+				"query": "error",
+				"bucketAggs": [{ "type": "date_histogram", "id": "2", "field": "@timestamp" }],
+				"metrics": [{ "id": "1", "type": "logs", "settings": { "limit": 10 } }]
+			}`, from, to, 15*time.Second)
+			So(err, ShouldBeNil)
+			sr := c.multisearchRequests[0].Requests[0]
+
+			So(sr.Size, ShouldEqual, 10)
+			So(sr.CustomProps["highlight"], ShouldNotBeNil)
+			So(sr.CustomProps["search_after"], ShouldBeNil)
+
+			So(sr.Aggs, ShouldHaveLength, 1)
+			So(sr.Aggs[0].Key, ShouldEqual, logsVolumeAggID)
+			hAgg := sr.Aggs[0].Aggregation.Aggregation.(*es.DateHistogramAgg)
+			So(hAgg.Field, ShouldEqual, "@timestamp")
+			So(hAgg.Interval, ShouldEqual, "$__interval")
+			So(hAgg.MinDocCount, ShouldEqual, 0)
+		})
+
 		Convey("With date histogram agg", func() {
 			c := newFakeClient(5)
 			_, err := executeTsdbQuery(c, `{
@@ -645,6 +690,14 @@ func (c *fakeClient) GetTimeField() string {
 	return c.timeField
 }
 
+func (c *fakeClient) GetLogMessageField() string {
+	return "message"
+}
+
+func (c *fakeClient) GetLogLevelField() string {
+	return "level"
+}
+
 func (c *fakeClient) GetMinInterval(queryInterval string) (time.Duration, error) {
 	return 15 * time.Second, nil
 }
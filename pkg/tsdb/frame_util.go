@@ -77,7 +77,16 @@ func FrameToSeriesSlice(frame *data.Frame) (TimeSeriesSlice, error) {
 			Points: make(TimeSeriesPoints, field.Len()),
 		}
 
+		// null values become null points if the field is configured to show them as null,
+		// otherwise they are converted to NaN
+		nullAsNull := field.Config != nil && field.Config.NullValueMode == data.NullValueModeNull
+
 		for rowIdx := 0; rowIdx < field.Len(); rowIdx++ { // for each value in the field, make a TimePoint
+			if _, ok := field.ConcreteAt(rowIdx); !ok && nullAsNull {
+				ts.Points[rowIdx] = TimePoint{null.Float{}, timeNullFloatSlice[rowIdx]}
+				continue
+			}
+
 			val, err := field.FloatAt(rowIdx)
 			if err != nil {
 				return nil, errutil.Wrapf(err, "failed to convert frame to tsdb.series, can not convert value %v to float", field.At(rowIdx))
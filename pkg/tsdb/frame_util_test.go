@@ -56,6 +56,30 @@ func TestFrameToSeriesSlice(t *testing.T) {
 			},
 			Err: require.NoError,
 		},
+		{
+			name: "a wide series with null values shown as null",
+			frame: data.NewFrame("",
+				data.NewField("Time", nil, []time.Time{
+					time.Date(2020, 1, 2, 3, 4, 0, 0, time.UTC),
+					time.Date(2020, 1, 2, 3, 4, 30, 0, time.UTC),
+				}),
+				data.NewField(`Values Int64s`, nil, []*int64{
+					nil,
+					pointer.Int64(3),
+				}).SetConfig(&data.FieldConfig{NullValueMode: data.NullValueModeNull})),
+
+			seriesSlice: TimeSeriesSlice{
+				&TimeSeries{
+					Name: "Values Int64s",
+					Tags: map[string]string{},
+					Points: TimeSeriesPoints{
+						TimePoint{null.Float{}, null.FloatFrom(1577934240000)},
+						TimePoint{null.FloatFrom(3), null.FloatFrom(1577934270000)},
+					},
+				},
+			},
+			Err: require.NoError,
+		},
 		{
 			name: "a long series",
 			frame: data.NewFrame("",
@@ -10,6 +10,8 @@ import (
 	"net/url"
 	"path"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
 
 	"golang.org/x/net/context/ctxhttp"
@@ -31,23 +33,36 @@ func NewGraphiteExecutor(datasource *models.DataSource) (tsdb.TsdbQueryEndpoint,
 
 var glog = log.New("tsdb.graphite")
 
+const (
+	// defaultMaxDataPoints is the number of data points of queries without maxDataPoints, e.g. of alert rules
+	defaultMaxDataPoints = 500
+)
+
+var consolidationFunctions = map[string]bool{
+	"average": true,
+	"avg":     true,
+	"sum":     true,
+	"min":     true,
+	"max":     true,
+	"first":   true,
+	"last":    true,
+}
+
 func init() {
 	tsdb.RegisterTsdbQueryEndpoint("graphite", NewGraphiteExecutor)
 }
 
 func (e *GraphiteExecutor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
+	if len(tsdbQuery.Queries) > 0 && tsdbQuery.Queries[0].Model.Get("type").MustString() == "metricFindQuery" {
+		return e.executeMetricFindQuery(ctx, dsInfo, tsdbQuery)
+	}
+
 	result := &tsdb.Response{}
 
 	from := "-" + formatTimeRange(tsdbQuery.TimeRange.From)
 	until := formatTimeRange(tsdbQuery.TimeRange.To)
 	var target string
-
-	formData := url.Values{
-		"from":          []string{from},
-		"until":         []string{until},
-		"format":        []string{"json"},
-		"maxDataPoints": []string{"500"},
-	}
+	maxDataPoints := int64(defaultMaxDataPoints)
 
 	emptyQueries := make([]string, 0)
 	for _, query := range tsdbQuery.Queries {
@@ -64,6 +79,22 @@ func (e *GraphiteExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 			continue
 		}
 		target = fixIntervalFormat(currTarget)
+
+		if query.MaxDataPoints > 0 {
+			maxDataPoints = query.MaxDataPoints
+		}
+		maxDataPoints = query.Model.Get("maxDataPoints").MustInt64(maxDataPoints)
+
+		consolidateBy := query.Model.Get("consolidateBy").MustString()
+		if consolidateBy == "" && dsInfo.JsonData != nil {
+			consolidateBy = dsInfo.JsonData.Get("consolidateBy").MustString()
+		}
+		if consolidateBy != "" {
+			var err error
+			if target, err = addConsolidateBy(target, consolidateBy); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	if target == "" {
@@ -71,7 +102,13 @@ func (e *GraphiteExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 		return nil, errors.New("No query target found for the alert rule")
 	}
 
-	formData["target"] = []string{target}
+	formData := url.Values{
+		"from":          []string{from},
+		"until":         []string{until},
+		"format":        []string{"json"},
+		"maxDataPoints": []string{strconv.FormatInt(maxDataPoints, 10)},
+		"target":        []string{target},
+	}
 
 	if setting.Env == setting.DEV {
 		glog.Debug("Graphite request", "params", formData)
@@ -117,9 +154,11 @@ func (e *GraphiteExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 	queryRes := tsdb.NewQueryResult()
 
 	for _, series := range data {
+		tags := formatTags(series.Tags)
 		queryRes.Series = append(queryRes.Series, &tsdb.TimeSeries{
-			Name:   series.Target,
+			Name:   formatSeriesName(series.Target, tags),
 			Points: series.DataPoints,
+			Tags:   tags,
 		})
 
 		if setting.Env == setting.DEV {
@@ -132,17 +171,11 @@ func (e *GraphiteExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 }
 
 func (e *GraphiteExecutor) parseResponse(res *http.Response) ([]TargetResponseDTO, error) {
-	body, err := ioutil.ReadAll(res.Body)
-	defer res.Body.Close()
+	body, err := readResponse(res)
 	if err != nil {
 		return nil, err
 	}
 
-	if res.StatusCode/100 != 2 {
-		glog.Info("Request failed", "status", res.Status, "body", string(body))
-		return nil, fmt.Errorf("Request failed status: %v", res.Status)
-	}
-
 	var data []TargetResponseDTO
 	err = json.Unmarshal(body, &data)
 	if err != nil {
@@ -153,6 +186,21 @@ func (e *GraphiteExecutor) parseResponse(res *http.Response) ([]TargetResponseDT
 	return data, nil
 }
 
+func readResponse(res *http.Response) ([]byte, error) {
+	body, err := ioutil.ReadAll(res.Body)
+	defer res.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode/100 != 2 {
+		glog.Info("Request failed", "status", res.Status, "body", string(body))
+		return nil, fmt.Errorf("Request failed status: %v", res.Status)
+	}
+
+	return body, nil
+}
+
 func (e *GraphiteExecutor) createRequest(dsInfo *models.DataSource, data url.Values) (*http.Request, error) {
 	u, _ := url.Parse(dsInfo.Url)
 	u.Path = path.Join(u.Path, "render")
@@ -189,3 +237,60 @@ func fixIntervalFormat(target string) string {
 	})
 	return target
 }
+
+// addConsolidateBy sets the function Graphite uses to consolidate data points of the
+// series of the target if there are more data points than maxDataPoints.
+func addConsolidateBy(target string, consolidateBy string) (string, error) {
+	if !consolidationFunctions[consolidateBy] {
+		return "", fmt.Errorf("invalid consolidation function %q", consolidateBy)
+	}
+	if strings.Contains(target, "consolidateBy(") {
+		return target, nil
+	}
+	return fmt.Sprintf("consolidateBy(%s, '%s')", target, consolidateBy), nil
+}
+
+func formatTags(tags map[string]interface{}) map[string]string {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	result := make(map[string]string, len(tags))
+	for k, v := range tags {
+		result[k] = fmt.Sprint(v)
+	}
+	return result
+}
+
+// formatSeriesName names series of seriesByTag targets by their tags, e.g.
+// cpu.load{dc=eu, host=a}, instead of the target expression.
+func formatSeriesName(target string, tags map[string]string) string {
+	if !strings.Contains(target, "seriesByTag(") || len(tags) == 0 {
+		return target
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		if k != "name" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, k+"="+tags[k])
+	}
+
+	name, ok := tags["name"]
+	if !ok || name == target {
+		name = ""
+	}
+	if len(pairs) == 0 {
+		if name == "" {
+			return target
+		}
+		return name
+	}
+	return name + "{" + strings.Join(pairs, ", ") + "}"
+}
@@ -1,8 +1,15 @@
 package graphite
 
 import (
-	. "github.com/smartystreets/goconvey/convey"
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	. "github.com/smartystreets/goconvey/convey"
 )
 
 func TestGraphiteFunctions(t *testing.T) {
@@ -57,5 +64,119 @@ func TestGraphiteFunctions(t *testing.T) {
 
 		})
 
+		Convey("should consolidate by the function", func() {
+
+			target, err := addConsolidateBy("app.grafana.*.count", "max")
+			So(err, ShouldBeNil)
+			So(target, ShouldEqual, "consolidateBy(app.grafana.*.count, 'max')")
+
+			target, err = addConsolidateBy("consolidateBy(app.grafana.*.count, 'sum')", "max")
+			So(err, ShouldBeNil)
+			So(target, ShouldEqual, "consolidateBy(app.grafana.*.count, 'sum')")
+
+			_, err = addConsolidateBy("app.grafana.*.count", "median")
+			So(err, ShouldNotBeNil)
+
+		})
+
+		Convey("should name seriesByTag series by their tags", func() {
+
+			tags := map[string]string{"name": "cpu.load", "host": "a", "dc": "eu"}
+			So(formatSeriesName("seriesByTag('name=cpu.load')", tags), ShouldEqual, "cpu.load{dc=eu, host=a}")
+			So(formatSeriesName("cpu.load;dc=eu;host=a", tags), ShouldEqual, "cpu.load;dc=eu;host=a")
+			So(formatSeriesName("seriesByTag('name=cpu.load')", nil), ShouldEqual, "seriesByTag('name=cpu.load')")
+
+		})
+
+	})
+}
+
+func TestGraphiteExecutor(t *testing.T) {
+	Convey("Graphite executor", t, func() {
+		var request *http.Request
+		responseBody := ""
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = r.ParseForm()
+			request = r
+			_, _ = w.Write([]byte(responseBody))
+		}))
+		defer server.Close()
+
+		dsInfo := &models.DataSource{Id: 1, Url: server.URL, JsonData: simplejson.New()}
+		executor := &GraphiteExecutor{}
+
+		query := func(model map[string]interface{}, maxDataPoints int64) (*tsdb.Response, error) {
+			return executor.Query(context.Background(), dsInfo, &tsdb.TsdbQuery{
+				TimeRange: tsdb.NewTimeRange("now-1h", "now"),
+				Queries:   []*tsdb.Query{{RefId: "A", Model: simplejson.NewFromAny(model), MaxDataPoints: maxDataPoints}},
+			})
+		}
+
+		Convey("should return the tags of series", func() {
+			responseBody = `[{
+				"target": "seriesByTag('name=cpu.load')",
+				"tags": {"name": "cpu.load", "host": "a"},
+				"datapoints": [[1.5, 1585735200]]
+			}]`
+
+			res, err := query(map[string]interface{}{"target": "seriesByTag('name=cpu.load')"}, 0)
+			So(err, ShouldBeNil)
+
+			So(request.URL.Path, ShouldEqual, "/render")
+			So(request.PostForm.Get("maxDataPoints"), ShouldEqual, "500")
+
+			series := res.Results["A"].Series
+			So(series, ShouldHaveLength, 1)
+			So(series[0].Name, ShouldEqual, "cpu.load{host=a}")
+			So(series[0].Tags, ShouldResemble, map[string]string{"name": "cpu.load", "host": "a"})
+			So(series[0].Points[0][0].Float64, ShouldEqual, 1.5)
+		})
+
+		Convey("should request maxDataPoints and consolidation of the query", func() {
+			responseBody = `[]`
+			dsInfo.JsonData.Set("consolidateBy", "max")
+
+			_, err := query(map[string]interface{}{"target": "app.count"}, 1000)
+			So(err, ShouldBeNil)
+			So(request.PostForm.Get("maxDataPoints"), ShouldEqual, "1000")
+			So(request.PostForm.Get("target"), ShouldEqual, "consolidateBy(app.count, 'max')")
+		})
+
+		Convey("should find tag values", func() {
+			responseBody = `["a", "b"]`
+
+			res, err := query(map[string]interface{}{
+				"type":        "metricFindQuery",
+				"subtype":     "tag_values",
+				"tag":         "host",
+				"expressions": []interface{}{"name=cpu.load"},
+				"valuePrefix": "a",
+			}, 0)
+			So(err, ShouldBeNil)
+
+			So(request.URL.Path, ShouldEqual, "/tags/autoComplete/values")
+			So(request.URL.Query().Get("tag"), ShouldEqual, "host")
+			So(request.URL.Query().Get("expr"), ShouldEqual, "name=cpu.load")
+			So(request.URL.Query().Get("valuePrefix"), ShouldEqual, "a")
+
+			table := res.Results["A"].Tables[0]
+			So(table.Rows, ShouldHaveLength, 2)
+			So(table.Rows[1], ShouldResemble, tsdb.RowValues{"b", "b"})
+		})
+
+		Convey("should find metrics", func() {
+			responseBody = `[{"text": "cpu", "id": "servers.cpu", "expandable": 1, "leaf": 0}]`
+
+			res, err := query(map[string]interface{}{"type": "metricFindQuery", "subtype": "metrics", "query": "servers.*"}, 0)
+			So(err, ShouldBeNil)
+			So(request.URL.Path, ShouldEqual, "/metrics/find")
+			So(res.Results["A"].Tables[0].Rows[0], ShouldResemble, tsdb.RowValues{"cpu", "cpu"})
+		})
+
+		Convey("should fail for unsupported metric find queries", func() {
+			_, err := query(map[string]interface{}{"type": "metricFindQuery", "subtype": "dashboards"}, 0)
+			So(err, ShouldNotBeNil)
+		})
 	})
 }
@@ -0,0 +1,139 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+
+	"golang.org/x/net/context/ctxhttp"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+// executeMetricFindQuery returns the tags, tag values or metrics of a metricFindQuery as a
+// table with text and value columns.
+func (e *GraphiteExecutor) executeMetricFindQuery(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
+	firstQuery := tsdbQuery.Queries[0]
+	queryResult := &tsdb.QueryResult{Meta: simplejson.New(), RefId: firstQuery.RefId}
+
+	params := url.Values{}
+	for _, expr := range firstQuery.Model.Get("expressions").MustStringArray() {
+		if expr = strings.TrimSpace(expr); expr != "" {
+			params.Add("expr", expr)
+		}
+	}
+	if limit := firstQuery.Model.Get("limit").MustInt(); limit > 0 {
+		params.Set("limit", fmt.Sprint(limit))
+	}
+	if tsdbQuery.TimeRange != nil {
+		params.Set("from", "-"+formatTimeRange(tsdbQuery.TimeRange.From))
+		params.Set("until", formatTimeRange(tsdbQuery.TimeRange.To))
+	}
+
+	var endpoint string
+	subType := firstQuery.Model.Get("subtype").MustString()
+	switch subType {
+	case "tags":
+		endpoint = "tags/autoComplete/tags"
+		if prefix := firstQuery.Model.Get("tagPrefix").MustString(); prefix != "" {
+			params.Set("tagPrefix", prefix)
+		}
+	case "tag_values":
+		endpoint = "tags/autoComplete/values"
+		tag := strings.TrimSpace(firstQuery.Model.Get("tag").MustString())
+		if tag == "" {
+			return nil, fmt.Errorf("missing tag of tag_values query")
+		}
+		params.Set("tag", tag)
+		if prefix := firstQuery.Model.Get("valuePrefix").MustString(); prefix != "" {
+			params.Set("valuePrefix", prefix)
+		}
+	case "metrics":
+		endpoint = "metrics/find"
+		query := strings.TrimSpace(firstQuery.Model.Get("query").MustString())
+		if query == "" {
+			return nil, fmt.Errorf("missing query of metrics query")
+		}
+		params.Set("query", query)
+	default:
+		return nil, fmt.Errorf("unsupported metric find query %q", subType)
+	}
+
+	body, err := e.doGetRequest(ctx, dsInfo, endpoint, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var values []string
+	if subType == "metrics" {
+		var nodes []MetricFindResponseDTO
+		if err := json.Unmarshal(body, &nodes); err != nil {
+			return nil, err
+		}
+		for _, node := range nodes {
+			values = append(values, node.Text)
+		}
+	} else {
+		if err := json.Unmarshal(body, &values); err != nil {
+			return nil, err
+		}
+	}
+
+	transformToTable(values, queryResult)
+
+	return &tsdb.Response{
+		Results: map[string]*tsdb.QueryResult{
+			firstQuery.RefId: queryResult,
+		},
+	}, nil
+}
+
+func (e *GraphiteExecutor) doGetRequest(ctx context.Context, dsInfo *models.DataSource, endpoint string, params url.Values) ([]byte, error) {
+	u, err := url.Parse(dsInfo.Url)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, endpoint)
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		glog.Info("Failed to create request", "error", err)
+		return nil, fmt.Errorf("Failed to create request. error: %v", err)
+	}
+
+	if dsInfo.BasicAuth {
+		req.SetBasicAuth(dsInfo.BasicAuthUser, dsInfo.DecryptedBasicAuthPassword())
+	}
+
+	httpClient, err := dsInfo.GetHttpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := ctxhttp.Do(ctx, httpClient, req)
+	if err != nil {
+		return nil, err
+	}
+
+	return readResponse(res)
+}
+
+func transformToTable(values []string, result *tsdb.QueryResult) {
+	table := &tsdb.Table{
+		Columns: []tsdb.TableColumn{{Text: "text"}, {Text: "value"}},
+		Rows:    make([]tsdb.RowValues, 0, len(values)),
+	}
+
+	for _, v := range values {
+		table.Rows = append(table.Rows, tsdb.RowValues{v, v})
+	}
+	result.Tables = append(result.Tables, table)
+	result.Meta.Set("rowCount", len(values))
+}
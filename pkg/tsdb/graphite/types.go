@@ -3,6 +3,15 @@ package graphite
 import "github.com/grafana/grafana/pkg/tsdb"
 
 type TargetResponseDTO struct {
-	Target     string                `json:"target"`
-	DataPoints tsdb.TimeSeriesPoints `json:"datapoints"`
+	Target     string                 `json:"target"`
+	DataPoints tsdb.TimeSeriesPoints  `json:"datapoints"`
+	Tags       map[string]interface{} `json:"tags"`
+}
+
+// MetricFindResponseDTO is a node of the response of the /metrics/find endpoint
+type MetricFindResponseDTO struct {
+	Text       string `json:"text"`
+	ID         string `json:"id"`
+	Expandable int    `json:"expandable"`
+	Leaf       int    `json:"leaf"`
 }
@@ -0,0 +1,185 @@
+// Package flux executes Flux queries against the InfluxDB 2.x query API.
+package flux
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"golang.org/x/net/context/ctxhttp"
+)
+
+var (
+	glog               = log.New("tsdb.influx_flux")
+	intervalCalculator = tsdb.NewIntervalCalculator(&tsdb.IntervalOptions{MinInterval: time.Millisecond})
+)
+
+// IsFlux returns true if the data source is configured to use Flux instead of InfluxQL.
+func IsFlux(dsInfo *models.DataSource) bool {
+	return dsInfo.JsonData != nil && dsInfo.JsonData.Get("version").MustString("") == "Flux"
+}
+
+// Query runs every query of the request against the /api/v2/query endpoint of the data source.
+func Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
+	if len(tsdbQuery.Queries) == 0 {
+		return nil, fmt.Errorf("query request contains no queries")
+	}
+
+	httpClient, err := dsInfo.GetHttpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &tsdb.Response{Results: make(map[string]*tsdb.QueryResult)}
+	for _, query := range tsdbQuery.Queries {
+		result.Results[query.RefId] = executeQuery(ctx, httpClient, dsInfo, tsdbQuery, query)
+	}
+
+	return result, nil
+}
+
+func executeQuery(ctx context.Context, httpClient *http.Client, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery, query *tsdb.Query) *tsdb.QueryResult {
+	queryResult := &tsdb.QueryResult{RefId: query.RefId}
+
+	rawQuery, err := interpolate(dsInfo, tsdbQuery.TimeRange, query)
+	if err != nil {
+		queryResult.Error = err
+		return queryResult
+	}
+
+	if setting.Env == setting.DEV {
+		glog.Debug("Flux query", "raw query", rawQuery)
+	}
+
+	req, err := createRequest(dsInfo, rawQuery)
+	if err != nil {
+		queryResult.Error = err
+		return queryResult
+	}
+
+	resp, err := ctxhttp.Do(ctx, httpClient, req)
+	if err != nil {
+		queryResult.Error = err
+		return queryResult
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		queryResult.Error = parseErrorResponse(resp)
+		return queryResult
+	}
+
+	frames, err := parseAnnotatedCSV(resp.Body)
+	if err != nil {
+		queryResult.Error = err
+		return queryResult
+	}
+
+	for _, frame := range frames {
+		frame.RefID = query.RefId
+		encoded, err := frame.MarshalArrow()
+		if err != nil {
+			queryResult.Error = err
+			return queryResult
+		}
+		queryResult.Dataframes = append(queryResult.Dataframes, encoded)
+	}
+
+	return queryResult
+}
+
+// interpolate replaces the variables Flux queries use in InfluxDB with the values of the request.
+func interpolate(dsInfo *models.DataSource, timeRange *tsdb.TimeRange, query *tsdb.Query) (string, error) {
+	rawQuery := query.Model.Get("query").MustString("")
+	if strings.TrimSpace(rawQuery) == "" {
+		return "", fmt.Errorf("query is empty")
+	}
+
+	minInterval, err := tsdb.GetIntervalFrom(dsInfo, query.Model, time.Millisecond)
+	if err != nil {
+		return "", err
+	}
+
+	windowPeriod := intervalCalculator.Calculate(timeRange, minInterval).Value
+	if requested := time.Duration(query.IntervalMs) * time.Millisecond; requested > windowPeriod {
+		windowPeriod = requested
+	}
+
+	replacer := strings.NewReplacer(
+		"v.timeRangeStart", timeRange.GetFromAsTimeUTC().Format(time.RFC3339Nano),
+		"v.timeRangeStop", timeRange.GetToAsTimeUTC().Format(time.RFC3339Nano),
+		"v.windowPeriod", fmt.Sprintf("%dms", windowPeriod.Milliseconds()),
+		"v.defaultBucket", fmt.Sprintf("%q", dsInfo.JsonData.Get("defaultBucket").MustString("")),
+		"v.organization", fmt.Sprintf("%q", dsInfo.JsonData.Get("organization").MustString("")),
+	)
+
+	return replacer.Replace(rawQuery), nil
+}
+
+func createRequest(dsInfo *models.DataSource, query string) (*http.Request, error) {
+	u, err := url.Parse(dsInfo.Url)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, "api/v2/query")
+
+	params := u.Query()
+	params.Set("org", dsInfo.JsonData.Get("organization").MustString(""))
+	u.RawQuery = params.Encode()
+
+	body, err := json.Marshal(map[string]interface{}{
+		"query": query,
+		"type":  "flux",
+		"dialect": map[string]interface{}{
+			"header":      true,
+			"delimiter":   ",",
+			"annotations": []string{"datatype", "group", "default"},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, u.String(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "Grafana")
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/csv")
+
+	if token, ok := dsInfo.DecryptedValue("token"); ok && token != "" {
+		req.Header.Set("Authorization", "Token "+token)
+	}
+
+	glog.Debug("Flux request", "url", req.URL.String())
+	return req, nil
+}
+
+func parseErrorResponse(resp *http.Response) error {
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("InfluxDB returned invalid status code: %v", resp.Status)
+	}
+
+	var errResponse struct {
+		Message string `json:"message"`
+	}
+	if err := json.Unmarshal(body, &errResponse); err == nil && errResponse.Message != "" {
+		return fmt.Errorf("InfluxDB returned error: %s", errResponse.Message)
+	}
+
+	return fmt.Errorf("InfluxDB returned invalid status code: %v", resp.Status)
+}
@@ -0,0 +1,86 @@
+package flux
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/securejsondata"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFluxQuery(t *testing.T) {
+	now := time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+	timeRange := tsdb.NewFakeTimeRange("now-1h", "now", now)
+
+	var receivedQueries []string
+	var receivedAuth, receivedOrg string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2/query", r.URL.Path)
+		receivedAuth = r.Header.Get("Authorization")
+		receivedOrg = r.URL.Query().Get("org")
+
+		var body struct {
+			Query string `json:"query"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		receivedQueries = append(receivedQueries, body.Query)
+
+		if body.Query == "fail" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = w.Write([]byte(`{"code":"invalid","message":"compilation failed"}`))
+			return
+		}
+
+		csv, err := ioutil.ReadFile("testdata/multiple_tables.csv")
+		require.NoError(t, err)
+		_, _ = w.Write(csv)
+	}))
+	defer server.Close()
+
+	dsInfo := &models.DataSource{
+		Url: server.URL,
+		JsonData: simplejson.NewFromAny(map[string]interface{}{
+			"version":       "Flux",
+			"organization":  "grafana",
+			"defaultBucket": "metrics",
+		}),
+		SecureJsonData: securejsondata.GetEncryptedJsonData(map[string]string{"token": "secret"}),
+	}
+	require.True(t, IsFlux(dsInfo))
+
+	newQuery := func(refID string, query string) *tsdb.Query {
+		return &tsdb.Query{RefId: refID, IntervalMs: 60000, Model: simplejson.NewFromAny(map[string]interface{}{"query": query})}
+	}
+
+	res, err := Query(context.Background(), dsInfo, &tsdb.TsdbQuery{
+		TimeRange: timeRange,
+		Queries: []*tsdb.Query{
+			newQuery("A", `from(bucket: v.defaultBucket) |> range(start: v.timeRangeStart, stop: v.timeRangeStop) |> aggregateWindow(every: v.windowPeriod, fn: mean)`),
+			newQuery("B", "fail"),
+		},
+	})
+	require.NoError(t, err)
+
+	t.Run("Should send the interpolated queries with the token", func(t *testing.T) {
+		require.Len(t, receivedQueries, 2)
+		assert.Equal(t, `from(bucket: "metrics") |> range(start: 2020-06-01T11:00:00Z, stop: 2020-06-01T12:00:00Z) |> aggregateWindow(every: 60000ms, fn: mean)`, receivedQueries[0])
+		assert.Equal(t, "Token secret", receivedAuth)
+		assert.Equal(t, "grafana", receivedOrg)
+	})
+
+	t.Run("Should return results of all queries", func(t *testing.T) {
+		require.Len(t, res.Results, 2)
+		assert.NoError(t, res.Results["A"].Error)
+		assert.Len(t, res.Results["A"].Dataframes, 3)
+		assert.EqualError(t, res.Results["B"].Error, "InfluxDB returned error: compilation failed")
+	})
+}
@@ -0,0 +1,297 @@
+package flux
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// Columns added by Flux to every table that are not useful as labels of a series
+var ignoredLabelColumns = map[string]bool{
+	"result": true,
+	"table":  true,
+	"_start": true,
+	"_stop":  true,
+	"_time":  true,
+	"_value": true,
+	"_field": true,
+}
+
+// column is a column of a table in an annotated CSV response.
+type column struct {
+	name         string
+	datatype     string
+	group        bool
+	defaultValue string
+}
+
+// table is a Flux table read from an annotated CSV response.
+type table struct {
+	columns []*column
+	rows    [][]string
+}
+
+// parseAnnotatedCSV reads the tables of a Flux response in the annotated CSV format
+// (https://docs.influxdata.com/influxdb/v2.0/reference/syntax/annotated-csv/)
+// and converts every table into a data frame.
+func parseAnnotatedCSV(r io.Reader) ([]*data.Frame, error) {
+	tables, err := readTables(r)
+	if err != nil {
+		return nil, err
+	}
+
+	frames := make([]*data.Frame, 0, len(tables))
+	for _, t := range tables {
+		frame, err := t.toFrame()
+		if err != nil {
+			return nil, err
+		}
+		frames = append(frames, frame)
+	}
+
+	return frames, nil
+}
+
+func readTables(r io.Reader) ([]*table, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = false
+
+	tables := []*table{}
+	var annotations map[string][]string
+	var columns []*column
+	var current *table
+	currentID := ""
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read flux response: %w", err)
+		}
+
+		// annotation rows start a new block of tables with the same columns
+		if strings.HasPrefix(record[0], "#") {
+			if columns != nil || annotations == nil {
+				annotations = map[string][]string{}
+				columns = nil
+				current = nil
+			}
+			annotations[record[0]] = record
+			continue
+		}
+
+		if columns == nil {
+			columns = newColumns(record, annotations)
+			if err := checkErrorTable(reader, columns); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		tableID := valueOf(columns, record, "table")
+		if current == nil || tableID != currentID {
+			current = &table{columns: columns}
+			currentID = tableID
+			tables = append(tables, current)
+		}
+		current.rows = append(current.rows, record)
+	}
+
+	return tables, nil
+}
+
+func newColumns(header []string, annotations map[string][]string) []*column {
+	columns := make([]*column, len(header))
+	for i, name := range header {
+		col := &column{name: name, datatype: "string"}
+		if datatypes, ok := annotations["#datatype"]; ok && i < len(datatypes) {
+			col.datatype = datatypes[i]
+		}
+		if groups, ok := annotations["#group"]; ok && i < len(groups) {
+			col.group = groups[i] == "true"
+		}
+		if defaults, ok := annotations["#default"]; ok && i < len(defaults) {
+			col.defaultValue = defaults[i]
+		}
+		columns[i] = col
+	}
+	return columns
+}
+
+// checkErrorTable returns the error reported by Flux in a table with
+// error and reference columns, which is sent when a query fails while streaming.
+func checkErrorTable(reader *csv.Reader, columns []*column) error {
+	if len(columns) < 2 || columns[1].name != "error" {
+		return nil
+	}
+
+	record, err := reader.Read()
+	if err != nil || len(record) < 2 {
+		return fmt.Errorf("flux query failed")
+	}
+	return fmt.Errorf("flux query failed: %s", record[1])
+}
+
+func valueOf(columns []*column, record []string, name string) string {
+	for i, col := range columns {
+		if col.name == name && i < len(record) {
+			return record[i]
+		}
+	}
+	return ""
+}
+
+func (t *table) columnIndex(name string) int {
+	for i, col := range t.columns {
+		if col.name == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// toFrame converts the table into a time series frame with the group key as labels
+// if it has _time and _value columns, and into a frame with all columns otherwise.
+func (t *table) toFrame() (*data.Frame, error) {
+	timeIdx := t.columnIndex("_time")
+	valueIdx := t.columnIndex("_value")
+
+	if timeIdx >= 0 && valueIdx >= 0 && isTime(t.columns[timeIdx].datatype) {
+		return t.toTimeSeriesFrame(timeIdx, valueIdx)
+	}
+
+	frame := data.NewFrame("")
+	for i, col := range t.columns {
+		// the first column holds the annotations
+		if i == 0 || col.name == "result" || col.name == "table" {
+			continue
+		}
+
+		field, err := t.newField(col.name, nil, i)
+		if err != nil {
+			return nil, err
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+
+	return frame, nil
+}
+
+func (t *table) toTimeSeriesFrame(timeIdx int, valueIdx int) (*data.Frame, error) {
+	labels := data.Labels{}
+	name := "_value"
+	if len(t.rows) > 0 {
+		first := t.rows[0]
+		for i, col := range t.columns {
+			if i == 0 || !col.group || ignoredLabelColumns[col.name] || i >= len(first) {
+				continue
+			}
+			labels[col.name] = t.value(first, i)
+		}
+
+		if fieldIdx := t.columnIndex("_field"); fieldIdx >= 0 && fieldIdx < len(first) {
+			name = t.value(first, fieldIdx)
+		}
+	}
+
+	timeField, err := t.newField("Time", nil, timeIdx)
+	if err != nil {
+		return nil, err
+	}
+
+	valueField, err := t.newField(name, labels, valueIdx)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.NewFrame(name, timeField, valueField), nil
+}
+
+func (t *table) value(record []string, idx int) string {
+	if idx >= len(record) || record[idx] == "" {
+		return t.columns[idx].defaultValue
+	}
+	return record[idx]
+}
+
+func (t *table) newField(name string, labels data.Labels, idx int) (*data.Field, error) {
+	datatype := t.columns[idx].datatype
+
+	var values interface{}
+	switch {
+	case datatype == "double":
+		values = make([]*float64, 0, len(t.rows))
+	case datatype == "long":
+		values = make([]*int64, 0, len(t.rows))
+	case datatype == "unsignedLong":
+		values = make([]*uint64, 0, len(t.rows))
+	case datatype == "boolean":
+		values = make([]*bool, 0, len(t.rows))
+	case isTime(datatype):
+		values = make([]*time.Time, 0, len(t.rows))
+	default:
+		values = make([]*string, 0, len(t.rows))
+	}
+
+	field := data.NewField(name, labels, values)
+	for _, record := range t.rows {
+		value, err := parseValue(datatype, t.value(record, idx))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse value of column %q: %w", t.columns[idx].name, err)
+		}
+		field.Append(value)
+	}
+
+	return field, nil
+}
+
+func isTime(datatype string) bool {
+	return strings.HasPrefix(datatype, "dateTime")
+}
+
+// parseValue converts a value of the annotated CSV to a pointer of the type of the field, nil if the value is empty.
+func parseValue(datatype string, value string) (interface{}, error) {
+	switch {
+	case datatype == "double":
+		if value == "" {
+			return (*float64)(nil), nil
+		}
+		v, err := strconv.ParseFloat(value, 64)
+		return &v, err
+	case datatype == "long":
+		if value == "" {
+			return (*int64)(nil), nil
+		}
+		v, err := strconv.ParseInt(value, 10, 64)
+		return &v, err
+	case datatype == "unsignedLong":
+		if value == "" {
+			return (*uint64)(nil), nil
+		}
+		v, err := strconv.ParseUint(value, 10, 64)
+		return &v, err
+	case datatype == "boolean":
+		if value == "" {
+			return (*bool)(nil), nil
+		}
+		v, err := strconv.ParseBool(value)
+		return &v, err
+	case isTime(datatype):
+		if value == "" {
+			return (*time.Time)(nil), nil
+		}
+		v, err := time.Parse(time.RFC3339Nano, value)
+		return &v, err
+	default:
+		v := value
+		return &v, nil
+	}
+}
@@ -0,0 +1,69 @@
+package flux
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readTestData(t *testing.T, name string) []*data.Frame {
+	t.Helper()
+
+	f, err := os.Open("testdata/" + name)
+	require.NoError(t, err)
+	defer f.Close()
+
+	frames, err := parseAnnotatedCSV(f)
+	require.NoError(t, err)
+	return frames
+}
+
+func TestParseAnnotatedCSV(t *testing.T) {
+	t.Run("Should convert tables with _time and _value to series with tag labels", func(t *testing.T) {
+		frames := readTestData(t, "multiple_tables.csv")
+		require.Len(t, frames, 3)
+
+		frame := frames[0]
+		require.Len(t, frame.Fields, 2)
+		assert.Equal(t, "Time", frame.Fields[0].Name)
+		assert.Equal(t, "usage_idle", frame.Fields[1].Name)
+		assert.Equal(t, data.Labels{"_measurement": "cpu", "host": "a"}, frame.Fields[1].Labels)
+		assert.Equal(t, 2, frame.Fields[1].Len())
+
+		ts := frame.Fields[0].At(1).(*time.Time)
+		assert.Equal(t, time.Date(2020, 6, 1, 11, 1, 0, 0, time.UTC), *ts)
+		assert.Equal(t, 1.5, *frame.Fields[1].At(0).(*float64))
+		assert.Nil(t, frame.Fields[1].At(1))
+
+		assert.Equal(t, data.Labels{"_measurement": "cpu", "host": "b"}, frames[1].Fields[1].Labels)
+
+		series, err := tsdb.FrameToSeriesSlice(frames[1])
+		require.NoError(t, err)
+		require.Len(t, series, 1)
+		assert.Equal(t, "b", series[0].Tags["host"])
+	})
+
+	t.Run("Should convert other tables to frames with all columns", func(t *testing.T) {
+		frame := readTestData(t, "multiple_tables.csv")[2]
+		require.Len(t, frame.Fields, 3)
+		assert.Equal(t, "host", frame.Fields[0].Name)
+		assert.Equal(t, "count", frame.Fields[1].Name)
+		assert.Equal(t, int64(12), *frame.Fields[1].At(1).(*int64))
+		assert.Equal(t, false, *frame.Fields[2].At(1).(*bool))
+	})
+
+	t.Run("Should return errors reported in the response", func(t *testing.T) {
+		f, err := os.Open("testdata/error.csv")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = parseAnnotatedCSV(f)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), `could not find bucket "missing"`)
+	})
+}
@@ -14,6 +14,7 @@ import (
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/setting"
 	"github.com/grafana/grafana/pkg/tsdb"
+	"github.com/grafana/grafana/pkg/tsdb/influxdb/flux"
 	"golang.org/x/net/context/ctxhttp"
 )
 
@@ -43,9 +44,39 @@ func init() {
 }
 
 func (e *InfluxDBExecutor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
-	result := &tsdb.Response{}
+	if flux.IsFlux(dsInfo) {
+		return flux.Query(ctx, dsInfo, tsdbQuery)
+	}
+
+	if len(tsdbQuery.Queries) == 0 {
+		return nil, fmt.Errorf("query request contains no queries")
+	}
+
+	httpClient, err := dsInfo.GetHttpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	result := &tsdb.Response{Results: make(map[string]*tsdb.QueryResult)}
+	for _, tsdbQ := range tsdbQuery.Queries {
+		queryResult, err := e.executeQuery(ctx, httpClient, dsInfo, tsdbQuery, tsdbQ)
+		if err != nil {
+			// a single query keeps returning the error of the request
+			if len(tsdbQuery.Queries) == 1 {
+				return nil, err
+			}
+			queryResult = &tsdb.QueryResult{Error: err}
+		}
+
+		queryResult.RefId = tsdbQ.RefId
+		result.Results[tsdbQ.RefId] = queryResult
+	}
+
+	return result, nil
+}
 
-	query, err := e.getQuery(dsInfo, tsdbQuery.Queries, tsdbQuery)
+func (e *InfluxDBExecutor) executeQuery(ctx context.Context, httpClient *http.Client, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery, tsdbQ *tsdb.Query) (*tsdb.QueryResult, error) {
+	query, err := e.QueryParser.Parse(tsdbQ.Model, dsInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -64,11 +95,6 @@ func (e *InfluxDBExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 		return nil, err
 	}
 
-	httpClient, err := dsInfo.GetHttpClient()
-	if err != nil {
-		return nil, err
-	}
-
 	resp, err := ctxhttp.Do(ctx, httpClient, req)
 	if err != nil {
 		return nil, err
@@ -92,23 +118,7 @@ func (e *InfluxDBExecutor) Query(ctx context.Context, dsInfo *models.DataSource,
 		return nil, response.Err
 	}
 
-	result.Results = make(map[string]*tsdb.QueryResult)
-	result.Results["A"] = e.ResponseParser.Parse(&response, query)
-
-	return result, nil
-}
-
-func (e *InfluxDBExecutor) getQuery(dsInfo *models.DataSource, queries []*tsdb.Query, context *tsdb.TsdbQuery) (*Query, error) {
-	// The model supports multiple queries, but right now this is only used from
-	// alerting so we only needed to support batch executing 1 query at a time.
-	if len(queries) > 0 {
-		query, err := e.QueryParser.Parse(queries[0].Model, dsInfo)
-		if err != nil {
-			return nil, err
-		}
-		return query, nil
-	}
-	return nil, fmt.Errorf("query request contains no queries")
+	return e.ResponseParser.Parse(&response, query), nil
 }
 
 func (e *InfluxDBExecutor) createRequest(dsInfo *models.DataSource, query string) (*http.Request, error) {
@@ -1,12 +1,17 @@
 package influxdb
 
 import (
+	"context"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
+	"strconv"
 	"testing"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
 	. "github.com/smartystreets/goconvey/convey"
 )
 
@@ -75,3 +80,38 @@ func TestInfluxDB(t *testing.T) {
 
 	})
 }
+
+func TestInfluxDBQuery(t *testing.T) {
+	Convey("When executing request with multiple queries", t, func() {
+		receivedQueries := []string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			q := r.URL.Query().Get("q")
+			receivedQueries = append(receivedQueries, q)
+			_, _ = w.Write([]byte(`{"results":[{"series":[{"name":"cpu","columns":["time","value"],"values":[[1,` + strconv.Itoa(len(receivedQueries)) + `]]}]}]}`))
+		}))
+		defer server.Close()
+
+		datasource := &models.DataSource{Url: server.URL, Database: "db", JsonData: simplejson.New()}
+		newQuery := func(refID string, rawQuery string) *tsdb.Query {
+			return &tsdb.Query{RefId: refID, Model: simplejson.NewFromAny(map[string]interface{}{
+				"rawQuery":     true,
+				"query":        rawQuery,
+				"resultFormat": "time_series",
+			})}
+		}
+
+		e, _ := NewInfluxDBExecutor(datasource)
+		res, err := e.Query(context.Background(), datasource, &tsdb.TsdbQuery{
+			TimeRange: tsdb.NewTimeRange("now-1h", "now"),
+			Queries:   []*tsdb.Query{newQuery("A", "SELECT a FROM cpu"), newQuery("B", "SELECT b FROM cpu")},
+		})
+		So(err, ShouldBeNil)
+
+		Convey("Should execute all queries", func() {
+			So(receivedQueries, ShouldResemble, []string{"SELECT a FROM cpu", "SELECT b FROM cpu"})
+			So(res.Results["A"].RefId, ShouldEqual, "A")
+			So(res.Results["A"].Series[0].Points[0][0].Float64, ShouldEqual, 1)
+			So(res.Results["B"].Series[0].Points[0][0].Float64, ShouldEqual, 2)
+		})
+	})
+}
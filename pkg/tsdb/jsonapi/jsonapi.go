@@ -0,0 +1,203 @@
+package jsonapi
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context/ctxhttp"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+// maxErrorBodyLength is the number of bytes of the body of failed requests added to errors
+const maxErrorBodyLength = 256
+
+// maxResponseSize is the maximum size of a response that is read
+var maxResponseSize = 50 * 1024 * 1024
+
+type JSONAPIExecutor struct {
+	intervalCalculator tsdb.IntervalCalculator
+}
+
+func NewJSONAPIExecutor(datasource *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+	return &JSONAPIExecutor{
+		intervalCalculator: tsdb.NewIntervalCalculator(nil),
+	}, nil
+}
+
+var (
+	plog log.Logger
+)
+
+func init() {
+	plog = log.New("tsdb.jsonapi")
+	tsdb.RegisterTsdbQueryEndpoint(models.DS_JSON_API, NewJSONAPIExecutor)
+}
+
+func (e *JSONAPIExecutor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
+	result := &tsdb.Response{
+		Results: map[string]*tsdb.QueryResult{},
+	}
+
+	httpClient, err := dsInfo.GetHttpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, q := range tsdbQuery.Queries {
+		queryResult := tsdb.NewQueryResult()
+		queryResult.RefId = q.RefId
+
+		if err := e.executeQuery(ctx, httpClient, dsInfo, q, tsdbQuery.TimeRange, queryResult); err != nil {
+			queryResult.Error = err
+			queryResult.ErrorString = err.Error()
+		}
+		result.Results[q.RefId] = queryResult
+	}
+
+	return result, nil
+}
+
+func (e *JSONAPIExecutor) executeQuery(ctx context.Context, httpClient *http.Client, dsInfo *models.DataSource, q *tsdb.Query, timeRange *tsdb.TimeRange, queryResult *tsdb.QueryResult) error {
+	query, err := parseQuery(q.RefId, q.Model)
+	if err != nil {
+		return err
+	}
+
+	minInterval, err := tsdb.GetIntervalFrom(dsInfo, q.Model, time.Second)
+	if err != nil {
+		return err
+	}
+	interval := e.intervalCalculator.Calculate(timeRange, minInterval)
+
+	req, err := e.createRequest(dsInfo, query, newInterpolator(timeRange, interval))
+	if err != nil {
+		return err
+	}
+
+	if setting.Env == setting.DEV {
+		plog.Debug("JSON API request", "method", req.Method, "url", req.URL.String())
+	}
+	queryResult.Meta = simplejson.NewFromAny(map[string]interface{}{
+		"executedQueryString": req.Method + " " + req.URL.String(),
+	})
+
+	res, err := ctxhttp.Do(ctx, httpClient, req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, int64(maxResponseSize)+1))
+	if err != nil {
+		return err
+	}
+	if len(body) > maxResponseSize {
+		return fmt.Errorf("response exceeds the maximum size of %d bytes", maxResponseSize)
+	}
+
+	if res.StatusCode/100 != 2 {
+		if len(body) > maxErrorBodyLength {
+			body = body[:maxErrorBodyLength]
+		}
+		plog.Debug("JSON API request failed", "status", res.Status, "body", string(body))
+		return fmt.Errorf("request failed, status: %s, body: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+
+	frame, err := parseResponse(body, query)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := frame.MarshalArrow()
+	if err != nil {
+		return err
+	}
+	queryResult.Dataframes = append(queryResult.Dataframes, encoded)
+	return nil
+}
+
+func (e *JSONAPIExecutor) createRequest(dsInfo *models.DataSource, query *jsonAPIQuery, interpolate func(string) string) (*http.Request, error) {
+	u, err := url.Parse(dsInfo.Url)
+	if err != nil {
+		return nil, err
+	}
+
+	rel, err := url.Parse(interpolate(query.Path))
+	if err != nil {
+		return nil, err
+	}
+	// the path is relative to the path of the data source and can't leave it
+	base := path.Clean("/" + u.Path)
+	u.Path = path.Join(base, rel.Path)
+	if u.Path != base && !strings.HasPrefix(u.Path, strings.TrimSuffix(base, "/")+"/") {
+		return nil, fmt.Errorf("path %q is outside of the URL of the data source", rel.Path)
+	}
+
+	params := u.Query()
+	for key, values := range rel.Query() {
+		for _, value := range values {
+			params.Add(key, value)
+		}
+	}
+	for _, param := range query.Params {
+		params.Add(param.Key, interpolate(param.Value))
+	}
+	u.RawQuery = params.Encode()
+
+	var body io.Reader
+	if query.Body != "" {
+		body = strings.NewReader(interpolate(query.Body))
+	}
+
+	req, err := http.NewRequest(query.Method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "Grafana/"+setting.BuildVersion)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for _, header := range query.Headers {
+		req.Header.Set(header.Key, interpolate(header.Value))
+	}
+
+	if dsInfo.BasicAuth {
+		req.SetBasicAuth(dsInfo.BasicAuthUser, dsInfo.DecryptedBasicAuthPassword())
+	}
+
+	return req, nil
+}
+
+// newInterpolator returns a function replacing the time range and interval variables
+// in the path, parameters, headers and body of requests.
+func newInterpolator(timeRange *tsdb.TimeRange, interval tsdb.Interval) func(string) string {
+	from := timeRange.GetFromAsTimeUTC()
+	to := timeRange.GetToAsTimeUTC()
+
+	replacer := strings.NewReplacer(
+		"$__fromISO", from.Format(time.RFC3339),
+		"$__toISO", to.Format(time.RFC3339),
+		"$__fromSeconds", strconv.FormatInt(timeRange.GetFromAsSecondsEpoch(), 10),
+		"$__toSeconds", strconv.FormatInt(timeRange.GetToAsSecondsEpoch(), 10),
+		"$__from", strconv.FormatInt(timeRange.GetFromAsMsEpoch(), 10),
+		"$__to", strconv.FormatInt(timeRange.GetToAsMsEpoch(), 10),
+		"$__interval_ms", strconv.FormatInt(interval.Milliseconds(), 10),
+		"$__interval", interval.Text,
+	)
+
+	return replacer.Replace
+}
@@ -0,0 +1,183 @@
+package jsonapi
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestJSONAPI(t *testing.T) {
+	Convey("JSON API", t, func() {
+		var request *http.Request
+		var requestBody string
+		responseStatus := http.StatusOK
+		responseBody := `{"data": [
+			{"time": "2020-04-01T10:01:00Z", "value": 2, "host": "b", "up": false},
+			{"time": "2020-04-01T10:00:00Z", "value": "1.5", "host": "a", "up": true}
+		]}`
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+			body, _ := ioutil.ReadAll(r.Body)
+			requestBody = string(body)
+			w.WriteHeader(responseStatus)
+			_, _ = w.Write([]byte(responseBody))
+		}))
+		defer server.Close()
+
+		dsInfo := &models.DataSource{
+			Id:            1,
+			Url:           server.URL + "/api",
+			BasicAuth:     true,
+			BasicAuthUser: "user",
+			JsonData:      simplejson.New(),
+		}
+
+		from := time.Date(2020, 4, 1, 10, 0, 0, 0, time.UTC)
+		to := from.Add(time.Hour)
+		timeRange := tsdb.NewTimeRange(fmt.Sprint(from.Unix()*1000), fmt.Sprint(to.Unix()*1000))
+
+		executor, err := NewJSONAPIExecutor(dsInfo)
+		So(err, ShouldBeNil)
+
+		query := func(model map[string]interface{}) *tsdb.QueryResult {
+			res, err := executor.Query(context.Background(), dsInfo, &tsdb.TsdbQuery{
+				TimeRange: timeRange,
+				Queries:   []*tsdb.Query{{RefId: "A", Model: simplejson.NewFromAny(model)}},
+			})
+			So(err, ShouldBeNil)
+			return res.Results["A"]
+		}
+
+		fields := []interface{}{
+			map[string]interface{}{"name": "Time", "jsonPath": "$.data[*].time", "type": "time"},
+			map[string]interface{}{"name": "Value", "jsonPath": "$.data[*].value", "type": "number"},
+			map[string]interface{}{"jsonPath": "$.data[*].host"},
+			map[string]interface{}{"name": "Up", "jsonPath": "$.data[*].up"},
+		}
+
+		Convey("should request the path with interpolated parameters, headers and body", func() {
+			result := query(map[string]interface{}{
+				"method": "post",
+				"path":   "metrics?source=web",
+				"params": []interface{}{
+					map[string]interface{}{"key": "from", "value": "$__from"},
+					map[string]interface{}{"key": "to", "value": "$__toISO"},
+					map[string]interface{}{"key": "", "value": "ignored"},
+				},
+				"headers": []interface{}{map[string]interface{}{"key": "X-Interval", "value": "$__interval_ms"}},
+				"body":    `{"from": $__fromSeconds, "interval": "$__interval"}`,
+				"fields":  fields,
+			})
+			So(result.Error, ShouldBeNil)
+
+			So(request.Method, ShouldEqual, "POST")
+			So(request.URL.Path, ShouldEqual, "/api/metrics")
+			So(request.URL.Query().Get("source"), ShouldEqual, "web")
+			So(request.URL.Query().Get("from"), ShouldEqual, "1585735200000")
+			So(request.URL.Query().Get("to"), ShouldEqual, "2020-04-01T11:00:00Z")
+			So(request.Header.Get("X-Interval"), ShouldEqual, "2000")
+			So(request.Header.Get("Content-Type"), ShouldEqual, "application/json")
+			So(requestBody, ShouldEqual, `{"from": 1585735200, "interval": "2s"}`)
+
+			user, _, ok := request.BasicAuth()
+			So(ok, ShouldBeTrue)
+			So(user, ShouldEqual, "user")
+		})
+
+		Convey("should extract fields into a frame sorted by time", func() {
+			result := query(map[string]interface{}{"path": "metrics", "fields": fields})
+			So(result.Error, ShouldBeNil)
+			So(result.Dataframes, ShouldHaveLength, 1)
+
+			frame, err := data.UnmarshalArrowFrame(result.Dataframes[0])
+			So(err, ShouldBeNil)
+			So(frame.RefID, ShouldEqual, "A")
+			So(frame.Fields, ShouldHaveLength, 4)
+
+			So(frame.Fields[0].Type(), ShouldEqual, data.FieldTypeNullableTime)
+			So(frame.Fields[1].Type(), ShouldEqual, data.FieldTypeNullableFloat64)
+			So(frame.Fields[2].Type(), ShouldEqual, data.FieldTypeNullableString)
+			So(frame.Fields[2].Name, ShouldEqual, "$.data[*].host")
+			So(frame.Fields[3].Type(), ShouldEqual, data.FieldTypeNullableBool)
+
+			t, _ := frame.Fields[0].ConcreteAt(0)
+			So(t, ShouldEqual, from)
+			value, _ := frame.Fields[1].ConcreteAt(0)
+			So(value, ShouldEqual, 1.5)
+			host, _ := frame.Fields[2].ConcreteAt(1)
+			So(host, ShouldEqual, "b")
+
+			series, err := tsdb.FrameToSeriesSlice(frame)
+			So(err, ShouldBeNil)
+			So(series, ShouldHaveLength, 4)
+		})
+
+		Convey("should fail if fields have a different number of values", func() {
+			result := query(map[string]interface{}{
+				"fields": []interface{}{
+					map[string]interface{}{"jsonPath": "$.data[*].value"},
+					map[string]interface{}{"jsonPath": "$.data[0].host"},
+				},
+			})
+			So(result.Error, ShouldNotBeNil)
+			So(result.Error.Error(), ShouldContainSubstring, "same number of values")
+		})
+
+		Convey("should fail if values can't be converted to the type of the field", func() {
+			result := query(map[string]interface{}{
+				"fields": []interface{}{map[string]interface{}{"name": "Host", "jsonPath": "$.data[*].host", "type": "number"}},
+			})
+			So(result.Error, ShouldNotBeNil)
+			So(result.Error.Error(), ShouldEqual, `invalid value of number field Host: "b" is not a number`)
+		})
+
+		Convey("should return the status and body of failed requests", func() {
+			responseStatus = http.StatusNotFound
+			responseBody = `{"message": "not found"}`
+
+			result := query(map[string]interface{}{"path": "missing", "fields": fields})
+			So(result.Error, ShouldNotBeNil)
+			So(result.Error.Error(), ShouldEqual, `request failed, status: 404 Not Found, body: {"message": "not found"}`)
+		})
+
+		Convey("should reject paths outside of the URL of the data source", func() {
+			for _, path := range []string{"../admin", "metrics/../../admin", "%2e%2e/admin"} {
+				request = nil
+				result := query(map[string]interface{}{"path": path, "fields": fields})
+				So(result.Error, ShouldNotBeNil)
+				So(result.Error.Error(), ShouldContainSubstring, "outside of the URL of the data source")
+				So(request, ShouldBeNil)
+			}
+
+			result := query(map[string]interface{}{"path": "metrics/../status", "fields": fields})
+			So(result.Error, ShouldBeNil)
+			So(request.URL.Path, ShouldEqual, "/api/status")
+		})
+
+		Convey("should fail if the response is too large", func() {
+			maxResponseSize = 16
+			defer func() { maxResponseSize = 50 * 1024 * 1024 }()
+
+			result := query(map[string]interface{}{"path": "metrics", "fields": fields})
+			So(result.Error, ShouldNotBeNil)
+			So(result.Error.Error(), ShouldEqual, "response exceeds the maximum size of 16 bytes")
+		})
+
+		Convey("should reject unsupported methods", func() {
+			result := query(map[string]interface{}{"method": "DELETE"})
+			So(result.Error, ShouldNotBeNil)
+		})
+	})
+}
@@ -0,0 +1,193 @@
+package jsonapi
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a parsed JSONPath expression. The supported syntax is the subset of JSONPath needed
+// to select values of JSON documents returned by APIs: the root $, members .name and ['name'],
+// array indices [n] with negative indices counting from the end, wildcards .* and [*], and
+// recursive descent with ..name, ..* and ..[n].
+type jsonPath []pathSegment
+
+type pathSegment struct {
+	recursive bool
+	wildcard  bool
+	isIndex   bool
+	index     int
+	key       string
+}
+
+func parseJSONPath(expr string) (jsonPath, error) {
+	expr = strings.TrimSpace(expr)
+	if !strings.HasPrefix(expr, "$") {
+		return nil, fmt.Errorf("invalid JSONPath %q, must start with $", expr)
+	}
+
+	path := jsonPath{}
+	recursive := false
+
+	for i := 1; i < len(expr); {
+		switch expr[i] {
+		case '.':
+			i++
+			if i < len(expr) && expr[i] == '.' {
+				if recursive {
+					return nil, fmt.Errorf("invalid JSONPath %q, unexpected . at position %d", expr, i)
+				}
+				recursive = true
+				i++
+				if i < len(expr) && expr[i] == '[' {
+					continue
+				}
+			}
+
+			end := i
+			for end < len(expr) && expr[end] != '.' && expr[end] != '[' {
+				end++
+			}
+			name := expr[i:end]
+			if name == "" {
+				return nil, fmt.Errorf("invalid JSONPath %q, missing member name at position %d", expr, i)
+			}
+
+			path = append(path, pathSegment{recursive: recursive, wildcard: name == "*", key: name})
+			recursive = false
+			i = end
+		case '[':
+			segment, end, err := parseBracket(expr, i)
+			if err != nil {
+				return nil, err
+			}
+
+			segment.recursive = recursive
+			path = append(path, segment)
+			recursive = false
+			i = end
+		default:
+			return nil, fmt.Errorf("invalid JSONPath %q, unexpected %c at position %d", expr, expr[i], i)
+		}
+	}
+
+	if recursive {
+		return nil, fmt.Errorf("invalid JSONPath %q, missing member name after ..", expr)
+	}
+
+	return path, nil
+}
+
+// parseBracket parses the bracket notation starting at position start, returning the
+// segment and the position after the closing bracket.
+func parseBracket(expr string, start int) (pathSegment, int, error) {
+	i := start + 1
+	if i < len(expr) && (expr[i] == '\'' || expr[i] == '"') {
+		quote := expr[i]
+		var key strings.Builder
+		for i++; i < len(expr) && expr[i] != quote; i++ {
+			if expr[i] == '\\' && i+1 < len(expr) {
+				i++
+			}
+			key.WriteByte(expr[i])
+		}
+		if i+1 >= len(expr) || expr[i+1] != ']' {
+			return pathSegment{}, 0, fmt.Errorf("invalid JSONPath %q, unterminated member name at position %d", expr, start)
+		}
+		return pathSegment{key: key.String()}, i + 2, nil
+	}
+
+	end := strings.IndexByte(expr[i:], ']')
+	if end < 0 {
+		return pathSegment{}, 0, fmt.Errorf("invalid JSONPath %q, missing ] at position %d", expr, start)
+	}
+	content := strings.TrimSpace(expr[i : i+end])
+
+	if content == "*" {
+		return pathSegment{wildcard: true}, i + end + 1, nil
+	}
+
+	index, err := strconv.Atoi(content)
+	if err != nil {
+		return pathSegment{}, 0, fmt.Errorf("invalid JSONPath %q, unsupported expression [%s]", expr, content)
+	}
+	return pathSegment{isIndex: true, index: index}, i + end + 1, nil
+}
+
+func (p jsonPath) eval(doc interface{}) []interface{} {
+	nodes := []interface{}{doc}
+	for _, segment := range p {
+		next := []interface{}{}
+		for _, node := range nodes {
+			if !segment.recursive {
+				next = append(next, segment.match(node)...)
+				continue
+			}
+			for _, descendant := range descendants(node) {
+				next = append(next, segment.match(descendant)...)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func (s pathSegment) match(node interface{}) []interface{} {
+	switch {
+	case s.wildcard:
+		return children(node)
+	case s.isIndex:
+		array, ok := node.([]interface{})
+		if !ok {
+			return nil
+		}
+		index := s.index
+		if index < 0 {
+			index += len(array)
+		}
+		if index < 0 || index >= len(array) {
+			return nil
+		}
+		return []interface{}{array[index]}
+	default:
+		object, ok := node.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if value, ok := object[s.key]; ok {
+			return []interface{}{value}
+		}
+		return nil
+	}
+}
+
+// children returns the values of an object ordered by key, or the items of an array
+func children(node interface{}) []interface{} {
+	switch v := node.(type) {
+	case []interface{}:
+		return v
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		values := make([]interface{}, 0, len(v))
+		for _, key := range keys {
+			values = append(values, v[key])
+		}
+		return values
+	}
+	return nil
+}
+
+// descendants returns a node followed by all values nested in it, depth first
+func descendants(node interface{}) []interface{} {
+	nodes := []interface{}{node}
+	for _, child := range children(node) {
+		nodes = append(nodes, descendants(child)...)
+	}
+	return nodes
+}
@@ -0,0 +1,70 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestJSONPath(t *testing.T) {
+	Convey("JSONPath", t, func() {
+		var doc interface{}
+		err := json.Unmarshal([]byte(`{
+			"status": "ok",
+			"data": {
+				"items": [
+					{"ts": 1, "value": 10, "tags": {"host": "a"}},
+					{"ts": 2, "value": 20, "tags": {"host": "b"}},
+					{"ts": 3, "value": 30, "tags": {"host": "c"}}
+				],
+				"my key": "spaced"
+			}
+		}`), &doc)
+		So(err, ShouldBeNil)
+
+		eval := func(expr string) []interface{} {
+			path, err := parseJSONPath(expr)
+			So(err, ShouldBeNil)
+			return path.eval(doc)
+		}
+
+		Convey("should select the root", func() {
+			So(eval("$"), ShouldResemble, []interface{}{doc})
+		})
+
+		Convey("should select members", func() {
+			So(eval("$.status"), ShouldResemble, []interface{}{"ok"})
+			So(eval("$['data']['my key']"), ShouldResemble, []interface{}{"spaced"})
+			So(eval(`$["data"].items[0].tags.host`), ShouldResemble, []interface{}{"a"})
+		})
+
+		Convey("should select array items by index", func() {
+			So(eval("$.data.items[1].value"), ShouldResemble, []interface{}{float64(20)})
+			So(eval("$.data.items[-1].value"), ShouldResemble, []interface{}{float64(30)})
+			So(eval("$.data.items[3].value"), ShouldBeEmpty)
+		})
+
+		Convey("should select all items with wildcards", func() {
+			So(eval("$.data.items[*].ts"), ShouldResemble, []interface{}{float64(1), float64(2), float64(3)})
+			So(eval("$.data.items.*.tags.host"), ShouldResemble, []interface{}{"a", "b", "c"})
+		})
+
+		Convey("should select nested members with recursive descent", func() {
+			So(eval("$..host"), ShouldResemble, []interface{}{"a", "b", "c"})
+			So(eval("$..items[0].value"), ShouldResemble, []interface{}{float64(10)})
+		})
+
+		Convey("should return nothing for missing members", func() {
+			So(eval("$.missing.value"), ShouldBeEmpty)
+			So(eval("$.status[0]"), ShouldBeEmpty)
+		})
+
+		Convey("should fail to parse invalid expressions", func() {
+			for _, expr := range []string{"data.items", "$.", "$..", "$[", "$['a'", "$[?(@.ts > 1)]", "$a"} {
+				_, err := parseJSONPath(expr)
+				So(err, ShouldNotBeNil)
+			}
+		})
+	})
+}
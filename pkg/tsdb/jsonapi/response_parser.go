@@ -0,0 +1,215 @@
+package jsonapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+// parseResponse extracts the values of the fields of the query from the JSON body of a response into
+// a frame. All fields must select the same number of values. Rows are sorted by the first time field.
+func parseResponse(body []byte, query *jsonAPIQuery) (*data.Frame, error) {
+	frame := data.NewFrame("")
+	frame.RefID = query.RefID
+
+	// queries without fields only check that requests succeed
+	if len(query.Fields) == 0 {
+		return frame, nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse response as JSON: %v", err)
+	}
+
+	rowCount := -1
+	timeIndex := -1
+	for _, f := range query.Fields {
+		values := f.path.eval(doc)
+		if rowCount >= 0 && len(values) != rowCount {
+			return nil, fmt.Errorf("all fields must have the same number of values, field %s has %d values, expected %d", f.Name, len(values), rowCount)
+		}
+		rowCount = len(values)
+
+		field, err := newField(f, values)
+		if err != nil {
+			return nil, err
+		}
+		if timeIndex < 0 && field.Type() == data.FieldTypeNullableTime {
+			timeIndex = len(frame.Fields)
+		}
+		frame.Fields = append(frame.Fields, field)
+	}
+
+	if timeIndex >= 0 {
+		sortByTime(frame, timeIndex)
+	}
+
+	return frame, nil
+}
+
+func newField(f jsonAPIField, values []interface{}) (*data.Field, error) {
+	fieldType := f.Type
+	if fieldType == fieldTypeAuto {
+		fieldType = valuesType(values)
+	}
+
+	switch fieldType {
+	case fieldTypeTime:
+		times := make([]*time.Time, len(values))
+		for i, value := range values {
+			t, err := toTime(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of time field %s: %v", f.Name, err)
+			}
+			times[i] = t
+		}
+		return data.NewField(f.Name, nil, times), nil
+	case fieldTypeNumber:
+		numbers := make([]*float64, len(values))
+		for i, value := range values {
+			n, err := toNumber(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid value of number field %s: %v", f.Name, err)
+			}
+			numbers[i] = n
+		}
+		return data.NewField(f.Name, nil, numbers), nil
+	case fieldTypeBoolean:
+		bools := make([]*bool, len(values))
+		for i, value := range values {
+			switch v := value.(type) {
+			case nil:
+			case bool:
+				bools[i] = &v
+			default:
+				return nil, fmt.Errorf("invalid value of boolean field %s: %v", f.Name, value)
+			}
+		}
+		return data.NewField(f.Name, nil, bools), nil
+	}
+
+	texts := make([]*string, len(values))
+	for i, value := range values {
+		texts[i] = toString(value)
+	}
+	return data.NewField(f.Name, nil, texts), nil
+}
+
+// valuesType returns the type of a field without type, which is number or boolean if all values have
+// that type and string otherwise.
+func valuesType(values []interface{}) string {
+	allNumbers, allBools := true, true
+	for _, value := range values {
+		switch value.(type) {
+		case nil:
+		case float64:
+			allBools = false
+		case bool:
+			allNumbers = false
+		default:
+			allNumbers, allBools = false, false
+		}
+	}
+
+	switch {
+	case allNumbers:
+		return fieldTypeNumber
+	case allBools:
+		return fieldTypeBoolean
+	}
+	return fieldTypeString
+}
+
+// toTime converts a number or string with an epoch in seconds, milliseconds or nanoseconds,
+// or a RFC3339 date, to a time.
+func toTime(value interface{}) (*time.Time, error) {
+	var epoch float64
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		epoch = v
+	case string:
+		if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+			t = t.UTC()
+			return &t, nil
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is neither a RFC3339 date nor an epoch", v)
+		}
+		epoch = f
+	default:
+		return nil, fmt.Errorf("%v is neither a RFC3339 date nor an epoch", value)
+	}
+
+	ms := tsdb.EpochPrecisionToMs(epoch)
+	t := time.Unix(0, int64(ms*float64(time.Millisecond))).UTC()
+	return &t, nil
+}
+
+func toNumber(value interface{}) (*float64, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case float64:
+		return &v, nil
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", v)
+		}
+		return &f, nil
+	}
+	return nil, fmt.Errorf("%v is not a number", value)
+}
+
+// toString returns strings as is and other values as JSON
+func toString(value interface{}) *string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return &v
+	}
+
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil
+	}
+	s := string(encoded)
+	return &s
+}
+
+// sortByTime sorts the rows of the frame ascending by the time field, with null times last
+func sortByTime(frame *data.Frame, timeIndex int) {
+	timeField := frame.Fields[timeIndex]
+	rows := make([]int, timeField.Len())
+	for i := range rows {
+		rows[i] = i
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		ti := timeField.At(rows[i]).(*time.Time)
+		tj := timeField.At(rows[j]).(*time.Time)
+		if ti == nil || tj == nil {
+			return tj == nil && ti != nil
+		}
+		return ti.Before(*tj)
+	})
+
+	for i, field := range frame.Fields {
+		sorted := data.NewFieldFromFieldType(field.Type(), field.Len())
+		sorted.Name, sorted.Labels, sorted.Config = field.Name, field.Labels, field.Config
+		for to, from := range rows {
+			sorted.Set(to, field.At(from))
+		}
+		frame.Fields[i] = sorted
+	}
+}
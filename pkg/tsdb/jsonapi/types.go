@@ -0,0 +1,99 @@
+package jsonapi
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+)
+
+const (
+	// Types of the fields extracted from responses. Fields without type get
+	// the type of their values.
+	fieldTypeAuto    = ""
+	fieldTypeTime    = "time"
+	fieldTypeNumber  = "number"
+	fieldTypeString  = "string"
+	fieldTypeBoolean = "boolean"
+)
+
+var methods = map[string]bool{"GET": true, "POST": true}
+
+type keyValue struct {
+	Key   string
+	Value string
+}
+
+type jsonAPIField struct {
+	Name     string
+	JSONPath string
+	Type     string
+	path     jsonPath
+}
+
+type jsonAPIQuery struct {
+	RefID   string
+	Method  string
+	Path    string
+	Params  []keyValue
+	Headers []keyValue
+	Body    string
+	Fields  []jsonAPIField
+}
+
+func parseQuery(refID string, model *simplejson.Json) (*jsonAPIQuery, error) {
+	query := &jsonAPIQuery{
+		RefID:  refID,
+		Method: strings.ToUpper(model.Get("method").MustString("GET")),
+		Path:   model.Get("path").MustString(),
+		Body:   model.Get("body").MustString(),
+	}
+
+	if !methods[query.Method] {
+		return nil, fmt.Errorf("unsupported method %s, must be GET or POST", query.Method)
+	}
+
+	query.Params = parseKeyValues(model.Get("params"))
+	query.Headers = parseKeyValues(model.Get("headers"))
+
+	for i := range model.Get("fields").MustArray() {
+		f := model.Get("fields").GetIndex(i)
+		field := jsonAPIField{
+			Name:     f.Get("name").MustString(),
+			JSONPath: f.Get("jsonPath").MustString(),
+			Type:     f.Get("type").MustString(fieldTypeAuto),
+		}
+
+		switch field.Type {
+		case fieldTypeAuto, fieldTypeTime, fieldTypeNumber, fieldTypeString, fieldTypeBoolean:
+		default:
+			return nil, fmt.Errorf("unsupported type %s of field %s", field.Type, field.JSONPath)
+		}
+
+		path, err := parseJSONPath(field.JSONPath)
+		if err != nil {
+			return nil, err
+		}
+		field.path = path
+
+		if field.Name == "" {
+			field.Name = field.JSONPath
+		}
+		query.Fields = append(query.Fields, field)
+	}
+
+	return query, nil
+}
+
+func parseKeyValues(list *simplejson.Json) []keyValue {
+	values := []keyValue{}
+	for i := range list.MustArray() {
+		item := list.GetIndex(i)
+		key := item.Get("key").MustString()
+		if key == "" {
+			continue
+		}
+		values = append(values, keyValue{Key: key, Value: item.Get("value").MustString()})
+	}
+	return values
+}
@@ -0,0 +1,339 @@
+package loki
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/common/model"
+	"golang.org/x/net/context/ctxhttp"
+
+	"github.com/grafana/grafana/pkg/components/null"
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/setting"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+const (
+	// Loki drops queries that might return more data points
+	maxDataPoints = 11000
+	// defaultLimit is the maximum number of log lines of stream queries
+	defaultLimit = 1000
+	// maxResponseSize is the maximum size of a Loki response that is read
+	maxResponseSize = 50 * 1024 * 1024
+)
+
+type LokiExecutor struct {
+	intervalCalculator tsdb.IntervalCalculator
+}
+
+func NewLokiExecutor(dsInfo *models.DataSource) (tsdb.TsdbQueryEndpoint, error) {
+	return &LokiExecutor{
+		intervalCalculator: tsdb.NewIntervalCalculator(&tsdb.IntervalOptions{MinInterval: time.Second}),
+	}, nil
+}
+
+var (
+	plog         log.Logger
+	legendFormat = regexp.MustCompile(`\{\{\s*(.+?)\s*\}\}`)
+)
+
+func init() {
+	plog = log.New("tsdb.loki")
+	tsdb.RegisterTsdbQueryEndpoint(models.DS_LOKI, NewLokiExecutor)
+}
+
+func (e *LokiExecutor) Query(ctx context.Context, dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) (*tsdb.Response, error) {
+	result := &tsdb.Response{
+		Results: map[string]*tsdb.QueryResult{},
+	}
+
+	queries, err := e.parseQuery(dsInfo, tsdbQuery)
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err := dsInfo.GetHttpClient()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, query := range queries {
+		queryResult, err := e.executeQuery(ctx, httpClient, dsInfo, query)
+		if err != nil {
+			queryResult = tsdb.NewQueryResult()
+			queryResult.Error = err
+			queryResult.ErrorString = err.Error()
+		}
+		queryResult.RefId = query.RefID
+		result.Results[query.RefID] = queryResult
+	}
+
+	return result, nil
+}
+
+func (e *LokiExecutor) parseQuery(dsInfo *models.DataSource, tsdbQuery *tsdb.TsdbQuery) ([]*lokiQuery, error) {
+	queries := []*lokiQuery{}
+
+	start, err := tsdbQuery.TimeRange.ParseFrom()
+	if err != nil {
+		return nil, err
+	}
+
+	end, err := tsdbQuery.TimeRange.ParseTo()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, query := range tsdbQuery.Queries {
+		expr, err := query.Model.Get("expr").String()
+		if err != nil {
+			return nil, err
+		}
+
+		dsInterval, err := tsdb.GetIntervalFrom(dsInfo, query.Model, time.Second)
+		if err != nil {
+			return nil, err
+		}
+
+		interval := e.intervalCalculator.Calculate(tsdbQuery.TimeRange, dsInterval)
+		step := time.Duration(int64(interval.Value) * query.Model.Get("intervalFactor").MustInt64(1))
+		if rangeStep := end.Sub(start) / maxDataPoints; step < rangeStep {
+			step = rangeStep
+		}
+		if step < time.Second {
+			step = time.Second
+		}
+
+		limit := int64(defaultLimit)
+		if dsInfo.JsonData != nil {
+			limit = parseMaxLines(dsInfo.JsonData.Get("maxLines"), limit)
+		}
+		limit = parseMaxLines(query.Model.Get("maxLines"), limit)
+
+		queries = append(queries, &lokiQuery{
+			Expr:         expr,
+			Step:         step.Round(time.Second),
+			LegendFormat: query.Model.Get("legendFormat").MustString(),
+			Start:        start,
+			End:          end,
+			Limit:        limit,
+			RefID:        query.RefId,
+		})
+	}
+
+	return queries, nil
+}
+
+// parseMaxLines returns the maximum number of log lines, which the data source
+// settings store as a string, or def if it isn't set or invalid.
+func parseMaxLines(value *simplejson.Json, def int64) int64 {
+	if maxLines, err := value.Int64(); err == nil && maxLines > 0 {
+		return maxLines
+	}
+
+	if str, err := value.String(); err == nil {
+		if maxLines, err := strconv.ParseInt(strings.TrimSpace(str), 10, 64); err == nil && maxLines > 0 {
+			return maxLines
+		}
+	}
+
+	return def
+}
+
+func (e *LokiExecutor) executeQuery(ctx context.Context, httpClient *http.Client, dsInfo *models.DataSource, query *lokiQuery) (*tsdb.QueryResult, error) {
+	req, err := e.createRequest(dsInfo, query)
+	if err != nil {
+		return nil, err
+	}
+
+	if setting.Env == setting.DEV {
+		plog.Debug("Loki request", "url", req.URL.String())
+	}
+
+	res, err := ctxhttp.Do(ctx, httpClient, req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > maxResponseSize {
+		return nil, fmt.Errorf("Loki response exceeds the maximum size of %d bytes", maxResponseSize)
+	}
+
+	if res.StatusCode/100 != 2 {
+		plog.Debug("Loki request failed", "status", res.Status, "body", string(body))
+		return nil, fmt.Errorf("Loki request failed, status: %s, error: %s", res.Status, strings.TrimSpace(string(body)))
+	}
+
+	var response lokiResponse
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, fmt.Errorf("failed to parse Loki response: %v", err)
+	}
+
+	if response.Status == "error" {
+		return nil, fmt.Errorf("Loki query failed: %s", response.Error)
+	}
+
+	return parseResponse(&response.Data, query)
+}
+
+func (e *LokiExecutor) createRequest(dsInfo *models.DataSource, query *lokiQuery) (*http.Request, error) {
+	u, err := url.Parse(dsInfo.Url)
+	if err != nil {
+		return nil, err
+	}
+	u.Path = path.Join(u.Path, "loki/api/v1/query_range")
+
+	params := url.Values{}
+	params.Set("query", query.Expr)
+	params.Set("start", strconv.FormatInt(query.Start.UnixNano(), 10))
+	params.Set("end", strconv.FormatInt(query.End.UnixNano(), 10))
+	params.Set("step", strconv.FormatFloat(query.Step.Seconds(), 'f', -1, 64))
+	params.Set("limit", strconv.FormatInt(query.Limit, 10))
+	params.Set("direction", "backward")
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("User-Agent", "Grafana/"+setting.BuildVersion)
+	if dsInfo.BasicAuth {
+		req.SetBasicAuth(dsInfo.BasicAuthUser, dsInfo.DecryptedBasicAuthPassword())
+	}
+
+	return req, nil
+}
+
+func parseResponse(data *lokiData, query *lokiQuery) (*tsdb.QueryResult, error) {
+	queryResult := tsdb.NewQueryResult()
+
+	var matrix model.Matrix
+	switch data.ResultType {
+	case matrixResultType:
+		if err := json.Unmarshal(data.Result, &matrix); err != nil {
+			return nil, err
+		}
+	case vectorResultType:
+		var vector model.Vector
+		if err := json.Unmarshal(data.Result, &vector); err != nil {
+			return nil, err
+		}
+		for _, sample := range vector {
+			matrix = append(matrix, &model.SampleStream{
+				Metric: sample.Metric,
+				Values: []model.SamplePair{{Timestamp: sample.Timestamp, Value: sample.Value}},
+			})
+		}
+	case streamsResultType:
+		var streams []lokiStream
+		if err := json.Unmarshal(data.Result, &streams); err != nil {
+			return nil, err
+		}
+		var err error
+		if matrix, err = streamsToMatrix(streams, query); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported result type %s", data.ResultType)
+	}
+
+	for _, v := range matrix {
+		series := tsdb.TimeSeries{
+			Name:   formatLegend(v.Metric, query),
+			Tags:   make(map[string]string, len(v.Metric)),
+			Points: make([]tsdb.TimePoint, 0, len(v.Values)),
+		}
+
+		for k, v := range v.Metric {
+			series.Tags[string(k)] = string(v)
+		}
+
+		for _, k := range v.Values {
+			series.Points = append(series.Points, tsdb.NewTimePoint(null.FloatFrom(float64(k.Value)), float64(k.Timestamp)))
+		}
+
+		queryResult.Series = append(queryResult.Series, &series)
+	}
+
+	return queryResult, nil
+}
+
+// streamsToMatrix converts log streams to series with the number of log lines
+// of the streams per step of the query. Loki returns at most the limit of the query
+// of log lines, so the counts are incomplete if the limit is reached.
+func streamsToMatrix(streams []lokiStream, query *lokiQuery) (model.Matrix, error) {
+	matrix := model.Matrix{}
+	step := query.Step.Nanoseconds()
+	start := query.Start.UnixNano()
+	lines := 0
+
+	for _, stream := range streams {
+		counts := map[int64]int{}
+		for _, entry := range stream.Values {
+			ts, err := strconv.ParseInt(entry[0], 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid timestamp %q of log line", entry[0])
+			}
+			counts[start+(ts-start)/step*step]++
+		}
+		lines += len(stream.Values)
+
+		buckets := make([]int64, 0, len(counts))
+		for bucket := range counts {
+			buckets = append(buckets, bucket)
+		}
+		sort.Slice(buckets, func(i, j int) bool { return buckets[i] < buckets[j] })
+
+		values := make([]model.SamplePair, 0, len(buckets))
+		for _, bucket := range buckets {
+			values = append(values, model.SamplePair{
+				Timestamp: model.TimeFromUnixNano(bucket),
+				Value:     model.SampleValue(counts[bucket]),
+			})
+		}
+		matrix = append(matrix, &model.SampleStream{Metric: model.Metric(stream.Stream), Values: values})
+	}
+
+	if int64(lines) >= query.Limit {
+		plog.Warn("Loki log query reached the maximum number of lines, the line counts are incomplete", "expr", query.Expr, "limit", query.Limit)
+	}
+
+	return matrix, nil
+}
+
+func formatLegend(metric model.Metric, query *lokiQuery) string {
+	if query.LegendFormat == "" {
+		return metric.String()
+	}
+
+	result := legendFormat.ReplaceAllFunc([]byte(query.LegendFormat), func(in []byte) []byte {
+		labelName := strings.Replace(string(in), "{{", "", 1)
+		labelName = strings.Replace(labelName, "}}", "", 1)
+		labelName = strings.TrimSpace(labelName)
+		if val, exists := metric[model.LabelName(labelName)]; exists {
+			return []byte(val)
+		}
+		return []byte{}
+	})
+
+	return string(result)
+}
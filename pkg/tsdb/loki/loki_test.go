@@ -0,0 +1,162 @@
+package loki
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/models"
+	"github.com/grafana/grafana/pkg/tsdb"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestLoki(t *testing.T) {
+	Convey("Loki", t, func() {
+		var request *http.Request
+		responseStatus := http.StatusOK
+		responseBody := ""
+
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			request = r
+			w.WriteHeader(responseStatus)
+			_, _ = w.Write([]byte(responseBody))
+		}))
+		defer server.Close()
+
+		dsInfo := &models.DataSource{
+			Id:       1,
+			Url:      server.URL,
+			JsonData: simplejson.NewFromAny(map[string]interface{}{"maxLines": 500}),
+		}
+
+		from := time.Date(2020, 4, 1, 10, 0, 0, 0, time.UTC)
+		to := from.Add(time.Hour)
+		timeRange := tsdb.NewTimeRange(fmt.Sprint(from.Unix()*1000), fmt.Sprint(to.Unix()*1000))
+
+		executor, err := NewLokiExecutor(dsInfo)
+		So(err, ShouldBeNil)
+
+		query := func(model map[string]interface{}) *tsdb.QueryResult {
+			res, err := executor.Query(context.Background(), dsInfo, &tsdb.TsdbQuery{
+				TimeRange: timeRange,
+				Queries:   []*tsdb.Query{{RefId: "A", Model: simplejson.NewFromAny(model)}},
+			})
+			So(err, ShouldBeNil)
+			So(res.Results["A"].RefId, ShouldEqual, "A")
+			return res.Results["A"]
+		}
+
+		Convey("should request the range of the query with the step of the interval", func() {
+			responseBody = `{"status": "success", "data": {"resultType": "matrix", "result": []}}`
+
+			result := query(map[string]interface{}{"expr": `rate({app="x"} |= "error"[5m])`, "interval": "30s"})
+			So(result.Error, ShouldBeNil)
+
+			So(request.URL.Path, ShouldEqual, "/loki/api/v1/query_range")
+			params := request.URL.Query()
+			So(params.Get("query"), ShouldEqual, `rate({app="x"} |= "error"[5m])`)
+			So(params.Get("start"), ShouldEqual, fmt.Sprint(from.UnixNano()))
+			So(params.Get("end"), ShouldEqual, fmt.Sprint(to.UnixNano()))
+			So(params.Get("step"), ShouldEqual, "30")
+			So(params.Get("limit"), ShouldEqual, "500")
+		})
+
+		Convey("should increase the step with the time range", func() {
+			responseBody = `{"status": "success", "data": {"resultType": "matrix", "result": []}}`
+			timeRange = tsdb.NewTimeRange(fmt.Sprint(from.Add(-30*24*time.Hour).Unix()*1000), fmt.Sprint(to.Unix()*1000))
+
+			result := query(map[string]interface{}{"expr": `rate({app="x"}[5m])`, "interval": "1s"})
+			So(result.Error, ShouldBeNil)
+			So(request.URL.Query().Get("step"), ShouldEqual, "1800")
+		})
+
+		Convey("should convert matrix results to series named by the legend format", func() {
+			responseBody = `{
+				"status": "success",
+				"data": {
+					"resultType": "matrix",
+					"result": [
+						{"metric": {"app": "x", "level": "error"}, "values": [[1585735200, "1.5"], [1585735230.5, "2"]]},
+						{"metric": {"app": "y", "level": "error"}, "values": [[1585735200, "3"]]}
+					]
+				}
+			}`
+
+			result := query(map[string]interface{}{"expr": `rate({level="error"}[5m])`, "legendFormat": "{{app}} errors"})
+			So(result.Error, ShouldBeNil)
+			So(result.Series, ShouldHaveLength, 2)
+
+			series := result.Series[0]
+			So(series.Name, ShouldEqual, "x errors")
+			So(series.Tags, ShouldResemble, map[string]string{"app": "x", "level": "error"})
+			So(series.Points, ShouldHaveLength, 2)
+			So(series.Points[0][0].Float64, ShouldEqual, 1.5)
+			So(series.Points[0][1].Float64, ShouldEqual, 1585735200000)
+			So(series.Points[1][1].Float64, ShouldEqual, 1585735230500)
+		})
+
+		Convey("should name series by their labels without legend format", func() {
+			responseBody = `{"status": "success", "data": {"resultType": "vector", "result": [
+				{"metric": {"app": "x"}, "value": [1585735200, "4"]}
+			]}}`
+
+			result := query(map[string]interface{}{"expr": `count_over_time({app="x"}[5m])`})
+			So(result.Error, ShouldBeNil)
+			So(result.Series, ShouldHaveLength, 1)
+			So(result.Series[0].Name, ShouldEqual, `{app="x"}`)
+			So(result.Series[0].Points[0][0].Float64, ShouldEqual, 4)
+		})
+
+		Convey("should convert streams to the number of log lines per step", func() {
+			start := from.UnixNano()
+			responseBody = fmt.Sprintf(`{"status": "success", "data": {"resultType": "streams", "result": [
+				{"stream": {"app": "x", "level": "error"}, "values": [["%d", "line 3"], ["%d", "line 2"], ["%d", "line 1"]]},
+				{"stream": {"app": "x", "level": "info"}, "values": [["%d", "line 1"]]}
+			]}}`, start+int64(70*time.Second), start+int64(5*time.Second), start, start+int64(2*time.Minute))
+
+			result := query(map[string]interface{}{"expr": `{app="x"}`, "interval": "1m", "legendFormat": "{{level}}"})
+			So(result.Error, ShouldBeNil)
+			So(result.Series, ShouldHaveLength, 2)
+			So(result.Series[0].Name, ShouldEqual, "error")
+			So(result.Series[0].Tags, ShouldResemble, map[string]string{"app": "x", "level": "error"})
+
+			points := result.Series[0].Points
+			So(points, ShouldHaveLength, 2)
+			So(points[0][0].Float64, ShouldEqual, 2)
+			So(points[0][1].Float64, ShouldEqual, from.Unix()*1000)
+			So(points[1][0].Float64, ShouldEqual, 1)
+			So(points[1][1].Float64, ShouldEqual, from.Add(time.Minute).Unix()*1000)
+
+			So(result.Series[1].Name, ShouldEqual, "info")
+			So(result.Series[1].Points, ShouldHaveLength, 1)
+			So(result.Series[1].Points[0][1].Float64, ShouldEqual, from.Add(2*time.Minute).Unix()*1000)
+		})
+
+		Convey("should use the maximum lines of the data source stored as string", func() {
+			responseBody = `{"status": "success", "data": {"resultType": "matrix", "result": []}}`
+			dsInfo.JsonData = simplejson.NewFromAny(map[string]interface{}{"maxLines": "200"})
+
+			result := query(map[string]interface{}{"expr": `rate({app="x"}[5m])`})
+			So(result.Error, ShouldBeNil)
+			So(request.URL.Query().Get("limit"), ShouldEqual, "200")
+
+			result = query(map[string]interface{}{"expr": `rate({app="x"}[5m])`, "maxLines": "20"})
+			So(result.Error, ShouldBeNil)
+			So(request.URL.Query().Get("limit"), ShouldEqual, "20")
+		})
+
+		Convey("should return errors of failed queries", func() {
+			responseStatus = http.StatusBadRequest
+			responseBody = "parse error : syntax error: unexpected IDENTIFIER\n"
+
+			result := query(map[string]interface{}{"expr": `rate(app[5m])`})
+			So(result.Error, ShouldNotBeNil)
+			So(result.Error.Error(), ShouldEqual, "Loki request failed, status: 400 Bad Request, error: parse error : syntax error: unexpected IDENTIFIER")
+		})
+	})
+}
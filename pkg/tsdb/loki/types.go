@@ -0,0 +1,44 @@
+package loki
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/prometheus/common/model"
+)
+
+const (
+	// Types of the results of Loki queries
+	matrixResultType  = "matrix"
+	vectorResultType  = "vector"
+	streamsResultType = "streams"
+)
+
+type lokiQuery struct {
+	Expr         string
+	Step         time.Duration
+	LegendFormat string
+	Start        time.Time
+	End          time.Time
+	Limit        int64
+	RefID        string
+}
+
+type lokiResponse struct {
+	Status    string   `json:"status"`
+	Data      lokiData `json:"data"`
+	ErrorType string   `json:"errorType"`
+	Error     string   `json:"error"`
+}
+
+type lokiData struct {
+	ResultType string          `json:"resultType"`
+	Result     json.RawMessage `json:"result"`
+}
+
+// lokiStream is a stream of log lines with the same labels, the values are pairs of
+// timestamps in nanoseconds and log lines.
+type lokiStream struct {
+	Stream model.LabelSet `json:"stream"`
+	Values [][2]string    `json:"values"`
+}
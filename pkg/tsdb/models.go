@@ -13,6 +13,8 @@ type TsdbQuery struct {
 	Headers   map[string]string
 	Debug     bool
 	User      *models.SignedInUser
+	// NoCache makes the query cache ignore cached results
+	NoCache bool
 }
 
 type Query struct {
@@ -37,6 +39,8 @@ type QueryResult struct {
 	Series      TimeSeriesSlice  `json:"series"`
 	Tables      []*Table         `json:"tables"`
 	Dataframes  [][]byte         `json:"dataframes"`
+	// Cached is true if the result has been read from the query cache
+	Cached bool `json:"-"`
 }
 
 type TimeSeries struct {
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
@@ -134,7 +135,7 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(err, ShouldBeNil)
 
-				column := queryResult.Tables[0].Rows[0]
+				column := frameTable(queryResult).Rows[0]
 
 				So(column[0].(bool), ShouldEqual, true)
 
@@ -163,7 +164,7 @@ func TestMSSQL(t *testing.T) {
 				So(column[19].(time.Time), ShouldEqual, dt.Truncate(time.Minute))
 				So(column[20].(time.Time), ShouldEqual, dt.Truncate(24*time.Hour))
 				So(column[21].(time.Time), ShouldEqual, time.Date(1, 1, 1, dt.Hour(), dt.Minute(), dt.Second(), dt.Nanosecond(), time.UTC))
-				So(column[22].(time.Time), ShouldEqual, dt2.In(time.FixedZone("UTC-7", int(-7*60*60))))
+				So(column[22].(time.Time).Equal(dt2.In(time.FixedZone("UTC-7", int(-7*60*60)))), ShouldBeTrue)
 			})
 		})
 
@@ -225,7 +226,7 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				points := frameSeries(queryResult)[0].Points
 				// without fill this should result in 4 buckets
 				So(len(points), ShouldEqual, 4)
 
@@ -272,7 +273,7 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				points := frameSeries(queryResult)[0].Points
 				So(len(points), ShouldEqual, 7)
 
 				dt := fromStart
@@ -359,7 +360,7 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				points := frameSeries(queryResult)[0].Points
 				So(points[3][0].Float64, ShouldEqual, 1.5)
 			})
 		})
@@ -449,8 +450,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int64 nullable) as time column and value column (int64 nullable) should return metric with time in milliseconds", func() {
@@ -471,8 +472,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float64) as time column and value column (float64) should return metric with time in milliseconds", func() {
@@ -493,8 +494,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float64 nullable) as time column and value column (float64 nullable) should return metric with time in milliseconds", func() {
@@ -515,8 +516,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int32) as time column and value column (int32) should return metric with time in milliseconds", func() {
@@ -537,8 +538,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int32 nullable) as time column and value column (int32 nullable) should return metric with time in milliseconds", func() {
@@ -559,8 +560,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float32) as time column and value column (float32) should return metric with time in milliseconds", func() {
@@ -581,8 +582,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
 			})
 
 			Convey("When doing a metric query using epoch (float32 nullable) as time column and value column (float32 nullable) should return metric with time in milliseconds", func() {
@@ -603,8 +604,8 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
 			})
 
 			Convey("When doing a metric query grouping by time and select metric column should return correct series", func() {
@@ -625,9 +626,9 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 2)
-				So(queryResult.Series[0].Name, ShouldEqual, "Metric A - value one")
-				So(queryResult.Series[1].Name, ShouldEqual, "Metric B - value one")
+				So(len(frameSeries(queryResult)), ShouldEqual, 2)
+				So(frameSeries(queryResult)[0].Name, ShouldEqual, "Metric A - value one")
+				So(frameSeries(queryResult)[1].Name, ShouldEqual, "Metric B - value one")
 			})
 
 			Convey("When doing a metric query grouping by time should return correct series", func() {
@@ -648,9 +649,9 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 2)
-				So(queryResult.Series[0].Name, ShouldEqual, "valueOne")
-				So(queryResult.Series[1].Name, ShouldEqual, "valueTwo")
+				So(len(frameSeries(queryResult)), ShouldEqual, 2)
+				So(frameSeries(queryResult)[0].Name, ShouldEqual, "valueOne")
+				So(frameSeries(queryResult)[1].Name, ShouldEqual, "valueTwo")
 			})
 
 			Convey("When doing a metric query with metric column and multiple value columns", func() {
@@ -671,11 +672,11 @@ func TestMSSQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 4)
-				So(queryResult.Series[0].Name, ShouldEqual, "Metric A valueOne")
-				So(queryResult.Series[1].Name, ShouldEqual, "Metric A valueTwo")
-				So(queryResult.Series[2].Name, ShouldEqual, "Metric B valueOne")
-				So(queryResult.Series[3].Name, ShouldEqual, "Metric B valueTwo")
+				So(len(frameSeries(queryResult)), ShouldEqual, 4)
+				So(frameSeries(queryResult)[0].Name, ShouldEqual, "Metric A valueOne")
+				So(frameSeries(queryResult)[1].Name, ShouldEqual, "Metric A valueTwo")
+				So(frameSeries(queryResult)[2].Name, ShouldEqual, "Metric B valueOne")
+				So(frameSeries(queryResult)[3].Name, ShouldEqual, "Metric B valueTwo")
 			})
 
 			Convey("When doing a query with timeFrom,timeTo,unixEpochFrom,unixEpochTo macros", func() {
@@ -773,11 +774,11 @@ func TestMSSQL(t *testing.T) {
 					So(err, ShouldBeNil)
 					So(queryResult.Error, ShouldBeNil)
 
-					So(len(queryResult.Series), ShouldEqual, 4)
-					So(queryResult.Series[0].Name, ShouldEqual, "Metric A valueOne")
-					So(queryResult.Series[1].Name, ShouldEqual, "Metric A valueTwo")
-					So(queryResult.Series[2].Name, ShouldEqual, "Metric B valueOne")
-					So(queryResult.Series[3].Name, ShouldEqual, "Metric B valueTwo")
+					So(len(frameSeries(queryResult)), ShouldEqual, 4)
+					So(frameSeries(queryResult)[0].Name, ShouldEqual, "Metric A valueOne")
+					So(frameSeries(queryResult)[1].Name, ShouldEqual, "Metric A valueTwo")
+					So(frameSeries(queryResult)[2].Name, ShouldEqual, "Metric B valueOne")
+					So(frameSeries(queryResult)[3].Name, ShouldEqual, "Metric B valueTwo")
 				})
 			})
 
@@ -852,11 +853,11 @@ func TestMSSQL(t *testing.T) {
 					So(err, ShouldBeNil)
 					So(queryResult.Error, ShouldBeNil)
 
-					So(len(queryResult.Series), ShouldEqual, 4)
-					So(queryResult.Series[0].Name, ShouldEqual, "Metric A valueOne")
-					So(queryResult.Series[1].Name, ShouldEqual, "Metric A valueTwo")
-					So(queryResult.Series[2].Name, ShouldEqual, "Metric B valueOne")
-					So(queryResult.Series[3].Name, ShouldEqual, "Metric B valueTwo")
+					So(len(frameSeries(queryResult)), ShouldEqual, 4)
+					So(frameSeries(queryResult)[0].Name, ShouldEqual, "Metric A valueOne")
+					So(frameSeries(queryResult)[1].Name, ShouldEqual, "Metric A valueTwo")
+					So(frameSeries(queryResult)[2].Name, ShouldEqual, "Metric B valueOne")
+					So(frameSeries(queryResult)[3].Name, ShouldEqual, "Metric B valueTwo")
 				})
 			})
 		})
@@ -926,7 +927,7 @@ func TestMSSQL(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Deploys"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query of ticket events should return expected result", func() {
@@ -949,7 +950,7 @@ func TestMSSQL(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Tickets"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query with a time column in datetime format", func() {
@@ -976,11 +977,11 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
-				So(columns[0].(float64), ShouldEqual, float64(dt.UnixNano()/1e6))
+				So(columns[0].(time.Time).UnixNano()/int64(time.Millisecond), ShouldEqual, dt.UnixNano()/1e6)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch second format should return ms", func() {
@@ -1006,11 +1007,11 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(columns[0].(time.Time).UnixNano()/int64(time.Millisecond), ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch second format (int) should return ms", func() {
@@ -1036,11 +1037,11 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(columns[0].(time.Time).UnixNano()/int64(time.Millisecond), ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch millisecond format should return ms", func() {
@@ -1066,11 +1067,11 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
-				So(columns[0].(float64), ShouldEqual, float64(dt.Unix()*1000))
+				So(columns[0].(time.Time).UnixNano()/int64(time.Millisecond), ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column holding a bigint null value should return nil", func() {
@@ -1094,8 +1095,8 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
 				So(columns[0], ShouldBeNil)
@@ -1122,8 +1123,8 @@ func TestMSSQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
 				So(columns[0], ShouldBeNil)
@@ -1158,3 +1159,41 @@ func genTimeRangeByInterval(from time.Time, duration time.Duration, interval tim
 
 	return timeRange
 }
+
+// frameTable returns the values of the frame of a table query as a table
+func frameTable(result *tsdb.QueryResult) *tsdb.Table {
+	So(result.Dataframes, ShouldHaveLength, 1)
+	frame, err := data.UnmarshalArrowFrame(result.Dataframes[0])
+	So(err, ShouldBeNil)
+
+	rowCount, err := frame.RowLen()
+	So(err, ShouldBeNil)
+
+	table := &tsdb.Table{Rows: make([]tsdb.RowValues, rowCount)}
+	for _, field := range frame.Fields {
+		table.Columns = append(table.Columns, tsdb.TableColumn{Text: field.Name})
+	}
+	for i := range table.Rows {
+		table.Rows[i] = make(tsdb.RowValues, len(frame.Fields))
+		for j, field := range frame.Fields {
+			if value, ok := field.ConcreteAt(i); ok {
+				table.Rows[i][j] = value
+			}
+		}
+	}
+	return table
+}
+
+// frameSeries returns the series of the frames of a time series query
+func frameSeries(result *tsdb.QueryResult) tsdb.TimeSeriesSlice {
+	series := tsdb.TimeSeriesSlice{}
+	for _, encoded := range result.Dataframes {
+		frame, err := data.UnmarshalArrowFrame(encoded)
+		So(err, ShouldBeNil)
+
+		frameSeries, err := tsdb.FrameToSeriesSlice(frame)
+		So(err, ShouldBeNil)
+		series = append(series, frameSeries...)
+	}
+	return series
+}
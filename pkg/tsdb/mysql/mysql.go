@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/VividCortex/mysqlerr"
 	"github.com/grafana/grafana/pkg/setting"
@@ -67,6 +68,10 @@ func newMysqlQueryEndpoint(datasource *models.DataSource) (tsdb.TsdbQueryEndpoin
 		Datasource:        datasource,
 		TimeColumnNames:   []string{"time", "time_sec"},
 		MetricColumnTypes: []string{"CHAR", "VARCHAR", "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT"},
+		TimeoutStatement: func(timeout time.Duration) string {
+			return fmt.Sprintf("SET SESSION max_execution_time = %d", timeout.Milliseconds())
+		},
+		ResetTimeoutStatement: "SET SESSION max_execution_time = DEFAULT",
 	}
 
 	rowTransformer := mysqlQueryResultTransformer{
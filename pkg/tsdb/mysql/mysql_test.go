@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
@@ -138,23 +139,23 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				column := queryResult.Tables[0].Rows[0]
+				column := frameTable(queryResult).Rows[0]
 
-				So(*column[0].(*int8), ShouldEqual, 1)
+				So(column[0].(int8), ShouldEqual, 1)
 				So(column[1].(string), ShouldEqual, "abc")
 				So(column[2].(string), ShouldEqual, "def")
-				So(*column[3].(*int32), ShouldEqual, 1)
-				So(*column[4].(*int16), ShouldEqual, 10)
-				So(*column[5].(*int64), ShouldEqual, 100)
-				So(*column[6].(*int32), ShouldEqual, 1420070400)
+				So(column[3].(int32), ShouldEqual, 1)
+				So(column[4].(int16), ShouldEqual, 10)
+				So(column[5].(int64), ShouldEqual, 100)
+				So(column[6].(int32), ShouldEqual, 1420070400)
 				So(column[7].(float64), ShouldEqual, 1.11)
 				So(column[8].(float64), ShouldEqual, 2.22)
-				So(*column[9].(*float32), ShouldEqual, 3.33)
+				So(column[9].(float32), ShouldEqual, 3.33)
 				So(column[10].(time.Time), ShouldHappenWithin, 10*time.Second, time.Now())
 				So(column[11].(time.Time), ShouldHappenWithin, 10*time.Second, time.Now())
 				So(column[12].(string), ShouldEqual, "11:11:11")
 				So(column[13].(int64), ShouldEqual, 2018)
-				So(*column[14].(*[]byte), ShouldHaveSameTypeAs, []byte{1})
+				So(column[14].(string), ShouldEqual, "\x01")
 				So(column[15].(string), ShouldEqual, "tinytext")
 				So(column[16].(string), ShouldEqual, "tinyblob")
 				So(column[17].(string), ShouldEqual, "text")
@@ -166,7 +167,7 @@ func TestMySQL(t *testing.T) {
 				So(column[23].(string), ShouldEqual, "val2")
 				So(column[24].(string), ShouldEqual, "a,b")
 				So(column[25].(time.Time).Format("2006-01-02T00:00:00Z"), ShouldEqual, time.Now().UTC().Format("2006-01-02T00:00:00Z"))
-				So(column[26].(float64), ShouldEqual, float64(1.514764861123456*1e12))
+				So(column[26].(time.Time), ShouldEqual, time.Date(2018, 1, 1, 0, 1, 1, 123456000, time.UTC))
 				So(column[27], ShouldEqual, nil)
 				So(column[28], ShouldEqual, nil)
 				So(column[29], ShouldEqual, "")
@@ -227,7 +228,7 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				points := frameSeries(queryResult)[0].Points
 				// without fill this should result in 4 buckets
 				So(len(points), ShouldEqual, 4)
 
@@ -274,7 +275,7 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				points := frameSeries(queryResult)[0].Points
 				So(len(points), ShouldEqual, 7)
 
 				dt := fromStart
@@ -362,7 +363,7 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				points := frameSeries(queryResult)[0].Points
 				So(points[3][0].Float64, ShouldEqual, 1.5)
 			})
 
@@ -388,7 +389,7 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				points := frameSeries(queryResult)[0].Points
 				So(points[2][0].Float64, ShouldEqual, 15.0)
 				So(points[3][0].Float64, ShouldEqual, 15.0)
 				So(points[6][0].Float64, ShouldEqual, 20.0)
@@ -484,8 +485,8 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using time (nullable) as time column should return metric with time in milliseconds", func() {
@@ -506,8 +507,8 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int64) as time column and value column (int64) should return metric with time in milliseconds", func() {
@@ -528,8 +529,8 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int64 nullable) as time column and value column (int64 nullable) should return metric with time in milliseconds", func() {
@@ -550,8 +551,8 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float64) as time column and value column (float64) should return metric with time in milliseconds", func() {
@@ -572,8 +573,8 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float64 nullable) as time column and value column (float64 nullable) should return metric with time in milliseconds", func() {
@@ -594,8 +595,8 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int32) as time column and value column (int32) should return metric with time in milliseconds", func() {
@@ -616,8 +617,8 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int32 nullable) as time column and value column (int32 nullable) should return metric with time in milliseconds", func() {
@@ -638,8 +639,8 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float32) as time column and value column (float32) should return metric with time in milliseconds", func() {
@@ -660,8 +661,8 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
 			})
 
 			Convey("When doing a metric query using epoch (float32 nullable) as time column and value column (float32 nullable) should return metric with time in milliseconds", func() {
@@ -682,8 +683,8 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
 			})
 
 			Convey("When doing a metric query grouping by time and select metric column should return correct series", func() {
@@ -704,9 +705,9 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 2)
-				So(queryResult.Series[0].Name, ShouldEqual, "Metric A - value one")
-				So(queryResult.Series[1].Name, ShouldEqual, "Metric B - value one")
+				So(len(frameSeries(queryResult)), ShouldEqual, 2)
+				So(frameSeries(queryResult)[0].Name, ShouldEqual, "Metric A - value one")
+				So(frameSeries(queryResult)[1].Name, ShouldEqual, "Metric B - value one")
 			})
 
 			Convey("When doing a metric query with metric column and multiple value columns", func() {
@@ -727,11 +728,11 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 4)
-				So(queryResult.Series[0].Name, ShouldEqual, "Metric A valueOne")
-				So(queryResult.Series[1].Name, ShouldEqual, "Metric A valueTwo")
-				So(queryResult.Series[2].Name, ShouldEqual, "Metric B valueOne")
-				So(queryResult.Series[3].Name, ShouldEqual, "Metric B valueTwo")
+				So(len(frameSeries(queryResult)), ShouldEqual, 4)
+				So(frameSeries(queryResult)[0].Name, ShouldEqual, "Metric A valueOne")
+				So(frameSeries(queryResult)[1].Name, ShouldEqual, "Metric A valueTwo")
+				So(frameSeries(queryResult)[2].Name, ShouldEqual, "Metric B valueOne")
+				So(frameSeries(queryResult)[3].Name, ShouldEqual, "Metric B valueTwo")
 			})
 
 			Convey("When doing a metric query grouping by time should return correct series", func() {
@@ -752,9 +753,9 @@ func TestMySQL(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 2)
-				So(queryResult.Series[0].Name, ShouldEqual, "valueOne")
-				So(queryResult.Series[1].Name, ShouldEqual, "valueTwo")
+				So(len(frameSeries(queryResult)), ShouldEqual, 2)
+				So(frameSeries(queryResult)[0].Name, ShouldEqual, "valueOne")
+				So(frameSeries(queryResult)[1].Name, ShouldEqual, "valueTwo")
 			})
 		})
 
@@ -836,7 +837,7 @@ func TestMySQL(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Deploys"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query of ticket events should return expected result", func() {
@@ -859,7 +860,7 @@ func TestMySQL(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Tickets"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query with a time column in datetime format", func() {
@@ -886,11 +887,11 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
-				So(columns[0].(float64), ShouldEqual, float64(dt.Unix()*1000))
+				So(columns[0].(time.Time).UnixNano()/int64(time.Millisecond), ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch second format should return ms", func() {
@@ -916,11 +917,11 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(columns[0].(time.Time).UnixNano()/int64(time.Millisecond), ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch second format (signed integer) should return ms", func() {
@@ -946,11 +947,11 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(columns[0].(time.Time).UnixNano()/int64(time.Millisecond), ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch millisecond format should return ms", func() {
@@ -976,11 +977,11 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(columns[0].(time.Time).UnixNano()/int64(time.Millisecond), ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column holding a unsigned integer null value should return nil", func() {
@@ -1004,8 +1005,8 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
 				So(columns[0], ShouldBeNil)
@@ -1032,8 +1033,8 @@ func TestMySQL(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
 				So(columns[0], ShouldBeNil)
@@ -1068,3 +1069,41 @@ func genTimeRangeByInterval(from time.Time, duration time.Duration, interval tim
 
 	return timeRange
 }
+
+// frameTable returns the values of the frame of a table query as a table
+func frameTable(result *tsdb.QueryResult) *tsdb.Table {
+	So(result.Dataframes, ShouldHaveLength, 1)
+	frame, err := data.UnmarshalArrowFrame(result.Dataframes[0])
+	So(err, ShouldBeNil)
+
+	rowCount, err := frame.RowLen()
+	So(err, ShouldBeNil)
+
+	table := &tsdb.Table{Rows: make([]tsdb.RowValues, rowCount)}
+	for _, field := range frame.Fields {
+		table.Columns = append(table.Columns, tsdb.TableColumn{Text: field.Name})
+	}
+	for i := range table.Rows {
+		table.Rows[i] = make(tsdb.RowValues, len(frame.Fields))
+		for j, field := range frame.Fields {
+			if value, ok := field.ConcreteAt(i); ok {
+				table.Rows[i][j] = value
+			}
+		}
+	}
+	return table
+}
+
+// frameSeries returns the series of the frames of a time series query
+func frameSeries(result *tsdb.QueryResult) tsdb.TimeSeriesSlice {
+	series := tsdb.TimeSeriesSlice{}
+	for _, encoded := range result.Dataframes {
+		frame, err := data.UnmarshalArrowFrame(encoded)
+		So(err, ShouldBeNil)
+
+		frameSeries, err := tsdb.FrameToSeriesSlice(frame)
+		So(err, ShouldBeNil)
+		series = append(series, frameSeries...)
+	}
+	return series
+}
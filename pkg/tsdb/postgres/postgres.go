@@ -6,6 +6,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/grafana/grafana/pkg/setting"
 
@@ -38,6 +39,10 @@ func newPostgresQueryEndpoint(datasource *models.DataSource) (tsdb.TsdbQueryEndp
 		ConnectionString:  cnnstr,
 		Datasource:        datasource,
 		MetricColumnTypes: []string{"UNKNOWN", "TEXT", "VARCHAR", "CHAR"},
+		TimeoutStatement: func(timeout time.Duration) string {
+			return fmt.Sprintf("SET statement_timeout = %d", timeout.Milliseconds())
+		},
+		ResetTimeoutStatement: "RESET statement_timeout",
 	}
 
 	queryResultTransformer := postgresQueryResultTransformer{
@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/securejsondata"
 	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
@@ -125,7 +126,7 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				column := queryResult.Tables[0].Rows[0]
+				column := frameTable(queryResult).Rows[0]
 				So(column[0].(int64), ShouldEqual, 1)
 				So(column[1].(int64), ShouldEqual, 2)
 				So(column[2].(int64), ShouldEqual, 3)
@@ -205,7 +206,7 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				points := frameSeries(queryResult)[0].Points
 				// without fill this should result in 4 buckets
 				So(len(points), ShouldEqual, 4)
 
@@ -287,7 +288,7 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				points := frameSeries(queryResult)[0].Points
 				So(len(points), ShouldEqual, 7)
 
 				dt := fromStart
@@ -341,7 +342,7 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				points := queryResult.Series[0].Points
+				points := frameSeries(queryResult)[0].Points
 				So(points[3][0].Float64, ShouldEqual, 1.5)
 			})
 		})
@@ -368,7 +369,7 @@ func TestPostgres(t *testing.T) {
 			queryResult := resp.Results["A"]
 			So(queryResult.Error, ShouldBeNil)
 
-			points := queryResult.Series[0].Points
+			points := frameSeries(queryResult)[0].Points
 			So(points[2][0].Float64, ShouldEqual, 15.0)
 			So(points[3][0].Float64, ShouldEqual, 15.0)
 			So(points[6][0].Float64, ShouldEqual, 20.0)
@@ -459,8 +460,8 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int64 nullable) as time column and value column (int64 nullable) should return metric with time in milliseconds", func() {
@@ -481,8 +482,8 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float64) as time column and value column (float64) should return metric with time in milliseconds", func() {
@@ -503,8 +504,8 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float64 nullable) as time column and value column (float64 nullable) should return metric with time in milliseconds", func() {
@@ -525,8 +526,8 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int32) as time column and value column (int32) should return metric with time in milliseconds", func() {
@@ -547,8 +548,8 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (int32 nullable) as time column and value column (int32 nullable) should return metric with time in milliseconds", func() {
@@ -569,8 +570,8 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(tInitial.UnixNano()/1e6))
 			})
 
 			Convey("When doing a metric query using epoch (float32) as time column and value column (float32) should return metric with time in milliseconds", func() {
@@ -591,8 +592,8 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
 			})
 
 			Convey("When doing a metric query using epoch (float32 nullable) as time column and value column (float32 nullable) should return metric with time in milliseconds", func() {
@@ -613,8 +614,8 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 1)
-				So(queryResult.Series[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
+				So(len(frameSeries(queryResult)), ShouldEqual, 1)
+				So(frameSeries(queryResult)[0].Points[0][1].Float64, ShouldEqual, float64(float32(tInitial.Unix()))*1e3)
 			})
 
 			Convey("When doing a metric query grouping by time and select metric column should return correct series", func() {
@@ -635,9 +636,9 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 2)
-				So(queryResult.Series[0].Name, ShouldEqual, "Metric A - value one")
-				So(queryResult.Series[1].Name, ShouldEqual, "Metric B - value one")
+				So(len(frameSeries(queryResult)), ShouldEqual, 2)
+				So(frameSeries(queryResult)[0].Name, ShouldEqual, "Metric A - value one")
+				So(frameSeries(queryResult)[1].Name, ShouldEqual, "Metric B - value one")
 			})
 
 			Convey("When doing a metric query with metric column and multiple value columns", func() {
@@ -658,11 +659,11 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 4)
-				So(queryResult.Series[0].Name, ShouldEqual, "Metric A valueOne")
-				So(queryResult.Series[1].Name, ShouldEqual, "Metric A valueTwo")
-				So(queryResult.Series[2].Name, ShouldEqual, "Metric B valueOne")
-				So(queryResult.Series[3].Name, ShouldEqual, "Metric B valueTwo")
+				So(len(frameSeries(queryResult)), ShouldEqual, 4)
+				So(frameSeries(queryResult)[0].Name, ShouldEqual, "Metric A valueOne")
+				So(frameSeries(queryResult)[1].Name, ShouldEqual, "Metric A valueTwo")
+				So(frameSeries(queryResult)[2].Name, ShouldEqual, "Metric B valueOne")
+				So(frameSeries(queryResult)[3].Name, ShouldEqual, "Metric B valueTwo")
 			})
 
 			Convey("When doing a metric query grouping by time should return correct series", func() {
@@ -683,9 +684,9 @@ func TestPostgres(t *testing.T) {
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
 
-				So(len(queryResult.Series), ShouldEqual, 2)
-				So(queryResult.Series[0].Name, ShouldEqual, "valueOne")
-				So(queryResult.Series[1].Name, ShouldEqual, "valueTwo")
+				So(len(frameSeries(queryResult)), ShouldEqual, 2)
+				So(frameSeries(queryResult)[0].Name, ShouldEqual, "valueOne")
+				So(frameSeries(queryResult)[1].Name, ShouldEqual, "valueTwo")
 			})
 
 			Convey("When doing a query with timeFrom,timeTo,unixEpochFrom,unixEpochTo macros", func() {
@@ -767,7 +768,7 @@ func TestPostgres(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Deploys"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query of ticket events should return expected result", func() {
@@ -790,7 +791,7 @@ func TestPostgres(t *testing.T) {
 				resp, err := endpoint.Query(context.Background(), nil, query)
 				queryResult := resp.Results["Tickets"]
 				So(err, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 3)
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 3)
 			})
 
 			Convey("When doing an annotation query with a time column in datetime format", func() {
@@ -817,11 +818,11 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
-				So(columns[0].(float64), ShouldEqual, float64(dt.UnixNano()/1e6))
+				So(columns[0].(time.Time).UnixNano()/int64(time.Millisecond), ShouldEqual, dt.UnixNano()/1e6)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch second format should return ms", func() {
@@ -847,11 +848,11 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(columns[0].(time.Time).UnixNano()/int64(time.Millisecond), ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch second format (int) should return ms", func() {
@@ -877,11 +878,11 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(columns[0].(time.Time).UnixNano()/int64(time.Millisecond), ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column in epoch millisecond format should return ms", func() {
@@ -907,11 +908,11 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
-				So(columns[0].(int64), ShouldEqual, dt.Unix()*1000)
+				So(columns[0].(time.Time).UnixNano()/int64(time.Millisecond), ShouldEqual, dt.Unix()*1000)
 			})
 
 			Convey("When doing an annotation query with a time column holding a bigint null value should return nil", func() {
@@ -935,8 +936,8 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
 				So(columns[0], ShouldBeNil)
@@ -963,8 +964,8 @@ func TestPostgres(t *testing.T) {
 				So(err, ShouldBeNil)
 				queryResult := resp.Results["A"]
 				So(queryResult.Error, ShouldBeNil)
-				So(len(queryResult.Tables[0].Rows), ShouldEqual, 1)
-				columns := queryResult.Tables[0].Rows[0]
+				So(len(frameTable(queryResult).Rows), ShouldEqual, 1)
+				columns := frameTable(queryResult).Rows[0]
 
 				//Should be in milliseconds
 				So(columns[0], ShouldBeNil)
@@ -999,3 +1000,41 @@ func genTimeRangeByInterval(from time.Time, duration time.Duration, interval tim
 
 	return timeRange
 }
+
+// frameTable returns the values of the frame of a table query as a table
+func frameTable(result *tsdb.QueryResult) *tsdb.Table {
+	So(result.Dataframes, ShouldHaveLength, 1)
+	frame, err := data.UnmarshalArrowFrame(result.Dataframes[0])
+	So(err, ShouldBeNil)
+
+	rowCount, err := frame.RowLen()
+	So(err, ShouldBeNil)
+
+	table := &tsdb.Table{Rows: make([]tsdb.RowValues, rowCount)}
+	for _, field := range frame.Fields {
+		table.Columns = append(table.Columns, tsdb.TableColumn{Text: field.Name})
+	}
+	for i := range table.Rows {
+		table.Rows[i] = make(tsdb.RowValues, len(frame.Fields))
+		for j, field := range frame.Fields {
+			if value, ok := field.ConcreteAt(i); ok {
+				table.Rows[i][j] = value
+			}
+		}
+	}
+	return table
+}
+
+// frameSeries returns the series of the frames of a time series query
+func frameSeries(result *tsdb.QueryResult) tsdb.TimeSeriesSlice {
+	series := tsdb.TimeSeriesSlice{}
+	for _, encoded := range result.Dataframes {
+		frame, err := data.UnmarshalArrowFrame(encoded)
+		So(err, ShouldBeNil)
+
+		frameSeries, err := tsdb.FrameToSeriesSlice(frame)
+		So(err, ShouldBeNil)
+		series = append(series, frameSeries...)
+	}
+	return series
+}
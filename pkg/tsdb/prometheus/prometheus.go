@@ -4,13 +4,17 @@ import (
 	"context"
 	"fmt"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/opentracing/opentracing-go"
 
 	"net/http"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
@@ -97,7 +101,57 @@ func (e *PrometheusExecutor) Query(ctx context.Context, dsInfo *models.DataSourc
 		return nil, err
 	}
 
+	// queries are executed concurrently and a failing query only sets the error of its own result
+	var mu sync.Mutex
+	var wg sync.WaitGroup
 	for _, query := range queries {
+		wg.Add(1)
+		go func(query *PrometheusQuery) {
+			defer wg.Done()
+
+			queryResult, err := e.runQuery(ctx, client, query)
+			if err != nil {
+				queryResult = &tsdb.QueryResult{Error: err, ErrorString: err.Error()}
+			}
+			queryResult.RefId = query.RefId
+
+			mu.Lock()
+			result.Results[query.RefId] = queryResult
+			mu.Unlock()
+		}(query)
+	}
+	wg.Wait()
+
+	return result, nil
+}
+
+// runQuery executes the query and returns panics as errors, as the query goroutine isn't
+// covered by the recovery of the request.
+func (e *PrometheusExecutor) runQuery(ctx context.Context, client apiv1.API, query *PrometheusQuery) (queryResult *tsdb.QueryResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			plog.Error("Query panic", "refId", query.RefId, "error", r, "stack", log.Stack(1))
+			queryResult, err = nil, fmt.Errorf("query panic: %v", r)
+		}
+	}()
+
+	return e.executeQuery(ctx, client, query)
+}
+
+func (e *PrometheusExecutor) executeQuery(ctx context.Context, client apiv1.API, query *PrometheusQuery) (*tsdb.QueryResult, error) {
+	span, ctx := opentracing.StartSpanFromContext(ctx, "alerting.prometheus")
+	span.SetTag("expr", query.Expr)
+	span.SetTag("start_unixnano", query.Start.UnixNano())
+	span.SetTag("stop_unixnano", query.End.UnixNano())
+	span.SetTag("instant", query.Instant)
+	defer span.Finish()
+
+	var value model.Value
+	var err error
+	if query.Instant {
+		plog.Debug("Sending instant query", "time", query.End, "query", query.Expr)
+		value, _, err = client.Query(ctx, query.Expr, query.End)
+	} else {
 		timeRange := apiv1.Range{
 			Start: query.Start,
 			End:   query.End,
@@ -105,27 +159,13 @@ func (e *PrometheusExecutor) Query(ctx context.Context, dsInfo *models.DataSourc
 		}
 
 		plog.Debug("Sending query", "start", timeRange.Start, "end", timeRange.End, "step", timeRange.Step, "query", query.Expr)
-
-		span, ctx := opentracing.StartSpanFromContext(ctx, "alerting.prometheus")
-		span.SetTag("expr", query.Expr)
-		span.SetTag("start_unixnano", query.Start.UnixNano())
-		span.SetTag("stop_unixnano", query.End.UnixNano())
-		defer span.Finish()
-
-		value, _, err := client.QueryRange(ctx, query.Expr, timeRange)
-
-		if err != nil {
-			return nil, err
-		}
-
-		queryResult, err := parseResponse(value, query)
-		if err != nil {
-			return nil, err
-		}
-		result.Results[query.RefId] = queryResult
+		value, _, err = client.QueryRange(ctx, query.Expr, timeRange)
+	}
+	if err != nil {
+		return nil, err
 	}
 
-	return result, nil
+	return parseResponse(value, query)
 }
 
 func formatLegend(metric model.Metric, query *PrometheusQuery) string {
@@ -154,7 +194,9 @@ func parseQuery(dsInfo *models.DataSource, queries []*tsdb.Query, queryContext *
 			return nil, err
 		}
 
-		format := queryModel.Model.Get("legendFormat").MustString("")
+		legend := queryModel.Model.Get("legendFormat").MustString("")
+		format := queryModel.Model.Get("format").MustString(timeSeriesFormat)
+		instant := queryModel.Model.Get("instant").MustBool(false)
 
 		start, err := queryContext.TimeRange.ParseFrom()
 		if err != nil {
@@ -178,7 +220,9 @@ func parseQuery(dsInfo *models.DataSource, queries []*tsdb.Query, queryContext *
 		qs = append(qs, &PrometheusQuery{
 			Expr:         expr,
 			Step:         step,
-			LegendFormat: format,
+			LegendFormat: legend,
+			Format:       format,
+			Instant:      instant,
 			Start:        start,
 			End:          end,
 			RefId:        queryModel.RefId,
@@ -191,18 +235,50 @@ func parseQuery(dsInfo *models.DataSource, queries []*tsdb.Query, queryContext *
 func parseResponse(value model.Value, query *PrometheusQuery) (*tsdb.QueryResult, error) {
 	queryRes := tsdb.NewQueryResult()
 
-	data, ok := value.(model.Matrix)
-	if !ok {
+	var matrix model.Matrix
+	switch v := value.(type) {
+	case model.Matrix:
+		matrix = v
+	case model.Vector:
+		matrix = vectorToMatrix(v)
+	case *model.Scalar:
+		matrix = model.Matrix{{
+			Metric: model.Metric{},
+			Values: []model.SamplePair{{Timestamp: v.Timestamp, Value: v.Value}},
+		}}
+	default:
 		return queryRes, fmt.Errorf("Unsupported result format: %s", value.Type().String())
 	}
 
-	for _, v := range data {
+	switch query.Format {
+	case tableFormat:
+		frame := matrixToTable(matrix)
+		frame.RefID = query.RefId
+		encoded, err := frame.MarshalArrow()
+		if err != nil {
+			return nil, err
+		}
+		queryRes.Dataframes = append(queryRes.Dataframes, encoded)
+		return queryRes, nil
+	case heatmapFormat:
+		var err error
+		if matrix, err = bucketsToHeatmap(matrix); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, v := range matrix {
 		series := tsdb.TimeSeries{
 			Name:   formatLegend(v.Metric, query),
 			Tags:   make(map[string]string, len(v.Metric)),
 			Points: make([]tsdb.TimePoint, 0, len(v.Values)),
 		}
 
+		// buckets of a heatmap are named after their upper bound unless a legend is set
+		if query.Format == heatmapFormat && query.LegendFormat == "" {
+			series.Name = string(v.Metric[model.BucketLabel])
+		}
+
 		for k, v := range v.Metric {
 			series.Tags[string(k)] = string(v)
 		}
@@ -216,3 +292,114 @@ func parseResponse(value model.Value, query *PrometheusQuery) (*tsdb.QueryResult
 
 	return queryRes, nil
 }
+
+// vectorToMatrix converts the samples of an instant query to series with a single value
+func vectorToMatrix(vector model.Vector) model.Matrix {
+	matrix := make(model.Matrix, 0, len(vector))
+	for _, sample := range vector {
+		matrix = append(matrix, &model.SampleStream{
+			Metric: sample.Metric,
+			Values: []model.SamplePair{{Timestamp: sample.Timestamp, Value: sample.Value}},
+		})
+	}
+	return matrix
+}
+
+// matrixToTable converts series into a frame with a row per sample, holding the time,
+// one column per label of the series and the value.
+func matrixToTable(matrix model.Matrix) *data.Frame {
+	labelNames := map[string]bool{}
+	for _, stream := range matrix {
+		for name := range stream.Metric {
+			labelNames[string(name)] = true
+		}
+	}
+
+	names := make([]string, 0, len(labelNames))
+	for name := range labelNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	timeField := data.NewField("Time", nil, []time.Time{})
+	labelFields := make([]*data.Field, len(names))
+	for i, name := range names {
+		labelFields[i] = data.NewField(name, nil, []string{})
+	}
+	valueField := data.NewField("Value", nil, []float64{})
+
+	for _, stream := range matrix {
+		for _, pair := range stream.Values {
+			timeField.Append(pair.Timestamp.Time().UTC())
+			for i, name := range names {
+				labelFields[i].Append(string(stream.Metric[model.LabelName(name)]))
+			}
+			valueField.Append(float64(pair.Value))
+		}
+	}
+
+	fields := append([]*data.Field{timeField}, labelFields...)
+	return data.NewFrame("", append(fields, valueField)...)
+}
+
+// bucketsToHeatmap converts the cumulative buckets of histograms into series with the number of
+// observations within each bucket. Buckets are grouped by their labels other than le, so every
+// histogram is converted on its own, and sorted by their upper bound within each histogram.
+func bucketsToHeatmap(matrix model.Matrix) (model.Matrix, error) {
+	bounds := make(map[*model.SampleStream]float64, len(matrix))
+	groups := make(map[model.Fingerprint]model.Matrix)
+	fingerprints := make([]model.Fingerprint, 0)
+	for _, stream := range matrix {
+		le, exists := stream.Metric[model.BucketLabel]
+		if !exists {
+			return nil, fmt.Errorf("series %s has no %s label, heatmap format requires histogram buckets", stream.Metric, model.BucketLabel)
+		}
+
+		bound, err := strconv.ParseFloat(string(le), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid bucket bound %q of series %s", le, stream.Metric)
+		}
+		bounds[stream] = bound
+
+		histogram := stream.Metric.Clone()
+		delete(histogram, model.BucketLabel)
+		fingerprint := histogram.Fingerprint()
+		if _, exists := groups[fingerprint]; !exists {
+			fingerprints = append(fingerprints, fingerprint)
+		}
+		groups[fingerprint] = append(groups[fingerprint], stream)
+	}
+
+	result := make(model.Matrix, 0, len(matrix))
+	for _, fingerprint := range fingerprints {
+		result = append(result, deaccumulateBuckets(groups[fingerprint], bounds)...)
+	}
+
+	return result, nil
+}
+
+// deaccumulateBuckets subtracts the buckets of a single histogram from each other. Samples are
+// matched by timestamp, as bucket series can miss samples, and subtracted by the sample of the
+// closest bucket below that has a sample at the timestamp.
+func deaccumulateBuckets(buckets model.Matrix, bounds map[*model.SampleStream]float64) model.Matrix {
+	sorted := make(model.Matrix, len(buckets))
+	copy(sorted, buckets)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return bounds[sorted[i]] < bounds[sorted[j]]
+	})
+
+	below := make(map[model.Time]model.SampleValue)
+	for i, current := range sorted {
+		values := make([]model.SamplePair, len(current.Values))
+		for j, pair := range current.Values {
+			values[j] = pair
+			if value, exists := below[pair.Timestamp]; exists {
+				values[j].Value -= value
+			}
+			below[pair.Timestamp] = pair.Value
+		}
+		sorted[i] = &model.SampleStream{Metric: current.Metric, Values: values}
+	}
+
+	return sorted
+}
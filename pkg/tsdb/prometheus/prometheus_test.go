@@ -1,13 +1,18 @@
 package prometheus
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/tsdb"
 
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	apiv1 "github.com/prometheus/client_golang/api/prometheus/v1"
 	p "github.com/prometheus/common/model"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -156,5 +161,226 @@ func TestPrometheus(t *testing.T) {
 			})
 		})
 
+		Convey("parsing query model with instant and format", func() {
+			json := `{
+				"expr": "go_goroutines",
+				"format": "table",
+				"instant": true,
+				"refId": "A"
+			}`
+			jsonModel, _ := simplejson.NewJson([]byte(json))
+			queryContext := &tsdb.TsdbQuery{TimeRange: tsdb.NewTimeRange("1h", "now")}
+			queryModels := []*tsdb.Query{
+				{Model: jsonModel},
+			}
+
+			models, err := parseQuery(dsInfo, queryModels, queryContext)
+			So(err, ShouldBeNil)
+			So(models[0].Instant, ShouldBeTrue)
+			So(models[0].Format, ShouldEqual, "table")
+		})
+
+		Convey("parsing response", func() {
+			ts := p.TimeFromUnix(1590000000)
+
+			Convey("of instant query", func() {
+				value := p.Vector{
+					{Metric: p.Metric{"app": "backend"}, Timestamp: ts, Value: 5},
+				}
+
+				res, err := parseResponse(value, &PrometheusQuery{LegendFormat: "{{app}}"})
+				So(err, ShouldBeNil)
+				So(res.Series, ShouldHaveLength, 1)
+				So(res.Series[0].Name, ShouldEqual, "backend")
+				So(res.Series[0].Points, ShouldHaveLength, 1)
+				So(res.Series[0].Points[0][0].Float64, ShouldEqual, 5)
+				So(res.Series[0].Points[0][1].Float64, ShouldEqual, 1590000000000)
+			})
+
+			Convey("with table format", func() {
+				value := p.Matrix{
+					{Metric: p.Metric{"__name__": "up", "job": "grafana"}, Values: []p.SamplePair{{Timestamp: ts, Value: 1}}},
+					{Metric: p.Metric{"__name__": "up", "instance": "localhost:9090"}, Values: []p.SamplePair{{Timestamp: ts, Value: 0}}},
+				}
+
+				res, err := parseResponse(value, &PrometheusQuery{Format: "table", RefId: "A"})
+				So(err, ShouldBeNil)
+				So(res.Series, ShouldHaveLength, 0)
+				So(res.Dataframes, ShouldHaveLength, 1)
+
+				frame, err := data.UnmarshalArrowFrame(res.Dataframes[0])
+				So(err, ShouldBeNil)
+				So(frame.RefID, ShouldEqual, "A")
+				So(frame.Rows(), ShouldEqual, 2)
+
+				names := []string{}
+				for _, f := range frame.Fields {
+					names = append(names, f.Name)
+				}
+				So(names, ShouldResemble, []string{"Time", "__name__", "instance", "job", "Value"})
+				So(frame.Fields[0].At(0), ShouldEqual, ts.Time().UTC())
+				So(frame.Fields[2].At(0), ShouldEqual, "")
+				So(frame.Fields[2].At(1), ShouldEqual, "localhost:9090")
+				So(frame.Fields[3].At(0), ShouldEqual, "grafana")
+				So(frame.Fields[4].At(0), ShouldEqual, float64(1))
+			})
+
+			Convey("with heatmap format", func() {
+				bucket := func(le string, values ...p.SampleValue) *p.SampleStream {
+					stream := &p.SampleStream{Metric: p.Metric{"le": p.LabelValue(le)}}
+					for i, v := range values {
+						stream.Values = append(stream.Values, p.SamplePair{Timestamp: ts.Add(time.Duration(i) * time.Minute), Value: v})
+					}
+					return stream
+				}
+				value := p.Matrix{
+					bucket("+Inf", 10, 20),
+					bucket("1", 4, 5),
+					bucket("0.5", 1, 2),
+				}
+
+				res, err := parseResponse(value, &PrometheusQuery{Format: "heatmap"})
+				So(err, ShouldBeNil)
+				So(res.Series, ShouldHaveLength, 3)
+
+				So(res.Series[0].Name, ShouldEqual, "0.5")
+				So(res.Series[0].Points[0][0].Float64, ShouldEqual, 1)
+				So(res.Series[0].Points[1][0].Float64, ShouldEqual, 2)
+				So(res.Series[1].Name, ShouldEqual, "1")
+				So(res.Series[1].Points[0][0].Float64, ShouldEqual, 3)
+				So(res.Series[1].Points[1][0].Float64, ShouldEqual, 3)
+				So(res.Series[2].Name, ShouldEqual, "+Inf")
+				So(res.Series[2].Points[0][0].Float64, ShouldEqual, 6)
+				So(res.Series[2].Points[1][0].Float64, ShouldEqual, 15)
+			})
+
+			Convey("with heatmap format of buckets with missing samples", func() {
+				bucket := func(le string, values map[int]p.SampleValue) *p.SampleStream {
+					stream := &p.SampleStream{Metric: p.Metric{"le": p.LabelValue(le)}}
+					for i := 0; i < 3; i++ {
+						if v, exists := values[i]; exists {
+							stream.Values = append(stream.Values, p.SamplePair{Timestamp: ts.Add(time.Duration(i) * time.Minute), Value: v})
+						}
+					}
+					return stream
+				}
+				value := p.Matrix{
+					bucket("+Inf", map[int]p.SampleValue{0: 10, 1: 20, 2: 30}),
+					bucket("1", map[int]p.SampleValue{0: 4, 2: 6}),
+					bucket("0.5", map[int]p.SampleValue{0: 1, 1: 2, 2: 3}),
+				}
+
+				res, err := parseResponse(value, &PrometheusQuery{Format: "heatmap"})
+				So(err, ShouldBeNil)
+				So(res.Series, ShouldHaveLength, 3)
+
+				So(res.Series[1].Name, ShouldEqual, "1")
+				So(res.Series[1].Points, ShouldHaveLength, 2)
+				So(res.Series[1].Points[0][0].Float64, ShouldEqual, 3)
+				So(res.Series[1].Points[1][0].Float64, ShouldEqual, 3)
+				So(res.Series[1].Points[1][1].Float64, ShouldEqual, float64(ts.Add(2*time.Minute)))
+
+				So(res.Series[2].Name, ShouldEqual, "+Inf")
+				So(res.Series[2].Points[0][0].Float64, ShouldEqual, 6)
+				So(res.Series[2].Points[1][0].Float64, ShouldEqual, 18)
+				So(res.Series[2].Points[2][0].Float64, ShouldEqual, 24)
+			})
+
+			Convey("with heatmap format of several histograms", func() {
+				bucket := func(job, le string, value p.SampleValue) *p.SampleStream {
+					return &p.SampleStream{
+						Metric: p.Metric{"job": p.LabelValue(job), "le": p.LabelValue(le)},
+						Values: []p.SamplePair{{Timestamp: ts, Value: value}},
+					}
+				}
+				value := p.Matrix{
+					bucket("api", "+Inf", 10),
+					bucket("db", "+Inf", 100),
+					bucket("api", "1", 4),
+					bucket("db", "1", 40),
+				}
+
+				res, err := parseResponse(value, &PrometheusQuery{Format: "heatmap"})
+				So(err, ShouldBeNil)
+				So(res.Series, ShouldHaveLength, 4)
+
+				So(res.Series[0].Tags["job"], ShouldEqual, "api")
+				So(res.Series[0].Name, ShouldEqual, "1")
+				So(res.Series[0].Points[0][0].Float64, ShouldEqual, 4)
+				So(res.Series[1].Tags["job"], ShouldEqual, "api")
+				So(res.Series[1].Name, ShouldEqual, "+Inf")
+				So(res.Series[1].Points[0][0].Float64, ShouldEqual, 6)
+				So(res.Series[2].Tags["job"], ShouldEqual, "db")
+				So(res.Series[2].Name, ShouldEqual, "1")
+				So(res.Series[2].Points[0][0].Float64, ShouldEqual, 40)
+				So(res.Series[3].Tags["job"], ShouldEqual, "db")
+				So(res.Series[3].Name, ShouldEqual, "+Inf")
+				So(res.Series[3].Points[0][0].Float64, ShouldEqual, 60)
+			})
+
+			Convey("with heatmap format of series without buckets", func() {
+				value := p.Matrix{
+					{Metric: p.Metric{"job": "grafana"}, Values: []p.SamplePair{{Timestamp: ts, Value: 1}}},
+				}
+
+				_, err := parseResponse(value, &PrometheusQuery{Format: "heatmap"})
+				So(err, ShouldNotBeNil)
+			})
+		})
+
+		Convey("executing queries", func() {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				_ = r.ParseForm()
+				w.Header().Set("Content-Type", "application/json")
+
+				switch {
+				case r.Form.Get("query") == "broken":
+					w.WriteHeader(http.StatusBadRequest)
+					_, _ = w.Write([]byte(`{"status":"error","errorType":"bad_data","error":"parse error"}`))
+				case r.URL.Path == "/api/v1/query":
+					_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{"job":"grafana"},"value":[1590000000,"1"]}]}}`))
+				default:
+					_, _ = w.Write([]byte(`{"status":"success","data":{"resultType":"matrix","result":[{"metric":{"job":"grafana"},"values":[[1590000000,"1"],[1590000015,"2"]]}]}}`))
+				}
+			}))
+			defer server.Close()
+
+			dsInfo.Url = server.URL
+			executor := &PrometheusExecutor{Transport: http.DefaultTransport}
+			query := &tsdb.TsdbQuery{
+				TimeRange: tsdb.NewTimeRange("1h", "now"),
+				Queries: []*tsdb.Query{
+					{RefId: "A", Model: simplejson.NewFromAny(map[string]interface{}{"expr": "up", "instant": true})},
+					{RefId: "B", Model: simplejson.NewFromAny(map[string]interface{}{"expr": "up"})},
+					{RefId: "C", Model: simplejson.NewFromAny(map[string]interface{}{"expr": "broken"})},
+				},
+			}
+
+			res, err := executor.Query(context.Background(), dsInfo, query)
+			So(err, ShouldBeNil)
+			So(res.Results, ShouldHaveLength, 3)
+
+			So(res.Results["A"].Error, ShouldBeNil)
+			So(res.Results["A"].Series, ShouldHaveLength, 1)
+			So(res.Results["A"].Series[0].Points, ShouldHaveLength, 1)
+
+			So(res.Results["B"].Error, ShouldBeNil)
+			So(res.Results["B"].Series[0].Points, ShouldHaveLength, 2)
+
+			So(res.Results["C"].Error, ShouldNotBeNil)
+			So(res.Results["C"].RefId, ShouldEqual, "C")
+		})
+
+		Convey("returning panics of queries as errors", func() {
+			executor := &PrometheusExecutor{}
+			_, err := executor.runQuery(context.Background(), panicClient{}, &PrometheusQuery{Expr: "up", RefId: "A"})
+			So(err, ShouldNotBeNil)
+			So(err.Error(), ShouldStartWith, "query panic")
+		})
 	})
 }
+
+// panicClient panics on every call as it doesn't implement the API.
+type panicClient struct {
+	apiv1.API
+}
@@ -2,10 +2,19 @@ package prometheus
 
 import "time"
 
+const (
+	// Formats of the result of a query
+	timeSeriesFormat = "time_series"
+	tableFormat      = "table"
+	heatmapFormat    = "heatmap"
+)
+
 type PrometheusQuery struct {
 	Expr         string
 	Step         time.Duration
 	LegendFormat string
+	Format       string
+	Instant      bool
 	Start        time.Time
 	End          time.Time
 	RefId        string
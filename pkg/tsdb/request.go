@@ -2,10 +2,15 @@ package tsdb
 
 import (
 	"context"
+	"fmt"
+	"sync"
 
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 )
 
+var tlog = log.New("tsdb")
+
 type HandleRequestFunc func(ctx context.Context, dsInfo *models.DataSource, req *TsdbQuery) (*Response, error)
 
 func HandleRequest(ctx context.Context, dsInfo *models.DataSource, req *TsdbQuery) (*Response, error) {
@@ -16,3 +21,91 @@ func HandleRequest(ctx context.Context, dsInfo *models.DataSource, req *TsdbQuer
 
 	return endpoint.Query(ctx, dsInfo, req)
 }
+
+// HandleMixedRequest runs every query against the data source set on the query using handleRequest.
+// Queries are grouped by data source, the groups are executed concurrently and the results are
+// merged by RefId. If a request to a data source fails or panics, the error is returned as the
+// result of every query in the group so the results of other data sources are kept.
+func HandleMixedRequest(ctx context.Context, req *TsdbQuery, handleRequest HandleRequestFunc) (*Response, error) {
+	groups := groupQueriesByDataSource(req)
+	if len(groups) == 1 {
+		return handleRequest(ctx, groups[0].dataSource, groups[0].request)
+	}
+
+	result := &Response{Results: make(map[string]*QueryResult)}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, g := range groups {
+		wg.Add(1)
+		go func(g *queryGroup) {
+			defer wg.Done()
+
+			res, err := handleGroupRequest(ctx, g, handleRequest)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			if err != nil {
+				for _, query := range g.request.Queries {
+					result.Results[query.RefId] = &QueryResult{RefId: query.RefId, Error: err}
+				}
+				return
+			}
+
+			for refID, queryResult := range res.Results {
+				result.Results[refID] = queryResult
+			}
+		}(g)
+	}
+	wg.Wait()
+
+	return result, nil
+}
+
+// handleGroupRequest runs the request of a group and returns panics of the data source as
+// errors, as the request goroutine that recovers from panics doesn't run it.
+func handleGroupRequest(ctx context.Context, g *queryGroup, handleRequest HandleRequestFunc) (res *Response, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			tlog.Error("Data source request panic", "datasource", g.dataSource.Name, "error", r, "stack", log.Stack(1))
+			res, err = nil, fmt.Errorf("data source request panic: %v", r)
+		}
+	}()
+
+	return handleRequest(ctx, g.dataSource, g.request)
+}
+
+type queryGroup struct {
+	dataSource *models.DataSource
+	request    *TsdbQuery
+}
+
+// groupQueriesByDataSource splits a request into one request per data
+// source, keeping the order of the data sources and queries.
+func groupQueriesByDataSource(req *TsdbQuery) []*queryGroup {
+	groups := []*queryGroup{}
+	byID := map[int64]*queryGroup{}
+
+	for _, query := range req.Queries {
+		g, exists := byID[query.DataSource.Id]
+		if !exists {
+			g = &queryGroup{
+				dataSource: query.DataSource,
+				request: &TsdbQuery{
+					TimeRange: req.TimeRange,
+					Headers:   req.Headers,
+					Debug:     req.Debug,
+					User:      req.User,
+					NoCache:   req.NoCache,
+				},
+			}
+			byID[query.DataSource.Id] = g
+			groups = append(groups, g)
+		}
+
+		g.request.Queries = append(g.request.Queries, query)
+	}
+
+	return groups
+}
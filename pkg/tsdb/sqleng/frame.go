@@ -0,0 +1,298 @@
+package sqleng
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"github.com/grafana/grafana/pkg/tsdb"
+)
+
+var (
+	timeType   = reflect.TypeOf(time.Time{})
+	stringType = reflect.TypeOf("")
+	floatType  = reflect.TypeOf(float64(0))
+)
+
+// nullTypes maps the nullable scan types of database/sql to the type of their value
+var nullTypes = map[reflect.Type]reflect.Type{
+	reflect.TypeOf(sql.NullString{}):  stringType,
+	reflect.TypeOf(sql.NullInt64{}):   reflect.TypeOf(int64(0)),
+	reflect.TypeOf(sql.NullInt32{}):   reflect.TypeOf(int32(0)),
+	reflect.TypeOf(sql.NullFloat64{}): floatType,
+	reflect.TypeOf(sql.NullBool{}):    reflect.TypeOf(false),
+	reflect.TypeOf(sql.NullTime{}):    timeType,
+	reflect.TypeOf(sql.RawBytes{}):    stringType,
+	reflect.TypeOf([]byte{}):          stringType,
+}
+
+// newFrame creates a frame with a nullable field per column. The type of a field is the type of the
+// values of the column returned by the query result transformer, or the scan type of the column
+// if all values are null. Columns with values of different numeric types become float64 fields,
+// columns with values of other mixed or unsupported types become string fields.
+func newFrame(columnNames []string, columnTypes []*sql.ColumnType, rows []tsdb.RowValues) *data.Frame {
+	frame := data.NewFrame("")
+
+	for col, name := range columnNames {
+		values := make([]interface{}, len(rows))
+		for i, row := range rows {
+			values[i] = normalizeValue(row[col])
+		}
+
+		var scanType reflect.Type
+		if col < len(columnTypes) && columnTypes[col] != nil {
+			scanType = columnTypes[col].ScanType()
+		}
+
+		frame.Fields = append(frame.Fields, newField(name, fieldType(values, scanType), values))
+	}
+
+	return frame
+}
+
+func newField(name string, t reflect.Type, values []interface{}) *data.Field {
+	vector := reflect.MakeSlice(reflect.SliceOf(reflect.PtrTo(t)), len(values), len(values))
+	for i, value := range values {
+		if value == nil {
+			continue
+		}
+
+		ptr := reflect.New(t)
+		v := reflect.ValueOf(value)
+		switch {
+		case v.Type() == t:
+			ptr.Elem().Set(v)
+		case t == stringType:
+			ptr.Elem().SetString(fmt.Sprint(value))
+		default:
+			ptr.Elem().Set(v.Convert(t))
+		}
+		vector.Index(i).Set(ptr)
+	}
+
+	return data.NewField(name, nil, vector.Interface())
+}
+
+// fieldType returns the type of the values of a field of the frame
+func fieldType(values []interface{}, scanType reflect.Type) reflect.Type {
+	var t reflect.Type
+	for _, value := range values {
+		if value == nil {
+			continue
+		}
+
+		vt := reflect.TypeOf(value)
+		switch {
+		case t == nil || t == vt:
+			t = vt
+		case isNumeric(t) && isNumeric(vt):
+			t = floatType
+		default:
+			return stringType
+		}
+	}
+
+	if t == nil {
+		t = scanValueType(scanType)
+	}
+
+	if t == nil || !data.ValidFieldType(reflect.MakeSlice(reflect.SliceOf(reflect.PtrTo(t)), 0, 0).Interface()) {
+		return stringType
+	}
+
+	return t
+}
+
+// scanValueType returns the type of the values of a column from its scan type
+func scanValueType(scanType reflect.Type) reflect.Type {
+	if scanType == nil {
+		return nil
+	}
+
+	for scanType.Kind() == reflect.Ptr {
+		scanType = scanType.Elem()
+	}
+
+	if t, ok := nullTypes[scanType]; ok {
+		return t
+	}
+
+	switch scanType.Kind() {
+	case reflect.Int:
+		return reflect.TypeOf(int64(0))
+	case reflect.Uint:
+		return reflect.TypeOf(uint64(0))
+	case reflect.Struct:
+		// driver specific nullable types such as mysql.NullTime
+		if field, ok := scanType.FieldByName("Time"); ok && field.Type == timeType {
+			return timeType
+		}
+	}
+
+	return scanType
+}
+
+func isNumeric(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// normalizeValue dereferences pointers and converts values to types supported by frames
+func normalizeValue(value interface{}) interface{} {
+	if value == nil {
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Int:
+		return v.Int()
+	case reflect.Uint:
+		return v.Uint()
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return string(v.Bytes())
+		}
+	}
+
+	return v.Interface()
+}
+
+// toTime converts a value of a time column, either a time or an epoch in
+// seconds, milliseconds or nanoseconds, to a time.
+func toTime(value interface{}) (time.Time, bool) {
+	value = normalizeValue(value)
+	if t, ok := value.(time.Time); ok {
+		return t, true
+	}
+
+	if value == nil || !isNumeric(reflect.TypeOf(value)) {
+		return time.Time{}, false
+	}
+
+	ms := tsdb.EpochPrecisionToMs(reflect.ValueOf(value).Convert(floatType).Float())
+	return time.Unix(0, int64(math.Round(ms*float64(time.Millisecond)))).UTC(), true
+}
+
+// convertTimeColumn converts the values of a time column to times, returning an error
+// with the type of the first value that can't be converted.
+func convertTimeColumn(rows []tsdb.RowValues, col int) error {
+	for _, row := range rows {
+		if row[col] == nil {
+			continue
+		}
+
+		t, ok := toTime(row[col])
+		if !ok {
+			return fmt.Errorf("Invalid type for column time, must be of type timestamp or unix timestamp, got: %T %v", row[col], row[col])
+		}
+		row[col] = t
+	}
+	return nil
+}
+
+// sortByTime sorts rows ascending by the value of the time column, keeping the order of rows with the same time
+func sortByTime(rows []tsdb.RowValues, timeIndex int) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		ti, _ := rows[i][timeIndex].(time.Time)
+		tj, _ := rows[j][timeIndex].(time.Time)
+		return ti.Before(tj)
+	})
+}
+
+// fillFrame inserts rows at every interval of the time range without values. The values of the inserted
+// rows are set according to fill mode. The time field must be the first field of the frame.
+func fillFrame(frame *data.Frame, fillMissing *data.FillMissing, interval time.Duration, timeRange *tsdb.TimeRange) (*data.Frame, error) {
+	if interval <= 0 {
+		return frame, nil
+	}
+
+	filled := data.NewFrame(frame.Name)
+	for _, f := range frame.Fields {
+		field := data.NewFieldFromFieldType(f.Type(), 0)
+		field.Name, field.Labels, field.Config = f.Name, f.Labels, f.Config
+		filled.Fields = append(filled.Fields, field)
+	}
+	filled.Meta = frame.Meta
+
+	appendFill := func(t time.Time) error {
+		previous := filled.Fields[0].Len() - 1
+		for i, field := range filled.Fields {
+			field.Extend(1)
+			if i == 0 {
+				field.Set(field.Len()-1, timeValue(field, t))
+				continue
+			}
+
+			value, err := data.GetMissing(fillMissing, field, previous)
+			if err != nil {
+				return err
+			}
+			field.Set(field.Len()-1, value)
+		}
+		return nil
+	}
+
+	// intervals are aligned to the unix epoch
+	align := func(t time.Time) time.Time {
+		return time.Unix(0, t.UnixNano()-t.UnixNano()%int64(interval)).UTC()
+	}
+
+	rowCount, err := frame.RowLen()
+	if err != nil {
+		return nil, err
+	}
+
+	next := align(timeRange.MustGetFrom())
+	for row := 0; row < rowCount; row++ {
+		t, ok := frame.Fields[0].ConcreteAt(row)
+		if !ok {
+			continue
+		}
+		current := t.(time.Time)
+
+		for ; next.Before(current); next = next.Add(interval) {
+			if err := appendFill(next); err != nil {
+				return nil, err
+			}
+		}
+
+		for i, field := range filled.Fields {
+			field.Extend(1)
+			field.Set(field.Len()-1, frame.Fields[i].CopyAt(row))
+		}
+		next = align(current).Add(interval)
+	}
+
+	end := timeRange.MustGetTo()
+	for ; next.Before(end); next = next.Add(interval) {
+		if err := appendFill(next); err != nil {
+			return nil, err
+		}
+	}
+
+	return filled, nil
+}
+
+func timeValue(field *data.Field, t time.Time) interface{} {
+	if field.Nullable() {
+		return &t
+	}
+	return t
+}
@@ -1,17 +1,18 @@
 package sqleng
 
 import (
-	"container/list"
 	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
-	"math"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/setting"
 
 	"github.com/grafana/grafana/pkg/infra/log"
@@ -64,6 +65,10 @@ type sqlQueryEndpoint struct {
 	engine                 *xorm.Engine
 	timeColumnNames        []string
 	metricColumnTypes      []string
+	timeoutStatement       func(timeout time.Duration) string
+	resetTimeoutStatement  string
+	queryTimeout           time.Duration
+	rowLimit               int
 	log                    log.Logger
 }
 
@@ -73,6 +78,12 @@ type SqlQueryEndpointConfiguration struct {
 	ConnectionString  string
 	TimeColumnNames   []string
 	MetricColumnTypes []string
+	// TimeoutStatement returns the statement limiting the execution time of the queries of a
+	// session, for databases that don't stop queries when the context of the query is cancelled.
+	TimeoutStatement func(timeout time.Duration) string
+	// ResetTimeoutStatement restores the execution time limit of the session after the query,
+	// before the connection is returned to the pool and used by other queries.
+	ResetTimeoutStatement string
 }
 
 var NewSqlQueryEndpoint = func(config *SqlQueryEndpointConfiguration, queryResultTransformer SqlQueryResultTransformer, macroEngine SqlMacroEngine, log log.Logger) (tsdb.TsdbQueryEndpoint, error) {
@@ -80,9 +91,17 @@ var NewSqlQueryEndpoint = func(config *SqlQueryEndpointConfiguration, queryResul
 		queryResultTransformer: queryResultTransformer,
 		macroEngine:            macroEngine,
 		timeColumnNames:        []string{"time"},
+		timeoutStatement:       config.TimeoutStatement,
+		resetTimeoutStatement:  config.ResetTimeoutStatement,
+		queryTimeout:           time.Duration(config.Datasource.JsonData.Get("queryTimeout").MustInt(0)) * time.Second,
+		rowLimit:               config.Datasource.JsonData.Get("maxRows").MustInt(rowLimit),
 		log:                    log,
 	}
 
+	if queryEndpoint.rowLimit <= 0 {
+		queryEndpoint.rowLimit = rowLimit
+	}
+
 	if len(config.TimeColumnNames) > 0 {
 		queryEndpoint.timeColumnNames = config.TimeColumnNames
 	}
@@ -120,6 +139,7 @@ var NewSqlQueryEndpoint = func(config *SqlQueryEndpointConfiguration, queryResul
 	return &queryEndpoint, nil
 }
 
+// rowLimit is the default maximum number of rows read from the result of a query
 const rowLimit = 1000000
 
 // Query is the main function for the SqlQueryEndpoint
@@ -159,13 +179,29 @@ func (e *sqlQueryEndpoint) Query(ctx context.Context, dsInfo *models.DataSource,
 
 		go func(rawSQL string, query *tsdb.Query, queryResult *tsdb.QueryResult) {
 			defer wg.Done()
-			session := e.engine.NewSession()
-			defer session.Close()
-			db := session.DB()
 
-			rows, err := db.Query(rawSQL)
+			queryCtx := ctx
+			if e.queryTimeout > 0 {
+				var cancel context.CancelFunc
+				queryCtx, cancel = context.WithTimeout(ctx, e.queryTimeout)
+				defer cancel()
+			}
+
+			conn, err := e.engine.DB().DB.Conn(queryCtx)
+			if err != nil {
+				queryResult.Error = e.transformQueryError(queryCtx, err)
+				return
+			}
+			defer conn.Close()
+
+			if e.setQueryTimeout(queryCtx, conn) {
+				// runs after the rows are closed and before the connection is released
+				defer e.resetQueryTimeout(conn)
+			}
+
+			rows, err := e.runQuery(queryCtx, conn, rawSQL)
 			if err != nil {
-				queryResult.Error = e.queryResultTransformer.TransformQueryError(err)
+				queryResult.Error = e.transformQueryError(queryCtx, err)
 				return
 			}
 
@@ -175,17 +211,17 @@ func (e *sqlQueryEndpoint) Query(ctx context.Context, dsInfo *models.DataSource,
 
 			switch format {
 			case "time_series":
-				err := e.transformToTimeSeries(query, rows, queryResult, tsdbQuery)
-				if err != nil {
-					queryResult.Error = err
-					return
-				}
+				err = e.transformToTimeSeries(query, rows, queryResult, tsdbQuery)
 			case "table":
-				err := e.transformToTable(query, rows, queryResult, tsdbQuery)
-				if err != nil {
-					queryResult.Error = err
-					return
-				}
+				err = e.transformToTable(query, rows, queryResult, tsdbQuery)
+			}
+			if err == nil {
+				// reading the rows stops early if the query is cancelled
+				err = rows.Err()
+			}
+			if err != nil {
+				queryResult.Error = e.transformQueryError(queryCtx, err)
+				return
 			}
 		}(rawSQL, query, queryResult)
 	}
@@ -194,6 +230,71 @@ func (e *sqlQueryEndpoint) Query(ctx context.Context, dsInfo *models.DataSource,
 	return result, nil
 }
 
+// setQueryTimeout limits the execution time of the queries of the session of the connection,
+// and returns true if the limit has to be reset before the connection is released.
+func (e *sqlQueryEndpoint) setQueryTimeout(ctx context.Context, conn *sql.Conn) bool {
+	if e.queryTimeout <= 0 || e.timeoutStatement == nil {
+		return false
+	}
+
+	if _, err := conn.ExecContext(ctx, e.timeoutStatement(e.queryTimeout)); err != nil {
+		// older database versions don't support limiting the execution time,
+		// queries are still cancelled when the context times out
+		e.log.Debug("Failed to set the maximum execution time of the session", "error", err)
+		return false
+	}
+	return true
+}
+
+// resetQueryTimeout restores the execution time limit of the session of the connection, so it
+// doesn't apply to the queries of other data sources using the connection. Connections that
+// can't be reset are closed instead of being returned to the pool.
+func (e *sqlQueryEndpoint) resetQueryTimeout(conn *sql.Conn) {
+	if e.resetTimeoutStatement == "" {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := conn.ExecContext(ctx, e.resetTimeoutStatement); err != nil {
+		e.log.Debug("Failed to reset the maximum execution time of the session", "error", err)
+		_ = conn.Raw(func(driverConn interface{}) error {
+			return driver.ErrBadConn
+		})
+	}
+}
+
+// runQuery executes a query on a connection of its own, so the statement
+// limiting the execution time applies to the session of the query.
+func (e *sqlQueryEndpoint) runQuery(ctx context.Context, conn *sql.Conn, rawSQL string) (*core.Rows, error) {
+	rows, err := conn.QueryContext(ctx, rawSQL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &core.Rows{Rows: rows}, nil
+}
+
+// transformQueryError returns a clear error for queries that are cancelled or take too long
+func (e *sqlQueryEndpoint) transformQueryError(ctx context.Context, err error) error {
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
+		return fmt.Errorf("query exceeded the maximum execution time of %v", e.queryTimeout)
+	case context.Canceled:
+		return fmt.Errorf("query cancelled")
+	}
+
+	return e.queryResultTransformer.TransformQueryError(err)
+}
+
+// setTruncated sets the warning shown when rows of the result are not returned because of the row limit
+func (e *sqlQueryEndpoint) setTruncated(result *tsdb.QueryResult) {
+	result.Meta.Set("truncated", true)
+	result.Meta.Set("rowLimit", e.rowLimit)
+	result.Meta.Set("warning", fmt.Sprintf("Query returned more than %d rows, the result has been truncated", e.rowLimit))
+}
+
 // global macros/substitutions for all sql datasources
 var Interpolate = func(query *tsdb.Query, timeRange *tsdb.TimeRange, sql string) (string, error) {
 	minInterval, err := tsdb.GetIntervalFrom(query.DataSource, query.Model, time.Second*60)
@@ -210,26 +311,45 @@ var Interpolate = func(query *tsdb.Query, timeRange *tsdb.TimeRange, sql string)
 	return sql, nil
 }
 
-func (e *sqlQueryEndpoint) transformToTable(query *tsdb.Query, rows *core.Rows, result *tsdb.QueryResult, tsdbQuery *tsdb.TsdbQuery) error {
+// readRows reads the rows of the result up to the row limit of the data source
+func (e *sqlQueryEndpoint) readRows(rows *core.Rows, result *tsdb.QueryResult) ([]string, []*sql.ColumnType, []tsdb.RowValues, error) {
 	columnNames, err := rows.Columns()
-	columnCount := len(columnNames)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	columnTypes, err := rows.ColumnTypes()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	values := make([]tsdb.RowValues, 0)
+	for rows.Next() {
+		if len(values) >= e.rowLimit {
+			e.setTruncated(result)
+			break
+		}
+
+		row, err := e.queryResultTransformer.TransformQueryResult(columnTypes, rows)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		values = append(values, row)
+	}
+
+	return columnNames, columnTypes, values, nil
+}
 
+func (e *sqlQueryEndpoint) transformToTable(query *tsdb.Query, rows *core.Rows, result *tsdb.QueryResult, tsdbQuery *tsdb.TsdbQuery) error {
+	columnNames, columnTypes, values, err := e.readRows(rows, result)
 	if err != nil {
 		return err
 	}
 
-	rowCount := 0
 	timeIndex := -1
 	timeEndIndex := -1
 
-	table := &tsdb.Table{
-		Columns: make([]tsdb.TableColumn, columnCount),
-		Rows:    make([]tsdb.RowValues, 0),
-	}
-
 	for i, name := range columnNames {
-		table.Columns[i].Text = name
-
 		for _, tc := range e.timeColumnNames {
 			if name == tc {
 				timeIndex = i
@@ -243,53 +363,31 @@ func (e *sqlQueryEndpoint) transformToTable(query *tsdb.Query, rows *core.Rows,
 		}
 	}
 
-	columnTypes, err := rows.ColumnTypes()
-	if err != nil {
-		return err
-	}
-
-	for ; rows.Next(); rowCount++ {
-		if rowCount > rowLimit {
-			return fmt.Errorf("query row limit exceeded, limit %d", rowLimit)
+	// converts columns named time and timeend to times to make native
+	// datetime types and epoch dates work in annotation and table queries.
+	for _, idx := range []int{timeIndex, timeEndIndex} {
+		if idx < 0 {
+			continue
 		}
-
-		values, err := e.queryResultTransformer.TransformQueryResult(columnTypes, rows)
-		if err != nil {
-			return err
+		for _, row := range values {
+			if t, ok := toTime(row[idx]); ok {
+				row[idx] = t
+			}
 		}
-
-		// converts column named time and timeend to unix timestamp in milliseconds
-		// to make native mssql datetime types and epoch dates work in
-		// annotation and table queries.
-		ConvertSqlTimeColumnToEpochMs(values, timeIndex)
-		ConvertSqlTimeColumnToEpochMs(values, timeEndIndex)
-		table.Rows = append(table.Rows, values)
 	}
 
-	result.Tables = append(result.Tables, table)
-	result.Meta.Set("rowCount", rowCount)
-	return nil
+	result.Meta.Set("rowCount", len(values))
+	return e.setFrame(query, result, newFrame(columnNames, columnTypes, values))
 }
 
 func (e *sqlQueryEndpoint) transformToTimeSeries(query *tsdb.Query, rows *core.Rows, result *tsdb.QueryResult, tsdbQuery *tsdb.TsdbQuery) error {
-	pointsBySeries := make(map[string]*tsdb.TimeSeries)
-	seriesByQueryOrder := list.New()
-
-	columnNames, err := rows.Columns()
-	if err != nil {
-		return err
-	}
-
-	columnTypes, err := rows.ColumnTypes()
+	columnNames, columnTypes, values, err := e.readRows(rows, result)
 	if err != nil {
 		return err
 	}
 
-	rowCount := 0
 	timeIndex := -1
 	metricIndex := -1
-	metricPrefix := false
-	var metricPrefixValue string
 
 	// check columns of resultset: a column named time is mandatory
 	// the first text column is treated as metric name unless a column named metric is present
@@ -318,68 +416,26 @@ func (e *sqlQueryEndpoint) transformToTimeSeries(query *tsdb.Query, rows *core.R
 	}
 
 	// use metric column as prefix with multiple value columns
-	if metricIndex != -1 && len(columnNames) > 3 {
-		metricPrefix = true
-	}
+	metricPrefix := metricIndex != -1 && len(columnNames) > 3
 
 	if timeIndex == -1 {
 		return fmt.Errorf("Found no column named %s", strings.Join(e.timeColumnNames, " or "))
 	}
 
-	fillMissing := query.Model.Get("fill").MustBool(false)
-	var fillInterval float64
-	fillValue := null.Float{}
-	fillPrevious := false
-
-	if fillMissing {
-		fillInterval = query.Model.Get("fillInterval").MustFloat64() * 1000
-		switch query.Model.Get("fillMode").MustString() {
-		case "null":
-		case "previous":
-			fillPrevious = true
-		case "value":
-			fillValue.Float64 = query.Model.Get("fillValue").MustFloat64()
-			fillValue.Valid = true
-		}
+	result.Meta.Set("rowCount", len(values))
+	if len(values) == 0 {
+		return nil
 	}
 
-	for rows.Next() {
-		var timestamp float64
-		var value null.Float
-		var metric string
-
-		if rowCount > rowLimit {
-			return fmt.Errorf("query row limit exceeded, limit %d", rowLimit)
-		}
-
-		values, err := e.queryResultTransformer.TransformQueryResult(columnTypes, rows)
-		if err != nil {
-			return err
-		}
-
-		// converts column named time to unix timestamp in milliseconds to make
-		// native mysql datetime types and epoch dates work in
-		// annotation and table queries.
-		ConvertSqlTimeColumnToEpochMs(values, timeIndex)
-
-		switch columnValue := values[timeIndex].(type) {
-		case int64:
-			timestamp = float64(columnValue)
-		case float64:
-			timestamp = columnValue
-		default:
-			return fmt.Errorf("Invalid type for column time, must be of type timestamp or unix timestamp, got: %T %v", columnValue, columnValue)
+	for _, row := range values {
+		// rows without time can't be part of a series
+		if row[timeIndex] == nil {
+			return fmt.Errorf("Invalid type for column time, must be of type timestamp or unix timestamp, got: %T %v", row[timeIndex], row[timeIndex])
 		}
 
 		if metricIndex >= 0 {
-			if columnValue, ok := values[metricIndex].(string); ok {
-				if metricPrefix {
-					metricPrefixValue = columnValue
-				} else {
-					metric = columnValue
-				}
-			} else {
-				return fmt.Errorf("Column metric must be of type %s. metric column name: %s type: %s but datatype is %T", strings.Join(e.metricColumnTypes, ", "), columnNames[metricIndex], columnTypes[metricIndex].DatabaseTypeName(), values[metricIndex])
+			if _, ok := normalizeValue(row[metricIndex]).(string); !ok {
+				return fmt.Errorf("Column metric must be of type %s. metric column name: %s type: %s but datatype is %T", strings.Join(e.metricColumnTypes, ", "), columnNames[metricIndex], columnTypes[metricIndex].DatabaseTypeName(), row[metricIndex])
 			}
 		}
 
@@ -387,85 +443,101 @@ func (e *sqlQueryEndpoint) transformToTimeSeries(query *tsdb.Query, rows *core.R
 			if i == timeIndex || i == metricIndex {
 				continue
 			}
-
-			if value, err = ConvertSqlValueColumnToFloat(col, values[i]); err != nil {
-				return err
-			}
-
-			if metricIndex == -1 {
-				metric = col
-			} else if metricPrefix {
-				metric = metricPrefixValue + " " + col
+			if value := normalizeValue(row[i]); value != nil && !isNumeric(reflect.TypeOf(value)) {
+				return fmt.Errorf("Value column must have numeric datatype, column: %s type: %T value: %v", col, row[i], row[i])
 			}
+		}
+	}
 
-			series, exist := pointsBySeries[metric]
-			if !exist {
-				series = &tsdb.TimeSeries{Name: metric}
-				pointsBySeries[metric] = series
-				seriesByQueryOrder.PushBack(metric)
-			}
+	if err := convertTimeColumn(values, timeIndex); err != nil {
+		return err
+	}
+	sortByTime(values, timeIndex)
 
-			if fillMissing {
-				var intervalStart float64
-				if !exist {
-					intervalStart = float64(tsdbQuery.TimeRange.MustGetFrom().UnixNano() / 1e6)
-				} else {
-					intervalStart = series.Points[len(series.Points)-1][1].Float64 + fillInterval
-				}
+	frame := newFrame(columnNames, columnTypes, values)
+	for i, field := range frame.Fields {
+		if i == timeIndex || i == metricIndex {
+			continue
+		}
 
-				if fillPrevious {
-					if len(series.Points) > 0 {
-						fillValue = series.Points[len(series.Points)-1][0]
-					} else {
-						fillValue.Valid = false
-					}
-				}
+		// values of time series are numbers, regardless of the numeric type of the column
+		columnValues := make([]interface{}, len(values))
+		for j, row := range values {
+			columnValues[j] = normalizeValue(row[i])
+		}
+		frame.Fields[i] = newField(field.Name, floatType, columnValues)
+	}
 
-				// align interval start
-				intervalStart = math.Floor(intervalStart/fillInterval) * fillInterval
+	// the time field is the first field of time series frames
+	timeField := frame.Fields[timeIndex]
+	frame.Fields = append(frame.Fields[:timeIndex], frame.Fields[timeIndex+1:]...)
+	frame.Fields = append([]*data.Field{timeField}, frame.Fields...)
 
-				for i := intervalStart; i < timestamp; i += fillInterval {
-					series.Points = append(series.Points, tsdb.TimePoint{fillValue, null.FloatFrom(i)})
-					rowCount++
-				}
-			}
+	var fillMissing *data.FillMissing
+	var fillInterval time.Duration
+	if query.Model.Get("fill").MustBool(false) {
+		fillInterval = time.Duration(query.Model.Get("fillInterval").MustFloat64() * float64(time.Second))
+		fillMissing = &data.FillMissing{Mode: data.FillModeNull}
+		switch query.Model.Get("fillMode").MustString() {
+		case "previous":
+			fillMissing.Mode = data.FillModePrevious
+		case "value":
+			fillMissing.Mode = data.FillModeValue
+			fillMissing.Value = query.Model.Get("fillValue").MustFloat64()
+		}
+	}
 
-			series.Points = append(series.Points, tsdb.TimePoint{value, null.FloatFrom(timestamp)})
+	if metricIndex >= 0 {
+		metricName := columnNames[metricIndex]
+		if frame, err = data.LongToWide(frame, fillMissing); err != nil {
+			return err
+		}
 
-			if setting.Env == setting.DEV {
-				e.log.Debug("Rows", "metric", metric, "time", timestamp, "value", value)
+		// series are named after the metric, prefixed to the value column with multiple value columns
+		for _, field := range frame.Fields[1:] {
+			metric := field.Labels[metricName]
+			if metricPrefix {
+				field.Name = metric + " " + field.Name
+			} else {
+				field.Name = metric
 			}
+			field.Labels = nil
 		}
 	}
 
-	for elem := seriesByQueryOrder.Front(); elem != nil; elem = elem.Next() {
-		key := elem.Value.(string)
-		result.Series = append(result.Series, pointsBySeries[key])
+	if fillMissing != nil {
+		if frame, err = fillFrame(frame, fillMissing, fillInterval, tsdbQuery.TimeRange); err != nil {
+			return err
+		}
+	}
 
-		if fillMissing {
-			series := pointsBySeries[key]
-			// fill in values from last fetched value till interval end
-			intervalStart := series.Points[len(series.Points)-1][1].Float64
-			intervalEnd := float64(tsdbQuery.TimeRange.MustGetTo().UnixNano() / 1e6)
+	// null values are null points of the series, also when the series are evaluated by alerting
+	for _, field := range frame.Fields[1:] {
+		field.SetConfig(&data.FieldConfig{NullValueMode: data.NullValueModeNull})
+	}
 
-			if fillPrevious {
-				if len(series.Points) > 0 {
-					fillValue = series.Points[len(series.Points)-1][0]
-				} else {
-					fillValue.Valid = false
-				}
-			}
+	if setting.Env == setting.DEV {
+		e.log.Debug("Time series frame", "refId", query.RefId, "fields", len(frame.Fields), "rows", len(values))
+	}
 
-			// align interval start
-			intervalStart = math.Floor(intervalStart/fillInterval) * fillInterval
-			for i := intervalStart + fillInterval; i < intervalEnd; i += fillInterval {
-				series.Points = append(series.Points, tsdb.TimePoint{fillValue, null.FloatFrom(i)})
-				rowCount++
-			}
-		}
+	return e.setFrame(query, result, frame)
+}
+
+// setFrame adds the frame to the result of the query
+func (e *sqlQueryEndpoint) setFrame(query *tsdb.Query, result *tsdb.QueryResult, frame *data.Frame) error {
+	frame.RefID = query.RefId
+	if result.Meta.Get("truncated").MustBool(false) {
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     result.Meta.Get("warning").MustString(),
+		})
 	}
 
-	result.Meta.Set("rowCount", rowCount)
+	encoded, err := frame.MarshalArrow()
+	if err != nil {
+		return err
+	}
+	result.Dataframes = append(result.Dataframes, encoded)
 	return nil
 }
 
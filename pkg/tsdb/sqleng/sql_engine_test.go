@@ -1,14 +1,20 @@
 package sqleng
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"testing"
 	"time"
 
+	"github.com/grafana/grafana-plugin-sdk-go/data"
 	"github.com/grafana/grafana/pkg/components/null"
 	"github.com/grafana/grafana/pkg/components/simplejson"
+	"github.com/grafana/grafana/pkg/infra/log"
 	"github.com/grafana/grafana/pkg/models"
 	"github.com/grafana/grafana/pkg/tsdb"
+	_ "github.com/mattn/go-sqlite3"
+	"xorm.io/core"
 
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -334,3 +340,312 @@ func TestSqlEngine(t *testing.T) {
 		})
 	})
 }
+
+func TestSqlEngineQueryLimits(t *testing.T) {
+	Convey("Query limits", t, func() {
+		newEndpoint := func(id int64, jsonData map[string]interface{}) tsdb.TsdbQueryEndpoint {
+			config := &SqlQueryEndpointConfiguration{
+				DriverName:       "sqlite3",
+				ConnectionString: ":memory:",
+				Datasource:       &models.DataSource{Id: id, JsonData: simplejson.NewFromAny(jsonData)},
+			}
+			endpoint, err := NewSqlQueryEndpoint(config, &testQueryResultTransformer{}, &testMacroEngine{}, log.New("tsdb.sqleng.test"))
+			So(err, ShouldBeNil)
+			return endpoint
+		}
+
+		newQuery := func(rawSQL string) *tsdb.TsdbQuery {
+			return &tsdb.TsdbQuery{
+				TimeRange: tsdb.NewTimeRange("5m", "now"),
+				Queries: []*tsdb.Query{{
+					RefId:      "A",
+					DataSource: &models.DataSource{},
+					Model:      simplejson.NewFromAny(map[string]interface{}{"rawSql": rawSQL, "format": "table"}),
+				}},
+			}
+		}
+
+		Convey("Should truncate results with more rows than the row limit", func() {
+			endpoint := newEndpoint(1001, map[string]interface{}{"maxRows": 10})
+			query := newQuery("WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x+1 FROM c WHERE x < 100) SELECT x FROM c")
+
+			res, err := endpoint.Query(context.Background(), nil, query)
+			So(err, ShouldBeNil)
+
+			result := res.Results["A"]
+			So(result.Error, ShouldBeNil)
+			frame := decodeFrame(result)
+			So(frame.Fields[0].Len(), ShouldEqual, 10)
+			So(frame.Meta.Notices, ShouldHaveLength, 1)
+			So(result.Meta.Get("truncated").MustBool(), ShouldBeTrue)
+			So(result.Meta.Get("rowLimit").MustInt(), ShouldEqual, 10)
+			So(result.Meta.Get("warning").MustString(), ShouldContainSubstring, "truncated")
+		})
+
+		Convey("Should not truncate results within the row limit", func() {
+			endpoint := newEndpoint(1002, map[string]interface{}{"maxRows": 10})
+			query := newQuery("WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x+1 FROM c WHERE x < 10) SELECT x FROM c")
+
+			res, err := endpoint.Query(context.Background(), nil, query)
+			So(err, ShouldBeNil)
+
+			result := res.Results["A"]
+			So(result.Error, ShouldBeNil)
+			So(decodeFrame(result).Fields[0].Len(), ShouldEqual, 10)
+			So(result.Meta.Get("truncated").MustBool(), ShouldBeFalse)
+		})
+
+		Convey("Should stop queries exceeding the maximum execution time", func() {
+			endpoint := newEndpoint(1003, map[string]interface{}{"queryTimeout": 1})
+			query := newQuery("WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x+1 FROM c) SELECT max(x) FROM c")
+
+			res, err := endpoint.Query(context.Background(), nil, query)
+			So(err, ShouldBeNil)
+			So(res.Results["A"].Error, ShouldNotBeNil)
+			So(res.Results["A"].Error.Error(), ShouldEqual, "query exceeded the maximum execution time of 1s")
+		})
+
+		Convey("Should reset the maximum execution time of the session after the query", func() {
+			config := &SqlQueryEndpointConfiguration{
+				DriverName:       "sqlite3",
+				ConnectionString: ":memory:",
+				Datasource:       &models.DataSource{Id: 1005, JsonData: simplejson.NewFromAny(map[string]interface{}{"queryTimeout": 10})},
+				// temporary tables belong to the session of the connection
+				TimeoutStatement: func(timeout time.Duration) string {
+					return fmt.Sprintf("CREATE TEMP TABLE session_timeout AS SELECT %d AS ms", timeout.Milliseconds())
+				},
+				ResetTimeoutStatement: "DROP TABLE temp.session_timeout",
+			}
+			endpoint, err := NewSqlQueryEndpoint(config, &testQueryResultTransformer{}, &testMacroEngine{}, log.New("tsdb.sqleng.test"))
+			So(err, ShouldBeNil)
+			engine := endpoint.(*sqlQueryEndpoint).engine
+			engine.SetMaxOpenConns(1)
+
+			for i := 0; i < 2; i++ {
+				res, err := endpoint.Query(context.Background(), nil, newQuery("SELECT ms FROM session_timeout"))
+				So(err, ShouldBeNil)
+				So(res.Results["A"].Error, ShouldBeNil)
+				So(decodeFrame(res.Results["A"]).Fields[0].Len(), ShouldEqual, 1)
+			}
+
+			var tables int
+			err = engine.DB().QueryRow("SELECT count(*) FROM sqlite_temp_master WHERE name = 'session_timeout'").Scan(&tables)
+			So(err, ShouldBeNil)
+			So(tables, ShouldEqual, 0)
+		})
+
+		Convey("Should not run queries of cancelled requests", func() {
+			endpoint := newEndpoint(1004, map[string]interface{}{})
+			query := newQuery("SELECT 1 AS x")
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+
+			res, err := endpoint.Query(ctx, nil, query)
+			So(err, ShouldBeNil)
+			So(res.Results["A"].Error, ShouldNotBeNil)
+			So(res.Results["A"].Error.Error(), ShouldEqual, "query cancelled")
+		})
+	})
+}
+
+func TestSqlEngineFrames(t *testing.T) {
+	Convey("Data frames", t, func() {
+		config := &SqlQueryEndpointConfiguration{
+			DriverName:        "sqlite3",
+			ConnectionString:  ":memory:",
+			Datasource:        &models.DataSource{Id: 1010, JsonData: simplejson.New()},
+			TimeColumnNames:   []string{"time"},
+			MetricColumnTypes: []string{"TEXT"},
+		}
+		endpoint, err := NewSqlQueryEndpoint(config, &testQueryResultTransformer{}, &testMacroEngine{}, log.New("tsdb.sqleng.test"))
+		So(err, ShouldBeNil)
+
+		from := time.Date(2018, 4, 12, 18, 0, 0, 0, time.UTC)
+		timeRange := tsdb.NewTimeRange(fmt.Sprint(from.Unix()*1000), fmt.Sprint(from.Add(5*time.Minute).Unix()*1000))
+
+		query := func(model map[string]interface{}) *tsdb.QueryResult {
+			res, err := endpoint.Query(context.Background(), nil, &tsdb.TsdbQuery{
+				TimeRange: timeRange,
+				Queries: []*tsdb.Query{{
+					RefId:      "A",
+					DataSource: &models.DataSource{},
+					Model:      simplejson.NewFromAny(model),
+				}},
+			})
+			So(err, ShouldBeNil)
+			So(res.Results["A"].Error, ShouldBeNil)
+			return res.Results["A"]
+		}
+
+		Convey("Table queries should keep the types of columns", func() {
+			result := query(map[string]interface{}{
+				"format": "table",
+				"rawSql": "SELECT 1523556000 AS time, 'a' AS name, 1 AS count, 1.5 AS value, NULL AS empty UNION ALL " +
+					"SELECT 1523556060, 'b', NULL, 2.5, NULL",
+			})
+
+			frame := decodeFrame(result)
+			So(frame.RefID, ShouldEqual, "A")
+			So(frame.Fields, ShouldHaveLength, 5)
+			So(frame.Fields[0].Type(), ShouldEqual, data.FieldTypeNullableTime)
+			So(frame.Fields[1].Type(), ShouldEqual, data.FieldTypeNullableString)
+			So(frame.Fields[2].Type(), ShouldEqual, data.FieldTypeNullableInt64)
+			So(frame.Fields[3].Type(), ShouldEqual, data.FieldTypeNullableFloat64)
+			So(frame.Fields[4].Type(), ShouldEqual, data.FieldTypeNullableString)
+
+			t, _ := frame.Fields[0].ConcreteAt(0)
+			So(t, ShouldEqual, from)
+			count, ok := frame.Fields[2].ConcreteAt(0)
+			So(ok, ShouldBeTrue)
+			So(count, ShouldEqual, 1)
+			_, ok = frame.Fields[2].ConcreteAt(1)
+			So(ok, ShouldBeFalse)
+			So(result.Meta.Get("rowCount").MustInt(), ShouldEqual, 2)
+		})
+
+		Convey("Time series queries with a metric column should return a wide frame sorted by time", func() {
+			result := query(map[string]interface{}{
+				"format": "time_series",
+				"rawSql": "SELECT 1523556060 AS time, 'b' AS metric, 4 AS value UNION ALL " +
+					"SELECT 1523556000, 'a', 1 UNION ALL " +
+					"SELECT 1523556000, 'b', 2 UNION ALL " +
+					"SELECT 1523556060, 'a', 3",
+			})
+
+			frame := decodeFrame(result)
+			So(frame.Fields, ShouldHaveLength, 3)
+			So(frame.Fields[0].Type(), ShouldEqual, data.FieldTypeTime)
+			So(frame.Fields[1].Name, ShouldEqual, "a")
+			So(frame.Fields[2].Name, ShouldEqual, "b")
+			So(frame.Fields[1].Type(), ShouldEqual, data.FieldTypeNullableFloat64)
+			So(frame.Fields[1].Labels, ShouldBeNil)
+
+			series, err := tsdb.FrameToSeriesSlice(frame)
+			So(err, ShouldBeNil)
+			So(series, ShouldHaveLength, 2)
+			So(series[0].Points[0][0].Float64, ShouldEqual, 1)
+			So(series[0].Points[1][0].Float64, ShouldEqual, 3)
+			So(series[1].Points[0][0].Float64, ShouldEqual, 2)
+			So(series[1].Points[1][1].Float64, ShouldEqual, float64(from.Add(time.Minute).Unix()*1000))
+		})
+
+		Convey("Time series queries with a metric column and multiple value columns should prefix the series with the metric", func() {
+			result := query(map[string]interface{}{
+				"format": "time_series",
+				"rawSql": "SELECT 1523556000 AS time, 'a' AS metric, 1 AS min, 2 AS max",
+			})
+
+			frame := decodeFrame(result)
+			So(frame.Fields, ShouldHaveLength, 3)
+			So(frame.Fields[1].Name, ShouldEqual, "a min")
+			So(frame.Fields[2].Name, ShouldEqual, "a max")
+		})
+
+		Convey("Time series queries with non numeric value columns should fail", func() {
+			res, err := endpoint.Query(context.Background(), nil, &tsdb.TsdbQuery{
+				TimeRange: timeRange,
+				Queries: []*tsdb.Query{{
+					RefId:      "A",
+					DataSource: &models.DataSource{},
+					Model: simplejson.NewFromAny(map[string]interface{}{
+						"format": "time_series",
+						"rawSql": "SELECT 1523556000 AS time, 1 AS value, 'a' AS metric, 'b' AS other",
+					}),
+				}},
+			})
+			So(err, ShouldBeNil)
+			So(res.Results["A"].Error, ShouldNotBeNil)
+			So(res.Results["A"].Error.Error(), ShouldContainSubstring, "Value column must have numeric datatype, column: other")
+		})
+
+		Convey("Time series queries with fill should insert rows for missing intervals", func() {
+			model := map[string]interface{}{
+				"format":       "time_series",
+				"rawSql":       "SELECT 1523556060 AS time, 1 AS value UNION ALL SELECT 1523556180, 3",
+				"fill":         true,
+				"fillInterval": 60,
+			}
+
+			Convey("with null values", func() {
+				frame := decodeFrame(query(model))
+				So(frame.Fields[0].Len(), ShouldEqual, 5)
+
+				t, _ := frame.Fields[0].ConcreteAt(0)
+				So(t, ShouldEqual, from)
+				_, ok := frame.Fields[1].ConcreteAt(0)
+				So(ok, ShouldBeFalse)
+				_, ok = frame.Fields[1].ConcreteAt(2)
+				So(ok, ShouldBeFalse)
+				v, _ := frame.Fields[1].ConcreteAt(3)
+				So(v, ShouldEqual, 3)
+
+				// alerting evaluates the null values as null points, not NaN
+				So(frame.Fields[1].Config.NullValueMode, ShouldEqual, data.NullValueModeNull)
+				series, err := tsdb.FrameToSeriesSlice(frame)
+				So(err, ShouldBeNil)
+				So(series[0].Points[0][0].Valid, ShouldBeFalse)
+				So(series[0].Points[3][0].Float64, ShouldEqual, 3)
+			})
+
+			Convey("with previous values", func() {
+				model["fillMode"] = "previous"
+				frame := decodeFrame(query(model))
+				v, _ := frame.Fields[1].ConcreteAt(2)
+				So(v, ShouldEqual, 1)
+				v, _ = frame.Fields[1].ConcreteAt(4)
+				So(v, ShouldEqual, 3)
+			})
+
+			Convey("with a fixed value", func() {
+				model["fillMode"] = "value"
+				model["fillValue"] = 1.5
+				frame := decodeFrame(query(model))
+				So(frame.Fields[1].Type(), ShouldEqual, data.FieldTypeNullableFloat64)
+				v, _ := frame.Fields[1].ConcreteAt(2)
+				So(v, ShouldEqual, 1.5)
+			})
+		})
+
+		Convey("Time series queries without rows should not return frames", func() {
+			result := query(map[string]interface{}{
+				"format": "time_series",
+				"rawSql": "SELECT 1523556000 AS time, 1 AS value WHERE 1 = 0",
+			})
+			So(result.Dataframes, ShouldBeEmpty)
+		})
+	})
+}
+
+func decodeFrame(result *tsdb.QueryResult) *data.Frame {
+	So(result.Dataframes, ShouldHaveLength, 1)
+	frame, err := data.UnmarshalArrowFrame(result.Dataframes[0])
+	So(err, ShouldBeNil)
+	return frame
+}
+
+type testQueryResultTransformer struct{}
+
+func (t *testQueryResultTransformer) TransformQueryResult(columnTypes []*sql.ColumnType, rows *core.Rows) (tsdb.RowValues, error) {
+	values := make([]interface{}, len(columnTypes))
+	pointers := make([]interface{}, len(columnTypes))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+
+	if err := rows.Scan(pointers...); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
+
+func (t *testQueryResultTransformer) TransformQueryError(err error) error {
+	return err
+}
+
+type testMacroEngine struct{}
+
+func (m *testMacroEngine) Interpolate(query *tsdb.Query, timeRange *tsdb.TimeRange, sql string) (string, error) {
+	return sql, nil
+}
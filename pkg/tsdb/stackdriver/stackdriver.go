@@ -657,7 +657,7 @@ func (e *StackdriverExecutor) createRequest(ctx context.Context, dsInfo *models.
 	req.Header.Set("User-Agent", fmt.Sprintf("Grafana/%s", setting.BuildVersion))
 
 	// find plugin
-	plugin, ok := plugins.DataSources[dsInfo.Type]
+	plugin, ok := plugins.DataSources()[dsInfo.Type]
 	if !ok {
 		return nil, errors.New("Unable to find datasource plugin Stackdriver")
 	}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"testing"
 
+	"github.com/grafana/grafana/pkg/components/simplejson"
 	"github.com/grafana/grafana/pkg/models"
 	. "github.com/smartystreets/goconvey/convey"
 )
@@ -61,6 +62,107 @@ func TestMetricQuery(t *testing.T) {
 	})
 }
 
+func TestMixedQuery(t *testing.T) {
+	Convey("When executing request with queries from different data sources", t, func() {
+		testDs := &models.DataSource{Id: 1, Type: "test"}
+		otherDs := &models.DataSource{Id: 2, Type: "other"}
+		req := &TsdbQuery{
+			Queries: []*Query{
+				{RefId: "A", DataSource: testDs},
+				{RefId: "B", DataSource: otherDs},
+				{RefId: "C", DataSource: testDs},
+			},
+		}
+
+		fakeExecutor := registerFakeExecutor()
+		fakeExecutor.Return("A", TimeSeriesSlice{&TimeSeries{Name: "argh"}})
+		fakeExecutor.Return("C", TimeSeriesSlice{&TimeSeries{Name: "carg"}})
+
+		otherExecutor, _ := NewFakeExecutor(nil)
+		otherExecutor.HandleQuery("B", func(query *TsdbQuery) *QueryResult {
+			return &QueryResult{RefId: "B", Series: TimeSeriesSlice{&TimeSeries{Name: "barg"}}, Meta: simplejson.NewFromAny(map[string]interface{}{"queries": len(query.Queries)})}
+		})
+		RegisterTsdbQueryEndpoint("other", func(dsInfo *models.DataSource) (TsdbQueryEndpoint, error) {
+			return otherExecutor, nil
+		})
+
+		res, err := HandleMixedRequest(context.TODO(), req, HandleRequest)
+		So(err, ShouldBeNil)
+
+		Convey("Should run queries against their own data source and merge the results", func() {
+			So(len(res.Results), ShouldEqual, 3)
+			So(res.Results["A"].Series[0].Name, ShouldEqual, "argh")
+			So(res.Results["B"].Series[0].Name, ShouldEqual, "barg")
+			So(res.Results["B"].Meta.Get("queries").MustInt(), ShouldEqual, 1)
+			So(res.Results["C"].Series[0].Name, ShouldEqual, "carg")
+		})
+	})
+
+	Convey("When one of the data sources fails", t, func() {
+		req := &TsdbQuery{
+			Queries: []*Query{
+				{RefId: "A", DataSource: &models.DataSource{Id: 1, Type: "test"}},
+				{RefId: "B", DataSource: &models.DataSource{Id: 2, Type: "unknown"}},
+			},
+		}
+
+		fakeExecutor := registerFakeExecutor()
+		fakeExecutor.Return("A", TimeSeriesSlice{&TimeSeries{Name: "argh"}})
+
+		res, err := HandleMixedRequest(context.TODO(), req, HandleRequest)
+		So(err, ShouldBeNil)
+
+		Convey("Should return the error as the result of its queries", func() {
+			So(res.Results["A"].Error, ShouldBeNil)
+			So(res.Results["A"].Series[0].Name, ShouldEqual, "argh")
+			So(res.Results["B"].Error, ShouldNotBeNil)
+		})
+	})
+
+	Convey("When one of the data sources panics", t, func() {
+		req := &TsdbQuery{
+			Queries: []*Query{
+				{RefId: "A", DataSource: &models.DataSource{Id: 1, Type: "test"}},
+				{RefId: "B", DataSource: &models.DataSource{Id: 2, Type: "panic"}},
+			},
+		}
+
+		fakeExecutor := registerFakeExecutor()
+		fakeExecutor.Return("A", TimeSeriesSlice{&TimeSeries{Name: "argh"}})
+
+		panicExecutor, _ := NewFakeExecutor(nil)
+		panicExecutor.HandleQuery("B", func(query *TsdbQuery) *QueryResult {
+			panic("boom")
+		})
+		RegisterTsdbQueryEndpoint("panic", func(dsInfo *models.DataSource) (TsdbQueryEndpoint, error) {
+			return panicExecutor, nil
+		})
+
+		res, err := HandleMixedRequest(context.TODO(), req, HandleRequest)
+		So(err, ShouldBeNil)
+
+		Convey("Should return the panic as the result of its queries", func() {
+			So(res.Results["A"].Error, ShouldBeNil)
+			So(res.Results["B"].Error, ShouldNotBeNil)
+			So(res.Results["B"].Error.Error(), ShouldContainSubstring, "boom")
+		})
+	})
+
+	Convey("When executing request with queries from one data source", t, func() {
+		req := &TsdbQuery{
+			Queries: []*Query{
+				{RefId: "A", DataSource: &models.DataSource{Id: 12, Type: "unknown"}},
+			},
+		}
+
+		_, err := HandleMixedRequest(context.TODO(), req, HandleRequest)
+
+		Convey("Should return the error of the data source", func() {
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
 func registerFakeExecutor() *FakeExecutor {
 	executor, _ := NewFakeExecutor(nil)
 	RegisterTsdbQueryEndpoint("test", func(dsInfo *models.DataSource) (TsdbQueryEndpoint, error) {
@@ -28,6 +28,9 @@ interface Props {
     isChangingPassword: boolean;
     skipPasswordChange: Function;
     login: (data: FormModel) => void;
+    isTwoFactorRequired: boolean;
+    verifyTwoFactor: (code: string) => void;
+    cancelTwoFactor: () => void;
     disableLoginForm: boolean;
     ldapEnabled: boolean;
     authProxyEnabled: boolean;
@@ -41,15 +44,20 @@ interface Props {
 interface State {
   isLoggingIn: boolean;
   isChangingPassword: boolean;
+  isTwoFactorRequired: boolean;
 }
 
 export class LoginCtrl extends PureComponent<Props, State> {
   result: any = {};
+  // the password of a login waiting for the two-factor code, to ask for a new password afterwards
+  pendingPassword = '';
+  twoFactorToken = '';
   constructor(props: Props) {
     super(props);
     this.state = {
       isLoggingIn: false,
       isChangingPassword: false,
+      isTwoFactorRequired: false,
     };
 
     if (config.loginError) {
@@ -79,13 +87,16 @@ export class LoginCtrl extends PureComponent<Props, State> {
     getBackendSrv()
       .post('/login', formModel)
       .then((result: any) => {
-        this.result = result;
-        if (formModel.password !== 'admin' || config.ldapEnabled || config.authProxyEnabled) {
-          this.toGrafana();
+        if (result.twoFactorRequired) {
+          this.pendingPassword = formModel.password;
+          this.twoFactorToken = result.twoFactorToken;
+          this.setState({
+            isLoggingIn: false,
+            isTwoFactorRequired: true,
+          });
           return;
-        } else {
-          this.changeView();
         }
+        this.loggedIn(result, formModel.password);
       })
       .catch(() => {
         this.setState({
@@ -94,6 +105,47 @@ export class LoginCtrl extends PureComponent<Props, State> {
       });
   };
 
+  verifyTwoFactor = (code: string) => {
+    this.setState({
+      isLoggingIn: true,
+    });
+
+    getBackendSrv()
+      .post('/login/two-factor', { token: this.twoFactorToken, code })
+      .then((result: any) => {
+        this.loggedIn(result, this.pendingPassword);
+      })
+      .catch((err: any) => {
+        // the login has to be started again once the pending login expired or had too many invalid codes
+        if (err.status === 401 && err.data?.message !== 'Invalid two-factor authentication code') {
+          this.cancelTwoFactor();
+          return;
+        }
+        this.setState({
+          isLoggingIn: false,
+        });
+      });
+  };
+
+  cancelTwoFactor = () => {
+    this.pendingPassword = '';
+    this.twoFactorToken = '';
+    this.setState({
+      isLoggingIn: false,
+      isTwoFactorRequired: false,
+    });
+  };
+
+  loggedIn = (result: any, password: string) => {
+    this.result = result;
+    this.pendingPassword = '';
+    if (password !== 'admin' || config.ldapEnabled || config.authProxyEnabled) {
+      this.toGrafana();
+    } else {
+      this.changeView();
+    }
+  };
+
   changeView = () => {
     this.setState({
       isChangingPassword: true,
@@ -122,8 +174,8 @@ export class LoginCtrl extends PureComponent<Props, State> {
 
   render() {
     const { children } = this.props;
-    const { isLoggingIn, isChangingPassword } = this.state;
-    const { login, toGrafana, changePassword } = this;
+    const { isLoggingIn, isChangingPassword, isTwoFactorRequired } = this.state;
+    const { login, toGrafana, changePassword, verifyTwoFactor, cancelTwoFactor } = this;
     const { loginHint, passwordHint, disableLoginForm, ldapEnabled, authProxyEnabled, disableUserSignUp } = config;
 
     return (
@@ -138,6 +190,9 @@ export class LoginCtrl extends PureComponent<Props, State> {
           disableUserSignUp,
           login,
           isLoggingIn,
+          isTwoFactorRequired,
+          verifyTwoFactor,
+          cancelTwoFactor,
           changePassword,
           skipPasswordChange: toGrafana,
           isChangingPassword,
@@ -8,6 +8,7 @@ import { LoginServiceButtons } from './LoginServiceButtons';
 import LoginCtrl from './LoginCtrl';
 import { LoginForm } from './LoginForm';
 import { ChangePassword } from './ChangePassword';
+import { TwoFactorForm } from './TwoFactorForm';
 import { Branding } from 'app/core/components/Branding/Branding';
 import { useStyles } from '@grafana/ui';
 import { GrafanaTheme } from '@grafana/data';
@@ -34,12 +35,15 @@ export const LoginPage: FC = () => {
             disableUserSignUp,
             login,
             isLoggingIn,
+            isTwoFactorRequired,
+            verifyTwoFactor,
+            cancelTwoFactor,
             changePassword,
             skipPasswordChange,
             isChangingPassword,
           }) => (
             <div className={loginStyles.loginOuterBox}>
-              {!isChangingPassword && (
+              {!isChangingPassword && !isTwoFactorRequired && (
                 <div className={`${loginStyles.loginInnerBox} ${isChangingPassword ? 'hidden' : ''}`} id="login-view">
                   {!disableLoginForm && (
                     <LoginForm
@@ -56,6 +60,12 @@ export const LoginPage: FC = () => {
                 </div>
               )}
 
+              {isTwoFactorRequired && !isChangingPassword && (
+                <div className={cx(loginStyles.loginInnerBox, loginStyles.enterAnimation)}>
+                  <TwoFactorForm onSubmit={verifyTwoFactor} onCancel={cancelTwoFactor} isLoggingIn={isLoggingIn} />
+                </div>
+              )}
+
               {isChangingPassword && (
                 <div className={cx(loginStyles.loginInnerBox, loginStyles.enterAnimation)}>
                   <ChangePassword onSubmit={changePassword} onSkip={skipPasswordChange as any} />
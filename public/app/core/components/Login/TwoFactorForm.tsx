@@ -0,0 +1,57 @@
+import React, { FC } from 'react';
+import { Button, Form, Input, Field, LinkButton, VerticalGroup } from '@grafana/ui';
+import { css } from 'emotion';
+import { submitButton } from './LoginForm';
+
+interface Props {
+  onSubmit: (code: string) => void;
+  onCancel: () => void;
+  isLoggingIn: boolean;
+}
+
+interface CodeDTO {
+  code: string;
+}
+
+const wrapperStyles = css`
+  width: 100%;
+  padding-bottom: 16px;
+`;
+
+export const TwoFactorForm: FC<Props> = ({ onSubmit, onCancel, isLoggingIn }) => {
+  const submit = (form: CodeDTO) => {
+    onSubmit(form.code.trim());
+  };
+
+  return (
+    <div className={wrapperStyles}>
+      <Form onSubmit={submit}>
+        {({ register, errors }) => (
+          <>
+            <Field
+              label="Authentication code"
+              description="Enter the code from your authenticator app, or one of your recovery codes."
+              invalid={!!errors.code}
+              error={errors.code?.message}
+            >
+              <Input
+                autoFocus
+                name="code"
+                autoComplete="one-time-code"
+                ref={register({ required: 'Code is required' })}
+              />
+            </Field>
+            <VerticalGroup>
+              <Button type="submit" className={submitButton} disabled={isLoggingIn}>
+                {isLoggingIn ? 'Verifying...' : 'Verify'}
+              </Button>
+              <LinkButton variant="link" onClick={onCancel}>
+                Back to login
+              </LinkButton>
+            </VerticalGroup>
+          </>
+        )}
+      </Form>
+    </div>
+  );
+};
@@ -61,18 +61,24 @@ export class LiveSrv {
   handleMessage(message: any) {
     message = JSON.parse(message);
 
-    if (!message.stream) {
-      console.log('Error: stream message without stream!', message);
+    if (!message.channel) {
+      console.log('Error: channel message without channel!', message);
       return;
     }
 
-    const observer = this.observers[message.stream];
+    const observer = this.observers[message.channel];
     if (!observer) {
-      this.removeObserver(message.stream, null);
+      this.removeObserver(message.channel, null);
       return;
     }
 
-    observer.next(message);
+    if (message.error) {
+      delete this.observers[message.channel];
+      observer.error({ message: message.error });
+      return;
+    }
+
+    observer.next(message.data);
   }
 
   reconnect() {
@@ -85,7 +91,7 @@ export class LiveSrv {
 
     this.getConnection().then((conn: any) => {
       _.each(this.observers, (value, key) => {
-        this.send({ action: 'subscribe', stream: key });
+        this.send({ action: 'subscribe', channel: key });
       });
     });
   }
@@ -94,31 +100,37 @@ export class LiveSrv {
     this.conn.send(JSON.stringify(data));
   }
 
-  addObserver(stream: any, observer: any) {
-    this.observers[stream] = observer;
+  addObserver(channel: string, observer: any) {
+    this.observers[channel] = observer;
 
     this.getConnection().then((conn: any) => {
-      this.send({ action: 'subscribe', stream: stream });
+      this.send({ action: 'subscribe', channel: channel });
     });
   }
 
-  removeObserver(stream: any, observer: any) {
-    console.log('unsubscribe', stream);
-    delete this.observers[stream];
+  removeObserver(channel: string, observer: any) {
+    console.log('unsubscribe', channel);
+    delete this.observers[channel];
 
     this.getConnection().then((conn: any) => {
-      this.send({ action: 'unsubscribe', stream: stream });
+      this.send({ action: 'unsubscribe', channel: channel });
+    });
+  }
+
+  publish(channel: string, data: any) {
+    return this.getConnection().then((conn: any) => {
+      this.send({ action: 'publish', channel: channel, data: data });
     });
   }
 
-  subscribe(streamName: string) {
-    console.log('LiveSrv.subscribe: ' + streamName);
+  subscribe(channel: string) {
+    console.log('LiveSrv.subscribe: ' + channel);
 
     return Observable.create((observer: any) => {
-      this.addObserver(streamName, observer);
+      this.addObserver(channel, observer);
 
       return () => {
-        this.removeObserver(streamName, observer);
+        this.removeObserver(channel, observer);
       };
     });
 
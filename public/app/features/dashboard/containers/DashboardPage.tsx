@@ -5,7 +5,8 @@ import { hot } from 'react-hot-loader';
 import { connect } from 'react-redux';
 import classNames from 'classnames';
 // Services & Utils
-import { createErrorNotification } from 'app/core/copy/appNotification';
+import { Unsubscribable } from 'rxjs';
+import { createErrorNotification, createWarningNotification } from 'app/core/copy/appNotification';
 import { getMessageFromError } from 'app/core/utils/errors';
 import { Branding } from 'app/core/components/Branding/Branding';
 
@@ -32,6 +33,8 @@ import { DashboardModel, PanelModel } from 'app/features/dashboard/state';
 import { InspectTab, PanelInspector } from '../components/Inspector/PanelInspector';
 import { getConfig } from '../../../core/config';
 import { SubMenu } from '../components/SubMenu/SubMenu';
+import { liveSrv } from 'app/core/live/live_srv';
+import { contextSrv } from 'app/core/services/context_srv';
 
 export interface Props {
   urlUid?: string;
@@ -67,6 +70,14 @@ export interface State {
   showLoadingState: boolean;
 }
 
+export interface DashboardEvent {
+  action: 'saved' | 'deleted' | 'provisioned';
+  uid: string;
+  version?: number;
+  userId?: number;
+  userLogin?: string;
+}
+
 export class DashboardPage extends PureComponent<Props, State> {
   state: State = {
     editPanel: null,
@@ -76,6 +87,8 @@ export class DashboardPage extends PureComponent<Props, State> {
     rememberScrollTop: 0,
   };
 
+  dashboardEvents?: Unsubscribable;
+
   async componentDidMount() {
     this.props.initDashboard({
       $injector: this.props.$injector,
@@ -90,12 +103,59 @@ export class DashboardPage extends PureComponent<Props, State> {
   }
 
   componentWillUnmount() {
+    this.unsubscribeDashboardEvents();
+
     if (this.props.dashboard) {
       this.props.cleanUpDashboard();
       this.setPanelFullscreenClass(false);
     }
   }
 
+  subscribeDashboardEvents(dashboard: DashboardModel) {
+    this.unsubscribeDashboardEvents();
+
+    if (!getConfig().featureToggles.live || !dashboard.uid) {
+      return;
+    }
+
+    this.dashboardEvents = liveSrv.subscribe(`grafana/dashboard/${dashboard.uid}`).subscribe({
+      next: (event: DashboardEvent) => this.onDashboardEvent(event),
+      error: () => {},
+    });
+  }
+
+  unsubscribeDashboardEvents() {
+    if (this.dashboardEvents) {
+      this.dashboardEvents.unsubscribe();
+      this.dashboardEvents = undefined;
+    }
+  }
+
+  onDashboardEvent(event: DashboardEvent) {
+    const { dashboard } = this.props;
+    if (!dashboard || event.uid !== dashboard.uid) {
+      return;
+    }
+
+    if (event.action === 'deleted') {
+      this.props.notifyApp(createWarningNotification('Dashboard deleted', 'This dashboard has been deleted.'));
+      return;
+    }
+
+    // ignore the versions of this dashboard saved by the user
+    if (!event.version || event.version <= dashboard.version || event.userId === contextSrv.user.id) {
+      return;
+    }
+
+    const author = event.action === 'provisioned' ? 'Provisioning' : event.userLogin || 'Someone';
+    this.props.notifyApp(
+      createWarningNotification(
+        'Dashboard changed',
+        `${author} saved version ${event.version} of this dashboard. Reload the dashboard to see the changes.`
+      )
+    );
+  }
+
   componentDidUpdate(prevProps: Props) {
     const { dashboard, urlEditPanelId, urlViewPanelId, urlUid } = this.props;
     const { editPanel, viewPanel } = this.state;
@@ -109,6 +169,11 @@ export class DashboardPage extends PureComponent<Props, State> {
       document.title = dashboard.title + ' - ' + Branding.AppTitle;
     }
 
+    // the page is reused when navigating between dashboards
+    if (!prevProps.dashboard || prevProps.dashboard.uid !== dashboard.uid) {
+      this.subscribeDashboardEvents(dashboard);
+    }
+
     // Due to the angular -> react url bridge we can ge an update here with new uid before the container unmounts
     // Can remove this condition after we switch to react router
     if (prevProps.urlUid !== urlUid) {
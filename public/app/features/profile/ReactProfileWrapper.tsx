@@ -4,6 +4,7 @@ import { UserProfileEditForm } from './UserProfileEditForm';
 import { SharedPreferences } from 'app/core/components/SharedPreferences/SharedPreferences';
 import { UserTeams } from './UserTeams';
 import { UserOrganizations } from './UserOrganizations';
+import { UserTwoFactor } from './UserTwoFactor';
 import { config } from '@grafana/runtime';
 import { LoadingPlaceholder } from '@grafana/ui';
 
@@ -22,6 +23,7 @@ export const ReactProfileWrapper = () => (
             />
           )}
           <SharedPreferences resourceUri="user" />
+          {config.twoFactorEnabled && <UserTwoFactor />}
           <UserTeams isLoading={states.loadTeams} loadTeams={api.loadTeams} teams={teams} />
           {!states.loadUser && (
             <UserOrganizations
@@ -0,0 +1,163 @@
+import React, { PureComponent } from 'react';
+import { css } from 'emotion';
+import { getBackendSrv } from '@grafana/runtime';
+import { Button, Field, Form, HorizontalGroup, Input, LoadingPlaceholder } from '@grafana/ui';
+
+interface Enrollment {
+  secret: string;
+  uri: string;
+}
+
+interface CodeDTO {
+  code: string;
+}
+
+interface State {
+  isLoading: boolean;
+  isSaving: boolean;
+  enabled: boolean;
+  required: boolean;
+  enrollment?: Enrollment;
+  recoveryCodes?: string[];
+}
+
+const formStyles = css`
+  max-width: 400px;
+`;
+
+export class UserTwoFactor extends PureComponent<{}, State> {
+  state: State = {
+    isLoading: true,
+    isSaving: false,
+    enabled: false,
+    required: false,
+  };
+
+  componentDidMount() {
+    this.loadStatus();
+  }
+
+  loadStatus = async () => {
+    const status = await getBackendSrv().get('/api/user/two-factor');
+    this.setState({ isLoading: false, enabled: status.enabled, required: status.required });
+  };
+
+  enroll = async () => {
+    this.setState({ isSaving: true });
+    try {
+      const enrollment = await getBackendSrv().post('/api/user/two-factor/enroll');
+      this.setState({ enrollment, recoveryCodes: undefined });
+    } finally {
+      this.setState({ isSaving: false });
+    }
+  };
+
+  activate = async ({ code }: CodeDTO) => {
+    this.setState({ isSaving: true });
+    try {
+      const result = await getBackendSrv().post('/api/user/two-factor/activate', { code: code.trim() });
+      this.setState({ enabled: true, required: false, enrollment: undefined, recoveryCodes: result.recoveryCodes });
+    } finally {
+      this.setState({ isSaving: false });
+    }
+  };
+
+  disable = async ({ code }: CodeDTO) => {
+    this.setState({ isSaving: true });
+    try {
+      await getBackendSrv().post('/api/user/two-factor/disable', { code: code.trim() });
+      this.setState({ enabled: false, recoveryCodes: undefined });
+      await this.loadStatus();
+    } finally {
+      this.setState({ isSaving: false });
+    }
+  };
+
+  renderCodeForm(onSubmit: (form: CodeDTO) => void, label: string, submitText: string) {
+    const { isSaving } = this.state;
+    return (
+      <Form onSubmit={onSubmit}>
+        {({ register, errors }) => (
+          <>
+            <Field label={label} invalid={!!errors.code} error={errors.code?.message}>
+              <Input name="code" autoComplete="one-time-code" ref={register({ required: 'Code is required' })} />
+            </Field>
+            <HorizontalGroup>
+              <Button variant="primary" disabled={isSaving}>
+                {submitText}
+              </Button>
+            </HorizontalGroup>
+          </>
+        )}
+      </Form>
+    );
+  }
+
+  renderContent() {
+    const { enabled, required, enrollment, recoveryCodes, isSaving } = this.state;
+
+    if (recoveryCodes) {
+      return (
+        <>
+          <p>
+            Two-factor authentication is enabled. Save these recovery codes in a safe place, each of them can be used
+            once instead of a code if you lose your authenticator app.
+          </p>
+          <pre>{recoveryCodes.join('\n')}</pre>
+          <Button variant="secondary" onClick={() => this.setState({ recoveryCodes: undefined })}>
+            Done
+          </Button>
+        </>
+      );
+    }
+
+    if (enabled) {
+      return (
+        <>
+          <p>Two-factor authentication is enabled. Enter a code to disable it.</p>
+          {this.renderCodeForm(this.disable, 'Authentication or recovery code', 'Disable')}
+        </>
+      );
+    }
+
+    if (enrollment) {
+      return (
+        <>
+          <p>
+            Add the account to your authenticator app by opening <a href={enrollment.uri}>this link</a> on your phone or
+            by entering the secret <code>{enrollment.secret}</code>, then enter the code the app shows.
+          </p>
+          {this.renderCodeForm(this.activate, 'Authentication code', 'Enable')}
+        </>
+      );
+    }
+
+    return (
+      <>
+        <p>
+          {required
+            ? 'Your organization requires two-factor authentication. Set it up to continue using Grafana.'
+            : 'Protect your account with a code from an authenticator app when you log in.'}
+        </p>
+        <Button variant="primary" onClick={this.enroll} disabled={isSaving}>
+          Set up two-factor authentication
+        </Button>
+      </>
+    );
+  }
+
+  render() {
+    const { isLoading } = this.state;
+
+    if (isLoading) {
+      return <LoadingPlaceholder text="Loading two-factor authentication..." />;
+    }
+
+    return (
+      <>
+        <h3 className="page-sub-heading">Two-factor authentication</h3>
+        <div className={formStyles}>{this.renderContent()}</div>
+      </>
+    );
+  }
+}
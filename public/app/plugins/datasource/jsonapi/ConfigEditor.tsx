@@ -0,0 +1,17 @@
+import React from 'react';
+import { DataSourceHttpSettings } from '@grafana/ui';
+import { DataSourcePluginOptionsEditorProps } from '@grafana/data';
+import { JsonApiOptions } from './types';
+
+export const ConfigEditor = (props: DataSourcePluginOptionsEditorProps<JsonApiOptions>) => {
+  const { options, onOptionsChange } = props;
+
+  return (
+    <DataSourceHttpSettings
+      defaultUrl="http://localhost:8080"
+      dataSourceConfig={options}
+      onChange={onOptionsChange}
+      showAccessOptions={false}
+    />
+  );
+};
@@ -0,0 +1,82 @@
+import _ from 'lodash';
+import {
+  DataQueryRequest,
+  DataQueryResponse,
+  DataSourceApi,
+  DataSourceInstanceSettings,
+  ScopedVars,
+} from '@grafana/data';
+import { getBackendSrv, toDataQueryResponse } from '@grafana/runtime';
+import { TemplateSrv } from 'app/features/templating/template_srv';
+import { JsonApiKeyValue, JsonApiOptions, JsonApiQuery } from './types';
+
+export class JsonApiDatasource extends DataSourceApi<JsonApiQuery, JsonApiOptions> {
+  /** @ngInject */
+  constructor(instanceSettings: DataSourceInstanceSettings<JsonApiOptions>, private templateSrv: TemplateSrv) {
+    super(instanceSettings);
+  }
+
+  query(options: DataQueryRequest<JsonApiQuery>): Promise<DataQueryResponse> {
+    const queries = _.filter(options.targets, target => !target.hide).map(target =>
+      this.interpolateQuery(target, options.scopedVars)
+    );
+
+    if (queries.length === 0) {
+      return Promise.resolve({ data: [] });
+    }
+
+    return this.doRequest(options.range.from.valueOf(), options.range.to.valueOf(), queries, options);
+  }
+
+  testDatasource() {
+    const now = Date.now();
+    const query = { refId: 'test', datasourceId: this.id, method: 'GET', path: '', fields: [] };
+
+    return this.doRequest(now - 5 * 60 * 1000, now, [query]).then(res => {
+      if (res.error) {
+        return { status: 'error', message: res.error.message };
+      }
+      return { status: 'success', message: 'Data source is working' };
+    });
+  }
+
+  interpolateQuery(target: JsonApiQuery, scopedVars: ScopedVars) {
+    const replace = (value?: string) => this.templateSrv.replace(value || '', scopedVars);
+    const replaceValues = (list?: JsonApiKeyValue[]) =>
+      (list || []).map(item => ({ key: item.key, value: replace(item.value) }));
+
+    return {
+      refId: target.refId,
+      datasourceId: this.id,
+      method: target.method || 'GET',
+      path: replace(target.path),
+      params: replaceValues(target.params),
+      headers: replaceValues(target.headers),
+      body: replace(target.body),
+      fields: target.fields || [],
+    };
+  }
+
+  doRequest(from: number, to: number, queries: any[], options?: DataQueryRequest<JsonApiQuery>) {
+    return getBackendSrv()
+      .datasourceRequest({
+        url: '/api/tsdb/query',
+        method: 'POST',
+        data: {
+          from: from.toString(),
+          to: to.toString(),
+          queries: queries.map(query => ({
+            ...query,
+            intervalMs: options?.intervalMs,
+            maxDataPoints: options?.maxDataPoints,
+          })),
+        },
+      })
+      .then(toDataQueryResponse, (err: any) => {
+        if (err.data && err.data.results) {
+          return toDataQueryResponse(err);
+        }
+        throw err;
+      });
+  }
+}
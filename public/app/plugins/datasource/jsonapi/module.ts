@@ -0,0 +1,8 @@
+import { DataSourcePlugin } from '@grafana/data';
+import { JsonApiDatasource } from './datasource';
+import { JsonApiQueryCtrl } from './query_ctrl';
+import { ConfigEditor } from './ConfigEditor';
+
+export const plugin = new DataSourcePlugin(JsonApiDatasource)
+  .setQueryCtrl(JsonApiQueryCtrl)
+  .setConfigEditor(ConfigEditor);
@@ -0,0 +1,47 @@
+import { QueryCtrl } from 'app/plugins/sdk';
+import { auto } from 'angular';
+import { JsonApiKeyValue, JsonApiQuery } from './types';
+
+export class JsonApiQueryCtrl extends QueryCtrl {
+  static templateUrl = 'partials/query.editor.html';
+
+  target: JsonApiQuery;
+  methods = ['GET', 'POST'];
+  fieldTypes = [
+    { text: 'Auto', value: '' },
+    { text: 'Time', value: 'time' },
+    { text: 'Number', value: 'number' },
+    { text: 'String', value: 'string' },
+    { text: 'Boolean', value: 'boolean' },
+  ];
+  showHelp: boolean;
+
+  /** @ngInject */
+  constructor($scope: any, $injector: auto.IInjectorService) {
+    super($scope, $injector);
+
+    this.target.method = this.target.method || 'GET';
+    this.target.path = this.target.path || '';
+    this.target.params = this.target.params || [];
+    this.target.headers = this.target.headers || [];
+    this.target.fields = this.target.fields || [{ name: '', jsonPath: '$', type: '' }];
+  }
+
+  addKeyValue(list: JsonApiKeyValue[]) {
+    list.push({ key: '', value: '' });
+  }
+
+  removeKeyValue(list: JsonApiKeyValue[], index: number) {
+    list.splice(index, 1);
+    this.refresh();
+  }
+
+  addField() {
+    this.target.fields.push({ name: '', jsonPath: '$', type: '' });
+  }
+
+  removeField(index: number) {
+    this.target.fields.splice(index, 1);
+    this.refresh();
+  }
+}
@@ -0,0 +1,25 @@
+import { DataQuery, DataSourceJsonData } from '@grafana/data';
+
+export type JsonApiFieldType = '' | 'time' | 'number' | 'string' | 'boolean';
+
+export interface JsonApiKeyValue {
+  key: string;
+  value: string;
+}
+
+export interface JsonApiField {
+  name?: string;
+  jsonPath: string;
+  type?: JsonApiFieldType;
+}
+
+export interface JsonApiQuery extends DataQuery {
+  method?: 'GET' | 'POST';
+  path?: string;
+  params?: JsonApiKeyValue[];
+  headers?: JsonApiKeyValue[];
+  body?: string;
+  fields?: JsonApiField[];
+}
+
+export interface JsonApiOptions extends DataSourceJsonData {}
@@ -1,4 +1,5 @@
 import _ from 'lodash';
+import { arrowTableToDataFrame, base64StringToArrowTable, DataFrame } from '@grafana/data';
 
 export default class ResponseParser {
   processQueryResult(res: any) {
@@ -11,6 +12,10 @@ export default class ResponseParser {
     for (const key in res.data.results) {
       const queryRes = res.data.results[key];
 
+      if (queryRes.dataframes) {
+        data.push(...this.getFrames(queryRes));
+      }
+
       if (queryRes.series) {
         for (const series of queryRes.series) {
           data.push({
@@ -35,13 +40,39 @@ export default class ResponseParser {
     return { data: data };
   }
 
+  getFrames(queryRes: any): DataFrame[] {
+    return queryRes.dataframes.map((encoded: string) => {
+      const frame = arrowTableToDataFrame(base64StringToArrowTable(encoded));
+      if (!frame.refId) {
+        frame.refId = queryRes.refId;
+      }
+      return frame;
+    });
+  }
+
+  // returns the first table of a query result, either a table or the rows of a data frame
+  getTable(queryRes: any) {
+    if (!queryRes.dataframes) {
+      return queryRes.tables[0];
+    }
+
+    const frame = this.getFrames(queryRes)[0];
+    const rows = [];
+    for (let i = 0; i < frame.length; i++) {
+      rows.push(frame.fields.map(field => field.values.get(i)));
+    }
+
+    return { columns: frame.fields.map(field => ({ text: field.name })), rows };
+  }
+
   parseMetricFindQueryResult(refId: string, results: any) {
     if (!results || results.data.length === 0 || results.data.results[refId].meta.rowCount === 0) {
       return [];
     }
 
-    const columns = results.data.results[refId].tables[0].columns;
-    const rows = results.data.results[refId].tables[0].rows;
+    const table = this.getTable(results.data.results[refId]);
+    const columns = table.columns;
+    const rows = table.rows;
     const textColIndex = this.findColIndex(columns, '__text');
     const valueColIndex = this.findColIndex(columns, '__value');
 
@@ -101,7 +132,7 @@ export default class ResponseParser {
   }
 
   transformAnnotationResponse(options: any, data: any) {
-    const table = data.data.results[options.annotation.name].tables[0];
+    const table = this.getTable(data.data.results[options.annotation.name]);
 
     let timeColumnIndex = -1;
     let timeEndColumnIndex = -1;